@@ -2,13 +2,19 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/tmc/nlm/internal/auth"
 	"github.com/tmc/nlm/internal/authuser"
@@ -42,6 +48,7 @@ type authOptions struct {
 	KeepOpenSeconds int
 	RemoteCDPURL    string
 	AuthUser        string // Google account index (0, 1, 2, ...) for multi-account profiles
+	SaveAs          string // Stored account to write the credentials to
 }
 
 func handleAuthWithOptions(args []string, globals globalOptions) (string, string, error) {
@@ -76,6 +83,18 @@ func handleDecodedAuth(args authArgs) (string, string, error) {
 		}
 	}
 
+	if name := args.Options.SaveAs; name != "" {
+		if !validAccountName(name) {
+			return "", "", badArgsf("invalid account name %q: use letters, digits, '.', '_' and '-'", name)
+		}
+		// Write to the named account, and drop the session state loaded
+		// from the account in use so it is not copied into the new one.
+		account = name
+		for _, key := range []string{"NLM_BROWSER_PROFILE", "NLM_SESSION_ID", "NLM_BL_PARAM", "NLM_SIGNALER_AUTH"} {
+			os.Unsetenv(key)
+		}
+	}
+
 	isTty := term.IsTerminal(int(os.Stdin.Fd()))
 
 	if globals.debug {
@@ -196,6 +215,9 @@ func handleDecodedAuth(args authArgs) (string, string, error) {
 	if err := persistSignalerAuthorization(authData.SignalerAuth); err != nil {
 		return "", "", err
 	}
+	if err := persistCookieExpiry(authData.CookieExpiry); err != nil {
+		return "", "", err
+	}
 	return authToken, cookies, nil
 }
 
@@ -273,11 +295,14 @@ func detectAuthInfo(cmd string) (string, string, error) {
 	return persistAuthToDisk(cookies, authToken, "", "", "", "")
 }
 
+// persistAuthToDisk writes credentials for the selected account through the
+// configured credential store.
 func persistAuthToDisk(cookies, authToken, profileName, sessionID, blParam, authUser string) (string, string, error) {
-	homeDir, err := os.UserHomeDir()
+	store, err := openCredentialStore()
 	if err != nil {
-		return "", "", fmt.Errorf("get home dir: %w", err)
+		return "", "", err
 	}
+	name := selectedAccount()
 
 	existing := readStoredEnv()
 	if profileName == "" {
@@ -291,15 +316,12 @@ func persistAuthToDisk(cookies, authToken, profileName, sessionID, blParam, auth
 	}
 	signalerAuth := firstNonEmpty(os.Getenv("NLM_SIGNALER_AUTH"), existing["NLM_SIGNALER_AUTH"])
 	authUser = authuser.Normalize(authUser)
-
-	// Create .nlm directory if it doesn't exist
-	nlmDir := filepath.Join(homeDir, ".nlm")
-	if err := os.MkdirAll(nlmDir, 0700); err != nil {
-		return "", "", fmt.Errorf("create .nlm directory: %w", err)
+	// Recorded cookie expiries describe only the cookies they came with.
+	var cookieExpires string
+	if cookies == existing["NLM_COOKIES"] {
+		cookieExpires = existing["NLM_COOKIE_EXPIRES"]
 	}
 
-	// Create or update env file
-	envFile := filepath.Join(nlmDir, "env")
 	values := map[string]string{
 		"NLM_COOKIES":         cookies,
 		"NLM_AUTH_TOKEN":      authToken,
@@ -308,8 +330,9 @@ func persistAuthToDisk(cookies, authToken, profileName, sessionID, blParam, auth
 		"NLM_BL_PARAM":        blParam,
 		"NLM_SIGNALER_AUTH":   signalerAuth,
 		"NLM_AUTHUSER":        authUser,
+		"NLM_COOKIE_EXPIRES":  cookieExpires,
 	}
-	if err := writeStoredEnvFile(envFile, values); err != nil {
+	if err := store.Save(name, values); err != nil {
 		return "", "", err
 	}
 
@@ -319,7 +342,7 @@ func persistAuthToDisk(cookies, authToken, profileName, sessionID, blParam, auth
 		}
 	}
 
-	fmt.Fprintf(os.Stderr, "nlm: auth info written to %s\n", envFile)
+	fmt.Fprintf(os.Stderr, "nlm: auth info written to %s\n", store.Location(name))
 	return authToken, cookies, nil
 }
 
@@ -328,11 +351,10 @@ func persistSignalerAuthorization(authz string) error {
 	if authz == "" {
 		return nil
 	}
-	homeDir, err := os.UserHomeDir()
+	store, err := openCredentialStore()
 	if err != nil {
-		return fmt.Errorf("get home dir: %w", err)
+		return err
 	}
-	envFile := filepath.Join(homeDir, ".nlm", "env")
 	values := readStoredEnv()
 	if values == nil {
 		values = make(map[string]string)
@@ -344,7 +366,7 @@ func persistSignalerAuthorization(authz string) error {
 	values["NLM_BL_PARAM"] = firstNonEmpty(os.Getenv("NLM_BL_PARAM"), values["NLM_BL_PARAM"])
 	values["NLM_AUTHUSER"] = firstNonEmpty(os.Getenv("NLM_AUTHUSER"), values["NLM_AUTHUSER"])
 	values["NLM_SIGNALER_AUTH"] = authz
-	if err := writeStoredEnvFile(envFile, values); err != nil {
+	if err := store.Save(selectedAccount(), values); err != nil {
 		return err
 	}
 	if err := os.Setenv("NLM_SIGNALER_AUTH", authz); err != nil {
@@ -353,9 +375,191 @@ func persistSignalerAuthorization(authz string) error {
 	return nil
 }
 
+// persistCookieExpiry records when the stored cookies expire, as reported
+// by the browser they were harvested from.
+func persistCookieExpiry(expiry map[string]time.Time) error {
+	if len(expiry) == 0 {
+		return nil
+	}
+	return updateStoredCredentials(map[string]string{"NLM_COOKIE_EXPIRES": formatCookieExpiry(expiry)})
+}
+
+// updateStoredCredentials sets variables in the selected account's stored
+// credentials and in the environment. It does nothing if the account has
+// no stored credentials.
+func updateStoredCredentials(updates map[string]string) error {
+	store, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+	values := readStoredEnv()
+	if values == nil {
+		return nil
+	}
+	maps.Copy(values, updates)
+	if err := store.Save(selectedAccount(), values); err != nil {
+		return err
+	}
+	for key, value := range updates {
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// credentialStore keeps the stored credentials of each account: a map of
+// the NLM_* variables that ~/.nlm/env has always held. The account "" is
+// the default account.
+type credentialStore interface {
+	// Name is the backend's name as accepted by NLM_CREDENTIAL_STORE.
+	Name() string
+	// Load returns an account's credentials, or an error wrapping
+	// fs.ErrNotExist when the account has none.
+	Load(account string) (map[string]string, error)
+	Save(account string, values map[string]string) error
+	Delete(account string) error
+	// Accounts lists the accounts with credentials, the default first.
+	Accounts() ([]string, error)
+	// Location describes where an account's credentials live, for messages.
+	Location(account string) string
+}
+
+// Credential store backends.
+const (
+	fileStoreName      = "file"
+	keyringStoreName   = "keyring"
+	encryptedStoreName = "encrypted"
+)
+
+var credentialStoreNames = []string{fileStoreName, keyringStoreName, encryptedStoreName}
+
+// newCredentialStore returns the named backend.
+func newCredentialStore(name string) (credentialStore, error) {
+	switch name {
+	case fileStoreName:
+		return fileStore{}, nil
+	case keyringStoreName:
+		return newKeyringStore()
+	case encryptedStoreName:
+		return encryptedStore{}, nil
+	}
+	return nil, fmt.Errorf("unknown credential store %q (want %s)", name, strings.Join(credentialStoreNames, ", "))
+}
+
+// configuredCredentialStore returns the name of the backend in use:
+// NLM_CREDENTIAL_STORE, else the one 'nlm auth migrate' recorded, else the
+// plaintext file store.
+func configuredCredentialStore() string {
+	if name := strings.TrimSpace(os.Getenv("NLM_CREDENTIAL_STORE")); name != "" {
+		return name
+	}
+	if path, err := credentialStorePath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			if name := strings.TrimSpace(string(data)); name != "" {
+				return name
+			}
+		}
+	}
+	return fileStoreName
+}
+
+func credentialStorePath() (string, error) {
+	dir, err := nlmConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "credential_store"), nil
+}
+
+// openCredentialStore returns the backend in use.
+func openCredentialStore() (credentialStore, error) {
+	return newCredentialStore(configuredCredentialStore())
+}
+
+// fileStore keeps credentials in plaintext files: ~/.nlm/env for the
+// default account and ~/.nlm/profiles/<name>.env for the others.
+type fileStore struct{}
+
+func (fileStore) Name() string { return fileStoreName }
+
+func (fileStore) Load(account string) (map[string]string, error) {
+	path, err := accountPath(account)
+	if err != nil {
+		return nil, err
+	}
+	return readStoredEnvFile(path)
+}
+
+func (fileStore) Save(account string, values map[string]string) error {
+	path, err := accountPath(account)
+	if err != nil {
+		return err
+	}
+	// Create .nlm (and profiles) directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create .nlm directory: %w", err)
+	}
+	return writeStoredEnvFile(path, values)
+}
+
+func (fileStore) Delete(account string) error {
+	path, err := accountPath(account)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (fileStore) Accounts() ([]string, error) {
+	return accountFiles("")
+}
+
+func (fileStore) Location(account string) string {
+	path, err := accountPath(account)
+	if err != nil {
+		return account
+	}
+	return path
+}
+
+// accountFiles lists the accounts with a credentials file ending in
+// suffix: the default account's ~/.nlm/env<suffix>, then named accounts'
+// ~/.nlm/profiles/<name>.env<suffix> in name order.
+func accountFiles(suffix string) ([]string, error) {
+	dir, err := nlmConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	var accounts []string
+	if _, err := os.Stat(filepath.Join(dir, "env"+suffix)); err == nil {
+		accounts = append(accounts, defaultAccount)
+	}
+	ext := ".env" + suffix
+	matches, err := filepath.Glob(filepath.Join(dir, "profiles", "*"+ext))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	for _, path := range matches {
+		if name := strings.TrimSuffix(filepath.Base(path), ext); validAccountName(name) {
+			accounts = append(accounts, name)
+		}
+	}
+	return accounts, nil
+}
+
 func writeStoredEnvFile(path string, values map[string]string) error {
-	content := fmt.Sprintf(
-		"NLM_COOKIES=%q\nNLM_AUTH_TOKEN=%q\nNLM_BROWSER_PROFILE=%q\nNLM_SESSION_ID=%q\nNLM_BL_PARAM=%q\nNLM_SIGNALER_AUTH=%q\nNLM_AUTHUSER=%q\n",
+	if err := os.WriteFile(path, formatStoredEnv(values), 0600); err != nil {
+		return fmt.Errorf("write env file: %w", err)
+	}
+	return nil
+}
+
+// formatStoredEnv renders credentials in the ~/.nlm/env format.
+func formatStoredEnv(values map[string]string) []byte {
+	return fmt.Appendf(nil,
+		"NLM_COOKIES=%q\nNLM_AUTH_TOKEN=%q\nNLM_BROWSER_PROFILE=%q\nNLM_SESSION_ID=%q\nNLM_BL_PARAM=%q\nNLM_SIGNALER_AUTH=%q\nNLM_AUTHUSER=%q\nNLM_COOKIE_EXPIRES=%q\n",
 		values["NLM_COOKIES"],
 		values["NLM_AUTH_TOKEN"],
 		values["NLM_BROWSER_PROFILE"],
@@ -363,11 +567,8 @@ func writeStoredEnvFile(path string, values map[string]string) error {
 		values["NLM_BL_PARAM"],
 		values["NLM_SIGNALER_AUTH"],
 		values["NLM_AUTHUSER"],
+		values["NLM_COOKIE_EXPIRES"],
 	)
-	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
-		return fmt.Errorf("write env file: %w", err)
-	}
-	return nil
 }
 
 func loadStoredEnv() {
@@ -381,17 +582,52 @@ func loadStoredEnv() {
 	}
 }
 
+// readStoredEnv reads the selected account's stored credentials. Failures
+// other than a missing account are reported on stderr once per run.
 func readStoredEnv() map[string]string {
-	home, err := os.UserHomeDir()
+	store, err := openCredentialStore()
+	if err == nil {
+		var values map[string]string
+		if values, err = store.Load(selectedAccount()); err == nil {
+			return values
+		}
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		storedEnvWarning.Do(func() {
+			fmt.Fprintf(os.Stderr, "nlm: read stored credentials: %v\n", err)
+		})
+	}
+	return nil
+}
+
+var storedEnvWarning sync.Once
+
+// readStoredProfile reads the credentials stored for a named account.
+func readStoredProfile(name string) (map[string]string, error) {
+	if !validAccountName(name) {
+		return nil, fmt.Errorf("invalid profile name %q", name)
+	}
+	store, err := openCredentialStore()
 	if err != nil {
-		return nil
+		return nil, err
+	}
+	values, err := store.Load(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("profile %q not found", name)
 	}
+	return values, err
+}
 
-	data, err := os.ReadFile(filepath.Join(home, ".nlm", "env"))
+func readStoredEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil
+		return nil, err
 	}
+	return parseStoredEnv(data), nil
+}
 
+// parseStoredEnv reads credentials in the ~/.nlm/env format.
+func parseStoredEnv(data []byte) map[string]string {
 	values := make(map[string]string)
 	s := bufio.NewScanner(strings.NewReader(string(data)))
 	for s.Scan() {
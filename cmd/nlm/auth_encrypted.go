@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// Encrypted credential files are encryptedMagic, a random salt and nonce,
+// and the AES-256-GCM sealed ~/.nlm/env text. The key is derived from the
+// passphrase with PBKDF2-SHA256.
+const (
+	encryptedMagic      = "nlm-encrypted-v1\n"
+	encryptedSaltSize   = 16
+	encryptedIterations = 600_000
+	encryptedSuffix     = ".enc"
+)
+
+var errWrongPassphrase = errors.New("wrong credential passphrase (or corrupt credentials file)")
+
+// encryptedStore keeps credentials in files like the file store's, with
+// an .enc suffix, encrypted with a passphrase from NLM_CREDENTIAL_PASSPHRASE
+// or the terminal.
+type encryptedStore struct{}
+
+func (encryptedStore) Name() string { return encryptedStoreName }
+
+func (encryptedStore) Load(account string) (map[string]string, error) {
+	path, err := encryptedPath(account)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := credentialPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := openCredentials(data, passphrase)
+	if errors.Is(err, errWrongPassphrase) {
+		forgetPassphrase()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseStoredEnv(plain), nil
+}
+
+func (encryptedStore) Save(account string, values map[string]string) error {
+	path, err := encryptedPath(account)
+	if err != nil {
+		return err
+	}
+	existing, err := accountFiles(encryptedSuffix)
+	if err != nil {
+		return err
+	}
+	// The first file written sets the passphrase, so ask for it twice.
+	passphrase, err := credentialPassphrase(len(existing) == 0)
+	if err != nil {
+		return err
+	}
+	// Later files must share it: a mistyped passphrase would otherwise
+	// replace the account's credentials with ones no other passphrase
+	// opens, and nothing asked twice to catch it.
+	if err := checkPassphrase(path, existing, passphrase); err != nil {
+		return err
+	}
+	data, err := sealCredentials(formatStoredEnv(values), passphrase)
+	if err != nil {
+		return err
+	}
+	if err := replaceFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write encrypted credentials: %w", err)
+	}
+	return nil
+}
+
+// checkPassphrase opens the file at path, or failing that another
+// account's encrypted file, with passphrase. It returns errWrongPassphrase,
+// and forgets the passphrase, if that file does not open.
+func checkPassphrase(path string, accounts []string, passphrase string) error {
+	paths := []string{path}
+	for _, a := range accounts {
+		p, err := encryptedPath(a)
+		if err == nil && p != path {
+			paths = append(paths, p)
+		}
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := openCredentials(data, passphrase); errors.Is(err, errWrongPassphrase) {
+			forgetPassphrase()
+			return err
+		}
+		return nil
+	}
+	return nil
+}
+
+// replaceFile writes data to a temporary file beside path and renames
+// it into place, so an interrupted write leaves the old file whole.
+func replaceFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (encryptedStore) Delete(account string) error {
+	path, err := encryptedPath(account)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (encryptedStore) Accounts() ([]string, error) {
+	return accountFiles(encryptedSuffix)
+}
+
+func (encryptedStore) Location(account string) string {
+	path, err := encryptedPath(account)
+	if err != nil {
+		return account
+	}
+	return path + " (encrypted)"
+}
+
+func encryptedPath(account string) (string, error) {
+	path, err := accountPath(account)
+	if err != nil {
+		return "", err
+	}
+	return path + encryptedSuffix, nil
+}
+
+func sealCredentials(plain []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, encryptedSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := credentialCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte(encryptedMagic), salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plain, []byte(encryptedMagic)), nil
+}
+
+func openCredentials(data []byte, passphrase string) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(encryptedMagic))
+	if !ok || len(rest) < encryptedSaltSize {
+		return nil, errors.New("not an nlm encrypted credentials file")
+	}
+	salt, rest := rest[:encryptedSaltSize], rest[encryptedSaltSize:]
+	aead, err := credentialCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errWrongPassphrase
+	}
+	nonce, sealed := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, []byte(encryptedMagic))
+	if err != nil {
+		return nil, errWrongPassphrase
+	}
+	return plain, nil
+}
+
+func credentialCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, encryptedIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+var (
+	passphraseMu     sync.Mutex
+	cachedPassphrase string
+)
+
+// credentialPassphrase returns NLM_CREDENTIAL_PASSPHRASE or reads the
+// passphrase from the terminal once per run. With confirm, a typed
+// passphrase must be entered twice.
+func credentialPassphrase(confirm bool) (string, error) {
+	if p := os.Getenv("NLM_CREDENTIAL_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	if cachedPassphrase != "" {
+		return cachedPassphrase, nil
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", errors.New("no terminal to ask for the credential passphrase; set NLM_CREDENTIAL_PASSPHRASE")
+	}
+	defer tty.Close()
+	read := func(prompt string) (string, error) {
+		fmt.Fprint(tty, prompt)
+		p, err := term.ReadPassword(int(tty.Fd()))
+		fmt.Fprintln(tty)
+		return strings.TrimRight(string(p), "\r\n"), err
+	}
+	passphrase, err := read("nlm credential passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	if passphrase == "" {
+		return "", errors.New("empty credential passphrase")
+	}
+	if confirm {
+		again, err := read("repeat passphrase: ")
+		if err != nil {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+		if again != passphrase {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	cachedPassphrase = passphrase
+	return passphrase, nil
+}
+
+// forgetPassphrase drops the cached passphrase, so the next use asks again
+// rather than reuse a mistyped one.
+func forgetPassphrase() {
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	cachedPassphrase = ""
+}
+
+// adoptCredentialPassphrase moves NLM_CREDENTIAL_PASSPHRASE from the
+// environment into the per-run cache, so the processes nlm starts (git,
+// --pre-process commands, credential helpers, browsers) do not inherit it.
+func adoptCredentialPassphrase() {
+	p, ok := os.LookupEnv("NLM_CREDENTIAL_PASSPHRASE")
+	if !ok {
+		return
+	}
+	os.Unsetenv("NLM_CREDENTIAL_PASSPHRASE")
+	if p == "" {
+		return
+	}
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	cachedPassphrase = p
+}
+
+// typedPassphrase returns the passphrase read from the terminal or adopted
+// from the environment, if any.
+func typedPassphrase() string {
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	return cachedPassphrase
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/tmc/nlm/internal/auth"
+	"github.com/tmc/nlm/internal/exitclass"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+type authImportArgs struct {
+	CookiesTxt string
+	Firefox    bool
+	Profile    string
+	Sealed     string
+	Identity   string
+	SaveAs     string
+}
+
+func authImportFlagSpecs() []flagSpec {
+	return []flagSpec{
+		{Name: "cookies-txt", Value: "file", Description: "Netscape cookies.txt file ('-' reads stdin)"},
+		{Name: "firefox", Description: "read cookies from a Firefox profile"},
+		{Name: "sealed", Value: "file", Description: "sealed credentials from 'nlm auth export' ('-' reads stdin)"},
+		{Name: "identity", Value: "file", Description: "age identity file that opens --sealed (default $NLM_SEALED_IDENTITY)"},
+		{Name: "save-as", Value: "name", Description: "stored account name"},
+	}
+}
+
+func decodeAuthImport(parsed parsedCommand) (commandCall, error) {
+	firefox, err := parsedBoolFlag(parsed, "firefox", false)
+	if err != nil {
+		return nil, err
+	}
+	args := authImportArgs{
+		CookiesTxt: parsedStringFlag(parsed, "cookies-txt", ""),
+		Firefox:    firefox,
+		Sealed:     parsedStringFlag(parsed, "sealed", ""),
+		Identity:   parsedStringFlag(parsed, "identity", ""),
+		SaveAs:     parsedStringFlag(parsed, "save-as", ""),
+	}
+	args.Profile, _, err = parsedOptionalArgument(parsed, "profile")
+	if err != nil {
+		return nil, err
+	}
+	sources := 0
+	for _, set := range []bool{args.CookiesTxt != "", args.Firefox, args.Sealed != ""} {
+		if set {
+			sources++
+		}
+	}
+	switch {
+	case sources != 1:
+		return nil, badArgsf("auth import needs exactly one of --cookies-txt <file>, --firefox [profile] and --sealed <file>")
+	case args.Profile != "" && !args.Firefox:
+		return nil, badArgsf("unexpected argument %q: a profile is only read with --firefox", args.Profile)
+	case args.Identity != "" && args.Sealed == "":
+		return nil, badArgsf("--identity is only used with --sealed")
+	case args.Sealed != "" && args.Identity == "" && os.Getenv(sealedIdentityEnv) == "":
+		return nil, badArgsf("--sealed needs the age identity: pass --identity <file> or set %s", sealedIdentityEnv)
+	case args.SaveAs != "" && !validAccountName(args.SaveAs):
+		return nil, badArgsf("invalid account name %q: use letters, digits, '.', '_' and '-'", args.SaveAs)
+	}
+	return func(context.Context, *notebooklm.Client) error {
+		var err error
+		if args.Sealed != "" {
+			err = importSealed(args, time.Now())
+		} else {
+			err = importCookies(args)
+		}
+		if err != nil {
+			return err
+		}
+		if args.SaveAs != "" {
+			printSavedAccountHint(args.SaveAs)
+		}
+		return nil
+	}, nil
+}
+
+// importCookies signs in with cookies exported from a browser: it keeps
+// the ones NotebookLM is sent, fetches a page token with them, and stores
+// the result for the selected account.
+func importCookies(args authImportArgs) error {
+	var (
+		cookieList []auth.Cookie
+		source     string
+		err        error
+	)
+	if args.Firefox {
+		var dir string
+		if dir, err = auth.FirefoxProfileDir(args.Profile); err != nil {
+			return err
+		}
+		source = "Firefox profile " + dir
+		cookieList, err = auth.ReadFirefoxCookies(dir)
+	} else {
+		source = args.CookiesTxt
+		cookieList, err = readCookiesTxtFile(args.CookiesTxt)
+	}
+	if err != nil {
+		return err
+	}
+
+	matched := auth.NotebookLMCookies(cookieList, time.Now())
+	if !auth.HasSessionCookies(matched) {
+		return exitclass.With(exitclass.Auth, fmt.Errorf("no unexpired Google sign-in cookies in %s; sign in to NotebookLM in that browser and export again", source))
+	}
+	header := auth.CookieHeader(matched)
+	state, err := extractNotebookLMPageState(header)
+	if err != nil {
+		return fmt.Errorf("load NotebookLM with the imported cookies: %w", err)
+	}
+	if state.AuthToken == "" {
+		return exitclass.With(exitclass.Auth, errors.New("NotebookLM issued no auth token for the imported cookies; they may be signed out"))
+	}
+
+	if args.SaveAs != "" {
+		account = args.SaveAs
+	}
+	if _, _, err := persistAuthToDisk(header, state.AuthToken, "", state.SessionID, state.BLParam, authUser); err != nil {
+		return err
+	}
+	// The stored browser profile and signaler auth belong to an earlier
+	// Chromium login; re-harvesting that profile would undo the import.
+	if err := updateStoredCredentials(map[string]string{
+		"NLM_BROWSER_PROFILE": "",
+		"NLM_SIGNALER_AUTH":   "",
+		"NLM_COOKIE_EXPIRES":  formatCookieExpiry(auth.CookieExpiry(matched)),
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "nlm: imported %d cookies from %s\n", len(matched), source)
+	return nil
+}
+
+func readCookiesTxtFile(path string) ([]auth.Cookie, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return auth.ReadCookiesTxt(r)
+}
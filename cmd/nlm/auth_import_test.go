@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tmc/nlm/internal/auth"
+)
+
+func TestAuthImportCookiesTxt(t *testing.T) {
+	home := isolateAccounts(t)
+	account = ""
+	if _, _, err := persistAuthToDisk("old-cookies", "old-token", "Work", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := extractNotebookLMPageState
+	extractNotebookLMPageState = func(cookies string) (auth.NotebookLMPageState, error) {
+		if want := "SID=sid; SAPISID=sap"; cookies != want {
+			t.Errorf("page fetched with cookies %q, want %q", cookies, want)
+		}
+		return auth.NotebookLMPageState{AuthToken: "token:1760788800000", SessionID: "session", BLParam: "bl"}, nil
+	}
+	t.Cleanup(func() { extractNotebookLMPageState = orig })
+
+	path := filepath.Join(home, "cookies.txt")
+	data := "# Netscape HTTP Cookie File\n" +
+		".google.com\tTRUE\t/\tTRUE\t4102444800\tSID\tsid\n" +
+		"#HttpOnly_.google.com\tTRUE\t/\tTRUE\t0\tSAPISID\tsap\n" +
+		".example.com\tTRUE\t/\tTRUE\t4102444800\tSID\tother\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := func(string) string { return "" }
+
+	if code := runCLI([]string{"auth", "import", "--cookies-txt", path, "--save-as", "lab"}, env, io.Discard, io.Discard); code != 0 {
+		t.Fatalf("auth import: exit %d", code)
+	}
+	account = "lab"
+	stored := readStoredEnv()
+	want := map[string]string{
+		"NLM_COOKIES":         "SID=sid; SAPISID=sap",
+		"NLM_AUTH_TOKEN":      "token:1760788800000",
+		"NLM_SESSION_ID":      "session",
+		"NLM_BROWSER_PROFILE": "",
+		"NLM_COOKIE_EXPIRES":  "SID=4102444800",
+	}
+	for key, value := range want {
+		if stored[key] != value {
+			t.Errorf("stored %s = %q, want %q", key, stored[key], value)
+		}
+	}
+	account = ""
+	if got := readStoredEnv()["NLM_AUTH_TOKEN"]; got != "old-token" {
+		t.Errorf("default account token = %q, want it untouched", got)
+	}
+
+	if err := os.WriteFile(path, []byte(".example.com\tTRUE\t/\tTRUE\t0\tSID\tother\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := runCLI([]string{"auth", "import", "--cookies-txt", path}, env, io.Discard, io.Discard); code != exitAuth {
+		t.Errorf("import without Google cookies: exit %d, want %d", code, exitAuth)
+	}
+	if code := runCLI([]string{"auth", "import", "--cookies-txt", path, "--firefox"}, env, io.Discard, io.Discard); code != exitBadArgs {
+		t.Errorf("import with both sources: exit %d, want %d", code, exitBadArgs)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// keepAliveProviders holds one refreshing credential provider per stored
+// account, shared by every client acting as that account, so concurrent
+// failures trigger one refresh and every client sees its result.
+var keepAliveProviders = struct {
+	sync.Mutex
+	m map[string]*notebooklm.RefreshingProvider
+}{m: make(map[string]*notebooklm.RefreshingProvider)}
+
+// keepAliveProvider returns the provider that keeps the stored account
+// name's credentials fresh, creating it with initial on first use. It
+// refreshes in the background for the rest of the process.
+func keepAliveProvider(name string, initial notebooklm.Credentials) *notebooklm.RefreshingProvider {
+	keepAliveProviders.Lock()
+	defer keepAliveProviders.Unlock()
+	if p, ok := keepAliveProviders.m[name]; ok {
+		return p
+	}
+	p := notebooklm.NewRefreshingProvider(initial, func(_ context.Context, current notebooklm.Credentials) (notebooklm.Credentials, error) {
+		return refreshAccountCredentials(name, current)
+	})
+	p.OnRefresh = func(_ notebooklm.Credentials, err error) {
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "nlm: refresh credentials%s: %v\n", accountSuffix(name), err)
+		case debug:
+			fmt.Fprintf(os.Stderr, "nlm: refreshed credentials%s\n", accountSuffix(name))
+		}
+	}
+	keepAliveProviders.m[name] = p
+	go p.Run(context.Background())
+	return p
+}
+
+func accountSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " for account " + name
+}
+
+// refreshAccountCredentials obtains credentials to replace current, which
+// belong to the stored account name. It tries, in order: a new page token
+// for the same cookies; credentials stored for the account since, for
+// example by 'nlm auth login' in another terminal; and, for the account
+// this process runs as, a fresh login from its cached browser profile.
+func refreshAccountCredentials(name string, current notebooklm.Credentials) (notebooklm.Credentials, error) {
+	state, err := extractNotebookLMPageState(current.Cookies)
+	if err == nil && state.AuthToken == "" {
+		err = errors.New("NotebookLM issued no auth token; the cookies may be signed out")
+	}
+	if err == nil {
+		fresh := notebooklm.Credentials{AuthToken: state.AuthToken, Cookies: current.Cookies}
+		if serr := saveAccountCredentials(name, current.Cookies, map[string]string{
+			"NLM_AUTH_TOKEN": state.AuthToken,
+			"NLM_SESSION_ID": state.SessionID,
+			"NLM_BL_PARAM":   state.BLParam,
+		}); serr != nil {
+			fmt.Fprintf(os.Stderr, "nlm: save refreshed credentials: %v\n", serr)
+		}
+		return fresh, nil
+	}
+
+	store, serr := openCredentialStore()
+	if serr != nil {
+		return current, serr
+	}
+	if stored, lerr := store.Load(name); lerr == nil {
+		fresh := notebooklm.Credentials{AuthToken: stored["NLM_AUTH_TOKEN"], Cookies: stored["NLM_COOKIES"]}
+		if fresh.AuthToken != "" && fresh.Cookies != "" && fresh != current {
+			return fresh, nil
+		}
+	}
+
+	if name == selectedAccount() && autoRefreshEnabled() && hasCachedBrowserProfile() {
+		token, cookieHeader, herr := reharvestBrowserCredentials(debug)
+		if herr != nil {
+			return current, fmt.Errorf("re-harvest browser profile: %w", herr)
+		}
+		return notebooklm.Credentials{AuthToken: token, Cookies: cookieHeader}, nil
+	}
+	return current, fmt.Errorf("fetch a new page token: %w", err)
+}
+
+// saveAccountCredentials merges updates into the stored account name, but
+// only while it still holds cookies: a refresh must not overwrite a newer
+// login. Empty updates keep the stored value.
+func saveAccountCredentials(name, cookies string, updates map[string]string) error {
+	store, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+	values, err := store.Load(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if values["NLM_COOKIES"] != cookies {
+		return nil
+	}
+	for key, value := range updates {
+		if value != "" {
+			values[key] = value
+		}
+	}
+	return store.Save(name, values)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tmc/nlm/internal/auth"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+func TestRefreshAccountCredentials(t *testing.T) {
+	isolateAccounts(t)
+	t.Setenv("NLM_AUTO_REFRESH", "false")
+	account = "work"
+	if _, _, err := persistAuthToDisk("SID=w", "token-old", "", "session-old", "bl-old", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	page := auth.NotebookLMPageState{AuthToken: "token-new", SessionID: "session-new"}
+	var pageErr error
+	orig := extractNotebookLMPageState
+	extractNotebookLMPageState = func(string) (auth.NotebookLMPageState, error) { return page, pageErr }
+	t.Cleanup(func() { extractNotebookLMPageState = orig })
+
+	current := notebooklm.Credentials{AuthToken: "token-old", Cookies: "SID=w"}
+	got, err := refreshAccountCredentials("work", current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (notebooklm.Credentials{AuthToken: "token-new", Cookies: "SID=w"}); got != want {
+		t.Errorf("refreshed = %+v, want %+v", got, want)
+	}
+	stored := readStoredEnv()
+	if stored["NLM_AUTH_TOKEN"] != "token-new" || stored["NLM_SESSION_ID"] != "session-new" || stored["NLM_BL_PARAM"] != "bl-old" {
+		t.Errorf("stored after refresh = %v", stored)
+	}
+
+	// A refresh of other cookies must not overwrite the stored login.
+	if _, err := refreshAccountCredentials("work", notebooklm.Credentials{AuthToken: "t", Cookies: "SID=other"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readStoredEnv()["NLM_COOKIES"]; got != "SID=w" {
+		t.Errorf("stored cookies = %q, want SID=w", got)
+	}
+
+	// With dead cookies, a newer stored login is picked up.
+	pageErr = errors.New("signed out")
+	got, err = refreshAccountCredentials("work", notebooklm.Credentials{AuthToken: "t", Cookies: "SID=dead"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (notebooklm.Credentials{AuthToken: "token-new", Cookies: "SID=w"}); got != want {
+		t.Errorf("adopted = %+v, want %+v", got, want)
+	}
+
+	// Nothing newer and no browser profile to re-harvest: the error says so.
+	stale := notebooklm.Credentials{AuthToken: "token-new", Cookies: "SID=w"}
+	if got, err := refreshAccountCredentials("work", stale); !errors.Is(err, pageErr) || got != stale {
+		t.Errorf("refresh with nothing to try = %+v, %v; want the current credentials and %v", got, err, pageErr)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// keyringService is the Secret Service "service" attribute of every item
+// nlm stores; the "account" attribute names the account.
+const keyringService = "nlm"
+
+// secretTool is the libsecret command-line client used to reach the
+// Secret Service D-Bus API (GNOME Keyring, KWallet, KeePassXC).
+var secretTool = "secret-tool"
+
+// keyringStore keeps each account's credentials as one Secret Service item
+// whose secret is the account's ~/.nlm/env text.
+type keyringStore struct {
+	path string
+}
+
+func newKeyringStore() (credentialStore, error) {
+	path, err := exec.LookPath(secretTool)
+	if err != nil {
+		return nil, fmt.Errorf("keyring credential store needs %s (install libsecret-tools): %w", secretTool, err)
+	}
+	return keyringStore{path: path}, nil
+}
+
+func (keyringStore) Name() string { return keyringStoreName }
+
+func (s keyringStore) Load(account string) (map[string]string, error) {
+	out, err := s.run(nil, "lookup", "service", keyringService, "account", keyringAccount(account))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(out) == 0 {
+		return nil, fmt.Errorf("keyring account %s: %w", keyringAccount(account), fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseStoredEnv(out), nil
+}
+
+func (s keyringStore) Save(account string, values map[string]string) error {
+	name := keyringAccount(account)
+	_, err := s.run(formatStoredEnv(values), "store", "--label", "nlm credentials ("+name+")",
+		"service", keyringService, "account", name)
+	return err
+}
+
+func (s keyringStore) Delete(account string) error {
+	_, err := s.run(nil, "clear", "service", keyringService, "account", keyringAccount(account))
+	return err
+}
+
+func (s keyringStore) Accounts() ([]string, error) {
+	out, err := s.run(nil, "search", "--all", "service", keyringService)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// secret-tool exits 1 when nothing matches.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseSecretToolAccounts(out), nil
+}
+
+func (keyringStore) Location(account string) string {
+	return fmt.Sprintf("the system keyring (service %s, account %s)", keyringService, keyringAccount(account))
+}
+
+// run runs secret-tool with stdin as its input and returns its output,
+// with its diagnostics in the error.
+func (s keyringStore) run(stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command(s.path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if args[0] == "search" {
+		// search prints item attributes on stderr.
+		return append(stdout.Bytes(), stderr.Bytes()...), err
+	}
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%s %s: %s: %w", secretTool, args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.Bytes(), err
+}
+
+// keyringAccount is the account attribute for account, where the default
+// account is "default".
+func keyringAccount(account string) string {
+	return firstNonEmpty(account, defaultAccount)
+}
+
+// parseSecretToolAccounts reads the account attributes from
+// 'secret-tool search --all' output, the default first and the rest in
+// name order.
+func parseSecretToolAccounts(out []byte) []string {
+	var accounts []string
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		key, value, ok := strings.Cut(s.Text(), "=")
+		if !ok || strings.TrimSpace(key) != "attribute.account" {
+			continue
+		}
+		name := strings.TrimSpace(value)
+		if validAccountName(name) && !slices.Contains(accounts, name) {
+			accounts = append(accounts, name)
+		}
+	}
+	slices.SortFunc(accounts, func(a, b string) int {
+		switch {
+		case a == b:
+			return 0
+		case a == defaultAccount:
+			return -1
+		case b == defaultAccount:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return accounts
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// defaultAccount names the credentials in ~/.nlm/env, which hold the
+// account used when no named account is selected.
+const defaultAccount = "default"
+
+var accountNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validAccountName reports whether name can name a stored account. Names
+// become file names under ~/.nlm/profiles, so they may not contain path
+// separators or start with a dot.
+func validAccountName(name string) bool {
+	return accountNamePattern.MatchString(name)
+}
+
+// accountFlag collects --account values. A value from NLM_ACCOUNT is the
+// default and is replaced, not extended, by the first flag.
+type accountFlag struct {
+	values []string
+	set    bool
+}
+
+func (f *accountFlag) String() string { return strings.Join(f.values, " ") }
+
+func (f *accountFlag) Set(value string) error {
+	if !f.set {
+		f.values = nil
+		f.set = true
+	}
+	f.values = append(f.values, value)
+	return nil
+}
+
+// checkAccountSelection rejects --account values spec cannot use. Only mcp
+// takes several accounts or account specs; other commands take one stored
+// account, which must exist unless the command needs no credentials.
+func checkAccountSelection(spec *commandSpec, values []string) error {
+	if spec.ID == "mcp" && mcpServesAccounts(values) {
+		return nil
+	}
+	switch {
+	case len(values) == 0:
+		return nil
+	case len(values) > 1:
+		return badArgsf("--account given %d times; only 'nlm mcp' serves several accounts", len(values))
+	}
+	name := values[0]
+	if !validAccountName(name) {
+		return badArgsf("invalid account name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	if !spec.noAuth && !accountExists(name) {
+		return badArgsf("unknown account %q; sign in with 'nlm auth login --save-as %s' (see 'nlm auth list')", name, name)
+	}
+	return nil
+}
+
+func nlmConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home dir: %w", err)
+	}
+	return filepath.Join(home, ".nlm"), nil
+}
+
+// profilePath returns the file holding the credentials of a named account,
+// ~/.nlm/profiles/<name>.env.
+func profilePath(name string) (string, error) {
+	if !validAccountName(name) {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	dir, err := nlmConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profiles", name+".env"), nil
+}
+
+// accountPath returns the credentials file of name, where the default
+// account is ~/.nlm/env.
+func accountPath(name string) (string, error) {
+	if name == "" || name == defaultAccount {
+		dir, err := nlmConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "env"), nil
+	}
+	return profilePath(name)
+}
+
+// accountExists reports whether name has stored credentials. The default
+// account always exists; without stored credentials it falls back to
+// NLM_AUTH_TOKEN and NLM_COOKIES.
+func accountExists(name string) bool {
+	if name == defaultAccount {
+		return true
+	}
+	store, err := openCredentialStore()
+	if err != nil {
+		return false
+	}
+	names, err := store.Accounts()
+	return err == nil && slices.Contains(names, name)
+}
+
+func activeAccountPath() (string, error) {
+	dir, err := nlmConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "active_profile"), nil
+}
+
+// readActiveAccount returns the account chosen by 'nlm auth use', or "" for
+// the default.
+func readActiveAccount() string {
+	path, err := activeAccountPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSpace(string(data))
+	if !validAccountName(name) || name == defaultAccount {
+		return ""
+	}
+	return name
+}
+
+// writeActiveAccount makes name the account commands use by default.
+func writeActiveAccount(name string) error {
+	path, err := activeAccountPath()
+	if err != nil {
+		return err
+	}
+	if name == "" || name == defaultAccount {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("clear active account: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create .nlm directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0600); err != nil {
+		return fmt.Errorf("write active account: %w", err)
+	}
+	return nil
+}
+
+// selectedAccount returns the account whose stored credentials this run
+// uses: the one named by --account or NLM_ACCOUNT, else the one chosen by
+// 'nlm auth use'. It returns "" for the default account.
+func selectedAccount() string {
+	if account != "" {
+		if account == defaultAccount {
+			return ""
+		}
+		return account
+	}
+	return readActiveAccount()
+}
+
+// storedAccount is an account with stored credentials.
+type storedAccount struct {
+	Name   string
+	Values map[string]string
+}
+
+// listStoredAccounts returns the accounts with stored credentials, the
+// default first and the named accounts in name order.
+func listStoredAccounts() ([]storedAccount, error) {
+	store, err := openCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	names, err := store.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]storedAccount, 0, len(names))
+	for _, name := range names {
+		values, err := store.Load(name)
+		if err != nil {
+			return nil, fmt.Errorf("read account %s: %w", name, err)
+		}
+		accounts = append(accounts, storedAccount{Name: name, Values: values})
+	}
+	return accounts, nil
+}
+
+// printSavedAccountHint tells the user how to use an account saved by
+// 'nlm auth --save-as', unless it is already the one in use.
+func printSavedAccountHint(name string) {
+	if firstNonEmpty(readActiveAccount(), defaultAccount) == name {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "nlm: run 'nlm auth use %s' to make account %s the default, or pass --account %s\n", name, name, name)
+}
+
+type authUseArgs struct {
+	Name string
+}
+
+type authRemoveArgs struct {
+	Name string
+	Yes  bool
+}
+
+type authMigrateArgs struct {
+	Store string
+}
+
+func configureAuthProfileCommandSpecs(specs map[commandID]*commandSpec) {
+	configureTypedCommandSpec(specs["auth-list"], commandFormOf(), decodeAuthList)
+	configureTypedCommandSpec(specs["auth-use"],
+		commandFormOf(withPlaceholder(requiredOperand("name"), "account")),
+		decodeAuthUse,
+	)
+	configureTypedCommandSpec(specs["auth-remove"],
+		commandFormOf(withPlaceholder(requiredOperand("name"), "account")),
+		decodeAuthRemove,
+	)
+	importSpec := specs["auth-import"]
+	importSpec.Flags = authImportFlagSpecs()
+	configureTypedCommandSpec(importSpec,
+		commandFormOf(withPlaceholder(optionalOperand("profile"), "firefox-profile")),
+		decodeAuthImport,
+	)
+	exportSpec := specs["auth-export"]
+	exportSpec.Flags = authExportFlagSpecs()
+	configureTypedCommandSpec(exportSpec, commandFormOf(), decodeAuthExport)
+	configureTypedCommandSpec(specs["auth-status"], commandFormOf(), decodeAuthStatus)
+	configureTypedCommandSpec(specs["auth-migrate"],
+		commandFormOf(requiredOperand("store")),
+		decodeAuthMigrate,
+	)
+}
+
+func decodeAuthList(parsed parsedCommand) (commandCall, error) {
+	return func(context.Context, *notebooklm.Client) error {
+		return listAccounts()
+	}, nil
+}
+
+func decodeAuthUse(parsed parsedCommand) (commandCall, error) {
+	name, err := parsedArgument(parsed, "name")
+	if err != nil {
+		return nil, err
+	}
+	if !validAccountName(name) {
+		return nil, badArgsf("invalid account name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	args := authUseArgs{Name: name}
+	return func(context.Context, *notebooklm.Client) error {
+		return useAccount(args.Name)
+	}, nil
+}
+
+func decodeAuthRemove(parsed parsedCommand) (commandCall, error) {
+	name, err := parsedArgument(parsed, "name")
+	if err != nil {
+		return nil, err
+	}
+	if !validAccountName(name) {
+		return nil, badArgsf("invalid account name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	yes, err := parsedBoolFlag(parsed, "yes", parsed.globals.yes)
+	if err != nil {
+		return nil, err
+	}
+	args := authRemoveArgs{Name: name, Yes: yes}
+	return func(context.Context, *notebooklm.Client) error {
+		return removeAccount(args.Name, args.Yes)
+	}, nil
+}
+
+func decodeAuthMigrate(parsed parsedCommand) (commandCall, error) {
+	store, err := parsedArgument(parsed, "store")
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(credentialStoreNames, store) {
+		return nil, badArgsf("unknown credential store %q (want %s)", store, strings.Join(credentialStoreNames, ", "))
+	}
+	args := authMigrateArgs{Store: store}
+	return func(context.Context, *notebooklm.Client) error {
+		return migrateCredentials(args.Store)
+	}, nil
+}
+
+func listAccounts() error {
+	accounts, err := listStoredAccounts()
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		fmt.Fprintln(os.Stderr, "nlm: no stored accounts; run 'nlm auth login' or 'nlm auth login --save-as <name>'")
+		return nil
+	}
+	current := firstNonEmpty(selectedAccount(), defaultAccount)
+	w, flush := newListWriter(os.Stdout)
+	fmt.Fprintln(w, "CURRENT\tNAME\tAUTHUSER\tBROWSER PROFILE")
+	for _, a := range accounts {
+		marker := ""
+		if a.Name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, a.Name, a.Values["NLM_AUTHUSER"], a.Values["NLM_BROWSER_PROFILE"])
+	}
+	return flush()
+}
+
+func useAccount(name string) error {
+	if !accountExists(name) {
+		return fmt.Errorf("account %q not found; sign in with 'nlm auth login --save-as %s'", name, name)
+	}
+	if err := writeActiveAccount(name); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "nlm: now using account %s\n", name)
+	return nil
+}
+
+func removeAccount(name string, yes bool) error {
+	store, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+	names, err := store.Accounts()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(names, name) {
+		return fmt.Errorf("account %q not found", name)
+	}
+	if !confirmAction(fmt.Sprintf("Remove the stored credentials of account %s?", name), yes) {
+		return fmt.Errorf("operation cancelled")
+	}
+	if err := store.Delete(name); err != nil {
+		return fmt.Errorf("remove account: %w", err)
+	}
+	if readActiveAccount() == name {
+		if err := writeActiveAccount(""); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "nlm: removed account %s; now using the default account\n", name)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "nlm: removed account %s\n", name)
+	return nil
+}
+
+// migrateCredentials moves every stored account from the credential store
+// in use to the named one, checks the copies, records the new store as the
+// one to use, and only then deletes the originals.
+func migrateCredentials(to string) error {
+	from, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+	if from.Name() == to {
+		return fmt.Errorf("credentials are already in the %s store", to)
+	}
+	target, err := newCredentialStore(to)
+	if err != nil {
+		return err
+	}
+	names, err := from.Accounts()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		values, err := from.Load(name)
+		if err != nil {
+			return fmt.Errorf("read account %s: %w", name, err)
+		}
+		if err := target.Save(name, values); err != nil {
+			return fmt.Errorf("write account %s: %w", name, err)
+		}
+		copied, err := target.Load(name)
+		if err != nil || !maps.Equal(copied, values) {
+			return fmt.Errorf("account %s did not read back from the %s store: %v", name, to, err)
+		}
+	}
+	if err := writeCredentialStoreName(to); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := from.Delete(name); err != nil {
+			fmt.Fprintf(os.Stderr, "nlm: warning: remove account %s from %s: %v\n", name, from.Location(name), err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "nlm: moved %d account(s) from the %s store to the %s store\n", len(names), from.Name(), to)
+	if env := os.Getenv("NLM_CREDENTIAL_STORE"); env != "" && env != to {
+		fmt.Fprintf(os.Stderr, "nlm: NLM_CREDENTIAL_STORE=%s still overrides it; unset it to use the %s store\n", env, to)
+	}
+	return nil
+}
+
+// writeCredentialStoreName records the store later runs use. The file
+// store is the default and needs no record.
+func writeCredentialStoreName(name string) error {
+	path, err := credentialStorePath()
+	if err != nil {
+		return err
+	}
+	if name == fileStoreName {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("record credential store: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create .nlm directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0600); err != nil {
+		return fmt.Errorf("record credential store: %w", err)
+	}
+	return nil
+}
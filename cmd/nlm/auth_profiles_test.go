@@ -0,0 +1,135 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolateAccounts points HOME at a temporary directory, clears the stored
+// credential variables, and restores the selected account afterwards.
+func isolateAccounts(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, key := range []string{"NLM_COOKIES", "NLM_AUTH_TOKEN", "NLM_BROWSER_PROFILE", "NLM_SESSION_ID", "NLM_BL_PARAM", "NLM_SIGNALER_AUTH", "NLM_AUTHUSER", "NLM_ACCOUNT", "NLM_COOKIE_EXPIRES"} {
+		t.Setenv(key, "")
+	}
+	savedAccount, savedAuthUser := account, authUser
+	t.Cleanup(func() { account, authUser = savedAccount, savedAuthUser })
+	return home
+}
+
+func TestPersistAuthToSelectedAccount(t *testing.T) {
+	home := isolateAccounts(t)
+
+	account = ""
+	if _, _, err := persistAuthToDisk("cookie-d", "token-d", "Default", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	account = "work"
+	if _, _, err := persistAuthToDisk("cookie-w", "token-w", "Work", "", "", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(home, ".nlm", "profiles", "work.env")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("%s mode = %v, want 0600", path, perm)
+	}
+	if got := readStoredEnv()["NLM_AUTH_TOKEN"]; got != "token-w" {
+		t.Errorf("work token = %q, want token-w", got)
+	}
+	account = defaultAccount
+	if got := readStoredEnv()["NLM_AUTH_TOKEN"]; got != "token-d" {
+		t.Errorf("default token = %q, want token-d", got)
+	}
+
+	account = ""
+	accounts, err := listStoredAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, a := range accounts {
+		names = append(names, a.Name+"/"+a.Values["NLM_AUTHUSER"])
+	}
+	if got, want := strings.Join(names, " "), "default/ work/2"; got != want {
+		t.Errorf("accounts = %q, want %q", got, want)
+	}
+}
+
+func TestAuthUseAndRemove(t *testing.T) {
+	home := isolateAccounts(t)
+	account = "work"
+	if _, _, err := persistAuthToDisk("cookie-w", "token-w", "Work", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	env := func(string) string { return "" }
+
+	if code := runCLI([]string{"auth", "use", "missing"}, env, io.Discard, io.Discard); code == 0 {
+		t.Error("auth use missing: want failure")
+	}
+	if code := runCLI([]string{"auth", "use", "work"}, env, io.Discard, io.Discard); code != 0 {
+		t.Fatalf("auth use work: exit %d", code)
+	}
+	if got := readActiveAccount(); got != "work" {
+		t.Fatalf("active account = %q, want work", got)
+	}
+	account = ""
+	if got := selectedAccount(); got != "work" {
+		t.Errorf("selected account = %q, want work", got)
+	}
+	if got := readStoredEnv()["NLM_COOKIES"]; got != "cookie-w" {
+		t.Errorf("stored cookies = %q, want cookie-w", got)
+	}
+
+	if code := runCLI([]string{"auth", "remove", "-y", "work"}, env, io.Discard, io.Discard); code != 0 {
+		t.Fatalf("auth remove work: exit %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".nlm", "profiles", "work.env")); !os.IsNotExist(err) {
+		t.Errorf("work.env still present: %v", err)
+	}
+	if got := readActiveAccount(); got != "" {
+		t.Errorf("active account after remove = %q, want default", got)
+	}
+}
+
+func TestCheckAccountSelection(t *testing.T) {
+	isolateAccounts(t)
+	account = "work"
+	if _, _, err := persistAuthToDisk("cookie-w", "token-w", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	authSpec := &commandSpec{ID: "auth", noAuth: true}
+	listSpec := &commandSpec{ID: "list"}
+	mcpSpec := &commandSpec{ID: "mcp"}
+	tests := []struct {
+		spec   *commandSpec
+		values []string
+		ok     bool
+	}{
+		{listSpec, nil, true},
+		{listSpec, []string{"work"}, true},
+		{listSpec, []string{"default"}, true},
+		{listSpec, []string{"missing"}, false},
+		{authSpec, []string{"missing"}, true},
+		{listSpec, []string{"../work"}, false},
+		{listSpec, []string{"work", "default"}, false},
+		{mcpSpec, []string{"work", "default"}, true},
+		{mcpSpec, []string{"profile=work,authuser=1"}, true},
+		{mcpSpec, []string{"missing"}, false},
+	}
+	for _, tt := range tests {
+		err := checkAccountSelection(tt.spec, tt.values)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkAccountSelection(%s, %q) = %v, want ok %v", tt.spec.ID, tt.values, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/tmc/nlm/internal/exitclass"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// Sealed credentials carry one account's credentials from a signed-in
+// machine to a headless runner. A sealed blob is an ASCII-armored age file
+// encrypted to an X25519 recipient, so the stock age tools can make the
+// key pair and open the blob. The plaintext is a block of metadata lines
+// ("Created: ..."), a blank line, and the stored credentials in ~/.nlm/env
+// form.
+const (
+	sealedVersion = "1"
+
+	// sealedIdentityEnv holds the identity when --identity is not given,
+	// typically from a CI secret.
+	sealedIdentityEnv = "NLM_SEALED_IDENTITY"
+)
+
+var errSealedOpen = errors.New("cannot open sealed credentials: wrong identity, or the blob was altered")
+
+// sealedMetadata describes sealed credentials. It is sealed with them, so
+// a runner can report on the credentials it opens.
+type sealedMetadata struct {
+	Account  string    // stored account the credentials came from; "" for the default
+	AuthUser string    // Google account index
+	Created  time.Time // when the credentials were sealed
+	Expires  time.Time // earliest known expiry of an essential cookie; zero if unknown
+}
+
+func (m sealedMetadata) headers() map[string]string {
+	h := map[string]string{
+		"Version": sealedVersion,
+		"Created": m.Created.UTC().Format(time.RFC3339),
+	}
+	if m.Account != "" {
+		h["Account"] = m.Account
+	}
+	if m.AuthUser != "" {
+		h["Authuser"] = m.AuthUser
+	}
+	if !m.Expires.IsZero() {
+		h["Expires"] = m.Expires.UTC().Format(time.RFC3339)
+	}
+	return h
+}
+
+func parseSealedMetadata(h map[string]string) (sealedMetadata, error) {
+	if h["Version"] != sealedVersion {
+		return sealedMetadata{}, fmt.Errorf("unsupported sealed credentials version %q", h["Version"])
+	}
+	m := sealedMetadata{Account: h["Account"], AuthUser: h["Authuser"]}
+	var err error
+	if m.Created, err = time.Parse(time.RFC3339, h["Created"]); err != nil {
+		return sealedMetadata{}, fmt.Errorf("sealed credentials: bad Created header: %w", err)
+	}
+	if v := h["Expires"]; v != "" {
+		if m.Expires, err = time.Parse(time.RFC3339, v); err != nil {
+			return sealedMetadata{}, fmt.Errorf("sealed credentials: bad Expires header: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// sealCredentialsTo seals the stored credentials values for recipient.
+func sealCredentialsTo(recipient age.Recipient, values map[string]string, meta sealedMetadata) ([]byte, error) {
+	var plain bytes.Buffer
+	headers := meta.headers()
+	for _, key := range slices.Sorted(maps.Keys(headers)) {
+		fmt.Fprintf(&plain, "%s: %s\n", key, headers[key])
+	}
+	plain.WriteString("\n")
+	plain.Write(formatStoredEnv(values))
+
+	var out bytes.Buffer
+	armored := armor.NewWriter(&out)
+	w, err := age.Encrypt(armored, recipient)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plain.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := armored.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// openSealedCredentials opens a blob made by sealCredentialsTo, armored or
+// not.
+func openSealedCredentials(data []byte, identities ...age.Identity) (map[string]string, sealedMetadata, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(trimmed))
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, sealedMetadata{}, errSealedOpen
+		}
+		return nil, sealedMetadata{}, fmt.Errorf("not nlm sealed credentials (want an age file from 'nlm auth export'): %w", err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, sealedMetadata{}, errSealedOpen
+	}
+	head, env, ok := strings.Cut(string(plain), "\n\n")
+	if !ok {
+		return nil, sealedMetadata{}, errors.New("sealed credentials: missing metadata")
+	}
+	headers := make(map[string]string)
+	for line := range strings.Lines(head) {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), ": ")
+		headers[key] = value
+	}
+	meta, err := parseSealedMetadata(headers)
+	if err != nil {
+		return nil, sealedMetadata{}, err
+	}
+	return parseStoredEnv([]byte(env)), meta, nil
+}
+
+// parseAgeRecipient reads an age X25519 recipient, "age1...".
+func parseAgeRecipient(s string) (*age.X25519Recipient, error) {
+	r, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: want an age X25519 public key (age1...)", s)
+	}
+	return r, nil
+}
+
+// parseAgeIdentity reads age identities, "AGE-SECRET-KEY-1...", from text
+// such as an age-keygen key file; comment lines are skipped.
+func parseAgeIdentity(text string) ([]age.Identity, error) {
+	ids, err := age.ParseIdentities(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity: want an age X25519 secret key (AGE-SECRET-KEY-1...): %w", err)
+	}
+	return ids, nil
+}
+
+type authExportArgs struct {
+	EncryptTo string
+}
+
+func authExportFlagSpecs() []flagSpec {
+	return []flagSpec{
+		{Name: "encrypt-to", Value: "recipient", Description: "age X25519 public key (age1...) to seal the credentials for"},
+	}
+}
+
+func decodeAuthExport(parsed parsedCommand) (commandCall, error) {
+	args := authExportArgs{EncryptTo: parsedStringFlag(parsed, "encrypt-to", "")}
+	if args.EncryptTo == "" {
+		return nil, badArgsf("auth export needs --encrypt-to <age1...>; use 'nlm auth --print-env' for plaintext")
+	}
+	recipient, err := parseAgeRecipient(args.EncryptTo)
+	if err != nil {
+		return nil, badArgsf("%v", err)
+	}
+	return func(context.Context, *notebooklm.Client) error {
+		return exportSealedCredentials(os.Stdout, recipient, time.Now())
+	}, nil
+}
+
+// exportSealedCredentials writes the selected account's stored credentials
+// to w, sealed for recipient. The browser profile is left out: the
+// runner has no browser to re-harvest.
+func exportSealedCredentials(w io.Writer, recipient age.Recipient, now time.Time) error {
+	name := selectedAccount()
+	values := readStoredEnv()
+	if values["NLM_COOKIES"] == "" || values["NLM_AUTH_TOKEN"] == "" {
+		return exitclass.With(exitclass.Auth, fmt.Errorf("no stored credentials%s to export; run 'nlm auth login' first", accountSuffix(name)))
+	}
+	values = maps.Clone(values)
+	values["NLM_BROWSER_PROFILE"] = ""
+	meta := sealedMetadata{
+		Account:  name,
+		AuthUser: values["NLM_AUTHUSER"],
+		Created:  now,
+		Expires:  essentialCookieExpiry(values["NLM_COOKIE_EXPIRES"]),
+	}
+	blob, err := sealCredentialsTo(recipient, values, meta)
+	if err != nil {
+		return fmt.Errorf("seal credentials: %w", err)
+	}
+	if _, err := w.Write(blob); err != nil {
+		return err
+	}
+	if meta.Expires.IsZero() {
+		fmt.Fprintln(os.Stderr, "nlm: sealed credentials; their cookie expiry is unknown (sign in with 'nlm auth login' to record it)")
+	} else {
+		fmt.Fprintf(os.Stderr, "nlm: sealed credentials; they expire %s\n", describeExpiry(meta.Expires, now))
+	}
+	return nil
+}
+
+// essentialCookieExpiry returns the earliest recorded expiry of an
+// essential cookie, or the zero time when none is recorded.
+func essentialCookieExpiry(recorded string) time.Time {
+	var earliest time.Time
+	expiry := parseCookieExpiry(recorded)
+	for _, name := range essentialCookies {
+		if t, ok := expiry[name]; ok && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+	return earliest
+}
+
+func describeExpiry(t, now time.Time) string {
+	date := t.UTC().Format(time.RFC3339)
+	switch days := int(t.Sub(now).Hours() / 24); {
+	case !t.After(now):
+		return "expired at " + date
+	case days < 1:
+		return "at " + date + ", in less than a day"
+	case days == 1:
+		return "at " + date + ", in 1 day"
+	default:
+		return fmt.Sprintf("at %s, in %d days", date, days)
+	}
+}
+
+// importSealed opens sealed credentials and stores them for --save-as, or
+// else for the named account they were exported from; credentials from the
+// default account need --save-as, so an import never replaces it. Expired credentials are
+// refused; credentials close to expiry are stored with a warning, so CI
+// logs show it before the credentials stop working.
+func importSealed(args authImportArgs, now time.Time) error {
+	var (
+		data []byte
+		err  error
+	)
+	if args.Sealed == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args.Sealed)
+	}
+	if err != nil {
+		return err
+	}
+	identityText := os.Getenv(sealedIdentityEnv)
+	if args.Identity != "" {
+		b, err := os.ReadFile(args.Identity)
+		if err != nil {
+			return err
+		}
+		identityText = string(b)
+	}
+	identity, err := parseAgeIdentity(identityText)
+	if err != nil {
+		return err
+	}
+	values, meta, err := openSealedCredentials(data, identity...)
+	if err != nil {
+		return err
+	}
+
+	name := firstNonEmpty(args.SaveAs, meta.Account)
+	if name == "" {
+		return badArgsf("sealed credentials came from the default account; pass --save-as <name> to store them as a named account")
+	}
+	if !validAccountName(name) {
+		return fmt.Errorf("sealed credentials name an invalid account %q; pass --save-as <name>", name)
+	}
+	if !meta.Expires.IsZero() && !meta.Expires.After(now) {
+		return exitclass.With(exitclass.Auth, fmt.Errorf("sealed credentials %s; export them again", describeExpiry(meta.Expires, now)))
+	}
+	store, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Save(name, values); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "nlm: imported credentials sealed at %s into %s\n", meta.Created.UTC().Format(time.RFC3339), store.Location(name))
+	switch {
+	case meta.Expires.IsZero():
+		fmt.Fprintln(os.Stderr, "nlm: warning: the sealed credentials do not record when they expire")
+	case meta.Expires.Sub(now) < cookieExpiryWarning:
+		fmt.Fprintf(os.Stderr, "nlm: warning: sealed credentials expire %s; export them again soon\n", describeExpiry(meta.Expires, now))
+	}
+	return nil
+}
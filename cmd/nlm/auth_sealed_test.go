@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// newAgeKey returns a fresh age X25519 identity and its encodings.
+func newAgeKey(t *testing.T) (*age.X25519Identity, string, string) {
+	t.Helper()
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, key.String(), key.Recipient().String()
+}
+
+func TestParseAgeKeys(t *testing.T) {
+	// From the age README.
+	if _, err := parseAgeRecipient("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"); err != nil {
+		t.Errorf("parseAgeRecipient: %v", err)
+	}
+	if _, err := parseAgeRecipient("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8q"); err == nil {
+		t.Error("parseAgeRecipient accepted a bad checksum")
+	}
+
+	key, identity, recipient := newAgeKey(t)
+	file := "# created: 2026-10-18T12:00:00Z\n# public key: " + recipient + "\n" + identity + "\n"
+	got, err := parseAgeIdentity(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].(*age.X25519Identity).String() != key.String() {
+		t.Error("parseAgeIdentity read a different key")
+	}
+	if _, err := parseAgeIdentity(recipient); err == nil {
+		t.Error("parseAgeIdentity accepted a recipient")
+	}
+}
+
+func TestSealedCredentialsRoundTrip(t *testing.T) {
+	key, _, _ := newAgeKey(t)
+	other, _, _ := newAgeKey(t)
+	values := map[string]string{"NLM_COOKIES": "SID=1; SAPISID=2", "NLM_AUTH_TOKEN": "token", "NLM_AUTHUSER": "1"}
+	meta := sealedMetadata{
+		Account:  "work",
+		AuthUser: "1",
+		Created:  time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Expires:  time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	blob, err := sealCredentialsTo(key.Recipient(), values, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(blob, []byte("SID=1")) || bytes.Contains(blob, []byte("work")) {
+		t.Error("sealed blob contains credentials or metadata in the clear")
+	}
+	if !bytes.HasPrefix(blob, []byte(armor.Header)) {
+		t.Errorf("sealed blob is not an armored age file:\n%s", blob)
+	}
+
+	// The blob is a plain age file: stock tools can open it.
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(blob)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := io.ReadAll(r)
+	for _, line := range []string{"Account: work\n", "Authuser: 1\n", "Expires: 2027-04-01T00:00:00Z\n"} {
+		if !bytes.Contains(plain, []byte(line)) {
+			t.Errorf("sealed plaintext lacks %q:\n%s", line, plain)
+		}
+	}
+
+	got, gotMeta, err := openSealedCredentials(blob, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["NLM_COOKIES"] != values["NLM_COOKIES"] || got["NLM_AUTH_TOKEN"] != "token" {
+		t.Errorf("opened %v", got)
+	}
+	if gotMeta != meta {
+		t.Errorf("metadata = %+v, want %+v", gotMeta, meta)
+	}
+
+	if _, _, err := openSealedCredentials(blob, other); !errors.Is(err, errSealedOpen) {
+		t.Errorf("open with another identity: %v, want %v", err, errSealedOpen)
+	}
+	var raw bytes.Buffer
+	w, _ := age.Encrypt(&raw, key.Recipient())
+	w.Write([]byte("Version: 1\nCreated: 2026-10-18T12:00:00Z\n\nNLM_AUTH_TOKEN=token\n"))
+	w.Close()
+	if got, _, err := openSealedCredentials(raw.Bytes(), key); err != nil || got["NLM_AUTH_TOKEN"] != "token" {
+		t.Errorf("open an unarmored blob = %v, %v", got, err)
+	}
+	tampered := slices.Clone(raw.Bytes())
+	tampered[len(tampered)-1] ^= 1
+	if _, _, err := openSealedCredentials(tampered, key); !errors.Is(err, errSealedOpen) {
+		t.Errorf("open an altered blob: %v, want %v", err, errSealedOpen)
+	}
+}
+
+func TestAuthExportImportSealed(t *testing.T) {
+	home := isolateAccounts(t)
+	now := time.Now()
+	account = "work"
+	if _, _, err := persistAuthToDisk("SID=1; SAPISID=2", "token", "Work", "", "", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateStoredCredentials(map[string]string{
+		"NLM_COOKIE_EXPIRES": formatCookieExpiry(map[string]time.Time{"SID": now.Add(3 * 24 * time.Hour), "NID": now.Add(time.Hour)}),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	key, identity, _ := newAgeKey(t)
+	var blob bytes.Buffer
+	if err := exportSealedCredentials(&blob, key.Recipient(), now); err != nil {
+		t.Fatal(err)
+	}
+	_, meta, err := openSealedCredentials(blob.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Account != "work" || meta.AuthUser != "1" || meta.Expires.Unix() != now.Add(3*24*time.Hour).Unix() {
+		t.Errorf("exported metadata = %+v", meta)
+	}
+
+	blobPath := filepath.Join(home, "creds.age")
+	identityPath := filepath.Join(home, "key.txt")
+	if err := os.WriteFile(blobPath, blob.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(identityPath, []byte(identity+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := func(string) string { return "" }
+	var stderr bytes.Buffer
+	if code := runCLI([]string{"auth", "import", "--sealed", blobPath, "--identity", identityPath, "--save-as", "ci"}, env, io.Discard, &stderr); code != 0 {
+		t.Fatalf("auth import --sealed: exit %d: %s", code, stderr.String())
+	}
+	account = "ci"
+	stored := readStoredEnv()
+	if stored["NLM_COOKIES"] != "SID=1; SAPISID=2" || stored["NLM_AUTH_TOKEN"] != "token" || stored["NLM_AUTHUSER"] != "1" {
+		t.Errorf("imported %v", stored)
+	}
+	if stored["NLM_BROWSER_PROFILE"] != "" {
+		t.Errorf("imported browser profile %q; the runner has none", stored["NLM_BROWSER_PROFILE"])
+	}
+
+	if code := runCLI([]string{"auth", "import", "--sealed", blobPath}, env, io.Discard, io.Discard); code != exitBadArgs {
+		t.Errorf("import --sealed without an identity: exit %d, want %d", code, exitBadArgs)
+	}
+}
+
+func TestImportSealedWarnsAndRefusesExpired(t *testing.T) {
+	home := isolateAccounts(t)
+	key, identity, _ := newAgeKey(t)
+	t.Setenv(sealedIdentityEnv, identity)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	seal := func(expires time.Time) string {
+		blob, err := sealCredentialsTo(key.Recipient(), map[string]string{"NLM_COOKIES": "SID=1", "NLM_AUTH_TOKEN": "t"},
+			sealedMetadata{Account: "ci", Created: now.Add(-time.Hour), Expires: expires})
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(home, "creds.age")
+		if err := os.WriteFile(path, blob, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if err := importSealed(authImportArgs{Sealed: seal(now.Add(-time.Minute))}, now); exitCodeFor(err) != exitAuth {
+		t.Errorf("import of expired credentials: %v, want an auth error", err)
+	}
+	if err := importSealed(authImportArgs{Sealed: seal(now.Add(2 * 24 * time.Hour))}, now); err != nil {
+		t.Fatal(err)
+	}
+	account = "ci"
+	if got := readStoredEnv()["NLM_AUTH_TOKEN"]; got != "t" {
+		t.Errorf("stored token for the exported account = %q, want t", got)
+	}
+}
+
+func TestImportSealedDefaultAccountNeedsSaveAs(t *testing.T) {
+	home := isolateAccounts(t)
+	key, identity, _ := newAgeKey(t)
+	t.Setenv(sealedIdentityEnv, identity)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	blob, err := sealCredentialsTo(key.Recipient(), map[string]string{"NLM_COOKIES": "SID=1", "NLM_AUTH_TOKEN": "t"},
+		sealedMetadata{Created: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(home, "creds.age")
+	if err := os.WriteFile(path, blob, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := importSealed(authImportArgs{Sealed: path}, now); exitCodeFor(err) != exitBadArgs {
+		t.Errorf("import of default-account credentials without --save-as: %v, want a usage error", err)
+	}
+	if got := readStoredEnv()["NLM_AUTH_TOKEN"]; got != "" {
+		t.Errorf("default account token = %q after a refused import, want it untouched", got)
+	}
+	if err := importSealed(authImportArgs{Sealed: path, SaveAs: "ci"}, now); err != nil {
+		t.Fatal(err)
+	}
+	account = "ci"
+	if got := readStoredEnv()["NLM_AUTH_TOKEN"]; got != "t" {
+		t.Errorf("stored token for --save-as ci = %q, want t", got)
+	}
+}
+
+func TestDescribeExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{now.Add(-time.Hour), "expired at 2026-10-18T11:00:00Z"},
+		{now.Add(2 * time.Hour), "at 2026-10-18T14:00:00Z, in less than a day"},
+		{now.Add(30 * time.Hour), "at 2026-10-19T18:00:00Z, in 1 day"},
+		{now.Add(10 * 24 * time.Hour), "at 2026-10-28T12:00:00Z, in 10 days"},
+	}
+	for _, tt := range tests {
+		if got := describeExpiry(tt.t, now); got != tt.want {
+			t.Errorf("describeExpiry(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
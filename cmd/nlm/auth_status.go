@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmc/nlm/internal/exitclass"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// essentialCookies are the Google session cookies batchexecute requests
+// are authenticated with. SAPISID also signs credential refreshes.
+var essentialCookies = []string{"SID", "HSID", "SSID", "APISID", "SAPISID"}
+
+const (
+	// cookieExpiryWarning is how close to expiry a stored cookie is
+	// reported as expiring soon.
+	cookieExpiryWarning = 7 * 24 * time.Hour
+	// authTokenRotation is how long nlm treats a page token (SNlM0e) as
+	// current. The web app fetches a fresh one with every page load;
+	// status reports an older token as due for rotation.
+	authTokenRotation = 12 * time.Hour
+	// authTokenRotationWarning is how long before authTokenRotation a
+	// token is reported as close to rotation.
+	authTokenRotationWarning = time.Hour
+)
+
+type authStatusArgs struct {
+	JSON bool
+}
+
+func decodeAuthStatus(parsed parsedCommand) (commandCall, error) {
+	jsonOutput, err := parsedBoolFlag(parsed, "json", parsed.globals.jsonOutput)
+	if err != nil {
+		return nil, err
+	}
+	args := authStatusArgs{JSON: jsonOutput}
+	return func(ctx context.Context, _ *notebooklm.Client) error {
+		return runAuthStatus(ctx, args)
+	}, nil
+}
+
+// runAuthStatus reports on the credentials this run would use and checks
+// them with one GetOrCreateAccount call. Missing or rejected credentials
+// fail with the auth exit class, after the report is written.
+func runAuthStatus(ctx context.Context, args authStatusArgs) error {
+	token := firstNonEmpty(authToken, os.Getenv("NLM_AUTH_TOKEN"))
+	cookieHeader := firstNonEmpty(cookies, os.Getenv("NLM_COOKIES"))
+	rec := inspectCredentials(token, cookieHeader, time.Now())
+
+	var err error
+	if token == "" || cookieHeader == "" {
+		err = exitclass.With(exitclass.Auth, errors.New("authentication required: no credentials; run 'nlm auth'"))
+	} else {
+		err = probeCredentials(ctx, token, cookieHeader, &rec)
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	rec.OK = err == nil
+
+	if args.JSON {
+		data, mErr := json.MarshalIndent(rec, "", "  ")
+		if mErr != nil {
+			return mErr
+		}
+		fmt.Println(string(data))
+	} else {
+		printAuthStatus(os.Stdout, rec)
+	}
+	return err
+}
+
+// inspectCredentials describes token and cookieHeader without contacting
+// the server. Cookie expiries are known only for cookies harvested from a
+// browser by 'nlm auth' and still stored.
+func inspectCredentials(token, cookieHeader string, now time.Time) authStatusRecord {
+	rec := authStatusRecord{
+		Account:  firstNonEmpty(selectedAccount(), defaultAccount),
+		Source:   "environment",
+		AuthUser: firstNonEmpty(authUser, "0"),
+	}
+	var expiry map[string]time.Time
+	if stored := readStoredEnv(); cookieHeader != "" && stored["NLM_COOKIES"] == cookieHeader {
+		if store, err := openCredentialStore(); err == nil {
+			rec.Source = store.Location(selectedAccount())
+		}
+		expiry = parseCookieExpiry(stored["NLM_COOKIE_EXPIRES"])
+	}
+
+	names := cookieNames(cookieHeader)
+	for _, name := range names {
+		c := authCookieRecord{Name: name}
+		if t, ok := expiry[name]; ok {
+			c.Expires = t.UTC().Format(time.RFC3339)
+			switch {
+			case !t.After(now):
+				c.Expired = true
+				rec.Warnings = append(rec.Warnings, fmt.Sprintf("cookie %s expired %s", name, t.Format(time.DateOnly)))
+			case t.Sub(now) < cookieExpiryWarning:
+				c.ExpiresSoon = true
+				rec.Warnings = append(rec.Warnings, fmt.Sprintf("cookie %s expires %s", name, t.Format(time.DateOnly)))
+			}
+		}
+		rec.Cookies = append(rec.Cookies, c)
+	}
+	for _, name := range essentialCookies {
+		if !slices.Contains(names, name) {
+			rec.MissingCookies = append(rec.MissingCookies, name)
+		}
+	}
+	if cookieHeader != "" && len(rec.MissingCookies) > 0 {
+		rec.Warnings = append(rec.Warnings, "missing cookies: "+strings.Join(rec.MissingCookies, ", "))
+	}
+
+	if issued, ok := authTokenIssued(token); ok {
+		rec.TokenIssued = issued.UTC().Format(time.RFC3339)
+		due := issued.Add(authTokenRotation)
+		rec.TokenRotationDue = due.Before(now.Add(authTokenRotationWarning))
+		if rec.TokenRotationDue {
+			rec.Warnings = append(rec.Warnings, fmt.Sprintf("auth token issued %s is due for rotation; run 'nlm auth'", rec.TokenIssued))
+		}
+	}
+	return rec
+}
+
+// probeCredentials checks the credentials with GetOrCreateAccount, the
+// cheapest authenticated call, and records the account's tier and limits.
+func probeCredentials(ctx context.Context, token, cookieHeader string, rec *authStatusRecord) error {
+	client := newNotebookLMClient(notebooklm.Credentials{AuthToken: token, Cookies: cookieHeader}, commandClientOptions{})
+	start := time.Now()
+	status, err := client.GetAccountStatus(ctx)
+	rec.ProbeMillis = time.Since(start).Milliseconds()
+	if err != nil {
+		if isAuthenticationError(err) {
+			return exitclass.With(exitclass.Auth, fmt.Errorf("credentials rejected: %w", err))
+		}
+		return err
+	}
+	rec.Tier = status.Tier
+	rec.NotebookLimit = status.NotebookLimit
+	rec.SourceLimit = status.SourceLimit
+	rec.UploadLimit = status.UploadLimit
+	return nil
+}
+
+func printAuthStatus(w io.Writer, rec authStatusRecord) {
+	state := "ok"
+	if !rec.OK {
+		state = "failed: " + rec.Error
+	}
+	fmt.Fprintf(w, "Account:        %s\n", rec.Account)
+	fmt.Fprintf(w, "Credentials:    %s\n", rec.Source)
+	fmt.Fprintf(w, "Authuser:       %s\n", rec.AuthUser)
+	fmt.Fprintf(w, "Status:         %s\n", state)
+	if rec.OK {
+		fmt.Fprintf(w, "Probe:          %dms\n", rec.ProbeMillis)
+	}
+	if rec.Tier > 0 {
+		fmt.Fprintf(w, "Tier:           %d\n", rec.Tier)
+	}
+	if rec.NotebookLimit > 0 {
+		fmt.Fprintf(w, "Notebook limit: %d\n", rec.NotebookLimit)
+	}
+	if rec.SourceLimit > 0 {
+		fmt.Fprintf(w, "Source limit:   %d\n", rec.SourceLimit)
+	}
+	if rec.UploadLimit > 0 {
+		fmt.Fprintf(w, "Upload limit:   %d\n", rec.UploadLimit)
+	}
+	if rec.TokenIssued != "" {
+		fmt.Fprintf(w, "Token issued:   %s\n", rec.TokenIssued)
+	}
+	if len(rec.Cookies) > 0 {
+		fmt.Fprintln(w, "Cookies:")
+		for _, c := range rec.Cookies {
+			expires := "unknown expiry"
+			if c.Expires != "" {
+				expires = "expires " + c.Expires
+			}
+			switch {
+			case c.Expired:
+				expires += " (expired)"
+			case c.ExpiresSoon:
+				expires += " (soon)"
+			}
+			fmt.Fprintf(w, "  %-24s %s\n", c.Name, expires)
+		}
+	}
+	for _, warning := range rec.Warnings {
+		fmt.Fprintf(w, "Warning:        %s\n", warning)
+	}
+}
+
+// cookieNames returns the cookie names in a Cookie header, in order.
+func cookieNames(header string) []string {
+	var names []string
+	for part := range strings.SplitSeq(header, ";") {
+		name, _, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// formatCookieExpiry renders cookie expiries as NLM_COOKIE_EXPIRES holds
+// them: "name=unix-seconds" pairs separated by spaces, in name order.
+func formatCookieExpiry(expiry map[string]time.Time) string {
+	names := make([]string, 0, len(expiry))
+	for name := range expiry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + strconv.FormatInt(expiry[name].Unix(), 10)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func parseCookieExpiry(s string) map[string]time.Time {
+	expiry := make(map[string]time.Time)
+	for pair := range strings.FieldsSeq(s) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+			expiry[name] = time.Unix(secs, 0)
+		}
+	}
+	return expiry
+}
+
+// authTokenIssued returns when a page token was issued. SNlM0e tokens end
+// in ":<issue time in Unix milliseconds>".
+func authTokenIssued(token string) (time.Time, bool) {
+	i := strings.LastIndexByte(token, ':')
+	if i < 0 {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(token[i+1:], 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestInspectCredentials(t *testing.T) {
+	home := isolateAccounts(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	const header = "SID=a; HSID=b; SSID=c; NID=d"
+	issued := now.Add(-authTokenRotation + 30*time.Minute)
+	token := "AJpMio-token:" + strconv.FormatInt(issued.UnixMilli(), 10)
+
+	account = ""
+	if _, _, err := persistAuthToDisk(header, token, "Default", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := persistCookieExpiry(map[string]time.Time{
+		"SID":  now.Add(365 * 24 * time.Hour),
+		"HSID": now.Add(2 * 24 * time.Hour),
+		"SSID": now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := inspectCredentials(token, header, now)
+	if want := home + "/.nlm/env"; rec.Source != want {
+		t.Errorf("Source = %q, want %q", rec.Source, want)
+	}
+	if rec.Account != defaultAccount || rec.AuthUser != "0" {
+		t.Errorf("Account, AuthUser = %q, %q; want default, 0", rec.Account, rec.AuthUser)
+	}
+	want := []authCookieRecord{
+		{Name: "SID", Expires: "2027-10-18T12:00:00Z"},
+		{Name: "HSID", Expires: "2026-10-20T12:00:00Z", ExpiresSoon: true},
+		{Name: "SSID", Expires: "2026-10-18T11:00:00Z", Expired: true},
+		{Name: "NID"},
+	}
+	if !slices.Equal(rec.Cookies, want) {
+		t.Errorf("Cookies = %+v, want %+v", rec.Cookies, want)
+	}
+	if !slices.Equal(rec.MissingCookies, []string{"APISID", "SAPISID"}) {
+		t.Errorf("MissingCookies = %v", rec.MissingCookies)
+	}
+	if !rec.TokenRotationDue || rec.TokenIssued != issued.Format(time.RFC3339) {
+		t.Errorf("TokenIssued, TokenRotationDue = %q, %v; want %s, true", rec.TokenIssued, rec.TokenRotationDue, issued.Format(time.RFC3339))
+	}
+	if got := len(rec.Warnings); got != 4 {
+		t.Errorf("Warnings = %q, want 4", rec.Warnings)
+	}
+
+	// Cookies other than the stored ones have no known expiry.
+	rec = inspectCredentials("plain-token", "SID=other", now)
+	if rec.Source != "environment" || rec.Cookies[0].Expires != "" || rec.TokenIssued != "" {
+		t.Errorf("inspect unstored credentials = %+v", rec)
+	}
+}
+
+func TestPersistAuthDropsStaleCookieExpiry(t *testing.T) {
+	isolateAccounts(t)
+	account = ""
+	expiry := map[string]time.Time{"SID": time.Unix(1800000000, 0)}
+	if _, _, err := persistAuthToDisk("SID=a", "token", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := persistCookieExpiry(expiry); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := persistAuthToDisk("SID=a", "token2", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := readStoredEnv()["NLM_COOKIE_EXPIRES"]; got != "SID=1800000000" {
+		t.Errorf("expiry after token update = %q, want SID=1800000000", got)
+	}
+	if _, _, err := persistAuthToDisk("SID=b", "token2", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := readStoredEnv()["NLM_COOKIE_EXPIRES"]; got != "" {
+		t.Errorf("expiry after cookie change = %q, want empty", got)
+	}
+}
+
+func TestAuthTokenIssued(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		token string
+		want  int64
+		ok    bool
+	}{
+		{"AJpMio2abc:1760788800000", 1760788800000, true},
+		{"AJpMio2abc", 0, false},
+		{"AJpMio2abc:soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := authTokenIssued(tt.token)
+		if ok != tt.ok || (ok && got.UnixMilli() != tt.want) {
+			t.Errorf("authTokenIssued(%q) = %v, %v; want %d, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
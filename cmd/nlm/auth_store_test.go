@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// fakeSecretTool installs a secret-tool stand-in that keeps each account's
+// secret in a file under dir.
+func fakeSecretTool(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake secret-tool is a shell script")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+dir=` + dir + `
+cmd=$1; shift
+case $cmd in
+store) shift 2; cat > "$dir/$4" ;;
+lookup) [ -f "$dir/$4" ] || exit 1; cat "$dir/$4" ;;
+clear) rm -f "$dir/$4" ;;
+search)
+	status=1
+	for f in "$dir"/*; do
+		[ -f "$f" ] || continue
+		echo "attribute.account = ${f##*/}" >&2
+		status=0
+	done
+	exit $status ;;
+esac
+`
+	path := filepath.Join(t.TempDir(), "secret-tool")
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	saved := secretTool
+	secretTool = path
+	t.Cleanup(func() { secretTool = saved })
+}
+
+func TestCredentialStoresRoundTrip(t *testing.T) {
+	isolateAccounts(t)
+	fakeSecretTool(t)
+	t.Setenv("NLM_CREDENTIAL_PASSPHRASE", "correct horse")
+
+	values := map[string]string{"NLM_COOKIES": "SID=secret", "NLM_AUTH_TOKEN": "tok", "NLM_AUTHUSER": "1"}
+	for _, name := range credentialStoreNames {
+		store, err := newCredentialStore(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Load("work"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s: Load(missing) error = %v, want not exist", name, err)
+		}
+		for _, account := range []string{"", "work"} {
+			if err := store.Save(account, values); err != nil {
+				t.Fatalf("%s: Save(%q): %v", name, account, err)
+			}
+		}
+		got, err := store.Load("work")
+		if err != nil {
+			t.Fatalf("%s: Load: %v", name, err)
+		}
+		if want := parseStoredEnv(formatStoredEnv(values)); !maps.Equal(got, want) {
+			t.Errorf("%s: Load = %v, want %v", name, got, want)
+		}
+		accounts, err := store.Accounts()
+		if err != nil || !slices.Equal(accounts, []string{"default", "work"}) {
+			t.Errorf("%s: Accounts = %v, %v; want [default work]", name, accounts, err)
+		}
+		for _, account := range []string{"", "work"} {
+			if err := store.Delete(account); err != nil {
+				t.Errorf("%s: Delete(%q): %v", name, account, err)
+			}
+		}
+		if accounts, _ := store.Accounts(); len(accounts) != 0 {
+			t.Errorf("%s: Accounts after Delete = %v", name, accounts)
+		}
+	}
+}
+
+func TestEncryptedStoreHidesCredentials(t *testing.T) {
+	home := isolateAccounts(t)
+	t.Setenv("NLM_CREDENTIAL_PASSPHRASE", "correct horse")
+
+	store := encryptedStore{}
+	if err := store.Save("work", map[string]string{"NLM_COOKIES": "SID=secret"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(home, ".nlm", "profiles", "work.env.enc")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("SID=secret")) {
+		t.Errorf("%s holds the cookie in plaintext", path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("%s mode = %v, %v; want 0600", path, info.Mode().Perm(), err)
+	}
+
+	t.Setenv("NLM_CREDENTIAL_PASSPHRASE", "wrong")
+	if _, err := store.Load("work"); !errors.Is(err, errWrongPassphrase) {
+		t.Errorf("Load with wrong passphrase error = %v, want %v", err, errWrongPassphrase)
+	}
+}
+
+func TestEncryptedStoreSaveChecksPassphrase(t *testing.T) {
+	home := isolateAccounts(t)
+	t.Setenv("NLM_CREDENTIAL_PASSPHRASE", "correct horse")
+
+	store := encryptedStore{}
+	if err := store.Save("work", map[string]string{"NLM_COOKIES": "SID=secret"}); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(home, ".nlm", "profiles")
+	path := filepath.Join(dir, "work.env.enc")
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No confirmation is asked once a file exists, so a mistyped
+	// passphrase is caught against the stored files instead.
+	t.Setenv("NLM_CREDENTIAL_PASSPHRASE", "wrong")
+	for _, account := range []string{"work", "other"} {
+		if err := store.Save(account, map[string]string{"NLM_COOKIES": "SID=new"}); !errors.Is(err, errWrongPassphrase) {
+			t.Errorf("Save(%q) with wrong passphrase error = %v, want %v", account, err, errWrongPassphrase)
+		}
+	}
+	if after, err := os.ReadFile(path); err != nil || !bytes.Equal(after, before) {
+		t.Errorf("%s changed after a rejected save (%v)", path, err)
+	}
+
+	t.Setenv("NLM_CREDENTIAL_PASSPHRASE", "correct horse")
+	if err := store.Save("work", map[string]string{"NLM_COOKIES": "SID=new"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Load("work"); err != nil || got["NLM_COOKIES"] != "SID=new" {
+		t.Errorf("Load after save = %v, %v; want the new cookie", got, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !slices.Equal(names, []string{"work.env.enc"}) {
+		t.Errorf("profiles dir holds %v, want only work.env.enc", names)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("%s mode = %v, %v; want 0600", path, info.Mode().Perm(), err)
+	}
+}
+
+func TestAdoptCredentialPassphrase(t *testing.T) {
+	t.Setenv("NLM_CREDENTIAL_PASSPHRASE", "correct horse")
+	t.Cleanup(func() { cachedPassphrase = "" })
+
+	adoptCredentialPassphrase()
+	if _, ok := os.LookupEnv("NLM_CREDENTIAL_PASSPHRASE"); ok {
+		t.Error("NLM_CREDENTIAL_PASSPHRASE is still in the environment child processes inherit")
+	}
+	if p, err := credentialPassphrase(false); err != nil || p != "correct horse" {
+		t.Errorf("credentialPassphrase() = %q, %v; want the adopted passphrase", p, err)
+	}
+}
+
+func TestMigrateCredentials(t *testing.T) {
+	home := isolateAccounts(t)
+	fakeSecretTool(t)
+	t.Setenv("NLM_CREDENTIAL_PASSPHRASE", "correct horse")
+	t.Setenv("NLM_CREDENTIAL_STORE", "")
+
+	for _, name := range []string{"", "work"} {
+		account = name
+		if _, _, err := persistAuthToDisk("cookie-"+name, "token-"+name, "", "", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, to := range []string{keyringStoreName, encryptedStoreName, fileStoreName} {
+		if err := migrateCredentials(to); err != nil {
+			t.Fatalf("migrate to %s: %v", to, err)
+		}
+		if got := configuredCredentialStore(); got != to {
+			t.Fatalf("store after migrating to %s = %s", to, got)
+		}
+		account = "work"
+		if got := readStoredEnv()["NLM_COOKIES"]; got != "cookie-work" {
+			t.Errorf("%s: work cookies = %q, want cookie-work", to, got)
+		}
+		account = ""
+		if got := readStoredEnv()["NLM_AUTH_TOKEN"]; got != "token-" {
+			t.Errorf("%s: default token = %q, want token-", to, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(home, ".nlm", "profiles", "work.env.enc")); !os.IsNotExist(err) {
+		t.Errorf("encrypted copy left behind: %v", err)
+	}
+	if err := migrateCredentials(fileStoreName); err == nil {
+		t.Error("migrate to the store in use: want error")
+	}
+}
+
+func TestParseSecretToolAccounts(t *testing.T) {
+	t.Parallel()
+
+	out := []byte(`[/org/freedesktop/secrets/collection/login/2]
+label = nlm credentials (work)
+secret = NLM_COOKIES="x"
+attribute.service = nlm
+attribute.account = work
+[/org/freedesktop/secrets/collection/login/1]
+attribute.account = default
+attribute.account = ../bad
+`)
+	if got, want := parseSecretToolAccounts(out), []string{"default", "work"}; !slices.Equal(got, want) {
+		t.Errorf("parseSecretToolAccounts() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// answerNoteTitleLimit bounds a note title taken from the question.
+const answerNoteTitleLimit = 80
+
+type chatSaveNoteArgs struct {
+	NotebookID     string
+	ConversationID string
+	MessageID      string
+	Title          string
+}
+
+func chatSaveNoteFlagSpecs() []flagSpec {
+	return []flagSpec{
+		{Name: "title", Value: "title", Description: "Note title (default: the question the answer replies to)"},
+	}
+}
+
+func decodeChatSaveNote(parsed parsedCommand) (commandCall, error) {
+	var args chatSaveNoteArgs
+	var err error
+	if args.NotebookID, err = parsedArgument(parsed, "notebook"); err != nil {
+		return nil, err
+	}
+	if args.ConversationID, err = parsedArgument(parsed, "conversation"); err != nil {
+		return nil, err
+	}
+	if args.MessageID, err = parsedArgument(parsed, "message"); err != nil {
+		return nil, err
+	}
+	args.Title = parsedStringFlag(parsed, "title", "")
+	return func(_ context.Context, client *notebooklm.Client) error {
+		return chatSaveNote(client, args)
+	}, nil
+}
+
+// chatSaveNote saves one answer of a conversation as a note. The local
+// session is tried first, as it holds the answer's rich tree and
+// citations; server history covers conversations held only by the server
+// and message IDs the local session never recorded.
+func chatSaveNote(c *notebooklm.Client, args chatSaveNoteArgs) error {
+	session, err := loadChatSessionByConversation(args.NotebookID, args.ConversationID)
+	index := -1
+	if err == nil {
+		index, err = findChatAnswer(session.Messages, args.MessageID)
+	}
+	if index < 0 {
+		conversationID := resolveConversationID(c, args.NotebookID, args.ConversationID)
+		messages, herr := c.GetConversationHistory(context.Background(), args.NotebookID, conversationID)
+		if herr != nil {
+			return fmt.Errorf("no local answer %s (%v) and no server history: %w", args.MessageID, err, herr)
+		}
+		session = chatSessionFromServerHistory(args.NotebookID, conversationID, messages)
+		if index, err = findChatAnswer(session.Messages, args.MessageID); err != nil {
+			return err
+		}
+	}
+	note, err := saveChatAnswerNote(c, session, index, args.Title)
+	if err != nil {
+		return err
+	}
+	fmt.Println(note.GetNoteId())
+	return nil
+}
+
+// findChatAnswer returns the index in messages of the assistant answer ref
+// names: "last", a 1-based answer number as 'chat show' lists them, or a
+// message ID or its prefix.
+func findChatAnswer(messages []storedMessage, ref string) (int, error) {
+	var answers []int
+	for i, m := range messages {
+		if m.Role == "assistant" {
+			answers = append(answers, i)
+		}
+	}
+	if len(answers) == 0 {
+		return -1, errors.New("the conversation has no answers")
+	}
+	if ref == "last" {
+		return answers[len(answers)-1], nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(answers) {
+			return -1, fmt.Errorf("answer %d out of range; the conversation has %d answer(s)", n, len(answers))
+		}
+		return answers[n-1], nil
+	}
+	for _, i := range answers {
+		if id := messages[i].MessageID; id != "" && strings.HasPrefix(id, ref) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no answer with message ID %s", ref)
+}
+
+// saveChatAnswerNote creates a note holding the answer at index, rendered
+// as Markdown with its citations as footnotes, and titled by title or else
+// by the question it answers. Excerpts come from server history, as the
+// live stream persists citations without them; when history is out of
+// reach the footnotes name their sources only.
+func saveChatAnswerNote(c *notebooklm.Client, session *chatSession, index int, title string) (*notebooklm.Note, error) {
+	answer := session.Messages[index]
+	message := chatDocMessage{Role: answer.Role, Content: answer.Content, Citations: answer.Citations}
+	if answer.Rich != nil {
+		message.Rich = richDocumentFromProto(answer.Rich)
+	}
+	key := citationContentKey(answer.Content)
+	history, err := c.GetConversationHistory(context.Background(), session.NotebookID, session.ConversationID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: could not fetch history for excerpts; saving without them: %v\n", err)
+	}
+	for _, m := range history {
+		if m.Role == 2 && len(m.Citations) > 0 && citationContentKey(m.Content) == key {
+			message.Citations = m.Citations
+			break
+		}
+	}
+
+	var body bytes.Buffer
+	err = renderAnswerNoteMarkdown(&body, message, chatRenderContext{
+		ResolveTitle: newNotebookSourceIndex(c, session.NotebookID).title,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if title == "" {
+		title = answerNoteTitle(session.Messages, index)
+	}
+	note, err := c.CreateNote(context.Background(), session.NotebookID, title, body.String())
+	if err != nil {
+		return nil, fmt.Errorf("create note: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Created note: %s\n", title)
+	return note, nil
+}
+
+// answerNoteTitle titles a saved answer after the question before it.
+func answerNoteTitle(messages []storedMessage, index int) string {
+	for i := index - 1; i >= 0; i-- {
+		if messages[i].Role != "user" {
+			continue
+		}
+		title := strings.Join(strings.Fields(messages[i].Content), " ")
+		if r := []rune(title); len(r) > answerNoteTitleLimit {
+			title = strings.TrimSpace(string(r[:answerNoteTitleLimit-1])) + "…"
+		}
+		if title != "" {
+			return title
+		}
+		break
+	}
+	return "Chat answer"
+}
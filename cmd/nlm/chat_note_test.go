@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindChatAnswer(t *testing.T) {
+	messages := []storedMessage{
+		{Role: "user", Content: "first question"},
+		{Role: "assistant", Content: "first answer", MessageID: "aaaa1111-0000"},
+		{Role: "user", Content: "second question"},
+		{Role: "assistant", Content: "second answer"},
+	}
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr string
+	}{
+		{ref: "last", want: 3},
+		{ref: "1", want: 1},
+		{ref: "2", want: 3},
+		{ref: "aaaa", want: 1},
+		{ref: "3", wantErr: "out of range"},
+		{ref: "bbbb", wantErr: "no answer with message ID bbbb"},
+	}
+	for _, tt := range tests {
+		got, err := findChatAnswer(messages, tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("findChatAnswer(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("findChatAnswer(%q) = %d, %v; want %d", tt.ref, got, err, tt.want)
+		}
+	}
+	if _, err := findChatAnswer(messages[:1], "last"); err == nil {
+		t.Error("findChatAnswer found an answer in a conversation without one")
+	}
+}
+
+func TestAnswerNoteTitle(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	messages := []storedMessage{
+		{Role: "assistant", Content: "greeting"},
+		{Role: "user", Content: "  what do\ncats   eat? "},
+		{Role: "assistant", Content: "fish"},
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: "many words"},
+	}
+	if got := answerNoteTitle(messages, 2); got != "what do cats eat?" {
+		t.Errorf("title = %q, want the question", got)
+	}
+	if got := answerNoteTitle(messages, 4); len([]rune(got)) > answerNoteTitleLimit || !strings.HasSuffix(got, "…") {
+		t.Errorf("title = %q, want at most %d runes ending in an ellipsis", got, answerNoteTitleLimit)
+	}
+	if got := answerNoteTitle(messages, 0); got != "Chat answer" {
+		t.Errorf("title without a question = %q, want Chat answer", got)
+	}
+}
@@ -10,11 +10,12 @@ type chatRenderOptions struct {
 	Verbose          bool
 	CitationMode     string
 	ResolveCitations bool
-	ExcerptBudget    int  // >0 shows the cited source span under each citation, clipped to this many chars
-	HideConfidence   bool // --citation-confidence=off: drop the (p=) column from the citation list
-	HideSpans        bool // --citation-spans=off: drop the trailing [chars N-M] from citation rows
-	IncludeFollowUps bool // --include-follow-ups: retain generated trailing prompts in HTML
-	Backfill         bool // --backfill: persist missing citations and rich trees from server history
+	ExcerptBudget    int    // >0 shows the cited source span under each citation, clipped to this many chars
+	HideConfidence   bool   // --citation-confidence=off: drop the (p=) column from the citation list
+	HideSpans        bool   // --citation-spans=off: drop the trailing [chars N-M] from citation rows
+	IncludeFollowUps bool   // --include-follow-ups: retain generated trailing prompts in HTML
+	Backfill         bool   // --backfill: persist missing citations and rich trees from server history
+	Message          string // --message N|ID: render only that message and its question or answer
 
 	// Whole-document output format for chat-show: "" (text, default),
 	// "markdown", or "html". OutFile and Open apply to html.
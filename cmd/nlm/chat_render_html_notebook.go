@@ -128,6 +128,9 @@ func notebookDocumentFromSession(session *chatSession) chatDocument {
 			Content:  message.Content,
 			Thinking: message.Thinking,
 		}
+		if message.Scope != nil {
+			dm.Scope, dm.ScopeSourceIDs = message.Scope.Selector, message.Scope.SourceIDs
+		}
 		if message.Role == "assistant" {
 			dm.Citations = message.Citations
 			if message.Rich != nil {
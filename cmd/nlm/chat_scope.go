@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tmc/nlm/internal/sourceselect"
+)
+
+// chatScope is the set of sources chat turns are grounded on. A nil scope
+// means the whole notebook. Scopes are replaced, never mutated, so a
+// session and the messages sent under a scope can share one value.
+type chatScope struct {
+	Selector  string   `json:"selector"`   // how the sources were chosen, e.g. "--source-match draft" or "/label Papers"
+	SourceIDs []string `json:"source_ids"` // the sources requests were limited to
+}
+
+// sourceIDs returns the IDs to send with a chat request: nil for the whole
+// notebook.
+func (s *chatScope) sourceIDs() []string {
+	if s == nil {
+		return nil
+	}
+	return s.SourceIDs
+}
+
+func (s *chatScope) String() string {
+	if s == nil {
+		return "all sources"
+	}
+	return fmt.Sprintf("%s (%d source(s))", s.Selector, len(s.SourceIDs))
+}
+
+// chatScopeFromSelectors resolves the chat command's selector flags to the
+// scope the REPL starts with, or nil when none is set.
+func chatScopeFromSelectors(c sourceselect.Client, notebookID string, opts selectorOptions) (*chatScope, error) {
+	if opts.Empty() {
+		return nil, nil
+	}
+	return resolveChatScope(c, notebookID, opts, describeSelectors(opts))
+}
+
+func resolveChatScope(c sourceselect.Client, notebookID string, opts selectorOptions, selector string) (*chatScope, error) {
+	ids, err := resolveSourceSelectorsWithOptions(c, notebookID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%s selected no sources", selector)
+	}
+	return &chatScope{Selector: selector, SourceIDs: ids}, nil
+}
+
+// describeSelectors spells out the selector flags that are set, in flag
+// form, for a scope's Selector.
+func describeSelectors(opts selectorOptions) string {
+	var parts []string
+	for _, f := range []struct{ name, value string }{
+		{"source-ids", opts.SourceIDs},
+		{"source-match", opts.SourceMatch},
+		{"source-exclude", opts.SourceExclude},
+		{"label-ids", opts.LabelIDs},
+		{"label-match", opts.LabelMatch},
+		{"label-exclude", opts.LabelExclude},
+	} {
+		if f.value != "" {
+			parts = append(parts, "--"+f.name+" "+f.value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// runChatScopeCommand handles the REPL commands that change or show the
+// session's source scope, and reports whether input was one of them:
+//
+//	/sources          list the notebook's sources, marking those in scope
+//	/scope            show the scope
+//	/scope <regex>    limit to sources whose title or ID matches
+//	/scope all        use every source again
+//	/label <name>     limit to sources with the label
+//	/exclude <regex>  drop matching sources from the scope
+//
+// A selector that fails to resolve leaves the scope unchanged.
+func runChatScopeCommand(c sourceselect.Client, session *chatSession, input string, stdout, stderr io.Writer) bool {
+	name, arg, _ := strings.Cut(input, " ")
+	name = strings.ToLower(name)
+	arg = strings.TrimSpace(arg)
+
+	var (
+		opts     selectorOptions
+		selector string
+	)
+	switch name {
+	case "/sources":
+		if err := listChatScopeSources(c, session, stdout); err != nil {
+			fmt.Fprintf(stderr, "nlm: /sources: %v\n", err)
+		}
+		return true
+	case "/scope":
+		switch arg {
+		case "":
+			fmt.Fprintf(stdout, "Scope: %s\n", session.Scope)
+			return true
+		case "all":
+			session.Scope = nil
+			fmt.Fprintf(stdout, "Scope: %s\n", session.Scope)
+			return true
+		}
+		opts, selector = selectorOptions{SourceMatch: arg}, "/scope "+arg
+	case "/label":
+		if arg == "" {
+			fmt.Fprintln(stderr, "nlm: usage: /label <name>")
+			return true
+		}
+		opts, selector = selectorOptions{LabelMatch: "(?i)^" + regexp.QuoteMeta(arg) + "$"}, "/label "+arg
+	case "/exclude":
+		if arg == "" {
+			fmt.Fprintln(stderr, "nlm: usage: /exclude <regex>")
+			return true
+		}
+		opts = selectorOptions{SourceIDs: strings.Join(session.Scope.sourceIDs(), ","), SourceExclude: arg}
+		selector = "/exclude " + arg
+		if session.Scope != nil {
+			selector = session.Scope.Selector + " " + selector
+		}
+	default:
+		return false
+	}
+
+	scope, err := resolveChatScope(c, session.NotebookID, opts, selector)
+	if err != nil {
+		fmt.Fprintf(stderr, "nlm: %s: %v\n", name, err)
+		return true
+	}
+	session.Scope = scope
+	fmt.Fprintf(stdout, "Scope: %s\n", session.Scope)
+	return true
+}
+
+// listChatScopeSources prints the notebook's sources, marking with '*'
+// those the next turn is grounded on.
+func listChatScopeSources(c sourceselect.Client, session *chatSession, w io.Writer) error {
+	p, err := c.GetProject(context.Background(), session.NotebookID)
+	if err != nil {
+		return fmt.Errorf("list sources: %w", err)
+	}
+	fmt.Fprintf(w, "Scope: %s\n", session.Scope)
+	for _, src := range p.Sources {
+		id := src.GetSourceId().GetSourceId()
+		mark := " "
+		if session.Scope == nil || slices.Contains(session.Scope.SourceIDs, id) {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "  %s %s  %s\n", mark, id, strings.TrimSpace(src.GetTitle()))
+	}
+	return nil
+}
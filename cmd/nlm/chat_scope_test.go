@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+type fakeScopeClient struct {
+	sources []*pb.Source
+	labels  []notebooklm.Label
+}
+
+func (f *fakeScopeClient) GetProject(context.Context, string) (*notebooklm.Notebook, error) {
+	return &notebooklm.Notebook{Sources: f.sources}, nil
+}
+
+func (f *fakeScopeClient) GetLabels(context.Context, string) ([]notebooklm.Label, error) {
+	return f.labels, nil
+}
+
+func newFakeScopeClient() *fakeScopeClient {
+	source := func(id, title string) *pb.Source {
+		return &pb.Source{SourceId: &pb.SourceId{SourceId: id}, Title: title}
+	}
+	return &fakeScopeClient{
+		sources: []*pb.Source{
+			source("s1", "design/overview"),
+			source("s2", "design/draft"),
+			source("s3", "paper: attention"),
+		},
+		labels: []notebooklm.Label{{LabelID: "l1", Name: "Papers", SourceIDs: []string{"s3"}}},
+	}
+}
+
+func TestChatScopeCommands(t *testing.T) {
+	c := newFakeScopeClient()
+	session := &chatSession{NotebookID: "nb"}
+	run := func(input string) string {
+		t.Helper()
+		var stdout bytes.Buffer
+		if !runChatScopeCommand(c, session, input, &stdout, io.Discard) {
+			t.Fatalf("%q was not handled as a scope command", input)
+		}
+		return stdout.String()
+	}
+	wantScope := func(selector string, ids ...string) {
+		t.Helper()
+		want := &chatScope{Selector: selector, SourceIDs: ids}
+		if !reflect.DeepEqual(session.Scope, want) {
+			t.Errorf("scope = %+v, want %+v", session.Scope, want)
+		}
+	}
+
+	run("/scope ^design/")
+	wantScope("/scope ^design/", "s1", "s2")
+	run("/exclude draft")
+	wantScope("/scope ^design/ /exclude draft", "s1")
+	run("/label papers")
+	wantScope("/label papers", "s3")
+	run("/scope no-such-source")
+	wantScope("/label papers", "s3")
+
+	if got := run("/sources"); !strings.Contains(got, "  * s3  paper: attention") || !strings.Contains(got, "    s1  design/overview") {
+		t.Errorf("/sources output:\n%s", got)
+	}
+	run("/scope all")
+	if session.Scope != nil {
+		t.Errorf("scope after /scope all = %+v, want nil", session.Scope)
+	}
+	run("/exclude paper")
+	wantScope("/exclude paper", "s1", "s2")
+
+	if runChatScopeCommand(c, session, "/history", io.Discard, io.Discard) {
+		t.Error("/history was handled as a scope command")
+	}
+}
+
+func TestChatScopeFromSelectors(t *testing.T) {
+	c := newFakeScopeClient()
+	scope, err := chatScopeFromSelectors(c, "nb", selectorOptions{SourceMatch: "design", SourceExclude: "draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &chatScope{Selector: "--source-match design --source-exclude draft", SourceIDs: []string{"s1"}}
+	if !reflect.DeepEqual(scope, want) {
+		t.Errorf("scope = %+v, want %+v", scope, want)
+	}
+	if scope, err := chatScopeFromSelectors(c, "nb", selectorOptions{}); scope != nil || err != nil {
+		t.Errorf("scope without selectors = %+v, %v; want nil", scope, err)
+	}
+}
+
+func TestChatShowRendersTurnScope(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	scope := &chatScope{Selector: "/label Papers", SourceIDs: []string{"s3"}}
+	session := &chatSession{
+		NotebookID:     "notebook",
+		ConversationID: "abcdef12-3456-7890-abcd-ef1234567890",
+		Scope:          scope,
+		Messages: []storedMessage{
+			{Role: "user", Content: "scoped question", Scope: scope},
+			{Role: "assistant", Content: "scoped answer"},
+			{Role: "user", Content: "open question"},
+		},
+	}
+	if err := saveChatSessionForConversation(session); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadChatSessionByConversation("notebook", "abcdef12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Messages[0].Scope, scope) || loaded.Messages[2].Scope != nil || !reflect.DeepEqual(loaded.Scope, scope) {
+		data, _ := json.Marshal(loaded)
+		t.Fatalf("scope did not survive a save and load:\n%s", data)
+	}
+
+	output := captureChatShowStdout(t, func() error {
+		return chatShowWithClients("notebook", "abcdef12", chatRenderOptions{}, nil, nil)
+	})
+	if !strings.Contains(output, "scoped question\n(scope: /label Papers — s3)\n") {
+		t.Errorf("chat show output lacks the turn's scope:\n%s", output)
+	}
+	if strings.Count(output, "scope:") != 1 {
+		t.Errorf("chat show output shows a scope for an unscoped turn:\n%s", output)
+	}
+}
@@ -0,0 +1,680 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// chatSearchIndexVersion is bumped when the index layout or tokenizer
+// changes; an index of another version is rebuilt from the transcripts.
+const chatSearchIndexVersion = 1
+
+// chatSearchSnippetWidth bounds a hit's snippet, in runes.
+const chatSearchSnippetWidth = 160
+
+// chatSearchIndex is an inverted index over the local chat transcripts in
+// ~/.nlm. It is kept up to date as sessions are saved and reconciled against
+// the transcript files before each search, so transcripts written by older
+// versions, or by a process that lost a race to write the index, are picked
+// up without a manual rebuild. The index carries no message text: snippets
+// are cut from the transcripts of the hits.
+type chatSearchIndex struct {
+	Version int `json:"version"`
+	// Files maps a transcript file name to the stamp it had when indexed.
+	// The default session file and the conversation file written by
+	// saveChatSession hold the same conversation, so both name one doc.
+	Files map[string]chatIndexFile `json:"files"`
+	// Docs maps a doc key (see chatIndexDocKey) to its conversation.
+	Docs map[string]*chatIndexDoc `json:"docs"`
+	// Postings maps a term to the messages containing it.
+	Postings map[string][]chatPosting `json:"postings"`
+}
+
+type chatIndexFile struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Doc     string    `json:"doc"`
+}
+
+type chatIndexDoc struct {
+	NotebookID     string             `json:"notebook_id"`
+	ConversationID string             `json:"conversation_id,omitempty"`
+	File           string             `json:"file"`
+	Messages       []chatIndexMessage `json:"messages"`
+	// Terms lists the doc's distinct terms, so re-indexing a conversation
+	// touches only its own postings.
+	Terms []string `json:"terms"`
+}
+
+type chatIndexMessage struct {
+	Role      string    `json:"role"`
+	MessageID string    `json:"message_id,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	Length    int       `json:"length"`
+}
+
+type chatPosting struct {
+	Doc     string `json:"d"`
+	Message int    `json:"m"`
+	Count   int    `json:"n"`
+}
+
+type chatSearchOptions struct {
+	Query      string
+	NotebookID string
+	Since      time.Time
+	Role       string
+	Limit      int
+	JSON       bool
+}
+
+// chatSearchHit is one matching message. Message is its 1-based position in
+// the transcript, the number 'chat show --message' takes.
+type chatSearchHit struct {
+	NotebookID     string
+	ConversationID string
+	Message        int
+	MessageID      string
+	Role           string
+	Timestamp      time.Time
+	Score          float64
+	Snippet        string
+	Highlights     [][2]int // rune offsets into Snippet
+}
+
+func chatSearchFlagSpecs() []flagSpec {
+	return []flagSpec{
+		{Name: "notebook", Value: "id", Description: "Only search conversations of this notebook"},
+		{Name: "since", Value: "date", Description: "Only search messages from this date (YYYY-MM-DD, RFC 3339, or an age such as 7d or 36h)"},
+		{Name: "role", Value: "role", Description: "Only search user or assistant messages"},
+		{Name: "limit", Value: "n", Description: "Show at most n hits (default 20)"},
+	}
+}
+
+func decodeChatSearch(parsed parsedCommand) (commandCall, error) {
+	query, err := parsedArguments(parsed, "query")
+	if err != nil {
+		return nil, err
+	}
+	opts := chatSearchOptions{
+		Query:      strings.Join(query, " "),
+		NotebookID: parsedStringFlag(parsed, "notebook", ""),
+		Role:       parsedStringFlag(parsed, "role", ""),
+	}
+	switch opts.Role {
+	case "", "user", "assistant":
+	default:
+		return nil, badArgsf("--role must be user or assistant, not %q", opts.Role)
+	}
+	if since := parsedStringFlag(parsed, "since", ""); since != "" {
+		if opts.Since, err = parseChatSearchSince(since, time.Now()); err != nil {
+			return nil, badArgsf("--since: %v", err)
+		}
+	}
+	if opts.Limit, err = parsedIntFlag(parsed, "limit", 20); err != nil {
+		return nil, err
+	}
+	if opts.Limit <= 0 {
+		return nil, badArgsf("--limit must be greater than 0")
+	}
+	if opts.JSON, err = parsedBoolFlag(parsed, "json", parsed.globals.jsonOutput); err != nil {
+		return nil, err
+	}
+	if len(chatSearchTerms(opts.Query)) == 0 {
+		return nil, badArgsf("query %q has no words to search for", opts.Query)
+	}
+	return func(context.Context, *notebooklm.Client) error {
+		return chatSearch(os.Stdout, os.Stderr, opts, isTerminal(os.Stdout))
+	}, nil
+}
+
+// parseChatSearchSince reads a --since value: a date, an RFC 3339 time, or
+// an age counted back from now in days (7d) or any Go duration (36h).
+func parseChatSearchSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (YYYY-MM-DD), an RFC 3339 time, or an age such as 7d", s)
+}
+
+func chatSearch(stdout, stderr io.Writer, opts chatSearchOptions, tty bool) error {
+	dir, err := chatSessionDir()
+	if err != nil {
+		return err
+	}
+	index, err := syncChatSearchIndex(dir)
+	if err != nil {
+		return err
+	}
+	hits := index.search(opts)
+	if len(hits) > opts.Limit {
+		hits = hits[:opts.Limit]
+	}
+	terms := chatSearchTerms(opts.Query)
+	for i := range hits {
+		hits[i].Snippet, hits[i].Highlights = chatSearchSnippet(index, dir, &hits[i], terms)
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(stdout)
+		for _, h := range hits {
+			rec := chatSearchRecord{
+				NotebookID:     h.NotebookID,
+				ConversationID: h.ConversationID,
+				Message:        h.Message,
+				MessageID:      h.MessageID,
+				Role:           h.Role,
+				Score:          math.Round(h.Score*1000) / 1000,
+				Snippet:        h.Snippet,
+				Highlights:     h.Highlights,
+			}
+			if !h.Timestamp.IsZero() {
+				rec.Timestamp = h.Timestamp.Format(time.RFC3339)
+			}
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(hits) == 0 {
+		fmt.Fprintln(stderr, "No matching chat messages.")
+		return nil
+	}
+	for i, h := range hits {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		conversation := shortID(h.ConversationID)
+		if conversation == "" {
+			conversation = "-"
+		}
+		header := fmt.Sprintf("%s %s --message %d  %s", h.NotebookID, conversation, h.Message, h.Role)
+		if !h.Timestamp.IsZero() {
+			header += "  " + h.Timestamp.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintln(stdout, header)
+		fmt.Fprintln(stdout, "    "+highlightSnippet(h.Snippet, h.Highlights, tty))
+	}
+	if tty {
+		fmt.Fprintln(stderr, "\nOpen a hit with: nlm chat show <notebook-id> <conversation-id> --message <n>")
+	}
+	return nil
+}
+
+// highlightSnippet marks the matched words: bold on a terminal, **word**
+// elsewhere, so piped output keeps the highlighting readable.
+func highlightSnippet(snippet string, highlights [][2]int, tty bool) string {
+	on, off := "**", "**"
+	if tty {
+		on, off = "\033[1m", "\033[0m"
+	}
+	runes := []rune(snippet)
+	var b strings.Builder
+	last := 0
+	for _, h := range highlights {
+		b.WriteString(string(runes[last:h[0]]))
+		b.WriteString(on + string(runes[h[0]:h[1]]) + off)
+		last = h[1]
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String()
+}
+
+// search returns the messages holding every query term, best first, ranked
+// by BM25 over the filtered messages' lengths.
+func (x *chatSearchIndex) search(opts chatSearchOptions) []chatSearchHit {
+	terms := chatSearchTerms(opts.Query)
+	keep := func(doc *chatIndexDoc, m chatIndexMessage) bool {
+		if opts.NotebookID != "" && doc.NotebookID != opts.NotebookID {
+			return false
+		}
+		if opts.Role != "" && m.Role != opts.Role {
+			return false
+		}
+		return opts.Since.IsZero() || !m.Timestamp.Before(opts.Since)
+	}
+
+	var count, total int
+	for _, doc := range x.Docs {
+		for _, m := range doc.Messages {
+			if keep(doc, m) {
+				count++
+				total += m.Length
+			}
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	avgLength := float64(total) / float64(count)
+
+	type messageKey struct {
+		doc     string
+		message int
+	}
+	scores := map[messageKey]float64{}
+	matched := map[messageKey]int{}
+	for _, term := range terms {
+		var postings []chatPosting
+		for _, p := range x.Postings[term] {
+			if doc := x.Docs[p.Doc]; doc != nil && p.Message < len(doc.Messages) && keep(doc, doc.Messages[p.Message]) {
+				postings = append(postings, p)
+			}
+		}
+		idf := math.Log(1 + (float64(count)-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+		for _, p := range postings {
+			const k1, b = 1.2, 0.75
+			length := float64(x.Docs[p.Doc].Messages[p.Message].Length)
+			tf := float64(p.Count)
+			key := messageKey{p.Doc, p.Message}
+			scores[key] += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*length/avgLength))
+			matched[key]++
+		}
+	}
+
+	var hits []chatSearchHit
+	for key, score := range scores {
+		if matched[key] < len(terms) {
+			continue
+		}
+		doc := x.Docs[key.doc]
+		m := doc.Messages[key.message]
+		hits = append(hits, chatSearchHit{
+			NotebookID:     doc.NotebookID,
+			ConversationID: doc.ConversationID,
+			Message:        key.message + 1,
+			MessageID:      m.MessageID,
+			Role:           m.Role,
+			Timestamp:      m.Timestamp,
+			Score:          score,
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].Timestamp.Equal(hits[j].Timestamp) {
+			return hits[i].Timestamp.After(hits[j].Timestamp)
+		}
+		if hits[i].ConversationID != hits[j].ConversationID {
+			return hits[i].ConversationID < hits[j].ConversationID
+		}
+		return hits[i].Message < hits[j].Message
+	})
+	return hits
+}
+
+// chatSearchSnippet cuts the text around the hit's first matched term from
+// its transcript, with the offsets of every query term inside it.
+func chatSearchSnippet(x *chatSearchIndex, dir string, h *chatSearchHit, terms []string) (string, [][2]int) {
+	doc := x.Docs[chatIndexDocKey(h.NotebookID, h.ConversationID)]
+	session, err := readChatSessionFile(filepath.Join(dir, doc.File))
+	if err != nil || h.Message > len(session.Messages) {
+		return "", nil
+	}
+	text := []rune(strings.Join(strings.Fields(session.Messages[h.Message-1].Content), " "))
+	wanted := map[string]bool{}
+	for _, t := range terms {
+		wanted[t] = true
+	}
+	var matches []chatToken
+	for _, tok := range chatSearchTokens(string(text)) {
+		if wanted[tok.Term] {
+			matches = append(matches, tok)
+		}
+	}
+
+	start, end := 0, len(text)
+	if len(text) > chatSearchSnippetWidth {
+		if len(matches) > 0 {
+			start = max(0, matches[0].Start-chatSearchSnippetWidth/3)
+		}
+		end = min(len(text), start+chatSearchSnippetWidth)
+		start = max(0, end-chatSearchSnippetWidth)
+	}
+	snippet := string(text[start:end])
+	shift := -start
+	if start > 0 {
+		snippet = "…" + snippet
+		shift++
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	var highlights [][2]int
+	for _, tok := range matches {
+		if tok.Start >= start && tok.End <= end {
+			highlights = append(highlights, [2]int{tok.Start + shift, tok.End + shift})
+		}
+	}
+	return snippet, highlights
+}
+
+// chatToken is a word of a message and its rune offsets.
+type chatToken struct {
+	Term       string
+	Start, End int
+}
+
+// chatSearchTokens splits s into lower-cased runs of letters and digits.
+func chatSearchTokens(s string) []chatToken {
+	var tokens []chatToken
+	start := -1
+	i := 0
+	var word []rune
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			word = append(word, unicode.ToLower(r))
+		} else if start >= 0 {
+			tokens = append(tokens, chatToken{Term: string(word), Start: start, End: i})
+			start, word = -1, word[:0]
+		}
+		i++
+	}
+	if start >= 0 {
+		tokens = append(tokens, chatToken{Term: string(word), Start: start, End: i})
+	}
+	return tokens
+}
+
+// chatSearchTerms returns the distinct terms of a query.
+func chatSearchTerms(query string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, tok := range chatSearchTokens(query) {
+		if !seen[tok.Term] {
+			seen[tok.Term] = true
+			terms = append(terms, tok.Term)
+		}
+	}
+	return terms
+}
+
+func chatIndexDocKey(notebookID, conversationID string) string {
+	return notebookID + "/" + conversationID
+}
+
+func chatSessionDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".nlm"), nil
+}
+
+// chatSearchIndexPath names the index file. Its extension keeps it out of
+// the chat-*.json scans that list transcripts.
+func chatSearchIndexPath(dir string) string {
+	return filepath.Join(dir, "chat-search.index")
+}
+
+func loadChatSearchIndex(dir string) *chatSearchIndex {
+	x := &chatSearchIndex{}
+	if data, err := os.ReadFile(chatSearchIndexPath(dir)); err == nil {
+		if json.Unmarshal(data, x) != nil || x.Version != chatSearchIndexVersion {
+			x = &chatSearchIndex{}
+		}
+	}
+	x.Version = chatSearchIndexVersion
+	if x.Files == nil {
+		x.Files = map[string]chatIndexFile{}
+	}
+	if x.Docs == nil {
+		x.Docs = map[string]*chatIndexDoc{}
+	}
+	if x.Postings == nil {
+		x.Postings = map[string][]chatPosting{}
+	}
+	return x
+}
+
+// save writes the index through a temporary file, so a concurrent reader
+// never sees half an index. Two writers may still race; the loser's update
+// is recovered by the next search's reconcile.
+func (x *chatSearchIndex) save(dir string) error {
+	data, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".chat-search-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), chatSearchIndexPath(dir))
+}
+
+// syncChatSearchIndex loads the index and brings it up to date with the
+// transcript files: new and modified files are indexed, and conversations
+// whose files are gone are dropped.
+func syncChatSearchIndex(dir string) (*chatSearchIndex, error) {
+	x := loadChatSearchIndex(dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	changed := false
+	present := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "chat-") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		present[name] = true
+		if stamp, ok := x.Files[name]; ok && stamp.ModTime.Equal(info.ModTime()) && stamp.Size == info.Size() {
+			continue
+		}
+		session, err := readChatSessionFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		x.indexFile(name, info, session)
+		changed = true
+	}
+	for name := range x.Files {
+		if !present[name] {
+			delete(x.Files, name)
+			changed = true
+		}
+	}
+	if x.dropOrphans() {
+		changed = true
+	}
+	if changed {
+		if err := x.save(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "nlm: could not save the chat search index: %v\n", err)
+		}
+	}
+	return x, nil
+}
+
+// updateChatSearchIndex records a just-saved session. It is best effort: a
+// failure leaves the index stale, and the next search reconciles it.
+func updateChatSearchIndex(session *chatSession, paths ...string) {
+	if len(paths) == 0 {
+		return
+	}
+	dir := filepath.Dir(paths[0])
+	x := loadChatSearchIndex(dir)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !strings.HasPrefix(filepath.Base(path), "chat-") {
+			return
+		}
+		x.indexFile(filepath.Base(path), info, session)
+	}
+	x.dropOrphans()
+	x.save(dir)
+}
+
+// indexFile points the transcript file name at its session's doc and
+// re-indexes the doc.
+func (x *chatSearchIndex) indexFile(name string, info os.FileInfo, session *chatSession) {
+	key := chatIndexDocKey(session.NotebookID, session.ConversationID)
+	x.Files[name] = chatIndexFile{ModTime: info.ModTime(), Size: info.Size(), Doc: key}
+	x.removeDoc(key)
+
+	doc := &chatIndexDoc{
+		NotebookID:     session.NotebookID,
+		ConversationID: session.ConversationID,
+		File:           name,
+	}
+	terms := map[string]struct{}{}
+	for i, m := range session.Messages {
+		counts := map[string]int{}
+		tokens := chatSearchTokens(m.Content)
+		for _, tok := range tokens {
+			counts[tok.Term]++
+		}
+		for term, n := range counts {
+			x.Postings[term] = append(x.Postings[term], chatPosting{Doc: key, Message: i, Count: n})
+			if _, ok := terms[term]; !ok {
+				terms[term] = struct{}{}
+				doc.Terms = append(doc.Terms, term)
+			}
+		}
+		doc.Messages = append(doc.Messages, chatIndexMessage{
+			Role:      m.Role,
+			MessageID: m.MessageID,
+			Timestamp: m.Timestamp,
+			Length:    len(tokens),
+		})
+	}
+	sort.Strings(doc.Terms)
+	x.Docs[key] = doc
+}
+
+func (x *chatSearchIndex) removeDoc(key string) {
+	doc := x.Docs[key]
+	if doc == nil {
+		return
+	}
+	for _, term := range doc.Terms {
+		postings := x.Postings[term][:0]
+		for _, p := range x.Postings[term] {
+			if p.Doc != key {
+				postings = append(postings, p)
+			}
+		}
+		if len(postings) == 0 {
+			delete(x.Postings, term)
+		} else {
+			x.Postings[term] = postings
+		}
+	}
+	delete(x.Docs, key)
+}
+
+// dropOrphans removes docs no transcript file names any more, and repoints
+// a doc whose own file now holds another conversation at one that still
+// holds it.
+func (x *chatSearchIndex) dropOrphans() bool {
+	files := map[string]string{}
+	for name, stamp := range x.Files {
+		if files[stamp.Doc] == "" || name < files[stamp.Doc] {
+			files[stamp.Doc] = name
+		}
+	}
+	changed := false
+	for key, doc := range x.Docs {
+		name, ok := files[key]
+		if !ok {
+			x.removeDoc(key)
+			changed = true
+			continue
+		}
+		if x.Files[doc.File].Doc != key {
+			doc.File = name
+			changed = true
+		}
+	}
+	return changed
+}
+
+func readChatSessionFile(path string) (*chatSession, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var session chatSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// chatExchange returns the range of messages 'chat show --message' renders
+// for ref: the numbered message together with the question it answers or
+// the answer it received. ref is a 1-based message number, as 'chat search'
+// reports it, or a message ID or its prefix.
+func chatExchange(messages []storedMessage, ref string) (start, end int, err error) {
+	i := -1
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(messages) {
+			return 0, 0, fmt.Errorf("message %d out of range; the conversation has %d message(s)", n, len(messages))
+		}
+		i = n - 1
+	} else {
+		for j, m := range messages {
+			if m.MessageID != "" && strings.HasPrefix(m.MessageID, ref) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return 0, 0, fmt.Errorf("no message with ID %s", ref)
+		}
+	}
+	start, end = i, i+1
+	switch messages[i].Role {
+	case "user":
+		if end < len(messages) && messages[end].Role == "assistant" {
+			end++
+		}
+	case "assistant":
+		if start > 0 && messages[start-1].Role == "user" {
+			start--
+		}
+	}
+	return start, end, nil
+}
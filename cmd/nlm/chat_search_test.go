@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func saveSearchTestSessions(t *testing.T) time.Time {
+	t.Helper()
+	old := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	recent := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	sessions := []*chatSession{
+		{
+			NotebookID:     "nb1",
+			ConversationID: "aaaaaaaa-0000-0000-0000-000000000000",
+			Messages: []storedMessage{
+				{Role: "user", Content: "How do attention heads work?", Timestamp: old},
+				{Role: "assistant", Content: "Attention heads weigh tokens against each other [1].", Timestamp: old},
+			},
+		},
+		{
+			NotebookID:     "nb2",
+			ConversationID: "bbbbbbbb-0000-0000-0000-000000000000",
+			Messages: []storedMessage{
+				{Role: "user", Content: "Summarize the retrieval section.", Timestamp: recent},
+				{Role: "assistant", Content: "Retrieval pulls passages; attention then reads them. Attention heads, attention heads.", Timestamp: recent},
+			},
+		},
+	}
+	for _, s := range sessions {
+		if err := saveChatSession(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return recent
+}
+
+func searchChats(t *testing.T, opts chatSearchOptions) []chatSearchRecord {
+	t.Helper()
+	if opts.Limit == 0 {
+		opts.Limit = 20
+	}
+	opts.JSON = true
+	var stdout bytes.Buffer
+	if err := chatSearch(&stdout, os.Stderr, opts, false); err != nil {
+		t.Fatal(err)
+	}
+	var records []chatSearchRecord
+	dec := json.NewDecoder(&stdout)
+	for dec.More() {
+		var rec chatSearchRecord
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestChatSearch(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	recent := saveSearchTestSessions(t)
+	if _, err := os.Stat(filepath.Join(home, ".nlm", "chat-search.index")); err != nil {
+		t.Fatalf("saving a session did not write the index: %v", err)
+	}
+
+	hits := searchChats(t, chatSearchOptions{Query: "attention heads"})
+	if len(hits) != 3 {
+		t.Fatalf("got %d hits, want 3: %+v", len(hits), hits)
+	}
+	if hits[0].NotebookID != "nb2" || hits[0].Message != 2 {
+		t.Errorf("top hit = %+v, want nb2 message 2 (most mentions)", hits[0])
+	}
+	for _, h := range hits {
+		runes := []rune(h.Snippet)
+		if len(h.Highlights) == 0 {
+			t.Errorf("hit %+v has no highlights", h)
+		}
+		for _, hl := range h.Highlights {
+			if word := strings.ToLower(string(runes[hl[0]:hl[1]])); word != "attention" && word != "heads" {
+				t.Errorf("highlight %v of %q covers %q", hl, h.Snippet, word)
+			}
+		}
+	}
+
+	if hits := searchChats(t, chatSearchOptions{Query: "attention", Role: "user"}); len(hits) != 1 || hits[0].Message != 1 {
+		t.Errorf("--role user hits = %+v, want nb1 message 1", hits)
+	}
+	if hits := searchChats(t, chatSearchOptions{Query: "attention", NotebookID: "nb1"}); len(hits) != 2 {
+		t.Errorf("--notebook nb1 hits = %+v, want 2", hits)
+	}
+	if hits := searchChats(t, chatSearchOptions{Query: "attention", Since: recent.Add(-time.Hour)}); len(hits) != 1 || hits[0].NotebookID != "nb2" {
+		t.Errorf("--since hits = %+v, want only nb2", hits)
+	}
+	if hits := searchChats(t, chatSearchOptions{Query: "attention missing"}); len(hits) != 0 {
+		t.Errorf("hits for a query with an absent word = %+v, want none", hits)
+	}
+}
+
+func TestChatSearchReconcilesTranscripts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	saveSearchTestSessions(t)
+	dir := filepath.Join(home, ".nlm")
+
+	// A transcript written without going through saveChatSession, as by an
+	// older version, is indexed on the next search.
+	data, err := json.Marshal(&chatSession{
+		NotebookID:     "nb3",
+		ConversationID: "cccccccc-0000-0000-0000-000000000000",
+		Messages:       []storedMessage{{Role: "assistant", Content: "Transformers stack attention layers."}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "chat-nb3-cccccccc.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if hits := searchChats(t, chatSearchOptions{Query: "transformers"}); len(hits) != 1 || hits[0].NotebookID != "nb3" {
+		t.Errorf("hits after an outside write = %+v, want nb3", hits)
+	}
+
+	// Removing every file of a conversation drops it from the index.
+	for _, name := range []string{"chat-nb1.json", "chat-nb1-aaaaaaaa.json"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, h := range searchChats(t, chatSearchOptions{Query: "attention"}) {
+		if h.NotebookID == "nb1" {
+			t.Errorf("hit %+v from a removed transcript", h)
+		}
+	}
+}
+
+func TestChatSearchSnippet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	long := strings.Repeat("filler words here ", 30) + "the needle sits here " + strings.Repeat("more filler ", 30)
+	if err := saveChatSession(&chatSession{
+		NotebookID:     "nb",
+		ConversationID: "dddddddd-0000-0000-0000-000000000000",
+		Messages:       []storedMessage{{Role: "assistant", Content: long}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	hits := searchChats(t, chatSearchOptions{Query: "needle"})
+	if len(hits) != 1 {
+		t.Fatalf("got %d hits, want 1", len(hits))
+	}
+	snippet := hits[0].Snippet
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || len([]rune(snippet)) > chatSearchSnippetWidth+2 {
+		t.Errorf("snippet %q is not a clipped window", snippet)
+	}
+	if got := highlightSnippet(snippet, hits[0].Highlights, false); !strings.Contains(got, "the **needle** sits") {
+		t.Errorf("highlighted snippet = %q", got)
+	}
+}
+
+func TestParseChatSearchSince(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"7d", now.AddDate(0, 0, -7)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"2026-10-01T08:00:00Z", time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)},
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseChatSearchSince(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseChatSearchSince(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseChatSearchSince("last week", now); err == nil {
+		t.Error("parseChatSearchSince accepted \"last week\"")
+	}
+}
+
+func TestChatShowMessage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := &chatSession{
+		NotebookID:     "notebook",
+		ConversationID: "abcdef12-3456-7890-abcd-ef1234567890",
+		Messages: []storedMessage{
+			{Role: "user", Content: "first question"},
+			{Role: "assistant", Content: "first answer"},
+			{Role: "user", Content: "second question"},
+			{Role: "assistant", Content: "second answer", MessageID: "msg-2"},
+		},
+	}
+	if err := saveChatSessionForConversation(session); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []string{"3", "4", "msg"} {
+		output := captureChatShowStdout(t, func() error {
+			return chatShowWithClients("notebook", "abcdef12", chatRenderOptions{Message: ref}, nil, nil)
+		})
+		if !strings.Contains(output, "second question") || !strings.Contains(output, "second answer") || strings.Contains(output, "first") {
+			t.Errorf("chat show --message %s output:\n%s", ref, output)
+		}
+	}
+	if _, _, err := chatExchange(session.Messages, "5"); err == nil {
+		t.Error("chatExchange accepted a message past the end")
+	}
+}
@@ -19,6 +19,7 @@ func TestGlobalFlagInventory(t *testing.T) {
 	})
 	slices.Sort(got)
 	want := []string{
+		"account",
 		"auth",
 		"authuser",
 		"cookies",
@@ -65,6 +66,11 @@ func TestTrueGlobalFlagsAcceptedAnywhere(t *testing.T) {
 			args: []string{"betool", "help", "--authuser", "3"},
 			want: globalOptions{authUser: "3", authUserSet: true},
 		},
+		{
+			name: "repeated account after",
+			args: []string{"betool", "help", "--account", "work", "--account=lab"},
+			want: globalOptions{accounts: accountFlag{values: []string{"work", "lab"}}},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -78,7 +84,8 @@ func TestTrueGlobalFlagsAcceptedAnywhere(t *testing.T) {
 			if inv.globals.debug != test.want.debug ||
 				inv.globals.authToken != test.want.authToken ||
 				inv.globals.authUser != test.want.authUser ||
-				inv.globals.authUserSet != test.want.authUserSet {
+				inv.globals.authUserSet != test.want.authUserSet ||
+				!slices.Equal(inv.globals.accounts.values, test.want.accounts.values) {
 				t.Fatalf("globals = %+v, want selected fields %+v", inv.globals, test.want)
 			}
 		})
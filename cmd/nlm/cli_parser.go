@@ -15,6 +15,7 @@ type globalOptions struct {
 	cookies              string
 	authUser             string
 	authUserSet          bool
+	accounts             accountFlag
 	debug                bool
 	debugDumpPayload     bool
 	debugParsing         bool
@@ -86,7 +87,7 @@ func defaultGlobalOptions(env func(string) string) globalOptions {
 	if env == nil {
 		env = os.Getenv
 	}
-	return globalOptions{
+	opts := globalOptions{
 		chromeProfile: env("NLM_BROWSER_PROFILE"),
 		cdpURL:        env("NLM_CDP_URL"),
 		authToken:     env("NLM_AUTH_TOKEN"),
@@ -94,6 +95,10 @@ func defaultGlobalOptions(env func(string) string) globalOptions {
 		authUser:      env("NLM_AUTHUSER"),
 		debug:         env("NLM_DEBUG") == "true",
 	}
+	if name := env("NLM_ACCOUNT"); name != "" {
+		opts.accounts.values = []string{name}
+	}
+	return opts
 }
 
 func newGlobalFlagSet(opts *globalOptions) *flag.FlagSet {
@@ -113,6 +118,7 @@ func registerGlobalFlags(flags *flag.FlagSet, opts *globalOptions) {
 	flags.StringVar(&opts.authToken, "auth", opts.authToken, "auth token (or set NLM_AUTH_TOKEN)")
 	flags.StringVar(&opts.cookies, "cookies", opts.cookies, "cookies for authentication (or set NLM_COOKIES)")
 	flags.StringVar(&opts.authUser, "authuser", opts.authUser, "Google account index for multi-account profiles")
+	flags.Var(&opts.accounts, "account", "stored account to use (or set NLM_ACCOUNT); see 'nlm auth list'")
 }
 
 func parseInvocation(args []string, env func(string) string, stdout, stderr io.Writer) (invocation, error) {
@@ -452,6 +458,10 @@ func applyGlobalOptions(opts globalOptions) {
 	authToken = opts.authToken
 	cookies = opts.cookies
 	authUser = opts.authUser
+	account = ""
+	if values := opts.accounts.values; len(values) == 1 && validAccountName(values[0]) {
+		account = values[0]
+	}
 	debug = opts.debug
 	debugDumpPayload = opts.debugDumpPayload
 	debugParsing = opts.debugParsing
@@ -50,6 +50,7 @@ func configureCommandFlagOwnership(specs map[commandID]*commandSpec) {
 		"guidebooks",
 		"audio-suggestions",
 		"chat-list",
+		"chat-search",
 		"account",
 		"audio-list",
 		"analytics",
@@ -57,6 +58,7 @@ func configureCommandFlagOwnership(specs map[commandID]*commandSpec) {
 		"source-guide",
 		"discover-sources",
 		"betool",
+		"auth-status",
 	)
 	addOwnedFlag(specs, yesFlag,
 		"rm",
@@ -67,6 +69,7 @@ func configureCommandFlagOwnership(specs map[commandID]*commandSpec) {
 		"delete-artifact",
 		"delete-chat",
 		"chat",
+		"auth-remove",
 	)
 
 	// Source read retains its old boolean format switches for one release.
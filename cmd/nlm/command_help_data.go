@@ -11,8 +11,9 @@ import (
 var commandHelpByPath = map[string]commandHelpSpec{
 	"notebook list":       {UsageTitle: "Usage", Body: "\nFlags:\n  --all          Show all notebooks when stdout is a terminal\n  --limit <n>    Show at most n notebooks (default: 10 on TTY, all when piped)\n  --json         Emit NDJSON instead of a table\n\nExamples:\n  nlm {{command}}\n  nlm {{command}} --all\n  nlm ls --limit 25\n"},
 	"source add":          {UsageTitle: "Usage", Body: "\nSources may be files, URLs, or text literals. A sole '-' streams all of\nstdin in as a single source (pair with --name and --mime-type). To add a\nlist of sources from stdin, compose with xargs.\n\nFlags:\n  --name, -n <name>         Custom name for the added source\n  --mime, --mime-type <t>   Override MIME detection for file/stdin content\n  --replace <source-id>     Upload a replacement, then delete the old source\n  --pre-process <cmd>       Pipe each non-URL source through 'sh -c cmd' before\n                            upload; stdout replaces the content. Non-zero exit\n                            aborts the batch. URL sources are passed through.\n  --chunk <bytes>           Split each non-URL source into parts of at most <bytes>\n                            each. Parts upload as \"name\", \"name (pt2)\", ... Use for\n                            content that exceeds the per-request size limit without\n                            switching to `nlm sync` txtar bundling.\n\nExamples:\n  nlm {{command}} <notebook-id> https://example.com/article\n  nlm {{command}} --name \"API notes\" <notebook-id> ./notes.txt\n  cat notes.md | nlm {{command}} --name \"April notes\" <notebook-id> -\n  cat urls.txt | xargs nlm {{command}} <notebook-id>\n  nlm {{command}} --pre-process 'pandoc -f docx -t markdown' <notebook-id> brief.docx\n  nlm {{command}} --chunk 5242880 <notebook-id> huge.log\n"},
-	"source sync":         {UsageTitle: "Usage", Body: "\n(also available as the top-level shortcut: nlm sync)\n\nBundles local files into a txtar archive and uploads them as a single named\nsource. Re-running sync updates that source in place: unchanged content is\nskipped via a hash cache, and archives larger than --max-bytes are split into\nnumbered parts (\"name\", \"name (pt2)\", ...).\n\nPath handling:\n  (no paths)                Sync the current directory\n  <dir>                     Include files tracked by git ls-files (falls back\n                            to a recursive walk; skips .git, node_modules,\n                            __pycache__, .eggs)\n  <file>                    Include that file verbatim\n  -                         Read newline-delimited paths from stdin\n\nBinary files are detected and skipped. Text files containing lines that look\nlike txtar markers are safely quoted so the archive round-trips.\n\nFlags:\n  --name, -n <name>         Source title (defaults to the basename of the\n                            single path; required with multiple paths or stdin)\n  --force                   Re-upload even when the content hash is unchanged\n  --dry-run                 Print the plan (add/update/skip/delete) without\n                            contacting the server\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --json                    Emit NDJSON progress records instead of text\n  --exclude <pattern>       Skip files matching a filepath.Match pattern;\n                            tested against the full path and basename. May\n                            be repeated. Trailing '/' or '/'-bearing patterns\n                            match as path prefixes (e.g. 'vendor/'). A\n                            .nlmignore file at the repo root adds patterns\n                            automatically (one per line, '#' comments).\n  --include-untracked       Include untracked, non-ignored files when syncing\n                            git directories\n  --parallel <n>            Max concurrent chunk uploads (default 4; use a\n                            negative value to force serial)\n  --pre-process <cmd>       Pipe each discovered file through 'sh -c cmd' before\n                            bundling; stdout replaces the bundled bytes (and\n                            participates in the hash). $NLM_FILE_NAME holds the\n                            file name. Non-zero exit aborts the sync.\n\nHash cache: ~/.cache/nlm/sync/<notebook-id>/\n\nExamples:\n  nlm {{command}} <notebook-id>                    # sync the current directory\n  nlm {{command}} -n docs <notebook-id> ./docs ./notes\n  nlm {{command}} --dry-run <notebook-id>          # preview without uploading\n  nlm {{command}} --force <notebook-id> README.md  # force re-upload\n  nlm {{command}} --exclude '*.pb.go' --exclude 'vendor/' <notebook-id>\n  git ls-files '*.go' | nlm {{command}} -n go-src <notebook-id> -\n  nlm {{command}} --pre-process 'jq .' <notebook-id> ./logs   # reformat JSON before bundling\n"},
-	"source pack":         {UsageTitle: "Usage", Body: "\n(also available as the top-level shortcut: nlm sync-pack)\n\nRuns the same discover/bundle pipeline as sync but writes the resulting txtar\narchive to stdout without contacting the server. Useful for previewing what\nsync would upload, or for piping into tools that consume txtar.\n\nWith no --chunk flag: emits the sole chunk, or lists chunk sizes to stderr\nwhen the bundle would be split. Pass --chunk N to emit the Nth chunk.\n\nFlags:\n  --name, -n <name>         Source title (same rules as sync)\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --chunk <n>               Emit the Nth chunk (1-indexed) when multiple\n  --exclude <pattern>       Skip files matching the pattern (repeatable;\n                            same rules as sync)\n  --pre-process <cmd>       Pipe each discovered file through 'sh -c cmd' before\n                            bundling (same semantics as sync)\n\nExamples:\n  nlm {{command}}                                  # pack the current directory\n  nlm {{command}} ./docs > docs.txtar\n  nlm {{command}} --chunk 2 ./docs\n  nlm {{command}} --exclude '*.pb.go' ./src\n"},
+	"source sync":         {UsageTitle: "Usage", Body: "\n(also available as the top-level shortcut: nlm sync)\n\nBundles local files into a txtar archive and uploads them as a single named\nsource. Re-running sync updates that source in place: unchanged content is\nskipped via a hash cache, and archives larger than --max-bytes are split into\nnumbered parts (\"name\", \"name (pt2)\", ...).\n\nPath handling:\n  (no paths)                Sync the current directory\n  <dir>                     Include files tracked by git ls-files (falls back\n                            to a recursive walk; skips .git, node_modules,\n                            __pycache__, .eggs)\n  <file>                    Include that file verbatim\n  -                         Read newline-delimited paths from stdin\n\nBinary files are detected and skipped. Text files containing lines that look\nlike txtar markers are safely quoted so the archive round-trips.\n\nFlags:\n  --name, -n <name>         Source title (defaults to the basename of the\n                            single path; required with multiple paths or stdin)\n  --force                   Re-upload even when the content hash is unchanged\n  --dry-run                 Print the plan (add/update/skip/delete) without\n                            contacting the server\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --json                    Emit NDJSON progress records instead of text\n  --exclude <pattern>       Skip files matching a filepath.Match pattern;\n                            tested against the full path and basename. May\n                            be repeated. Trailing '/' or '/'-bearing patterns\n                            match as path prefixes (e.g. 'vendor/'). A\n                            .nlmignore file at the repo root adds patterns\n                            automatically (one per line, '#' comments).\n  --include-untracked       Include untracked, non-ignored files when syncing\n                            git directories\n  --parallel <n>            Max concurrent chunk uploads (default 4; use a\n                            negative value to force serial)\n  --pre-process <cmd>       Pipe each discovered file through 'sh -c cmd' before\n                            bundling; stdout replaces the bundled bytes (and\n                            participates in the hash). $NLM_FILE_NAME holds the\n                            file name. Non-zero exit aborts the sync.\n  --extract <names>         Run built-in extractors in-process before bundling\n                            (comma-separated or repeated): ipynb (code and\n                            Markdown cells, no outputs), html (readable text),\n                            pbgo (*.pb.go declarations only), csv (CSV/TSV as\n                            Markdown tables). Output is hashed like\n                            --pre-process output and runs before it. A\n                            .nlmextract file at the repo root adds names\n                            automatically (one per line, '#' comments).\n  --rev <rev>               Bundle file contents as of a git revision (tag,\n                            branch, or commit) without checking it out. The\n                            default source name becomes \"<name>@<rev>\"\n  --since <rev>             Also sync \"<name> (changes since <rev>)\" holding\n                            the commit log and unified diff from <rev> to\n                            --rev (or the working tree)\n  --label <pattern=label>   Attach every synced part holding a file that\n                            matches pattern (filepath.Match per segment, '**'\n                            spans directories) to label, creating it if\n                            missing. May be repeated. A .nlmlabels file at\n                            the repo root adds rules automatically (one\n                            pattern=label per line, '#' comments).\n  --per-file                Upload each file as its own source titled\n                            \"<name>/<path>\", so label rules apply file by\n                            file; sources of deleted files are removed\n  --repair                  Only recover from an interrupted sync: delete or\n                            restore stranded \"name [old]\" sources sync\n                            uploaded and reset the hash cache. Takes no\n                            paths.\n\nHash cache: ~/.cache/nlm/sync/<notebook-id>/ (keyed by source title, so\nswitching --rev under one --name re-uploads only when content differs)\n\nConcurrent syncs of one notebook wait on a lock file in the cache directory.\nEach replace is journaled there first; a run that finds a journal left by a\ncrashed sync reconciles it before syncing.\n\nExamples:\n  nlm {{command}} <notebook-id>                    # sync the current directory\n  nlm {{command}} -n docs <notebook-id> ./docs ./notes\n  nlm {{command}} --dry-run <notebook-id>          # preview without uploading\n  nlm {{command}} --force <notebook-id> README.md  # force re-upload\n  nlm {{command}} --exclude '*.pb.go' --exclude 'vendor/' <notebook-id>\n  git ls-files '*.go' | nlm {{command}} -n go-src <notebook-id> -\n  nlm {{command}} --pre-process 'jq .' <notebook-id> ./logs   # reformat JSON before bundling\n  nlm {{command}} --extract ipynb,html <notebook-id> .  # notebooks and HTML as text\n  nlm {{command}} --rev v1.4.0 --since v1.3.0 <notebook-id> .   # release snapshot + changes\n  nlm {{command}} --label 'docs/**=Docs' --label '**/api/*.go=API' <notebook-id> .\n  nlm {{command}} --per-file --label 'docs/**=Docs' <notebook-id> .   # one labeled source per file\n  nlm {{command}} --repair <notebook-id>          # clean up after a killed sync\n"},
+	"source sync status":  {UsageTitle: "Usage", Body: "\n(also available as the top-level shortcut: nlm sync-status)\n\nCompares what sync would upload with the notebook without changing either.\nBundles and hashes the paths locally (same discovery and flags as sync),\nchecks the hash cache, and makes a single source-list call. Each part is\nreported as one of:\n\n  up-to-date                The notebook holds the part and its content\n                            matches the local bundle\n  changed                   The local bundle differs from what was last\n                            uploaded (or was never synced from this machine)\n  missing                   No source holds the part\n  renamed                   The source last uploaded for the part now has\n                            another title\n  orphaned                  The notebook holds a part the tree no longer\n                            produces; sync would delete it\n\nExit status is 0 when every part is up to date and 5 (precondition) when any\npart has drifted, so CI can fail when a notebook lags the branch.\n\nFlags:\n  --name, -n <name>         Source title (same rules as sync)\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --json                    Emit one JSON record per part\n  --exclude <pattern>       Skip files matching the pattern (repeatable)\n  --include-untracked       Include untracked, non-ignored files\n  --pre-process <cmd>       Pipe files through a command (same as sync)\n  --extract <names>         Run built-in extractors (same names as sync)\n  --rev <rev>               Compare file contents as of a git revision\n  --since <rev>             Include the \"changes since\" source sync would add\n  --per-file                Compare one source per file, as sync --per-file\n\nExamples:\n  nlm {{command}} <notebook-id>                    # check the current directory\n  nlm {{command}} -n docs <notebook-id> ./docs\n  nlm {{command}} --json <notebook-id> . | jq 'select(.state != \"up-to-date\")'\n"},
+	"source pack":         {UsageTitle: "Usage", Body: "\n(also available as the top-level shortcut: nlm sync-pack)\n\nRuns the same discover/bundle pipeline as sync but writes the resulting txtar\narchive to stdout without contacting the server. Useful for previewing what\nsync would upload, or for piping into tools that consume txtar.\n\nWith no --chunk flag: emits the sole chunk, or lists chunk sizes to stderr\nwhen the bundle would be split. Pass --chunk N to emit the Nth chunk.\n\nFlags:\n  --name, -n <name>         Source title (same rules as sync)\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --chunk <n>               Emit the Nth chunk (1-indexed) when multiple\n  --exclude <pattern>       Skip files matching the pattern (repeatable;\n                            same rules as sync)\n  --pre-process <cmd>       Pipe each discovered file through 'sh -c cmd' before\n                            bundling (same semantics as sync)\n  --extract <names>         Run built-in extractors (same names as sync)\n  --rev <rev>               Pack file contents as of a git revision\n  --since <rev>             Append the \"changes since\" chunks sync would add\n\nExamples:\n  nlm {{command}}                                  # pack the current directory\n  nlm {{command}} ./docs > docs.txtar\n  nlm {{command}} --chunk 2 ./docs\n  nlm {{command}} --exclude '*.pb.go' ./src\n  nlm {{command}} --rev v1.4.0 ./docs\n"},
 	"source read":         {UsageTitle: "Usage", Body: "\nFlags:\n  --format <fmt>  Output format: text (default), markdown, html, json, raw, or prototext\n\nThe json format is nlm's stable decoded source model. The raw format is\nthe unstable LoadSource protobuf encoded with protojson. The prototext format\nis the unstable LoadSource protobuf in protobuf text format.\n\nDeprecated aliases: --markdown, --html, and --json.\n"},
 	"note read":           {UsageTitle: "Usage", Body: "\nFlags:\n  --format <fmt>  Output format: text (default), markdown, or html\n  --out <file>    Write html output to a file instead of stdout (--format=html only)\n  --open          Open the written html file in a browser (--format=html with --out)\n"},
 	"note create":         {UsageTitle: "Usage", Body: "\nFlags:\n  --content <text>     Set note content directly\n  --content-file <file> Read note content from a file ('-' reads stdin)\n\nWithout a content flag, piped stdin supplies the content; otherwise the note\nis created with an empty body.\n"},
@@ -27,7 +28,7 @@ var commandHelpByPath = map[string]commandHelpSpec{
 	"label relabel-all":   {UsageTitle: "Usage", Body: "\nTrigger a full re-cluster of the notebook (mode 1) — the UI's \"Relabel all\".\nOn large notebooks this can hit the 60s server deadline (exit-class=transient).\n\nFlags:\n  --json  Emit JSON\n"},
 	"label attach":        {UsageTitle: "Usage", Body: "\nAttach a source to an existing label. Either argument may be a UUID or a\nname; names are resolved case-insensitively against the notebook's labels\nand sources, and must match exactly one entry. Only the single-source form\nis HAR-verified — invoke once per source for now.\n"},
 	"artifact export":     {UsageTitle: "Usage", Body: "\nExports a READY artifact using its server-rendered download.\nType-4 flashcard apps additionally support md, json, tsv, and html.\nOutput is written to stdout by default.\n\nFlags:\n  --format, -f <format>  Server file extension or flashcard format (default md)\n  --output, -o <file>    Write to a file instead of stdout\n"},
-	"chat show":           {UsageTitle: "Usage", Body: "\nFlags:\n  --thinking, --reasoning  Show persisted thinking traces on stderr\n  --citations <mode>       Citation rendering: off|list|json (default list; block/stream/tail are deprecated aliases of list)\n  --citation-confidence=off  Hide the (p=…) confidence column in the citation list\n  --citation-spans=off       Hide the trailing [chars N-M] span column in the citation list\n  --resolve-citations      Resolve citations to file:line for txtar-archive sources\n  --citation-excerpts[=N]  Show the cited source text under each citation (N chars, default 160); rehydrates from the saved conversation\n  --format <fmt>           Output format: text (default), markdown, or html\n  --out <file>             Write HTML to file; - writes to stdout (default: render cache)\n  --open                   Open the written HTML file in a browser (--format=html)\n  --include-follow-ups     Include generated trailing follow-up prompts in HTML\n  --backfill               Persist missing citations and rich trees from server history\n\nWith no conversation ID, renders an HTML notebook switcher.\n"},
+	"chat show":           {UsageTitle: "Usage", Body: "\nFlags:\n  --thinking, --reasoning  Show persisted thinking traces on stderr\n  --citations <mode>       Citation rendering: off|list|json (default list; block/stream/tail are deprecated aliases of list)\n  --citation-confidence=off  Hide the (p=…) confidence column in the citation list\n  --citation-spans=off       Hide the trailing [chars N-M] span column in the citation list\n  --resolve-citations      Resolve citations to file:line for txtar-archive sources\n  --citation-excerpts[=N]  Show the cited source text under each citation (N chars, default 160); rehydrates from the saved conversation\n  --format <fmt>           Output format: text (default), markdown, or html\n  --out <file>             Write HTML to file; - writes to stdout (default: render cache)\n  --open                   Open the written HTML file in a browser (--format=html)\n  --include-follow-ups     Include generated trailing follow-up prompts in HTML\n  --backfill               Persist missing citations and rich trees from server history\n  --message <n>            Show only message n (as 'chat search' numbers it, or a message ID) with its question or answer\n\nWith no conversation ID, renders an HTML notebook switcher.\n"},
 	"audio create":        {UsageTitle: "Usage", Body: "\nFlags:\n  --length <value>         Audio length: default, short, or long\n  --language <code>        Language code (default en)\n  --audio-type <value>     Audio style: deep-dive, brief, critique, or debate\n  --yes, -y                Replace an existing audio overview without prompting\n"},
 	"video create":        {UsageTitle: "Usage", Body: "\nFlags:\n  --style <value>          Video style: auto, classic, or whiteboard\n  --language <code>        Language code (default en)\n  --audio-type <value>     Content style: brief, deep-dive, critique, or debate\n"},
 	"deck create":         {UsageTitle: "Usage", Body: "\nFlags:\n  --format, -f <value>     Deck format: detailed (default) or presenter\n                           presenter is experimental (wire values not yet HAR-verified)\n  --source-ids <ids>       Focus on these source IDs ('a,b,c' or '-' for stdin)\n  --source-match <regex>   Focus on sources whose title or UUID matches the regex\n  --source-exclude <regex> Exclude sources whose title or UUID matches the regex\n  --label-ids <ids>        Include sources tagged with any of these label IDs\n  --label-match <regex>    Include sources tagged with any label whose name matches the regex\n  --label-exclude <regex>  Exclude sources tagged with any label whose name matches the regex\n\nWhen no source selector is given, every source in the notebook is used.\n"},
@@ -36,8 +37,9 @@ var commandHelpByPath = map[string]commandHelpSpec{
 	"mindmap create":      {UsageTitle: "Usage", Body: "\nFlags:\n  --type <type>            App type: prototype, mindmap, or canvas\n  --instructions <text>    Generation instructions\n  --source-ids <ids>       Focus on these source IDs ('a,b,c' or '-' for stdin)\n  --source-match <regex>   Focus on sources whose title or UUID matches the regex\n  --source-exclude <regex> Exclude sources whose title or UUID matches the regex\n  --label-ids <ids>        Include sources tagged with any of these label IDs\n  --label-match <regex>    Include sources tagged with any label whose name matches the regex\n  --label-exclude <regex>  Exclude sources tagged with any label whose name matches the regex\n"},
 	"list":                {UsageTitle: "Usage", Body: "\nFlags:\n  --all          Show all notebooks when stdout is a terminal\n  --limit <n>    Show at most n notebooks (default: 10 on TTY, all when piped)\n  --json         Emit NDJSON instead of a table\n\nExamples:\n  nlm {{command}}\n  nlm notebook list --all\n  nlm ls --limit 25\n"},
 	"add":                 {UsageTitle: "Usage", Body: "\nSources may be files, URLs, or text literals. A sole '-' streams all of\nstdin in as a single source (pair with --name and --mime-type). To add a\nlist of sources from stdin, compose with xargs.\n\nFlags:\n  --name, -n <name>         Custom name for the added source\n  --mime, --mime-type <t>   Override MIME detection for file/stdin content\n  --replace <source-id>     Upload a replacement, then delete the old source\n  --pre-process <cmd>       Pipe each non-URL source through 'sh -c cmd' before\n                            upload; stdout replaces the content. Non-zero exit\n                            aborts the batch. URL sources are passed through.\n  --chunk <bytes>           Split each non-URL source into parts of at most <bytes>\n                            each. Parts upload as \"name\", \"name (pt2)\", ... Use for\n                            content that exceeds the per-request size limit without\n                            switching to `nlm sync` txtar bundling.\n\nExamples:\n  nlm {{command}} <notebook-id> https://example.com/article\n  nlm {{command}} --name \"API notes\" <notebook-id> ./notes.txt\n  cat notes.md | nlm {{command}} --name \"April notes\" <notebook-id> -\n  cat urls.txt | xargs nlm {{command}} <notebook-id>\n  nlm {{command}} --pre-process 'pandoc -f docx -t markdown' <notebook-id> brief.docx\n  nlm {{command}} --chunk 5242880 <notebook-id> huge.log\n"},
-	"sync":                {UsageTitle: "Usage", Body: "\nBundles local files into a txtar archive and uploads them as a single named\nsource. Re-running sync updates that source in place: unchanged content is\nskipped via a hash cache, and archives larger than --max-bytes are split into\nnumbered parts (\"name\", \"name (pt2)\", ...).\n\nPath handling:\n  (no paths)                Sync the current directory\n  <dir>                     Include files tracked by git ls-files (falls back\n                            to a recursive walk; skips .git, node_modules,\n                            __pycache__, .eggs)\n  <file>                    Include that file verbatim\n  -                         Read newline-delimited paths from stdin\n\nBinary files are detected and skipped. Text files containing lines that look\nlike txtar markers are safely quoted so the archive round-trips.\n\nFlags:\n  --name, -n <name>         Source title (defaults to the basename of the\n                            single path; required with multiple paths or stdin)\n  --force                   Re-upload even when the content hash is unchanged\n  --dry-run                 Print the plan (add/update/skip/delete) without\n                            contacting the server\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --json                    Emit NDJSON progress records instead of text\n  --exclude <pattern>       Skip files matching a filepath.Match pattern;\n                            tested against the full path and basename. May\n                            be repeated. Trailing '/' or '/'-bearing patterns\n                            match as path prefixes (e.g. 'vendor/'). A\n                            .nlmignore file at the repo root adds patterns\n                            automatically (one per line, '#' comments).\n  --include-untracked       Include untracked, non-ignored files when syncing\n                            git directories\n  --parallel <n>            Max concurrent chunk uploads (default 4; use a\n                            negative value to force serial)\n  --pre-process <cmd>       Pipe each discovered file through 'sh -c cmd' before\n                            bundling; stdout replaces the bundled bytes (and\n                            participates in the hash). $NLM_FILE_NAME holds the\n                            file name. Non-zero exit aborts the sync.\n\nHash cache: ~/.cache/nlm/sync/<notebook-id>/\n\nExamples:\n  nlm {{command}} <notebook-id>                    # sync the current directory\n  nlm {{command}} -n docs <notebook-id> ./docs ./notes\n  nlm {{command}} --dry-run <notebook-id>          # preview without uploading\n  nlm {{command}} --force <notebook-id> README.md  # force re-upload\n  nlm {{command}} --exclude '*.pb.go' --exclude 'vendor/' <notebook-id>\n  git ls-files '*.go' | nlm {{command}} -n go-src <notebook-id> -\n  nlm {{command}} --pre-process 'jq .' <notebook-id> ./logs   # reformat JSON before bundling\n"},
-	"sync-pack":           {UsageTitle: "Usage", Body: "\nRuns the same discover/bundle pipeline as sync but writes the resulting txtar\narchive to stdout without contacting the server. Useful for previewing what\nsync would upload, or for piping into tools that consume txtar.\n\nWith no --chunk flag: emits the sole chunk, or lists chunk sizes to stderr\nwhen the bundle would be split. Pass --chunk N to emit the Nth chunk.\n\nFlags:\n  --name, -n <name>         Source title (same rules as sync)\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --chunk <n>               Emit the Nth chunk (1-indexed) when multiple\n  --exclude <pattern>       Skip files matching the pattern (repeatable;\n                            same rules as sync)\n  --pre-process <cmd>       Pipe each discovered file through 'sh -c cmd' before\n                            bundling (same semantics as sync)\n\nExamples:\n  nlm {{command}}                                  # pack the current directory\n  nlm {{command}} ./docs > docs.txtar\n  nlm {{command}} --chunk 2 ./docs\n  nlm {{command}} --exclude '*.pb.go' ./src\n"},
+	"sync":                {UsageTitle: "Usage", Body: "\nBundles local files into a txtar archive and uploads them as a single named\nsource. Re-running sync updates that source in place: unchanged content is\nskipped via a hash cache, and archives larger than --max-bytes are split into\nnumbered parts (\"name\", \"name (pt2)\", ...).\n\nPath handling:\n  (no paths)                Sync the current directory\n  <dir>                     Include files tracked by git ls-files (falls back\n                            to a recursive walk; skips .git, node_modules,\n                            __pycache__, .eggs)\n  <file>                    Include that file verbatim\n  -                         Read newline-delimited paths from stdin\n\nBinary files are detected and skipped. Text files containing lines that look\nlike txtar markers are safely quoted so the archive round-trips.\n\nFlags:\n  --name, -n <name>         Source title (defaults to the basename of the\n                            single path; required with multiple paths or stdin)\n  --force                   Re-upload even when the content hash is unchanged\n  --dry-run                 Print the plan (add/update/skip/delete) without\n                            contacting the server\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --json                    Emit NDJSON progress records instead of text\n  --exclude <pattern>       Skip files matching a filepath.Match pattern;\n                            tested against the full path and basename. May\n                            be repeated. Trailing '/' or '/'-bearing patterns\n                            match as path prefixes (e.g. 'vendor/'). A\n                            .nlmignore file at the repo root adds patterns\n                            automatically (one per line, '#' comments).\n  --include-untracked       Include untracked, non-ignored files when syncing\n                            git directories\n  --parallel <n>            Max concurrent chunk uploads (default 4; use a\n                            negative value to force serial)\n  --pre-process <cmd>       Pipe each discovered file through 'sh -c cmd' before\n                            bundling; stdout replaces the bundled bytes (and\n                            participates in the hash). $NLM_FILE_NAME holds the\n                            file name. Non-zero exit aborts the sync.\n  --extract <names>         Run built-in extractors in-process before bundling\n                            (comma-separated or repeated): ipynb (code and\n                            Markdown cells, no outputs), html (readable text),\n                            pbgo (*.pb.go declarations only), csv (CSV/TSV as\n                            Markdown tables). Output is hashed like\n                            --pre-process output and runs before it. A\n                            .nlmextract file at the repo root adds names\n                            automatically (one per line, '#' comments).\n  --rev <rev>               Bundle file contents as of a git revision (tag,\n                            branch, or commit) without checking it out. The\n                            default source name becomes \"<name>@<rev>\"\n  --since <rev>             Also sync \"<name> (changes since <rev>)\" holding\n                            the commit log and unified diff from <rev> to\n                            --rev (or the working tree)\n  --label <pattern=label>   Attach every synced part holding a file that\n                            matches pattern (filepath.Match per segment, '**'\n                            spans directories) to label, creating it if\n                            missing. May be repeated. A .nlmlabels file at\n                            the repo root adds rules automatically (one\n                            pattern=label per line, '#' comments).\n  --per-file                Upload each file as its own source titled\n                            \"<name>/<path>\", so label rules apply file by\n                            file; sources of deleted files are removed\n  --repair                  Only recover from an interrupted sync: delete or\n                            restore stranded \"name [old]\" sources sync\n                            uploaded and reset the hash cache. Takes no\n                            paths.\n\nHash cache: ~/.cache/nlm/sync/<notebook-id>/ (keyed by source title, so\nswitching --rev under one --name re-uploads only when content differs)\n\nConcurrent syncs of one notebook wait on a lock file in the cache directory.\nEach replace is journaled there first; a run that finds a journal left by a\ncrashed sync reconciles it before syncing.\n\nExamples:\n  nlm {{command}} <notebook-id>                    # sync the current directory\n  nlm {{command}} -n docs <notebook-id> ./docs ./notes\n  nlm {{command}} --dry-run <notebook-id>          # preview without uploading\n  nlm {{command}} --force <notebook-id> README.md  # force re-upload\n  nlm {{command}} --exclude '*.pb.go' --exclude 'vendor/' <notebook-id>\n  git ls-files '*.go' | nlm {{command}} -n go-src <notebook-id> -\n  nlm {{command}} --pre-process 'jq .' <notebook-id> ./logs   # reformat JSON before bundling\n  nlm {{command}} --extract ipynb,html <notebook-id> .  # notebooks and HTML as text\n  nlm {{command}} --rev v1.4.0 --since v1.3.0 <notebook-id> .   # release snapshot + changes\n  nlm {{command}} --label 'docs/**=Docs' --label '**/api/*.go=API' <notebook-id> .\n  nlm {{command}} --per-file --label 'docs/**=Docs' <notebook-id> .   # one labeled source per file\n  nlm {{command}} --repair <notebook-id>          # clean up after a killed sync\n"},
+	"sync-status":         {UsageTitle: "Usage", Body: "\nCompares what sync would upload with the notebook without changing either.\nBundles and hashes the paths locally (same discovery and flags as sync),\nchecks the hash cache, and makes a single source-list call. Each part is\nreported as one of:\n\n  up-to-date                The notebook holds the part and its content\n                            matches the local bundle\n  changed                   The local bundle differs from what was last\n                            uploaded (or was never synced from this machine)\n  missing                   No source holds the part\n  renamed                   The source last uploaded for the part now has\n                            another title\n  orphaned                  The notebook holds a part the tree no longer\n                            produces; sync would delete it\n\nExit status is 0 when every part is up to date and 5 (precondition) when any\npart has drifted, so CI can fail when a notebook lags the branch.\n\nFlags:\n  --name, -n <name>         Source title (same rules as sync)\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --json                    Emit one JSON record per part\n  --exclude <pattern>       Skip files matching the pattern (repeatable)\n  --include-untracked       Include untracked, non-ignored files\n  --pre-process <cmd>       Pipe files through a command (same as sync)\n  --extract <names>         Run built-in extractors (same names as sync)\n  --rev <rev>               Compare file contents as of a git revision\n  --since <rev>             Include the \"changes since\" source sync would add\n  --per-file                Compare one source per file, as sync --per-file\n\nExamples:\n  nlm {{command}} <notebook-id>                    # check the current directory\n  nlm {{command}} -n docs <notebook-id> ./docs\n  nlm {{command}} --json <notebook-id> . | jq 'select(.state != \"up-to-date\")'\n"},
+	"sync-pack":           {UsageTitle: "Usage", Body: "\nRuns the same discover/bundle pipeline as sync but writes the resulting txtar\narchive to stdout without contacting the server. Useful for previewing what\nsync would upload, or for piping into tools that consume txtar.\n\nWith no --chunk flag: emits the sole chunk, or lists chunk sizes to stderr\nwhen the bundle would be split. Pass --chunk N to emit the Nth chunk.\n\nFlags:\n  --name, -n <name>         Source title (same rules as sync)\n  --max-bytes <n>           Per-chunk size threshold (default 5120000)\n  --chunk <n>               Emit the Nth chunk (1-indexed) when multiple\n  --exclude <pattern>       Skip files matching the pattern (repeatable;\n                            same rules as sync)\n  --pre-process <cmd>       Pipe each discovered file through 'sh -c cmd' before\n                            bundling (same semantics as sync)\n  --extract <names>         Run built-in extractors (same names as sync)\n  --rev <rev>               Pack file contents as of a git revision\n  --since <rev>             Append the \"changes since\" chunks sync would add\n\nExamples:\n  nlm {{command}}                                  # pack the current directory\n  nlm {{command}} ./docs > docs.txtar\n  nlm {{command}} --chunk 2 ./docs\n  nlm {{command}} --exclude '*.pb.go' ./src\n  nlm {{command}} --rev v1.4.0 ./docs\n"},
 	"read-source":         {UsageTitle: "Usage", Body: "\nFlags:\n  --format <fmt>  Output format: text (default), markdown, html, json, raw, or prototext\n\nThe json format is nlm's stable decoded source model. The raw format is\nthe unstable LoadSource protobuf encoded with protojson. The prototext format\nis the unstable LoadSource protobuf in protobuf text format.\n\nDeprecated aliases: --markdown, --html, and --json.\n"},
 	"read-note":           {UsageTitle: "Usage", Body: "\nFlags:\n  --format <fmt>  Output format: text (default), markdown, or html\n  --out <file>    Write html output to a file instead of stdout (--format=html only)\n  --open          Open the written html file in a browser (--format=html with --out)\n"},
 	"label-list":          {UsageTitle: "Usage", Body: "\nList autolabel clusters (labels) for a notebook.\n\nExamples:\n  nlm {{command}} NOTEBOOK_ID\n  nlm --json {{command}} NOTEBOOK_ID\n"},
@@ -61,10 +63,14 @@ var commandHelpByPath = map[string]commandHelpSpec{
 	"generate-chat":       {UsageTitle: "Usage", Body: "\nFlags:\n  --conversation, -c <id>  Continue an existing conversation by ID\n  --web                    Use the most recent server-side conversation\n  --prompt-file, -f <path> Read the prompt from a file ('-' reads stdin)\n  --thinking, --reasoning  Show thinking headers while streaming\n  --verbose, -v            Show full thinking traces while streaming\n  --citations <mode>       Citation rendering: off|list|json (default list; block/stream/tail are deprecated aliases of list)\n  --citation-confidence=off  Hide the (p=…) confidence column in the citation list\n  --citation-spans=off       Hide the trailing [chars N-M] span column in the citation list\n  --resolve-citations      Resolve citations to file:line for txtar-archive sources\n  --citation-excerpts[=N]  Show the cited source text under each citation (N chars, default 160)\n  --source-ids <ids>       Focus on these source IDs ('a,b,c' or '-' for stdin)\n  --source-match <regex>   Focus on sources whose title or UUID matches the regex\n  --source-exclude <regex> Exclude sources whose title or UUID matches the regex\n  --label-ids <ids>        Include sources tagged with any of these label IDs\n  --label-match <regex>    Include sources tagged with any label whose name matches the regex\n  --label-exclude <regex>  Exclude sources tagged with any label whose name matches the regex\n\nExamples:\n  nlm {{command}} <notebook-id> \"Summarize the architecture\"\n  nlm {{command}} --prompt-file prompt.txt <notebook-id>\n  nlm {{command}} --conversation <id> <notebook-id> \"Follow up on section 2\"\n"},
 	"generate-report":     {UsageTitle: "Usage", Body: "\nFlags:\n  --prompt <template>      Per-section prompt template ({topic} is replaced)\n  --instructions <text>    Set notebook instructions before generation\n  --sections <n>           Generate at most n sections (0 = all)\n  --thinking, --reasoning  Show thinking headers while streaming\n  --verbose, -v            Show full thinking traces while streaming\n  --citations <mode>       Citation rendering: off|list|json (default list; block/stream/tail are deprecated aliases of list)\n  --citation-confidence=off  Hide the (p=…) confidence column in the citation list\n  --citation-spans=off       Hide the trailing [chars N-M] span column in the citation list\n  --resolve-citations      Resolve citations to file:line for txtar-archive sources\n  --citation-excerpts[=N]  Show the cited source text under each citation (N chars, default 160)\n  --source-ids <ids>       Focus on these source IDs ('a,b,c' or '-' for stdin)\n  --source-match <regex>   Focus on sources whose title or UUID matches the regex\n  --source-exclude <regex> Exclude sources whose title or UUID matches the regex\n  --label-ids <ids>        Include sources tagged with any of these label IDs\n  --label-match <regex>    Include sources tagged with any label whose name matches the regex\n  --label-exclude <regex>  Exclude sources tagged with any label whose name matches the regex\n\nExamples:\n  nlm {{command}} <notebook-id>\n  nlm {{command}} --sections 3 <notebook-id>\n  nlm {{command}} --prompt '# {topic}\\n\\nExplain the design.' <notebook-id>\n"},
 	"chat":                {UsageTitle: "Usage", Body: "\nFlags:\n  --prompt-file, -f <path> Read the prompt from a file ('-' reads stdin)\n  --history                Show previous chat conversation on start\n  --yes, -y                Pre-authorize in-session history clears\n  --thinking, --reasoning  Show thinking headers while streaming\n  --verbose, -v            Show full thinking traces while streaming\n  --citations <mode>       Citation rendering: off|list|json (default list; block/stream/tail are deprecated aliases of list)\n  --citation-confidence=off  Hide the (p=…) confidence column in the citation list\n  --citation-spans=off       Hide the trailing [chars N-M] span column in the citation list\n  --resolve-citations      Resolve citations to file:line for txtar-archive sources\n  --citation-excerpts[=N]  Show the cited source text under each citation (N chars, default 160)\n  --source-ids <ids>       Focus on these source IDs ('a,b,c' or '-' for stdin)\n  --source-match <regex>   Focus on sources whose title or UUID matches the regex\n  --source-exclude <regex> Exclude sources whose title or UUID matches the regex\n  --label-ids <ids>        Include sources tagged with any of these label IDs\n  --label-match <regex>    Include sources tagged with any label whose name matches the regex\n  --label-exclude <regex>  Exclude sources tagged with any label whose name matches the regex\n\nExamples:\n  nlm {{command}} <notebook-id>\n  nlm {{command}} <notebook-id> \"What changed this week?\"\n  nlm {{command}} --prompt-file prompt.txt <notebook-id>\n"},
-	"chat-show":           {UsageTitle: "Usage", Body: "\nFlags:\n  --thinking, --reasoning  Show persisted thinking traces on stderr\n  --citations <mode>       Citation rendering: off|list|json (default list; block/stream/tail are deprecated aliases of list)\n  --citation-confidence=off  Hide the (p=…) confidence column in the citation list\n  --citation-spans=off       Hide the trailing [chars N-M] span column in the citation list\n  --resolve-citations      Resolve citations to file:line for txtar-archive sources\n  --citation-excerpts[=N]  Show the cited source text under each citation (N chars, default 160); rehydrates from the saved conversation\n  --format <fmt>           Output format: text (default), markdown, or html\n  --out <file>             Write HTML to file; - writes to stdout (default: render cache)\n  --open                   Open the written HTML file in a browser (--format=html)\n  --include-follow-ups     Include generated trailing follow-up prompts in HTML\n  --backfill               Persist missing citations and rich trees from server history\n\nWith no conversation ID, renders an HTML notebook switcher.\n"},
+	"chat-save-note":      {UsageTitle: "Usage", Body: "\nCreates a note holding one answer, rendered as Markdown. Each cited [N]\nbecomes a footnote naming its source and quoting the cited excerpt.\n\n<message-id> names the answer: \"last\", its number among the answers as\n'chat show' lists them (1 is the first), or a message ID from 'chat history'\n(a prefix is enough).\n\nFlags:\n  --title <title>          Note title (default: the question the answer replies to)\n\nIn the interactive chat, /note [title] saves the latest answer.\n\nExamples:\n  nlm {{command}} <notebook-id> <conversation-id> last\n  nlm {{command}} --title \"Key findings\" <notebook-id> <conversation-id> 2\n"},
+	"chat-search":         {UsageTitle: "Usage", Body: "\nSearches the local chat transcripts in ~/.nlm for messages containing every\nword of the query, best matches first. Each hit names its notebook,\nconversation and message number, so the exchange opens with\n'nlm chat show <notebook-id> <conversation-id> --message <n>'.\n\nThe search index is updated as chats are saved; transcripts changed by\nother means are re-indexed on the next search.\n\nFlags:\n  --notebook <id>          Only search conversations of this notebook\n  --since <date>           Only search messages from this date (YYYY-MM-DD, RFC 3339, or an age such as 7d or 36h)\n  --role <role>            Only search user or assistant messages\n  --limit <n>              Show at most n hits (default 20)\n  --json                   Emit one JSON object per hit\n\nExamples:\n  nlm {{command}} \"attention heads\"\n  nlm {{command}} --notebook <notebook-id> --role assistant --since 7d retrieval\n"},
+	"eval":                {UsageTitle: "Usage", Body: "\nAsks each question of a YAML suite in a new conversation, checks the answer\nagainst the case's expectations, and writes a JUnit XML and a Markdown\nreport. Citations are resolved to source titles and, where the source is a\ntxtar bundle, to file:line locations. Exits 1 when any case fails.\n\nA case lists its prompt and any of: contains and forbid (phrases, ignoring\ncase), matches (Go regular expressions), cites (source title substrings)\nand min_citations. A top-level defaults block applies to every case:\n\n  defaults:\n    min_citations: 1\n  cases:\n    - name: install\n      prompt: How do I install the CLI?\n      contains: [\"go install\"]\n      cites: [README]\n\nFlags:\n  --concurrency <n>        Ask at most n questions at once (default 4)\n  --junit <file>           Write the JUnit XML report to file (default <suite>.junit.xml; - for stdout)\n  --markdown <file>        Write the Markdown report to file (default <suite>.md; - for stdout)\n\nExamples:\n  nlm {{command}} <notebook-id> questions.yaml\n  nlm source sync <notebook-id> && nlm {{command}} --junit - <notebook-id> questions.yaml > junit.xml\n"},
+	"chat-show":           {UsageTitle: "Usage", Body: "\nFlags:\n  --thinking, --reasoning  Show persisted thinking traces on stderr\n  --citations <mode>       Citation rendering: off|list|json (default list; block/stream/tail are deprecated aliases of list)\n  --citation-confidence=off  Hide the (p=…) confidence column in the citation list\n  --citation-spans=off       Hide the trailing [chars N-M] span column in the citation list\n  --resolve-citations      Resolve citations to file:line for txtar-archive sources\n  --citation-excerpts[=N]  Show the cited source text under each citation (N chars, default 160); rehydrates from the saved conversation\n  --format <fmt>           Output format: text (default), markdown, or html\n  --out <file>             Write HTML to file; - writes to stdout (default: render cache)\n  --open                   Open the written HTML file in a browser (--format=html)\n  --include-follow-ups     Include generated trailing follow-up prompts in HTML\n  --backfill               Persist missing citations and rich trees from server history\n  --message <n>            Show only message n (as 'chat search' numbers it, or a message ID) with its question or answer\n\nWith no conversation ID, renders an HTML notebook switcher.\n"},
 	"research":            {UsageTitle: "Usage", Body: "\nFlags:\n  --mode <fast|deep>  Research mode (default: deep)\n  --md                Emit Markdown with source footnotes instead of JSON-lines\n  --poll-ms <n>       Override deep-research polling interval in milliseconds\n  --import            Import discovered sources into the notebook after completion\n\nExamples:\n  nlm {{command}} <notebook-id> \"What changed in the auth flow?\"\n  nlm {{command}} --mode fast <notebook-id> \"Which docs should I read first?\"\n"},
+	"mcp":                 {UsageTitle: "Usage", Body: "\nWithout flags the server speaks MCP on stdin/stdout. With --http it serves\nthe streamable HTTP transport at http://<addr>/mcp and a health check at\n/healthz, and stops gracefully on SIGINT or SIGTERM.\n\nFlags:\n  --http <addr>        Listen address, e.g. :8765 or 127.0.0.1:8765\n  --token-file <file>  Accepted bearer tokens, one per line, each optionally\n                       bound to credentials: <token> [profile=<name>] [authuser=<n>]\n  --read-only          Register only tools annotated read-only (chat, which\n                       records conversation history, is left out)\n  --tools <globs>      Register only tools matching these patterns\n                       (comma-separated or repeated), e.g. 'list_*,chat'\n  --deny <globs>       Leave out tools matching these patterns, e.g. 'delete_*'\n  --notebook <id>      Confine tools and resources to this notebook (repeatable);\n                       tools that name no notebook are refused, and\n                       list_notebooks shows only these\n  --export-dir <dir>   Write export_artifact files too large to embed here and\n                       return file:// links to them\n  --local-files        Also expose the commands and flags that read or write\n                       files on this machine: source add, source sync, source\n                       pack, audio and deck download, notebook cover-image,\n                       --content-file and --out/--output. Tools never take\n                       --pre-process, and read-only tools never write files\n  --account <spec>     Serve this account (repeatable; stdio only). The spec is\n                       a stored account name (see 'nlm auth list') or\n                       comma-separated profile=<name>, authuser=<n> and\n                       name=<name> fields. The first account is the default;\n                       tools take an account argument, and calls naming a\n                       notebook go to the account that owns it. A single\n                       account name just selects those credentials\n\nBearer tokens come from --token-file and $NLM_MCP_TOKEN. A token is required\nunless the address is loopback. Unless its token is bound to credentials, a\nsession may pick them when it opens with the X-NLM-Profile header (a profile\nstored in ~/.nlm/profiles/<name>.env) or the X-NLM-Authuser header; otherwise\nit uses the stored credentials.\n\nExamples:\n  nlm {{command}}\n  NLM_MCP_TOKEN=$(openssl rand -hex 16) nlm {{command}} --http :8765\n  nlm {{command}} --http 127.0.0.1:8765\n  nlm {{command}} --http :8765 --token-file ~/.nlm/mcp-tokens\n  nlm {{command}} --read-only --notebook <notebook-id>\n  nlm {{command}} --deny 'delete_*'\n  nlm {{command}} --export-dir ~/Downloads/nlm\n  nlm {{command}} --account work --account authuser=1 --account profile=lab\n"},
 	"betool":              {UsageTitle: "usage", Body: "\nTranslate raw batchexecute network payloads to a readable summary or JSON, and\nback. Reads from [file], or from stdin when [file] is \"-\" or omitted. Performs\nno network I/O.\n\nModes:\n  decode-request    raw \"f.req=...&at=...&\" body      -> text (--json for JSON)\n  encode-request    JSON request spec                 -> raw form body\n  decode-response   raw \")]}'\"-prefixed response body -> text (--json for JSON)\n  encode-response   JSON response spec                -> raw response body\n  infer-proto       raw response payloads             -> descriptor textproto\n  audit-corpus      JSONL traffic files               -> per-RPC verification\n\ninfer-proto flags:\n  --rpc-id=<id>     select the response descriptor; required for inference\n  --samples=<dir>   infer from every regular file in a directory\n                    (multiple input files may also be listed; raw responses,\n                    HAR, JSONL traffic, and httprr recordings are accepted)\n  --json            emit FileDescriptorProto as protojson instead of textproto\n\nDecode modes print a human-readable summary by default; pass the global --json\nflag (before the mode: \"nlm --json {{command}} decode-response …\") for the full\nstructured output. The encode modes consume that JSON, so round-tripping a\npayload needs --json on the decode side.\n\nFlags (decode modes only):\n  --proto           decode into the proto message type bound to the rpc_id,\n                    showing proto JSON with named fields\n  --rpc-id=<id>     supply or override the rpc_id, or a method name to\n                    disambiguate a shared rpc_id (e.g. CreateVideoOverview)\n  --verify          (implies --proto) re-encode the proto back to wire and\n                    report whether the round-trip is lossless, plus the wire\n                    positions the proto type does not model, grouped by\n                    normalized path (with --json: \"roundtrip_lossless\",\n                    \"missing_field_count\", \"missing_field_groups\")\n  --verify-all      (implies --verify) also attach the full unabridged list of\n                    findings (\"missing_fields\")\n\t  --infer-missing   (alias: --infer; implies --verify) show inferred missing fields as a\n                    compact source-style proto fragment\n\nExamples:\n  # Inspect a request captured from a HAR:\n  pbpaste | nlm {{command}} decode-request\n\n  # Decode a response into its typed proto message:\n  nlm {{command}} decode-response --proto resp.txt\n\n  # A response body has no rpc_id, so supply it:\n  nlm {{command}} decode-response --proto --rpc-id=CCqFvf resp.txt\n\n  # Round-trip a response body (encode consumes JSON, so decode with --json):\n  nlm --json {{command}} decode-response resp.txt | nlm {{command}} encode-response\n\n  # Hand-craft a request body from JSON:\n  echo '{\"rpcs\":[{\"id\":\"wXbhsf\",\"args\":[]}],\"at\":\"TOKEN\"}' \\\n    | nlm {{command}} encode-request\n\n  # Audit every RPC request and response in captured JSONL traffic:\n  nlm --json {{command}} audit-corpus \"$NLM_CORPUS_DIR\"/*/notebooklm.google.com/*.jsonl\n"},
-	"auth":                {UsageTitle: "Usage", Body: "\nCommands:\n  login            Explicitly use browser authentication (recommended)\n\nOptions:\n  -a\tTry all available browser profiles (shorthand)\n  -all\n    \tTry all available browser profiles\n  -au string\n    \tGoogle account index (shorthand)\n  -authuser string\n    \tGoogle account index for multi-account profiles (e.g. 1)\n  -c string\n    \tRemote CDP WebSocket URL (shorthand)\n  -cdp-url string\n    \tRemote CDP WebSocket URL (e.g. ws://localhost:9222)\n  -d\tEnable debug output (shorthand)\n  -debug\n    \tEnable debug output\n  -h\tShow help for auth command (shorthand)\n  -help\n    \tShow help for auth command\n  -k int\n    \tKeep browser open for N seconds after successful auth (shorthand)\n  -keep-open int\n    \tKeep browser open for N seconds after successful auth\n  -n\tCheck notebook count for profiles (shorthand)\n  -notebooks\n    \tCheck notebook count for profiles\n  -p string\n    \tSpecific Chrome profile to use (shorthand)\n  -print-env\n    \tPrint shell-safe export lines for the current session to stdout\n  -profile string\n    \tSpecific Chrome profile to use\n  -u string\n    \tTarget URL to authenticate against (shorthand) (default \"https://notebook.google.com\")\n  -url string\n    \tTarget URL to authenticate against (default \"https://notebook.google.com\")\n\nExample: nlm {{command}} login -all -notebooks\nExample: nlm {{command}} login -profile Work\nExample: nlm {{command}} login -keep-open 10\nExample: nlm {{command}} -cdp-url ws://localhost:9222\nExample: nlm {{command}} -all\nExample: nlm {{command}} --print-env > creds.sh   # shell-safe exports for CI\n"},
+	"auth":                {UsageTitle: "Usage", Body: "\nCommands:\n  login            Explicitly use browser authentication (recommended)\n  list             List stored accounts; * marks the one in use\n  use <account>    Make a stored account the default (\"default\" is ~/.nlm/env)\n  remove <account> Delete a stored account's credentials\n  import           Sign in with exported cookies instead of a Chromium browser:\n                   --cookies-txt <file> reads a Netscape cookies.txt ('-' for\n                   stdin); --firefox [profile] reads a Firefox profile (name or\n                   directory; default profile if omitted); --sealed <file> opens\n                   credentials from 'auth export' ('-' for stdin) with the age\n                   key in --identity <file> or $NLM_SEALED_IDENTITY.\n                   --save-as <name> stores them as a named account (required\n                   for sealed credentials from the default account)\n  export --encrypt-to <age1...>\n                   Seal the stored credentials for a CI runner; the blob\n                   records the account and when the cookies expire\n  status [--json]  Check the credentials in use: cookie expiry, token age,\n                   and a live probe; exits 3 when they are missing or rejected\n  migrate <store>  Move stored credentials to the file, keyring (Secret\n                   Service via secret-tool) or encrypted (passphrase) store\n\nOptions:\n  -a\tTry all available browser profiles (shorthand)\n  -all\n    \tTry all available browser profiles\n  -au string\n    \tGoogle account index (shorthand)\n  -authuser string\n    \tGoogle account index for multi-account profiles (e.g. 1)\n  -c string\n    \tRemote CDP WebSocket URL (shorthand)\n  -cdp-url string\n    \tRemote CDP WebSocket URL (e.g. ws://localhost:9222)\n  -d\tEnable debug output (shorthand)\n  -debug\n    \tEnable debug output\n  -h\tShow help for auth command (shorthand)\n  -help\n    \tShow help for auth command\n  -k int\n    \tKeep browser open for N seconds after successful auth (shorthand)\n  -keep-open int\n    \tKeep browser open for N seconds after successful auth\n  -n\tCheck notebook count for profiles (shorthand)\n  -notebooks\n    \tCheck notebook count for profiles\n  -p string\n    \tSpecific Chrome profile to use (shorthand)\n  -print-env\n    \tPrint shell-safe export lines for the current session to stdout\n  -profile string\n    \tSpecific Chrome profile to use\n  -save-as string\n    \tStore the credentials as a named account in ~/.nlm/profiles\n  -u string\n    \tTarget URL to authenticate against (shorthand) (default \"https://notebook.google.com\")\n  -url string\n    \tTarget URL to authenticate against (default \"https://notebook.google.com\")\n\nExample: nlm {{command}} login -all -notebooks\nExample: nlm {{command}} login -profile Work\nExample: nlm {{command}} login -keep-open 10\nExample: nlm {{command}} -cdp-url ws://localhost:9222\nExample: nlm {{command}} -all\nExample: nlm {{command}} --print-env > creds.sh   # shell-safe exports for CI\nExample: nlm {{command}} login --save-as work -profile Work\nExample: nlm --account work notebook list        # or NLM_ACCOUNT=work\nExample: nlm {{command}} import --cookies-txt cookies.txt\nExample: nlm {{command}} import --firefox default-release\nExample: nlm {{command}} export --encrypt-to age1... > creds.age\nExample: nlm {{command}} import --sealed - --save-as ci < creds.age   # with NLM_SEALED_IDENTITY set\nExample: nlm {{command}} status --json || exit      # check auth before a long job\n"},
 }
 
 func configureCommandHelp(specs []*commandSpec) {
@@ -78,31 +78,31 @@ func TestCommandParityGolden(t *testing.T) {
 
 func TestCommandParityPhase1Baseline(t *testing.T) {
 	baseline := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.phase1.golden.json"))
-	current := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json"))
+	current := withoutAddedCommands(readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json")))
 	compareCommandParityPhase1(t, baseline, current)
 }
 
 func TestCommandParityPhase2Baseline(t *testing.T) {
 	baseline := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.phase2.golden.json"))
-	current := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json"))
+	current := withoutAddedCommands(readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json")))
 	compareCommandParityPhase2(t, baseline, current)
 }
 
 func TestCommandParityPhase4Baseline(t *testing.T) {
 	baseline := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.phase4.golden.json"))
-	current := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json"))
+	current := withoutAddedCommands(readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json")))
 	compareCommandParityPhase4(t, baseline, current)
 }
 
 func TestCommandParityPhase5Baseline(t *testing.T) {
 	baseline := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.phase5.golden.json"))
-	current := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json"))
+	current := withoutAddedCommands(readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json")))
 	compareCommandParityPhase5(t, baseline, current)
 }
 
 func TestCommandParityPhase6UnknownFlags(t *testing.T) {
 	baseline := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.phase5.golden.json"))
-	current := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json"))
+	current := withoutAddedCommands(readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json")))
 	if len(baseline.Commands) != len(current.Commands) {
 		t.Fatalf("command count changed: got %d, want %d", len(current.Commands), len(baseline.Commands))
 	}
@@ -126,7 +126,7 @@ func TestCommandParityPhase6UnknownFlags(t *testing.T) {
 
 func TestCommandParityPhase6IgnoredArguments(t *testing.T) {
 	baseline := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.phase5.golden.json"))
-	current := readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json"))
+	current := withoutAddedCommands(readCommandParityGolden(t, filepath.Join("testdata", "command_parity.golden.json")))
 	if len(baseline.Commands) != len(current.Commands) {
 		t.Fatalf("command count changed: got %d, want %d", len(current.Commands), len(baseline.Commands))
 	}
@@ -137,6 +137,7 @@ func TestCommandParityPhase6IgnoredArguments(t *testing.T) {
 		if before.Path != after.Path {
 			t.Fatalf("command %d path changed: got %q, want %q", i, after.Path, before.Path)
 		}
+		normalizeFeatureTerminatorCase(&after, before)
 		beforeCases := commandParityCaseSemantics(before.Cases)
 		afterCases := commandParityCaseSemantics(after.Cases)
 		if reflect.DeepEqual(beforeCases, afterCases) {
@@ -204,7 +205,8 @@ func compareCommandParityPhase1(t *testing.T, baseline, current commandParityGol
 		}
 		normalizePhase6UnknownCase(t, &got, want)
 		normalizePhase6IgnoredArgumentCases(&got, want)
-		if phase4CommandPaths[want.Path] || phase5CommandPaths[want.Path] || prototextCommandPaths[want.Path] || phase6OwnershipCommandPaths[want.Path] {
+		normalizeFeatureTerminatorCase(&got, want)
+		if phase4CommandPaths[want.Path] || phase5CommandPaths[want.Path] || prototextCommandPaths[want.Path] || phase6OwnershipCommandPaths[want.Path] || featureCommandPaths[want.Path] {
 			got.ArgsUsage, got.Help = want.ArgsUsage, want.Help
 			if len(got.Cases) != len(want.Cases) {
 				t.Errorf("%s argument case count changed", want.Path)
@@ -272,7 +274,8 @@ func compareCommandParityPhase2(t *testing.T, baseline, current commandParityGol
 		}
 		normalizePhase6UnknownCase(t, &got, want)
 		normalizePhase6IgnoredArgumentCases(&got, want)
-		if !phase4CommandPaths[want.Path] && !phase5CommandPaths[want.Path] && !prototextCommandPaths[want.Path] && !phase6OwnershipCommandPaths[want.Path] {
+		normalizeFeatureTerminatorCase(&got, want)
+		if !phase4CommandPaths[want.Path] && !phase5CommandPaths[want.Path] && !prototextCommandPaths[want.Path] && !phase6OwnershipCommandPaths[want.Path] && !featureCommandPaths[want.Path] {
 			if !reflect.DeepEqual(got, want) {
 				t.Errorf("%s changed outside Phase 4 and Phase 5", want.Path)
 			}
@@ -319,7 +322,8 @@ func compareCommandParityPhase4(t *testing.T, baseline, current commandParityGol
 		}
 		normalizePhase6UnknownCase(t, &got, want)
 		normalizePhase6IgnoredArgumentCases(&got, want)
-		if !phase5CommandPaths[want.Path] && !prototextCommandPaths[want.Path] && !phase6OwnershipCommandPaths[want.Path] {
+		normalizeFeatureTerminatorCase(&got, want)
+		if !phase5CommandPaths[want.Path] && !prototextCommandPaths[want.Path] && !phase6OwnershipCommandPaths[want.Path] && !featureCommandPaths[want.Path] {
 			if !reflect.DeepEqual(got, want) {
 				t.Errorf("%s changed outside Phase 5 and prototext", want.Path)
 			}
@@ -341,8 +345,7 @@ func compareCommandParityPhase4(t *testing.T, baseline, current commandParityGol
 
 func compareCommandParityPhase5(t *testing.T, baseline, current commandParityGolden) {
 	t.Helper()
-	if filterHelpLines(filterHelpLines(current.RootHelp, prototextCommandPaths), phase6OwnershipCommandPaths) !=
-		filterHelpLines(filterHelpLines(baseline.RootHelp, prototextCommandPaths), phase6OwnershipCommandPaths) {
+	if filterPhase5HelpLines(current.RootHelp) != filterPhase5HelpLines(baseline.RootHelp) {
 		t.Error("root help differs outside the prototext paths")
 	}
 	if len(current.SectionHelp) != len(baseline.SectionHelp) {
@@ -353,8 +356,7 @@ func compareCommandParityPhase5(t *testing.T, baseline, current commandParityGol
 		if got.Name != want.Name {
 			t.Fatalf("section %d name changed: got %q, want %q", i, got.Name, want.Name)
 		}
-		if filterHelpLines(filterHelpLines(got.Help, prototextCommandPaths), phase6OwnershipCommandPaths) !=
-			filterHelpLines(filterHelpLines(want.Help, prototextCommandPaths), phase6OwnershipCommandPaths) {
+		if filterPhase5HelpLines(got.Help) != filterPhase5HelpLines(want.Help) {
 			t.Errorf("%s section help differs outside the prototext paths", want.Name)
 		}
 	}
@@ -368,7 +370,8 @@ func compareCommandParityPhase5(t *testing.T, baseline, current commandParityGol
 		}
 		normalizePhase6UnknownCase(t, &got, want)
 		normalizePhase6IgnoredArgumentCases(&got, want)
-		if !prototextCommandPaths[want.Path] && !phase6OwnershipCommandPaths[want.Path] {
+		normalizeFeatureTerminatorCase(&got, want)
+		if !prototextCommandPaths[want.Path] && !phase6OwnershipCommandPaths[want.Path] && !featureCommandPaths[want.Path] {
 			if !reflect.DeepEqual(got, want) {
 				t.Errorf("%s changed outside the prototext paths", want.Path)
 			}
@@ -395,7 +398,8 @@ func filterPhase1HelpLines(help string) string {
 			!helpLineForPaths(line, phase4CommandPaths) &&
 			!helpLineForPaths(line, phase5CommandPaths) &&
 			!helpLineForPaths(line, prototextCommandPaths) &&
-			!helpLineForPaths(line, phase6OwnershipCommandPaths) {
+			!helpLineForPaths(line, phase6OwnershipCommandPaths) &&
+			!helpLineForPaths(line, featureCommandPaths) {
 			kept = append(kept, line)
 		}
 	}
@@ -408,7 +412,8 @@ func filterLaterPhaseHelpLines(help string) string {
 		if !helpLineForPaths(line, phase4CommandPaths) &&
 			!helpLineForPaths(line, phase5CommandPaths) &&
 			!helpLineForPaths(line, prototextCommandPaths) &&
-			!helpLineForPaths(line, phase6OwnershipCommandPaths) {
+			!helpLineForPaths(line, phase6OwnershipCommandPaths) &&
+			!helpLineForPaths(line, featureCommandPaths) {
 			kept = append(kept, line)
 		}
 	}
@@ -420,13 +425,18 @@ func filterLaterThanPhase4HelpLines(help string) string {
 	for _, line := range strings.Split(help, "\n") {
 		if !helpLineForPaths(line, phase5CommandPaths) &&
 			!helpLineForPaths(line, prototextCommandPaths) &&
-			!helpLineForPaths(line, phase6OwnershipCommandPaths) {
+			!helpLineForPaths(line, phase6OwnershipCommandPaths) &&
+			!helpLineForPaths(line, featureCommandPaths) {
 			kept = append(kept, line)
 		}
 	}
 	return strings.Join(kept, "\n")
 }
 
+func filterPhase5HelpLines(help string) string {
+	return filterHelpLines(filterHelpLines(filterHelpLines(help, prototextCommandPaths), phase6OwnershipCommandPaths), featureCommandPaths)
+}
+
 func filterHelpLines(help string, paths map[string]bool) string {
 	var kept []string
 	for _, line := range strings.Split(help, "\n") {
@@ -526,6 +536,72 @@ var phase6OwnershipCommandPaths = map[string]bool{
 	"sources":           true,
 }
 
+// featureCommandPaths lists surfaces whose flags or help grew in feature
+// work after the migration phases froze their baselines. Their usage and
+// help text may differ from every phase golden; argument-case semantics
+// must still match.
+var featureCommandPaths = map[string]bool{
+	"auth":        true,
+	"mcp":         true,
+	"source sync": true,
+	"source pack": true,
+	"sync":        true,
+	"sync-pack":   true,
+	"chat show":   true,
+	"chat-show":   true,
+}
+
+// addedCommandPaths lists surfaces added in feature work after the phase
+// baselines froze. They are absent from every phase golden, so the
+// baseline comparisons drop them (and their help lines) from the current
+// golden; the current golden itself still covers them.
+var addedCommandPaths = map[string]bool{
+	"source sync status": true,
+	"sync-status":        true,
+	"auth list":          true,
+	"auth use":           true,
+	"auth remove":        true,
+	"auth-list":          true,
+	"auth-use":           true,
+	"auth-remove":        true,
+	"auth migrate":       true,
+	"auth-migrate":       true,
+	"auth status":        true,
+	"auth-status":        true,
+	"auth import":        true,
+	"auth-import":        true,
+	"auth export":        true,
+	"auth-export":        true,
+	"chat search":        true,
+	"chat-search":        true,
+	"chat save-note":     true,
+	"chat-save-note":     true,
+	"eval":               true,
+}
+
+// withoutAddedCommands returns golden minus the addedCommandPaths surfaces.
+func withoutAddedCommands(golden commandParityGolden) commandParityGolden {
+	filter := func(help string) string {
+		var kept []string
+		for _, line := range strings.Split(help, "\n") {
+			if !helpLineForPaths(line, addedCommandPaths) {
+				kept = append(kept, line)
+			}
+		}
+		return strings.Join(kept, "\n")
+	}
+	out := commandParityGolden{RootHelp: filter(golden.RootHelp)}
+	for _, section := range golden.SectionHelp {
+		out.SectionHelp = append(out.SectionHelp, commandParitySection{Name: section.Name, Help: filter(section.Help)})
+	}
+	for _, command := range golden.Commands {
+		if !addedCommandPaths[command.Path] {
+			out.Commands = append(out.Commands, command)
+		}
+	}
+	return out
+}
+
 // phase6UnknownAcceptedPaths is the exact set of surfaces whose synthetic
 // --unknown case was accepted before shared unknown-flag rejection.
 var phase6UnknownAcceptedPaths = map[string]bool{
@@ -638,6 +714,30 @@ func normalizePhase6IgnoredArgumentCases(got *commandParityCommand, want command
 	}
 }
 
+// featureTerminatorPaths lists feature surfaces that gained their first
+// flags. Flag parsing now consumes a bare "--", so that case is accepted
+// where the phase goldens rejected it as an argument.
+var featureTerminatorPaths = map[string]bool{
+	"mcp": true,
+}
+
+func normalizeFeatureTerminatorCase(got *commandParityCommand, want commandParityCommand) {
+	if !featureTerminatorPaths[got.Path] {
+		return
+	}
+	before, ok := findCommandParityCase(want, []string{"--"})
+	if !ok || before.Accepted {
+		return
+	}
+	cases := slices.Clone(got.Cases)
+	for i, test := range cases {
+		if slices.Equal(test.Args, []string{"--"}) && test.Accepted {
+			cases[i] = before
+		}
+	}
+	got.Cases = cases
+}
+
 func commandParityCaseSemantics(cases []commandParityArgsCase) []commandParityArgsCase {
 	var out []commandParityArgsCase
 	for _, test := range cases {
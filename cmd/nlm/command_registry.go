@@ -27,6 +27,7 @@ var groupedCommandSurfaces = []groupedCommandSurface{
 	{ID: "sources", Path: "source list"},
 	{ID: "add", Path: "source add"},
 	{ID: "sync", Path: "source sync"},
+	{ID: "sync-status", Path: "source sync status"},
 	{ID: "sync-pack", Path: "source pack"},
 	{ID: "rm-source", Path: "source delete"},
 	{ID: "rename-source", Path: "source rename"},
@@ -60,6 +61,8 @@ var groupedCommandSurfaces = []groupedCommandSurface{
 	{ID: "chat-list", Path: "chat list"},
 	{ID: "chat-history", Path: "chat history"},
 	{ID: "chat-show", Path: "chat show"},
+	{ID: "chat-search", Path: "chat search"},
+	{ID: "chat-save-note", Path: "chat save-note"},
 	{ID: "delete-chat", Path: "chat delete"},
 	{ID: "chat-config", Path: "chat config"},
 	{ID: "set-instructions", Path: "chat instructions set"},
@@ -79,6 +82,14 @@ var groupedCommandSurfaces = []groupedCommandSurface{
 
 	{ID: "app-create", Path: "app create"},
 	{ID: "mindmap-create", Path: "mindmap create"},
+
+	{ID: "auth-list", Path: "auth list"},
+	{ID: "auth-use", Path: "auth use"},
+	{ID: "auth-remove", Path: "auth remove"},
+	{ID: "auth-import", Path: "auth import"},
+	{ID: "auth-export", Path: "auth export"},
+	{ID: "auth-status", Path: "auth status"},
+	{ID: "auth-migrate", Path: "auth migrate"},
 }
 
 var commands []command
@@ -136,10 +147,12 @@ func buildCommandRegistry() {
 	configureOtherCommandSpecs(specs)
 	configureDeckCommandSpecs(specs)
 	configureResearchCommandSpecs(specs)
+	configureEvalCommandSpec(specs)
 	configureSelectorCommandSpecs(specs)
 	configureCreateCommandSpecs(specs)
 	configureChatCommandSpecs(specs)
 	configureAuthCommandSpec(specs)
+	configureAuthProfileCommandSpecs(specs)
 	configureCommandFlagOwnership(specs)
 
 	for _, spec := range commandSpecs {
@@ -36,11 +36,12 @@ type commandSpec struct {
 	// when the command gains its first declared flag.
 	BareDoubleDashArg bool
 
-	aliases  []string
-	noAuth   bool // command does not require authentication
-	noClient bool // command does not need an API client
-	hidden   bool // base surface is omitted from help
-	parse    func(*commandSurfaceSpec, []string, globalOptions) (parsedCommand, error)
+	aliases   []string
+	noAuth    bool // command does not require authentication
+	noClient  bool // command does not need an API client
+	hidden    bool // base surface is omitted from help
+	keepAlive bool // long-running command: keep credentials fresh while it runs
+	parse     func(*commandSurfaceSpec, []string, globalOptions) (parsedCommand, error)
 }
 
 // commandSurfaceSpec describes one user-visible route to a command behavior.
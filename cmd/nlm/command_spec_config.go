@@ -107,7 +107,7 @@ func remainingOperand(name string) operandSpec {
 
 func operandPlaceholder(name string) string {
 	switch name {
-	case "artifact", "conversation", "guidebook", "label", "note", "notebook", "share", "source":
+	case "artifact", "conversation", "guidebook", "label", "message", "note", "notebook", "share", "source":
 		return name + "-id"
 	case "image":
 		return "image-path"
@@ -8,13 +8,13 @@ import (
 )
 
 func TestCommandSpecsCoverRegistry(t *testing.T) {
-	if got, want := len(commandSpecs), 87; got != want {
+	if got, want := len(commandSpecs), 98; got != want {
 		t.Fatalf("command specs = %d, want %d", got, want)
 	}
-	if got, want := len(groupedCommandSurfaces), 57; got != want {
+	if got, want := len(groupedCommandSurfaces), 67; got != want {
 		t.Fatalf("grouped surfaces = %d, want %d", got, want)
 	}
-	if got, want := len(commands), 144; got != want {
+	if got, want := len(commands), 165; got != want {
 		t.Fatalf("bound commands = %d, want %d", got, want)
 	}
 
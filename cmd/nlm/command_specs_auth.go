@@ -78,14 +78,20 @@ func authFlagSpecs() []flagSpec {
 		{Name: "keep-open", Aliases: []string{"k"}, Value: "int", Description: "keep browser open"},
 		{Name: "cdp-url", Aliases: []string{"c"}, Value: "string", Description: "remote CDP URL"},
 		{Name: "authuser", Aliases: []string{"au"}, Value: "string", Description: "Google account index"},
+		{Name: "save-as", Value: "string", Description: "stored account name"},
 	}
 }
 
 func decodeAuth(parsed parsedCommand) (commandCall, error) {
 	args := decodeAuthArgs(parsed)
 	return func(_ context.Context, _ *notebooklm.Client) error {
-		_, _, err := handleDecodedAuth(args)
-		return err
+		if _, _, err := handleDecodedAuth(args); err != nil {
+			return err
+		}
+		if name := args.Options.SaveAs; name != "" {
+			printSavedAccountHint(name)
+		}
+		return nil
 	}, nil
 }
 
@@ -159,6 +165,8 @@ func setAuthOption(options *authOptions, flag parsedFlag) error {
 		options.RemoteCDPURL = flag.Value
 	case "authuser":
 		options.AuthUser = flag.Value
+	case "save-as":
+		options.SaveAs = flag.Value
 	}
 	return nil
 }
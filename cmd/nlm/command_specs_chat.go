@@ -77,6 +77,7 @@ func configureChatCommandSpecs(specs map[commandID]*commandSpec) {
 		flagSpec{Name: "open", Description: "open HTML"},
 		flagSpec{Name: "include-follow-ups", Description: "include follow-up prompts"},
 		flagSpec{Name: "backfill", Description: "backfill saved conversation"},
+		flagSpec{Name: "message", Value: "n", Description: "show one exchange"},
 	)
 	configureTypedCommandSpecWithUsage(
 		showSpec,
@@ -356,6 +357,10 @@ func decodeChatShowArgs(parsed parsedCommand) (chatShowArgs, error) {
 	if len(positionals) == 1 && options.Backfill {
 		return chatShowArgs{}, fmt.Errorf("--backfill requires a conversation id")
 	}
+	options.Message = parsedStringFlag(parsed, "message", "")
+	if len(positionals) == 1 && options.Message != "" {
+		return chatShowArgs{}, fmt.Errorf("--message requires a conversation id")
+	}
 	args := chatShowArgs{
 		NotebookID: positionals[0],
 		Options:    options,
@@ -49,6 +49,22 @@ func configureSimpleChatCommandSpecs(specs map[commandID]*commandSpec) {
 		),
 		decodeChatHistory,
 	)
+	searchSpec := specs["chat-search"]
+	searchSpec.Flags = chatSearchFlagSpecs()
+	configureTypedCommandSpec(searchSpec,
+		commandFormOf(withUsage(repeatedOperand("query"), "<query...>")),
+		decodeChatSearch,
+	)
+	saveNoteSpec := specs["chat-save-note"]
+	saveNoteSpec.Flags = chatSaveNoteFlagSpecs()
+	configureTypedCommandSpec(saveNoteSpec,
+		commandFormOf(
+			requiredOperand("notebook"),
+			requiredOperand("conversation"),
+			requiredOperand("message"),
+		),
+		decodeChatSaveNote,
+	)
 	configureTypedCommandSpec(specs["delete-chat"],
 		commandFormOf(requiredOperand("notebook")),
 		decodeChatDelete,
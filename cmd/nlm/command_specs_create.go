@@ -170,7 +170,7 @@ func appCreateCall(args appCreateArgs) commandCall {
 			return err
 		}
 		var sourceIDs []string
-		if !args.Options.Selectors.empty() {
+		if !args.Options.Selectors.Empty() {
 			sourceIDs, err = resolveSourceSelectorsWithOptions(client, args.NotebookID, args.Options.Selectors)
 			if err != nil {
 				return err
@@ -258,7 +258,7 @@ func decodeSlidesCreate(parsed parsedCommand) (commandCall, error) {
 	return func(_ context.Context, client *notebooklm.Client) error {
 		var sourceIDs []string
 		var err error
-		if !args.Options.Selectors.empty() {
+		if !args.Options.Selectors.Empty() {
 			sourceIDs, err = resolveSourceSelectorsWithOptions(client, args.NotebookID, args.Options.Selectors)
 			if err != nil {
 				return err
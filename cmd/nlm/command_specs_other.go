@@ -2,11 +2,22 @@ package main
 
 import (
 	"context"
+	"strings"
 
+	"github.com/tmc/nlm/internal/nlmmcp"
 	"github.com/tmc/nlm/notebooklm"
 )
 
-type mcpArgs struct{}
+type mcpArgs struct {
+	HTTPAddr  string
+	TokenFile string
+	ExportDir string
+	Accounts  []string
+	Policy    nlmmcp.Policy
+	// LocalFiles exposes the commands and flags that read or write files
+	// on the server's machine.
+	LocalFiles bool
+}
 
 type betoolArgs struct {
 	Values []string
@@ -30,7 +41,18 @@ type accountArgs struct {
 type heartbeatArgs struct{}
 
 func configureOtherCommandSpecs(specs map[commandID]*commandSpec) {
-	configureTypedCommandSpec(specs["mcp"], commandFormOf(), decodeMCP)
+	mcpSpec := specs["mcp"]
+	mcpSpec.Flags = []flagSpec{
+		{Name: "http", Value: "addr", Description: "serve streamable HTTP on addr instead of stdio"},
+		{Name: "token-file", Value: "file", Description: "bearer tokens and the credentials each selects"},
+		{Name: "read-only", Description: "register only read-only tools"},
+		{Name: "tools", Value: "globs", Description: "register only matching tools"},
+		{Name: "deny", Value: "globs", Description: "leave out matching tools"},
+		{Name: "notebook", Value: "id", Description: "confine tools to a notebook (repeatable)"},
+		{Name: "export-dir", Value: "dir", Description: "write large exported artifacts to dir"},
+		{Name: "local-files", Description: "expose commands that read or write local files"},
+	}
+	configureTypedCommandSpec(mcpSpec, commandFormOf(), decodeMCP)
 	betoolSpec := specs["betool"]
 	betoolSpec.Flags = []flagSpec{
 		{Name: "proto", PassThrough: true, Visibility: flagHidden},
@@ -79,13 +101,66 @@ func configureOtherCommandSpecs(specs map[commandID]*commandSpec) {
 	configureTypedCommandSpec(specs["hb"], commandFormOf(), decodeHeartbeat)
 }
 
-func decodeMCP(parsedCommand) (commandCall, error) {
-	_ = mcpArgs{}
+func decodeMCP(parsed parsedCommand) (commandCall, error) {
+	args := mcpArgs{
+		HTTPAddr:  parsedStringFlag(parsed, "http", ""),
+		TokenFile: parsedStringFlag(parsed, "token-file", ""),
+		ExportDir: parsedStringFlag(parsed, "export-dir", ""),
+	}
+	if args.TokenFile != "" && args.HTTPAddr == "" {
+		return nil, badArgsf("--token-file requires --http")
+	}
+	if mcpServesAccounts(parsed.globals.accounts.values) {
+		args.Accounts = parsed.globals.accounts.values
+	}
+	if len(args.Accounts) > 0 && args.HTTPAddr != "" {
+		return nil, badArgsf("--account cannot be used with --http; HTTP sessions choose their credentials when they open")
+	}
+	seen := make(map[string]bool)
+	for _, value := range args.Accounts {
+		a, err := parseMCPAccount(value)
+		if err != nil {
+			return nil, badArgsf("%v", err)
+		}
+		if seen[a.Name] {
+			return nil, badArgsf("duplicate account %q; give one a name=<name>", a.Name)
+		}
+		seen[a.Name] = true
+	}
+	readOnly, err := parsedBoolFlag(parsed, "read-only", false)
+	if err != nil {
+		return nil, err
+	}
+	if args.LocalFiles, err = parsedBoolFlag(parsed, "local-files", false); err != nil {
+		return nil, err
+	}
+	args.Policy = nlmmcp.Policy{
+		ReadOnly:  readOnly,
+		Tools:     splitFlagList(parsed.Flags["tools"]),
+		Deny:      splitFlagList(parsed.Flags["deny"]),
+		Notebooks: splitFlagList(parsed.Flags["notebook"]),
+	}
+	if err := args.Policy.Validate(); err != nil {
+		return nil, badArgsf("%v", err)
+	}
 	return func(_ context.Context, client *notebooklm.Client) error {
-		return runMCP(client)
+		return runMCP(client, args)
 	}, nil
 }
 
+// splitFlagList flattens repeated, comma-separated flag values.
+func splitFlagList(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
 func decodeBetool(parsed parsedCommand) (commandCall, error) {
 	jsonOutput, err := parsedBoolFlag(parsed, "json", parsed.globals.jsonOutput)
 	if err != nil {
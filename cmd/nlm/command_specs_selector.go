@@ -84,7 +84,7 @@ func decodeSourceGuideArgs(parsed parsedCommand) (sourceGuideArgs, error) {
 	}
 	selectors := decodeSelectorOptions(parsed)
 	sourceIDs := append([]string(nil), positionals[1:]...)
-	if len(sourceIDs) == 0 && selectors.empty() {
+	if len(sourceIDs) == 0 && selectors.Empty() {
 		return sourceGuideArgs{}, fmt.Errorf("missing source ids or selectors")
 	}
 	force, err := parsedBoolFlag(parsed, "force", parsed.globals.force)
@@ -77,6 +77,7 @@ func configureSourceCommandSpecs(specs map[commandID]*commandSpec) {
 	configureSourceAddSpec(specs["add"])
 	configureSourceSyncSpec(specs["sync"])
 	configureSourcePackSpec(specs["sync-pack"])
+	configureSourceSyncStatusSpec(specs["sync-status"])
 	configureTypedCommandSpec(specs["rm-source"],
 		commandFormOf(requiredOperand("notebook"), withPlaceholder(requiredOperand("sources"), "source-id|-|a,b,c")),
 		decodeSourceDelete,
@@ -178,6 +179,12 @@ func configureSourceSyncSpec(spec *commandSpec) {
 		{Name: "include-untracked", Description: "include untracked files"},
 		{Name: "parallel", Value: "n", Description: "parallel uploads"},
 		{Name: "pre-process", Value: "command", Description: "pre-process command"},
+		{Name: "extract", Value: "names", Description: "built-in extractors"},
+		{Name: "rev", Value: "rev", Description: "git revision to sync"},
+		{Name: "since", Value: "rev", Description: "add changes-since source"},
+		{Name: "label", Value: "pattern=label", Description: "label rule"},
+		{Name: "per-file", Description: "one source per file"},
+		{Name: "repair", Description: "recover an interrupted sync"},
 	}
 	configureTypedCommandSpecWithUsage(spec,
 		[]commandForm{{
@@ -193,6 +200,33 @@ func configureSourceSyncSpec(spec *commandSpec) {
 	)
 }
 
+func configureSourceSyncStatusSpec(spec *commandSpec) {
+	spec.Flags = []flagSpec{
+		{Name: "name", Aliases: []string{"n"}, Value: "name", Description: "source name"},
+		{Name: "max-bytes", Value: "n", Description: "chunk size"},
+		{Name: "json", Description: "emit JSON"},
+		{Name: "exclude", Aliases: []string{"x"}, Value: "pattern", Description: "exclude pattern"},
+		{Name: "include-untracked", Description: "include untracked files"},
+		{Name: "pre-process", Value: "command", Description: "pre-process command"},
+		{Name: "extract", Value: "names", Description: "built-in extractors"},
+		{Name: "rev", Value: "rev", Description: "git revision to compare"},
+		{Name: "since", Value: "rev", Description: "include changes-since source"},
+		{Name: "per-file", Description: "one source per file"},
+	}
+	configureTypedCommandSpecWithUsage(spec,
+		[]commandForm{{
+			Parts: []operandSpec{withUsage(remainingOperand("positionals"), "<notebook-id> [path...]")},
+			Constraints: []constraint{
+				constraintFunc(validateSourceSyncCommand),
+			},
+		}},
+		decodeSourceSyncStatus,
+		func(path string) {
+			printCommandUsageForPath(path)
+		},
+	)
+}
+
 func configureSourcePackSpec(spec *commandSpec) {
 	spec.Flags = []flagSpec{
 		{Name: "name", Aliases: []string{"n"}, Value: "name", Description: "source name"},
@@ -200,6 +234,9 @@ func configureSourcePackSpec(spec *commandSpec) {
 		{Name: "chunk", Value: "n", Description: "chunk number"},
 		{Name: "exclude", Aliases: []string{"x"}, Value: "pattern", Description: "exclude pattern"},
 		{Name: "pre-process", Value: "command", Description: "pre-process command"},
+		{Name: "extract", Value: "names", Description: "built-in extractors"},
+		{Name: "rev", Value: "rev", Description: "git revision to pack"},
+		{Name: "since", Value: "rev", Description: "add changes-since source"},
 	}
 	configureTypedCommandSpecWithUsage(spec,
 		[]commandForm{{
@@ -285,12 +322,60 @@ func decodeSourceSync(parsed parsedCommand) (commandCall, error) {
 			IncludeUntracked: args.Options.IncludeUntracked,
 			Parallel:         args.Options.Parallel,
 			PreProcess:       args.Options.PreProcess,
+			Extract:          args.Options.Extract,
+			Rev:              args.Options.Rev,
+			Since:            args.Options.Since,
+			Labels:           args.Options.Labels,
+			PerFile:          args.Options.PerFile,
 		}
 		adapter := &syncClientAdapter{client: client}
+		if args.Options.Repair {
+			return nlmsync.Repair(ctx, adapter, args.NotebookID, syncOpts, os.Stdout)
+		}
 		return nlmsync.Run(ctx, adapter, args.NotebookID, args.Paths, syncOpts, os.Stdout)
 	}, nil
 }
 
+// decodeSourceSyncStatus reuses the sync decoder: status takes the subset
+// of sync's flags that shape the bundle, so both see the same parts.
+func decodeSourceSyncStatus(parsed parsedCommand) (commandCall, error) {
+	args, err := decodeSourceSyncArgs(parsed)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, client *notebooklm.Client) error {
+		statusOpts := nlmsync.Options{
+			MaxBytes:         args.Options.MaxBytes,
+			Name:             args.Options.Name,
+			Exclude:          args.Options.Exclude,
+			IncludeUntracked: args.Options.IncludeUntracked,
+			PreProcess:       args.Options.PreProcess,
+			Extract:          args.Options.Extract,
+			Rev:              args.Options.Rev,
+			Since:            args.Options.Since,
+			PerFile:          args.Options.PerFile,
+		}
+		adapter := &syncClientAdapter{client: client}
+		report, err := nlmsync.Status(ctx, adapter, args.NotebookID, args.Paths, statusOpts)
+		if err != nil {
+			return err
+		}
+		if err := nlmsync.WriteStatus(os.Stdout, report, args.Options.JSON); err != nil {
+			return err
+		}
+		drifted := 0
+		for _, st := range report {
+			if st.Drifted() {
+				drifted++
+			}
+		}
+		if drifted > 0 {
+			return fmt.Errorf("%w: %d of %d synced parts out of date", errPrecondition, drifted, len(report))
+		}
+		return nil
+	}, nil
+}
+
 func decodeSourceSyncArgs(parsed parsedCommand) (sourceSyncArgs, error) {
 	positionals := parsed.Args["positionals"]
 	if len(positionals) == 0 {
@@ -325,6 +410,25 @@ func decodeSourceSyncArgs(parsed parsedCommand) (sourceSyncArgs, error) {
 	if maxBytes < 0 {
 		return sourceSyncArgs{}, fmt.Errorf("--max-bytes must be >= 0")
 	}
+	perFile, err := parsedBoolFlag(parsed, "per-file", false)
+	if err != nil {
+		return sourceSyncArgs{}, err
+	}
+	repair, err := parsedBoolFlag(parsed, "repair", false)
+	if err != nil {
+		return sourceSyncArgs{}, err
+	}
+	if repair && len(rawPaths) > 0 {
+		return sourceSyncArgs{}, fmt.Errorf("--repair takes no paths")
+	}
+	var labels []nlmsync.LabelRule
+	for _, v := range parsed.Flags["label"] {
+		rule, err := nlmsync.ParseLabelRule(v)
+		if err != nil {
+			return sourceSyncArgs{}, err
+		}
+		labels = append(labels, rule)
+	}
 	paths := append([]string(nil), rawPaths...)
 	switch {
 	case len(paths) == 0:
@@ -345,6 +449,12 @@ func decodeSourceSyncArgs(parsed parsedCommand) (sourceSyncArgs, error) {
 			IncludeUntracked: includeUntracked,
 			Parallel:         parallel,
 			PreProcess:       parsedStringFlag(parsed, "pre-process", ""),
+			Extract:          append([]string(nil), parsed.Flags["extract"]...),
+			Rev:              parsedStringFlag(parsed, "rev", ""),
+			Since:            parsedStringFlag(parsed, "since", ""),
+			Labels:           labels,
+			PerFile:          perFile,
+			Repair:           repair,
 		},
 	}, nil
 }
@@ -387,6 +497,9 @@ func decodeSourcePackArgs(parsed parsedCommand) (sourcePackArgs, error) {
 			Chunk:      chunk,
 			Exclude:    append([]string(nil), parsed.Flags["exclude"]...),
 			PreProcess: parsedStringFlag(parsed, "pre-process", ""),
+			Extract:    append([]string(nil), parsed.Flags["extract"]...),
+			Rev:        parsedStringFlag(parsed, "rev", ""),
+			Since:      parsedStringFlag(parsed, "since", ""),
 		},
 	}, nil
 }
@@ -86,6 +86,11 @@ var commandSpecs = []*commandSpec{
 		hidden:   true, // top-level shortcut for `source pack`; kept first-class but de-duplicated from help
 		noClient: true,
 	},
+	{
+		ID: "sync-status", Summary: "Report which synced parts are stale without uploading (exit 5 on drift)",
+		Section: "Source",
+		hidden:  true, // top-level shortcut for `source sync status`; de-duplicated from help
+	},
 	{
 		ID: "rm-source", aliases: []string{"source-rm"}, Summary: "Remove one or more sources (pass '-' to read newline-delimited IDs from stdin)", Section: "Source",
 	},
@@ -283,6 +288,7 @@ var commandSpecs = []*commandSpec{
 	// Chat operations
 	{
 		ID: "chat", Summary: "Open interactive chat (one-shot if a prompt is given; -f <file> reads a long prompt from file)", Section: "Chat",
+		keepAlive: true,
 	},
 	{
 		ID: "chat-list", Summary: "List chat sessions (server-side when a notebook is given)", Section: "Chat",
@@ -295,6 +301,19 @@ var commandSpecs = []*commandSpec{
 		ID: "chat-show", Summary: "Render a local chat transcript (see --citations)", Section: "Chat",
 		noAuth: true, noClient: true,
 	},
+	{
+		ID: "chat-search", Summary: "Search local chat transcripts", Section: "Chat",
+		noAuth: true, noClient: true,
+		hidden: true, // flat name for `chat search`; de-duplicated from help
+	},
+	{
+		ID: "chat-save-note", Summary: "Save a chat answer as a note, with its citations as footnotes", Section: "Chat",
+		hidden: true, // flat name for `chat save-note`; de-duplicated from help
+	},
+	{
+		ID: "eval", Summary: "Run a YAML question set against a notebook and write JUnit XML and Markdown reports", Section: "Chat",
+		keepAlive: true,
+	},
 	{
 		ID: "delete-chat", Summary: "Delete server-side chat history", Section: "Chat",
 	},
@@ -328,6 +347,7 @@ var commandSpecs = []*commandSpec{
 	{
 		ID:      "mcp",
 		Summary: "Run the MCP server on stdin/stdout", Section: "Other",
+		keepAlive: true,
 	},
 	{
 		ID: "betool", Summary: "Translate raw batchexecute payloads to JSON and back (offline codec)",
@@ -339,6 +359,41 @@ var commandSpecs = []*commandSpec{
 		ID: "auth", Summary: "Set up authentication from a browser profile", Section: "Other",
 		noAuth: true, noClient: true,
 	},
+	{
+		ID: "auth-list", Summary: "List stored accounts and mark the one in use", Section: "Other",
+		noAuth: true, noClient: true,
+		hidden: true, // flat name for `auth list`; de-duplicated from help
+	},
+	{
+		ID: "auth-use", Summary: "Make a stored account the default for later commands", Section: "Other",
+		noAuth: true, noClient: true,
+		hidden: true, // flat name for `auth use`; de-duplicated from help
+	},
+	{
+		ID: "auth-remove", Summary: "Delete a stored account's credentials", Section: "Other",
+		noAuth: true, noClient: true,
+		hidden: true, // flat name for `auth remove`; de-duplicated from help
+	},
+	{
+		ID: "auth-import", Summary: "Sign in with cookies from a cookies.txt file or a Firefox profile", Section: "Other",
+		noAuth: true, noClient: true,
+		hidden: true, // flat name for `auth import`; de-duplicated from help
+	},
+	{
+		ID: "auth-export", Summary: "Seal stored credentials for another machine (see auth import --sealed)", Section: "Other",
+		noAuth: true, noClient: true,
+		hidden: true, // flat name for `auth export`; de-duplicated from help
+	},
+	{
+		ID: "auth-status", Summary: "Check stored credentials: cookie expiry, token age, and a live account probe", Section: "Other",
+		noAuth: true, noClient: true,
+		hidden: true, // flat name for `auth status`; de-duplicated from help
+	},
+	{
+		ID: "auth-migrate", Summary: "Move stored credentials to another credential store (file, keyring, encrypted)", Section: "Other",
+		noAuth: true, noClient: true,
+		hidden: true, // flat name for `auth migrate`; de-duplicated from help
+	},
 	{
 		ID:      "refresh",
 		Summary: "Refresh stored authentication credentials", Section: "Other",
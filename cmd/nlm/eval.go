@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/tmc/nlm/internal/chateval"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+type evalArgs struct {
+	NotebookID  string
+	SuitePath   string
+	Concurrency int
+	JUnit       string
+	Markdown    string
+}
+
+func configureEvalCommandSpec(specs map[commandID]*commandSpec) {
+	spec := specs["eval"]
+	spec.Flags = []flagSpec{
+		{Name: "concurrency", Value: "n", Description: "Ask at most n questions at once (default 4)"},
+		{Name: "junit", Value: "file", Description: "Write the JUnit XML report to file (default <suite>.junit.xml; - for stdout)"},
+		{Name: "markdown", Value: "file", Description: "Write the Markdown report to file (default <suite>.md; - for stdout)"},
+	}
+	configureTypedCommandSpec(spec,
+		commandFormOf(
+			requiredOperand("notebook"),
+			withUsage(requiredOperand("questions"), "<questions.yaml>"),
+		),
+		decodeEval,
+	)
+}
+
+func decodeEval(parsed parsedCommand) (commandCall, error) {
+	var args evalArgs
+	var err error
+	if args.NotebookID, err = parsedArgument(parsed, "notebook"); err != nil {
+		return nil, err
+	}
+	if args.SuitePath, err = parsedArgument(parsed, "questions"); err != nil {
+		return nil, err
+	}
+	if args.Concurrency, err = parsedIntFlag(parsed, "concurrency", 4); err != nil {
+		return nil, err
+	}
+	if args.Concurrency <= 0 {
+		return nil, badArgsf("--concurrency must be greater than 0")
+	}
+	args.JUnit = parsedStringFlag(parsed, "junit", "")
+	args.Markdown = parsedStringFlag(parsed, "markdown", "")
+	if args.JUnit == "-" && args.Markdown == "-" {
+		return nil, badArgsf("--junit and --markdown cannot both write to stdout")
+	}
+	return func(ctx context.Context, client *notebooklm.Client) error {
+		return runEval(ctx, client, args, os.Stdout, os.Stderr)
+	}, nil
+}
+
+// runEval asks every case of the suite, printing each outcome as it lands,
+// then writes both reports. It fails when any case fails, so CI can gate on
+// the exit status alone.
+func runEval(ctx context.Context, c chateval.Client, args evalArgs, stdout, stderr io.Writer) error {
+	suite, err := chateval.Load(args.SuitePath)
+	if err != nil {
+		return err
+	}
+	if args.JUnit == "" {
+		args.JUnit = suite.Name + ".junit.xml"
+	}
+	if args.Markdown == "" {
+		args.Markdown = suite.Name + ".md"
+	}
+
+	fmt.Fprintf(stderr, "Asking %d questions from %s...\n", len(suite.Cases), args.SuitePath)
+	report, runErr := chateval.Run(ctx, c, suite, chateval.Options{
+		NotebookID:  args.NotebookID,
+		Concurrency: args.Concurrency,
+		Progress: func(r chateval.Result) {
+			printEvalResult(stderr, r)
+		},
+	})
+	// A run cut short still reports the cases it asked.
+	if err := writeEvalReport(args.JUnit, stdout, report, chateval.WriteJUnit); err != nil {
+		return fmt.Errorf("write JUnit report: %w", err)
+	}
+	if err := writeEvalReport(args.Markdown, stdout, report, chateval.WriteMarkdown); err != nil {
+		return fmt.Errorf("write Markdown report: %w", err)
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	passed, failed, errored := report.Counts()
+	fmt.Fprintf(stderr, "%d passed, %d failed, %d errors in %s\n", passed, failed, errored, report.Duration.Round(time.Second))
+	for _, path := range []string{args.JUnit, args.Markdown} {
+		if path != "-" {
+			fmt.Fprintf(stderr, "Wrote %s\n", path)
+		}
+	}
+	if failed+errored > 0 {
+		return fmt.Errorf("%d of %d cases did not pass", failed+errored, len(report.Results))
+	}
+	return nil
+}
+
+func printEvalResult(w io.Writer, r chateval.Result) {
+	switch {
+	case r.Err != nil:
+		fmt.Fprintf(w, "ERROR %s: %v\n", r.Case.Name, r.Err)
+	case len(r.Failures) > 0:
+		fmt.Fprintf(w, "FAIL  %s (%s)\n", r.Case.Name, r.Duration.Round(time.Millisecond))
+		for _, f := range r.Failures {
+			fmt.Fprintf(w, "      %s\n", f)
+		}
+	default:
+		fmt.Fprintf(w, "PASS  %s (%s)\n", r.Case.Name, r.Duration.Round(time.Millisecond))
+	}
+}
+
+// writeEvalReport writes one report to path, or to stdout for "-".
+func writeEvalReport(path string, stdout io.Writer, report *chateval.Report, write func(io.Writer, *chateval.Report) error) error {
+	if path == "-" {
+		return write(stdout, report)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return errors.Join(write(f, report), f.Close())
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// evalTestClient answers every prompt with the prompt itself.
+type evalTestClient struct{}
+
+func (evalTestClient) StreamChat(ctx context.Context, req notebooklm.ChatRequest, callback func(notebooklm.ChatChunk) bool) error {
+	callback(notebooklm.ChatChunk{Phase: notebooklm.ChatChunkAnswer, Text: req.Prompt})
+	return nil
+}
+
+func (evalTestClient) GetProject(ctx context.Context, projectID string) (*notebooklm.Notebook, error) {
+	return &notebooklm.Notebook{}, nil
+}
+
+func (evalTestClient) LoadSourceText(ctx context.Context, sourceID, notebookID string) (notebooklm.LoadSourceText, error) {
+	return notebooklm.LoadSourceText{}, nil
+}
+
+func TestRunEval(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	suite := "cases:\n  - {name: echo, prompt: say hello, contains: [hello]}\n  - {name: quiet, prompt: say nothing, forbid: [nothing]}\n"
+	if err := os.WriteFile("docs.yaml", []byte(suite), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := runEval(context.Background(), evalTestClient{}, evalArgs{
+		NotebookID:  "nb",
+		SuitePath:   "docs.yaml",
+		Concurrency: 2,
+		Markdown:    "-",
+	}, &stdout, &stderr)
+	if err == nil || err.Error() != "1 of 2 cases did not pass" {
+		t.Errorf("runEval error = %v, want one failing case", err)
+	}
+	for _, want := range []string{"PASS  echo", "FAIL  quiet", `contains forbidden "nothing"`, "1 passed, 1 failed, 0 errors"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr lacks %q:\n%s", want, stderr.String())
+		}
+	}
+	if !strings.Contains(stdout.String(), "## FAIL: quiet") {
+		t.Errorf("--markdown - did not write the report to stdout:\n%s", stdout.String())
+	}
+	junit, err := os.ReadFile(filepath.Join(dir, "docs.junit.xml"))
+	if err != nil {
+		t.Fatalf("default JUnit report not written: %v", err)
+	}
+	if !strings.Contains(string(junit), `<testcase name="quiet" classname="docs"`) {
+		t.Errorf("JUnit report:\n%s", junit)
+	}
+}
@@ -71,6 +71,18 @@ type guidebookListRecord struct {
 	Status      string `json:"status"`
 }
 
+type chatSearchRecord struct {
+	NotebookID     string   `json:"notebook_id"`
+	ConversationID string   `json:"conversation_id,omitempty"`
+	Message        int      `json:"message"`
+	MessageID      string   `json:"message_id,omitempty"`
+	Role           string   `json:"role"`
+	Timestamp      string   `json:"timestamp,omitempty"`
+	Score          float64  `json:"score"`
+	Snippet        string   `json:"snippet"`
+	Highlights     [][2]int `json:"highlights,omitempty"`
+}
+
 type chatConversationRecord struct {
 	ConversationID string `json:"conversation_id"`
 	MessageCount   int    `json:"message_count,omitempty"`
@@ -84,3 +96,28 @@ type labelListRecord struct {
 	SourceCount int      `json:"source_count"`
 	SourceIDs   []string `json:"source_ids,omitempty"`
 }
+
+type authStatusRecord struct {
+	OK               bool               `json:"ok"`
+	Account          string             `json:"account"`
+	Source           string             `json:"source"`
+	AuthUser         string             `json:"authuser"`
+	ProbeMillis      int64              `json:"probe_ms,omitempty"`
+	Tier             int                `json:"tier,omitempty"`
+	NotebookLimit    int                `json:"notebook_limit,omitempty"`
+	SourceLimit      int                `json:"source_limit,omitempty"`
+	UploadLimit      int                `json:"upload_limit,omitempty"`
+	TokenIssued      string             `json:"token_issued,omitempty"`
+	TokenRotationDue bool               `json:"token_rotation_due"`
+	Cookies          []authCookieRecord `json:"cookies"`
+	MissingCookies   []string           `json:"missing_cookies,omitempty"`
+	Warnings         []string           `json:"warnings,omitempty"`
+	Error            string             `json:"error,omitempty"`
+}
+
+type authCookieRecord struct {
+	Name        string `json:"name"`
+	Expires     string `json:"expires,omitempty"`
+	ExpiresSoon bool   `json:"expires_soon,omitempty"`
+	Expired     bool   `json:"expired,omitempty"`
+}
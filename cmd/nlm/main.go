@@ -24,6 +24,7 @@ import (
 	"github.com/tmc/nlm/internal/auth"
 	"github.com/tmc/nlm/internal/authuser"
 	"github.com/tmc/nlm/internal/batchexecute"
+	"github.com/tmc/nlm/internal/exitclass"
 	intmethod "github.com/tmc/nlm/internal/method"
 	"github.com/tmc/nlm/internal/nlmmcp"
 	"github.com/tmc/nlm/nlmsync"
@@ -40,6 +41,7 @@ var (
 	authToken         string
 	cookies           string
 	authUser          string
+	account           string // stored account selected by --account or NLM_ACCOUNT
 	debug             bool
 	debugDumpPayload  bool
 	debugParsing      bool
@@ -55,6 +57,7 @@ type chatSession struct {
 	Messages       []storedMessage `json:"messages"`
 	SeqNum         int             `json:"seq_num,omitempty"`          // Next sequence number for this session
 	LastResponseID string          `json:"last_response_id,omitempty"` // ID of last assistant response (for threading)
+	Scope          *chatScope      `json:"scope,omitempty"`            // Sources the next turn is grounded on; nil for all
 	CreatedAt      time.Time       `json:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at"`
 }
@@ -71,6 +74,10 @@ type storedMessage struct {
 	MessageID string `json:"message_id,omitempty"` // Server-assigned response ID
 	SeqNum    int    `json:"seq_num,omitempty"`    // Sequence number within conversation
 
+	// Scope records the sources a user turn was grounded on; nil when the
+	// turn used the whole notebook.
+	Scope *chatScope `json:"scope,omitempty"`
+
 	// Stream data persisted for replay. Thinking is local-only; server history
 	// can supply citations and rich text but may omit live-stream metadata.
 	Thinking  string                `json:"thinking,omitempty"`  // Reasoning traces from intermediate chunks
@@ -96,6 +103,7 @@ func (m storedMessage) MarshalJSON() ([]byte, error) {
 		Thinking  string                `json:"thinking,omitempty"`
 		Citations []notebooklm.Citation `json:"citations,omitempty"`
 		Rich      json.RawMessage       `json:"rich,omitempty"`
+		Scope     *chatScope            `json:"scope,omitempty"`
 	}{
 		Role:      m.Role,
 		Content:   m.Content,
@@ -105,6 +113,7 @@ func (m storedMessage) MarshalJSON() ([]byte, error) {
 		Thinking:  m.Thinking,
 		Citations: m.Citations,
 		Rich:      rich,
+		Scope:     m.Scope,
 	})
 }
 
@@ -118,6 +127,7 @@ func (m *storedMessage) UnmarshalJSON(data []byte) error {
 		Thinking  string                `json:"thinking,omitempty"`
 		Citations []notebooklm.Citation `json:"citations,omitempty"`
 		Rich      json.RawMessage       `json:"rich,omitempty"`
+		Scope     *chatScope            `json:"scope,omitempty"`
 	}
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return err
@@ -130,6 +140,7 @@ func (m *storedMessage) UnmarshalJSON(data []byte) error {
 		SeqNum:    raw.SeqNum,
 		Thinking:  raw.Thinking,
 		Citations: raw.Citations,
+		Scope:     raw.Scope,
 	}
 	if len(raw.Rich) == 0 || string(raw.Rich) == "null" {
 		return nil
@@ -163,6 +174,7 @@ func prepareRuntime(stderr io.Writer, globals globalOptions) {
 		}
 	}
 
+	adoptCredentialPassphrase()
 	// Load stored environment variables
 	loadStoredEnv()
 	if authUser == "" {
@@ -307,6 +319,9 @@ func run(inv invocation) error {
 
 	cmdName, entry, args := inv.name, inv.cmd, inv.args
 	warnCompatibilityCommand(cmdName, entry)
+	if err := checkAccountSelection(entry.spec, inv.globals.accounts.values); err != nil {
+		return err
+	}
 
 	parsed, err := parseBoundCommand(entry, cmdName, args, inv.globals)
 	if err != nil {
@@ -331,6 +346,10 @@ func run(inv invocation) error {
 
 	// Check authentication.
 	if !entry.spec.noAuth && (authToken == "" || cookies == "") {
+		if name := selectedAccount(); name != "" {
+			fmt.Fprintf(os.Stderr, "nlm: Authentication required for '%s'. Account %s has no stored credentials; run 'nlm auth login --save-as %s'.\n", cmdName, name, name)
+			return fmt.Errorf("authentication required")
+		}
 		fmt.Fprintf(os.Stderr, "nlm: Authentication required for '%s'. Run 'nlm auth' first, or export NLM_AUTH_TOKEN and NLM_COOKIES (see 'nlm auth --print-env').\n", cmdName)
 		return fmt.Errorf("authentication required")
 	}
@@ -366,6 +385,13 @@ func run(inv invocation) error {
 	if autoRefreshEnabled() && hasCachedBrowserProfile() {
 		maxAttempts = 2
 	}
+	// Long-running commands refresh credentials per request instead, so a
+	// failure hours in does not restart the whole command.
+	if entry.spec.keepAlive {
+		provider := keepAliveProvider(selectedAccount(), notebooklm.Credentials{AuthToken: authToken, Cookies: cookies})
+		opts = append(opts, notebooklm.WithCredentialProvider(provider))
+		maxAttempts = 1
+	}
 
 	for i := 0; i < maxAttempts; i++ {
 		if i > 0 {
@@ -415,64 +441,7 @@ func run(inv invocation) error {
 
 // isAuthenticationError checks if an error is related to authentication
 func isAuthenticationError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	var apiErr *batchexecute.APIError
-	if errors.As(err, &apiErr) {
-		if apiErr.ErrorCode != nil {
-			switch apiErr.ErrorCode.Type {
-			case batchexecute.ErrorTypeAuthentication:
-				return true
-			case batchexecute.ErrorTypeAuthorization,
-				batchexecute.ErrorTypePermissionDenied,
-				batchexecute.ErrorTypeNotFound:
-				return false
-			}
-		}
-		switch apiErr.HTTPStatus {
-		case 401:
-			return true
-		case 403, 404:
-			return false
-		}
-	}
-
-	// Check for batchexecute unauthorized error
-	if errors.Is(err, batchexecute.ErrUnauthorized) {
-		return true
-	}
-
-	// Check for common authentication error messages
-	errorStr := strings.ToLower(err.Error())
-	authKeywords := []string{
-		"unauthenticated",
-		"authentication",
-		"unauthorized",
-		"api error 16", // Google API authentication error
-		"error 16",
-		"status: 401",
-		"session invalid",
-		"invalid session",
-		"session expired",
-		"expired session",
-		"login required",
-		"auth required",
-		"invalid credentials",
-		"token expired",
-		"expired token",
-		"cookie invalid",
-		"invalid cookie",
-	}
-
-	for _, keyword := range authKeywords {
-		if strings.Contains(errorStr, keyword) {
-			return true
-		}
-	}
-
-	return false
+	return exitclass.IsAuthentication(err)
 }
 
 // versionString returns a human-readable version line derived from
@@ -524,17 +493,35 @@ func versionString() string {
 	return fmt.Sprintf("nlm %s", version)
 }
 
-func runMCP(client *notebooklm.Client) error {
+func runMCP(client *notebooklm.Client, args mcpArgs) error {
 	info, ok := runtimedebug.ReadBuildInfo()
 	version := "devel"
 	if ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
 		version = info.Main.Version
 	}
-	impl := &mcp.Implementation{
-		Name:    "nlm",
-		Version: version,
+	opts := nlmmcp.Options{
+		Impl: &mcp.Implementation{
+			Name:    "nlm",
+			Version: version,
+		},
+		Policy:     &args.Policy,
+		Commands:   mcpCommandTools(args.LocalFiles),
+		RunCommand: runMCPCommand,
+		ExportDir:  args.ExportDir,
+	}
+	if args.HTTPAddr != "" {
+		return runMCPHTTP(client, opts, args)
 	}
-	return nlmmcp.Run(context.Background(), client, impl)
+	if len(args.Accounts) > 0 {
+		accounts, err := newMCPAccounts(args.Accounts)
+		if err != nil {
+			return err
+		}
+		opts.Accounts = accounts
+		opts.Selection = accounts[0].Selection
+		client = accounts[0].Client
+	}
+	return nlmmcp.Run(context.Background(), client, &opts)
 }
 
 var openControllingTTY = func() (io.ReadWriteCloser, error) {
@@ -964,6 +951,30 @@ func (a *syncClientAdapter) LabelsForSource(ctx context.Context, notebookID, sou
 	return labelsForSource(context.Background(), a.client, notebookID, sourceID)
 }
 
+func (a *syncClientAdapter) ListLabels(ctx context.Context, notebookID string) ([]nlmsync.Label, error) {
+	labels, err := a.client.GetLabels(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	return syncLabels(labels), nil
+}
+
+func (a *syncClientAdapter) CreateLabel(ctx context.Context, notebookID, name string) ([]nlmsync.Label, error) {
+	labels, err := a.client.CreateLabel(ctx, notebookID, name, "")
+	if err != nil {
+		return nil, err
+	}
+	return syncLabels(labels), nil
+}
+
+func syncLabels(labels []notebooklm.Label) []nlmsync.Label {
+	out := make([]nlmsync.Label, 0, len(labels))
+	for _, l := range labels {
+		out = append(out, nlmsync.Label{ID: l.LabelID, Name: l.Name, SourceIDs: l.SourceIDs})
+	}
+	return out
+}
+
 func (a *syncClientAdapter) AttachLabelSource(ctx context.Context, notebookID, labelID, sourceID string) error {
 	return a.client.AttachLabelSource(ctx, notebookID, labelID, sourceID)
 }
@@ -2540,7 +2551,7 @@ func isConversationID(s string) bool {
 
 // oneShotChat sends a single prompt and streams the response without entering interactive mode.
 func oneShotChat(c *notebooklm.Client, notebookID, prompt string, opts chatOptions) error {
-	sourceIDs, err := resolveSourceSelectorsWithOptions(c, notebookID, opts.Selectors)
+	scope, err := chatScopeFromSelectors(c, notebookID, opts.Selectors)
 	if err != nil {
 		return err
 	}
@@ -2563,13 +2574,14 @@ func oneShotChat(c *notebooklm.Client, notebookID, prompt string, opts chatOptio
 	// Add user message
 	session.Messages = append(session.Messages, storedMessage{
 		Role: "user", Content: prompt, Timestamp: time.Now(),
+		Scope: scope,
 	})
 
 	wireHistory := buildWireHistory(session)
 	chatReq := notebooklm.ChatRequest{
 		ProjectID:      notebookID,
 		Prompt:         prompt,
-		SourceIDs:      sourceIDs,
+		SourceIDs:      scope.sourceIDs(),
 		ConversationID: session.ConversationID,
 		History:        wireHistory,
 		SeqNum:         len(session.Messages)/2 + 1,
@@ -2633,7 +2645,7 @@ func readPromptFile(path string) (string, error) {
 // Mirrors oneShotChat but preserves the server-side conversation ID so callers
 // can chain turns via automation.
 func oneShotChatInConv(c *notebooklm.Client, notebookID, conversationID, prompt string, opts chatOptions) error {
-	sourceIDs, err := resolveSourceSelectorsWithOptions(c, notebookID, opts.Selectors)
+	scope, err := chatScopeFromSelectors(c, notebookID, opts.Selectors)
 	if err != nil {
 		return err
 	}
@@ -2651,12 +2663,13 @@ func oneShotChatInConv(c *notebooklm.Client, notebookID, conversationID, prompt
 	session.ConversationID = conversationID
 	session.Messages = append(session.Messages, storedMessage{
 		Role: "user", Content: prompt, Timestamp: time.Now(),
+		Scope: scope,
 	})
 	wireHistory := buildWireHistory(session)
 	chatReq := notebooklm.ChatRequest{
 		ProjectID:      notebookID,
 		Prompt:         prompt,
-		SourceIDs:      sourceIDs,
+		SourceIDs:      scope.sourceIDs(),
 		ConversationID: conversationID,
 		History:        wireHistory,
 		SeqNum:         len(session.Messages)/2 + 1,
@@ -2692,7 +2705,7 @@ func oneShotChatInConv(c *notebooklm.Client, notebookID, conversationID, prompt
 
 // interactiveChatWithConv starts or resumes an interactive chat with a specific conversation ID.
 func interactiveChatWithConv(c *notebooklm.Client, notebookID, conversationID string, opts chatOptions) error {
-	sourceIDs, err := resolveSourceSelectorsWithOptions(c, notebookID, opts.Selectors)
+	scope, err := chatScopeFromSelectors(c, notebookID, opts.Selectors)
 	if err != nil {
 		return err
 	}
@@ -2735,7 +2748,7 @@ func interactiveChatWithConv(c *notebooklm.Client, notebookID, conversationID st
 	// Override the conversation ID (the loaded session might have an old one)
 	session.ConversationID = conversationID
 
-	return runInteractiveChat(c, session, sourceIDs, opts)
+	return runInteractiveChat(c, session, scope, opts)
 }
 
 // printChatHistory prints conversation history, trying the server first then
@@ -2755,6 +2768,10 @@ func printChatHistory(c *notebooklm.Client, notebookID, conversationID string) e
 			case 2:
 				role = "ASSISTANT"
 			}
+			if m.MessageID != "" {
+				fmt.Printf("[%s] %s\n%s\n\n", role, m.MessageID, m.Content)
+				continue
+			}
 			fmt.Printf("[%s]\n%s\n\n", role, m.Content)
 		}
 		return nil
@@ -2923,6 +2940,12 @@ func chatShowWithClients(notebookID, conversationID string, opts chatRenderOptio
 		fmt.Fprintln(os.Stderr, "No messages in local session.")
 		return nil
 	}
+	first, last := 0, len(session.Messages)
+	if opts.Message != "" {
+		if first, last, err = chatExchange(session.Messages, opts.Message); err != nil {
+			return err
+		}
+	}
 
 	mode := resolveCitationMode(opts.CitationMode)
 	warnDeprecatedCitationMode(os.Stderr, opts.CitationMode)
@@ -3055,8 +3078,11 @@ func chatShowWithClients(notebookID, conversationID string, opts chatRenderOptio
 		NotebookID:     notebookID,
 		ConversationID: conversationID,
 	}
-	for _, m := range session.Messages {
+	for _, m := range session.Messages[first:last] {
 		dm := chatDocMessage{Role: m.Role, Content: m.Content, Thinking: m.Thinking}
+		if m.Scope != nil {
+			dm.Scope, dm.ScopeSourceIDs = m.Scope.Selector, m.Scope.SourceIDs
+		}
 		if m.Role == "assistant" {
 			key := citationContentKey(m.Content)
 			cites := m.Citations
@@ -3499,13 +3525,20 @@ func saveChatSession(session *chatSession) error {
 		return err
 	}
 
-	if err := os.WriteFile(getChatSessionPath(session.NotebookID), data, 0600); err != nil {
+	path := getChatSessionPath(session.NotebookID)
+	if err := os.WriteFile(path, data, 0600); err != nil {
 		return err
 	}
 	if session.ConversationID == "" {
+		updateChatSearchIndex(session, path)
 		return nil
 	}
-	return os.WriteFile(getChatSessionPathForConv(session.NotebookID, session.ConversationID), data, 0600)
+	convPath := getChatSessionPathForConv(session.NotebookID, session.ConversationID)
+	if err := os.WriteFile(convPath, data, 0600); err != nil {
+		return err
+	}
+	updateChatSearchIndex(session, path, convPath)
+	return nil
 }
 
 // saveChatSessionForConversation updates only the selected conversation file.
@@ -3519,7 +3552,12 @@ func saveChatSessionForConversation(session *chatSession) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(getChatSessionPathForConv(session.NotebookID, session.ConversationID), data, 0600)
+	path := getChatSessionPathForConv(session.NotebookID, session.ConversationID)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	updateChatSearchIndex(session, path)
+	return nil
 }
 
 func listChatSessions(jsonOutput bool) error {
@@ -3575,6 +3613,9 @@ func showRecentHistory(session *chatSession, maxMessages int) {
 		timestamp := msg.Timestamp.Format("15:04")
 		if msg.Role == "user" {
 			fmt.Printf("[%s] 👤 You: %s\n", timestamp, msg.Content)
+			if msg.Scope != nil {
+				fmt.Printf("        scope: %s\n", msg.Scope)
+			}
 		} else {
 			fmt.Printf("[%s] 🤖 Assistant: %s\n", timestamp, msg.Content)
 		}
@@ -3642,7 +3683,7 @@ func getFallbackResponse(input, notebookID string) string {
 
 // interactiveChat starts a new or resumes the default interactive chat session for a notebook.
 func interactiveChat(c *notebooklm.Client, notebookID string, opts chatOptions) error {
-	sourceIDs, err := resolveSourceSelectorsWithOptions(c, notebookID, opts.Selectors)
+	scope, err := chatScopeFromSelectors(c, notebookID, opts.Selectors)
 	if err != nil {
 		return err
 	}
@@ -3659,13 +3700,17 @@ func interactiveChat(c *notebooklm.Client, notebookID string, opts chatOptions)
 	if session.ConversationID == "" {
 		session.ConversationID = uuid.New().String()
 	}
-	return runInteractiveChat(c, session, sourceIDs, opts)
+	return runInteractiveChat(c, session, scope, opts)
 }
 
 // runInteractiveChat runs the interactive chat loop with the given session.
-// sourceIDs, when non-empty, scopes every request in the loop to that subset.
-func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []string, opts chatOptions) error {
+// scope, when non-nil, replaces the session's saved scope; the /scope
+// family of commands changes it from there.
+func runInteractiveChat(c *notebooklm.Client, session *chatSession, scope *chatScope, opts chatOptions) error {
 	notebookID := session.NotebookID
+	if scope != nil {
+		session.Scope = scope
+	}
 
 	fmt.Println("\nNotebookLM Interactive Chat")
 	fmt.Println("================================")
@@ -3675,6 +3720,9 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 		convShort = convShort[:8]
 	}
 	fmt.Printf("Conversation: %s\n", convShort)
+	if session.Scope != nil {
+		fmt.Printf("Scope: %s\n", session.Scope)
+	}
 
 	if len(session.Messages) > 0 {
 		fmt.Printf("Chat history: %d messages (started %s)\n",
@@ -3685,7 +3733,7 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 		}
 	}
 
-	fmt.Println("\nCommands: /exit /clear /history /reset /new /fork /conversations /save /help /multiline /file")
+	fmt.Println("\nCommands: /exit /clear /history /reset /new /fork /conversations /save /help /multiline /file /note /sources /scope")
 	fmt.Println("Type your message and press Enter to send.")
 
 	// bufio.Reader (not Scanner): Scanner's 64KB token cap truncates pasted
@@ -3715,11 +3763,14 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 	}
 
 	for {
-		historyCount := len(session.Messages)
+		status := fmt.Sprintf("%s %d msgs", convShort, len(session.Messages))
+		if session.Scope != nil {
+			status += fmt.Sprintf(", %d src", len(session.Scope.SourceIDs))
+		}
 		if multiline {
-			fmt.Printf("[%s %d msgs] (multiline) > ", convShort, historyCount)
+			fmt.Printf("[%s] (multiline) > ", status)
 		} else {
-			fmt.Printf("[%s %d msgs] > ", convShort, historyCount)
+			fmt.Printf("[%s] > ", status)
 		}
 
 		var input string
@@ -3761,6 +3812,18 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 			}
 			input = prompt
 			fmt.Printf("(loaded %d bytes from %s)\n", len(prompt), path)
+		} else if runChatScopeCommand(c, session, input, os.Stdout, os.Stderr) {
+			continue
+		} else if input == "/note" || strings.HasPrefix(input, "/note ") {
+			// /note [title] — save the latest answer as a notebook note.
+			index, err := findChatAnswer(session.Messages, "last")
+			if err == nil {
+				_, err = saveChatAnswerNote(c, session, index, strings.TrimSpace(input[len("/note"):]))
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "nlm: /note: %v\n", err)
+			}
+			continue
 		}
 
 		switch strings.ToLower(input) {
@@ -3798,6 +3861,7 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 				NotebookID:     notebookID,
 				ConversationID: uuid.New().String(),
 				Messages:       []storedMessage{},
+				Scope:          session.Scope,
 				CreatedAt:      time.Now(),
 				UpdatedAt:      time.Now(),
 			}
@@ -3817,6 +3881,7 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 				NotebookID:     notebookID,
 				ConversationID: uuid.New().String(),
 				Messages:       forkedMsgs,
+				Scope:          session.Scope,
 				CreatedAt:      time.Now(),
 				UpdatedAt:      time.Now(),
 			}
@@ -3867,6 +3932,12 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 			fmt.Println("  /save              - Save current session")
 			fmt.Println("  /multiline         - Toggle multiline mode")
 			fmt.Println("  /file <path>       - Send contents of file as the next message")
+			fmt.Println("  /note [title]      - Save the latest answer as a note, citations as footnotes")
+			fmt.Println("  /sources           - List sources; * marks those in scope")
+			fmt.Println("  /scope [<regex>]   - Show the scope, or limit it to matching sources")
+			fmt.Println("  /scope all         - Use every source again")
+			fmt.Println("  /label <name>      - Limit the scope to sources with a label")
+			fmt.Println("  /exclude <regex>   - Drop matching sources from the scope")
 			fmt.Println("  /help              - Show this help")
 			continue
 		case "/multiline":
@@ -3883,6 +3954,7 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 			Role:      "user",
 			Content:   input,
 			Timestamp: time.Now(),
+			Scope:     session.Scope,
 		}
 		session.Messages = append(session.Messages, userMsg)
 
@@ -3895,7 +3967,7 @@ func runInteractiveChat(c *notebooklm.Client, session *chatSession, sourceIDs []
 		chatReq := notebooklm.ChatRequest{
 			ProjectID:      notebookID,
 			Prompt:         input,
-			SourceIDs:      sourceIDs,
+			SourceIDs:      session.Scope.sourceIDs(),
 			ConversationID: session.ConversationID,
 			History:        wireHistory,
 			SeqNum:         session.SeqNum,
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/tmc/nlm/internal/authuser"
+	"github.com/tmc/nlm/internal/nlmmcp"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// runMCPHTTP serves the MCP server over streamable HTTP until interrupted.
+// Sessions that select no credentials share the default client; the others get a
+// client built from the stored profile or authuser they name.
+func runMCPHTTP(client *notebooklm.Client, opts nlmmcp.Options, args mcpArgs) error {
+	tokens, err := loadMCPTokens(args.TokenFile, os.Getenv("NLM_MCP_TOKEN"))
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", args.HTTPAddr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "nlm: serving MCP on http://%s%s (%d bearer tokens)\n", ln.Addr(), nlmmcp.HTTPPath, len(tokens))
+	err = nlmmcp.ServeHTTP(ctx, ln, nlmmcp.HTTPOptions{
+		Tokens: tokens,
+		Server: opts,
+		NewClient: func(sel nlmmcp.Selection) (*notebooklm.Client, error) {
+			if sel == (nlmmcp.Selection{}) {
+				return client, nil
+			}
+			return newMCPSessionClient(sel)
+		},
+	})
+	if err == nil {
+		fmt.Fprintln(os.Stderr, "nlm: MCP server stopped")
+	}
+	return err
+}
+
+// newMCPSessionClient builds a client for a session's selection. A profile
+// supplies its own credentials and default authuser; otherwise the
+// process credentials are used with the selected authuser.
+func newMCPSessionClient(sel nlmmcp.Selection) (*notebooklm.Client, error) {
+	name := selectedAccount()
+	creds := notebooklm.Credentials{AuthToken: authToken, Cookies: cookies}
+	user := authUser
+	if sel.Profile != "" {
+		name = sel.Profile
+		values, err := readStoredProfile(sel.Profile)
+		if err != nil {
+			return nil, err
+		}
+		creds = notebooklm.Credentials{AuthToken: values["NLM_AUTH_TOKEN"], Cookies: values["NLM_COOKIES"]}
+		if creds.AuthToken == "" || creds.Cookies == "" {
+			return nil, fmt.Errorf("profile %q has no stored credentials", sel.Profile)
+		}
+		user = authuser.Normalize(values["NLM_AUTHUSER"])
+	}
+	if sel.AuthUser != "" {
+		user = authuser.Normalize(sel.AuthUser)
+	}
+	return newNotebookLMClient(creds, commandClientOptions{},
+		notebooklm.WithAuthUser(user),
+		notebooklm.WithCredentialProvider(keepAliveProvider(name, creds)),
+	), nil
+}
+
+// newMCPAccounts builds the accounts a stdio server acts as from
+// --account values; the first is the default.
+func newMCPAccounts(values []string) ([]nlmmcp.Account, error) {
+	var accounts []nlmmcp.Account
+	for _, value := range values {
+		a, err := parseMCPAccount(value)
+		if err != nil {
+			return nil, err
+		}
+		if a.Client, err = newMCPSessionClient(a.Selection); err != nil {
+			return nil, fmt.Errorf("account %s: %w", a.Name, err)
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, nil
+}
+
+// mcpServesAccounts reports whether --account values ask mcp to serve
+// several accounts, rather than select the one stored account every
+// command would use.
+func mcpServesAccounts(values []string) bool {
+	return len(values) > 1 || len(values) == 1 && strings.Contains(values[0], "=")
+}
+
+// parseMCPAccount reads an --account value: a stored account name, or
+// comma-separated profile=<name>, authuser=<n> and name=<name> fields.
+// Without a name, the account is called by its profile, or else its
+// authuser.
+func parseMCPAccount(value string) (nlmmcp.Account, error) {
+	var a nlmmcp.Account
+	if validAccountName(value) {
+		value = "profile=" + value
+	}
+	for _, field := range strings.Split(value, ",") {
+		key, v, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch {
+		case key == "profile" && v != "":
+			a.Selection.Profile = v
+		case key == "authuser" && v != "":
+			a.Selection.AuthUser = v
+		case key == "name" && v != "":
+			a.Name = v
+		default:
+			return nlmmcp.Account{}, fmt.Errorf("bad --account %q: unknown field %q (want profile=<name>, authuser=<n> or name=<name>)", value, field)
+		}
+	}
+	if a.Selection == (nlmmcp.Selection{}) {
+		return nlmmcp.Account{}, fmt.Errorf("bad --account %q: want profile=<name> or authuser=<n>", value)
+	}
+	if a.Name == "" {
+		a.Name = firstNonEmpty(a.Selection.Profile, a.Selection.AuthUser)
+	}
+	return a, nil
+}
+
+// loadMCPTokens returns the bearer tokens accepted by the HTTP server:
+// envToken, which leaves credential selection to the client, plus the
+// entries of the token file at path, if any.
+func loadMCPTokens(path, envToken string) (map[string]nlmmcp.Selection, error) {
+	tokens := make(map[string]nlmmcp.Selection)
+	if envToken = strings.TrimSpace(envToken); envToken != "" {
+		tokens[envToken] = nlmmcp.Selection{}
+	}
+	if path == "" {
+		return tokens, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open token file: %w", err)
+	}
+	defer f.Close()
+	fileTokens, err := parseMCPTokens(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for token, sel := range fileTokens {
+		tokens[token] = sel
+	}
+	return tokens, nil
+}
+
+// parseMCPTokens reads one token per line, optionally followed by
+// profile=<name> and authuser=<n> to bind the token's sessions to those
+// credentials. Blank lines and lines starting with '#' are ignored.
+func parseMCPTokens(r io.Reader) (map[string]nlmmcp.Selection, error) {
+	tokens := make(map[string]nlmmcp.Selection)
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		var sel nlmmcp.Selection
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			switch {
+			case key == "profile" && value != "":
+				sel.Profile = value
+			case key == "authuser" && value != "":
+				sel.AuthUser = value
+			default:
+				return nil, fmt.Errorf("line %d: unknown field %q (want profile=<name> or authuser=<n>)", n, field)
+			}
+		}
+		if _, dup := tokens[fields[0]]; dup {
+			return nil, fmt.Errorf("line %d: duplicate token", n)
+		}
+		tokens[fields[0]] = sel
+	}
+	return tokens, s.Err()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmc/nlm/internal/nlmmcp"
+)
+
+func TestParseMCPTokens(t *testing.T) {
+	got, err := parseMCPTokens(strings.NewReader(`# team gateway
+shared
+alice profile=work
+bob   authuser=2
+carol profile=work authuser=1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]nlmmcp.Selection{
+		"shared": {},
+		"alice":  {Profile: "work"},
+		"bob":    {AuthUser: "2"},
+		"carol":  {Profile: "work", AuthUser: "1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokens = %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{"t profile=", "t user=1", "t\nt"} {
+		if _, err := parseMCPTokens(strings.NewReader(bad)); err == nil {
+			t.Errorf("parseMCPTokens(%q): want error", bad)
+		}
+	}
+}
+
+func TestNewMCPSessionClientProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".nlm", "profiles")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeStoredEnvFile(filepath.Join(dir, "work.env"), map[string]string{
+		"NLM_AUTH_TOKEN": "tok",
+		"NLM_COOKIES":    "SID=1",
+		"NLM_AUTHUSER":   "1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newMCPSessionClient(nlmmcp.Selection{Profile: "work"}); err != nil {
+		t.Errorf("profile work: %v", err)
+	}
+	for _, name := range []string{"missing", "../env", ".hidden"} {
+		if _, err := newMCPSessionClient(nlmmcp.Selection{Profile: name}); err == nil {
+			t.Errorf("profile %q: want error", name)
+		}
+	}
+}
+
+func TestParseMCPAccount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  nlmmcp.Account
+	}{
+		{"authuser=1", nlmmcp.Account{Name: "1", Selection: nlmmcp.Selection{AuthUser: "1"}}},
+		{"work", nlmmcp.Account{Name: "work", Selection: nlmmcp.Selection{Profile: "work"}}},
+		{"profile=lab", nlmmcp.Account{Name: "lab", Selection: nlmmcp.Selection{Profile: "lab"}}},
+		{"profile=lab, authuser=2,name=lab2", nlmmcp.Account{Name: "lab2", Selection: nlmmcp.Selection{Profile: "lab", AuthUser: "2"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseMCPAccount(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("parseMCPAccount(%q) = %+v, %v; want %+v", tt.value, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "name=x", "user=1", "profile=", "../work"} {
+		if _, err := parseMCPAccount(bad); err == nil {
+			t.Errorf("parseMCPAccount(%q): want error", bad)
+		}
+	}
+}
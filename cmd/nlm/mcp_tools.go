@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/tmc/nlm/internal/authuser"
+	"github.com/tmc/nlm/internal/exitclass"
+	"github.com/tmc/nlm/internal/nlmmcp"
+)
+
+// mcpExcludedCommands are stable commands that are not exposed as MCP
+// tools, with the reason.
+var mcpExcludedCommands = map[commandID]string{
+	"chat":          "interactive REPL; the chat tool covers it",
+	"generate-chat": "the chat tool covers it",
+	"mcp":           "the server itself",
+	"auth":          "drives a browser login",
+	"refresh":       "drives a browser login",
+	"eval":          "reads a question file and writes report files; a CI harness",
+
+	"auth-list":    "manages local credential files",
+	"auth-use":     "manages local credential files",
+	"auth-remove":  "manages local credential files",
+	"auth-migrate": "manages local credential files",
+	"auth-status":  "reports on the server's own credentials",
+	"auth-import":  "manages local credential files",
+	"auth-export":  "manages local credential files",
+}
+
+// mcpLocalFileCommands are stable commands whose operands name files on
+// the server's machine, with what they do there. They become tools only
+// under nlm mcp --local-files.
+var mcpLocalFileCommands = map[commandID]string{
+	"add":                  "reads source files",
+	"sync":                 "reads the synced tree",
+	"sync-status":          "reads the synced tree",
+	"sync-pack":            "reads the packed tree",
+	"audio-download":       "writes the audio file",
+	"deck-download":        "writes the deck file",
+	"notebook-cover-image": "reads the image file",
+}
+
+// mcpReadOnlyCommands are the commands whose tools are annotated read-only:
+// they change nothing in a notebook and, with the flags tools expose, run
+// no commands and write no files.
+var mcpReadOnlyCommands = map[commandID]bool{
+	"analytics":          true,
+	"artifacts":          true,
+	"audio-get":          true,
+	"audio-list":         true,
+	"audio-suggestions":  true,
+	"chat-history":       true,
+	"chat-list":          true,
+	"chat-search":        true,
+	"chat-show":          true,
+	"check-source":       true,
+	"get-artifact":       true,
+	"get-instructions":   true,
+	"guidebook":          true,
+	"guidebook-details":  true,
+	"guidebooks":         true,
+	"label-list":         true,
+	"list":               true,
+	"list-featured":      true,
+	"notes":              true,
+	"read-artifact":      true,
+	"read-note":          true,
+	"read-source":        true,
+	"report-suggestions": true,
+	"share-details":      true,
+	"sources":            true,
+	"sync-pack":          true,
+	"sync-status":        true,
+}
+
+// mcpDestructiveCommands are the commands whose tools are annotated
+// destructive.
+var mcpDestructiveCommands = map[commandID]bool{
+	"audio-rm":        true,
+	"delete-artifact": true,
+	"delete-chat":     true,
+	"guidebook-rm":    true,
+	"label-delete":    true,
+	"rm":              true,
+	"rm-note":         true,
+	"rm-source":       true,
+}
+
+// mcpSkippedFlags are flags left out of generated tool schemas: --yes is
+// always passed, --pre-process would let a caller run shell commands on
+// the server, and the rest only change how output reaches a terminal.
+var mcpSkippedFlags = map[string]bool{
+	"yes":         true,
+	"pre-process": true,
+	"chunked":     true,
+	"open":        true,
+}
+
+// mcpLocalFileFlags name files on the server's machine to read or write.
+// They are left out of tool schemas unless nlm mcp --local-files is set.
+var mcpLocalFileFlags = map[string]bool{
+	"content-file": true,
+	"out":          true,
+	"output":       true,
+	"prompt-file":  true,
+}
+
+// mcpOperandNames renames positional operands to the property names the
+// built-in tools use.
+var mcpOperandNames = map[string]string{
+	"notebook": "notebook_id",
+	"source":   "source_id",
+	"note":     "note_id",
+	"artifact": "artifact_id",
+}
+
+// mcpCommandTools returns a tool for each stable command, named after its
+// preferred path. localFiles admits the commands and flags that read or
+// write files on the server's machine.
+func mcpCommandTools(localFiles bool) []nlmmcp.CommandTool {
+	var tools []nlmmcp.CommandTool
+	seen := make(map[commandID]bool)
+	for _, cmd := range commands {
+		if cmd.surface != surfaceStable || cmd.hidden || seen[cmd.spec.ID] {
+			continue
+		}
+		seen[cmd.spec.ID] = true
+		if _, ok := mcpExcludedCommands[cmd.spec.ID]; ok {
+			continue
+		}
+		if _, ok := mcpLocalFileCommands[cmd.spec.ID]; ok && !localFiles {
+			continue
+		}
+		tools = append(tools, mcpCommandTool(cmd, localFiles))
+	}
+	return tools
+}
+
+func mcpCommandTool(cmd command, localFiles bool) nlmmcp.CommandTool {
+	tool := nlmmcp.CommandTool{
+		Name:        strings.NewReplacer(" ", "_", "-", "_").Replace(cmd.name),
+		Path:        strings.Fields(cmd.name),
+		Description: strings.TrimSpace(cmd.spec.Summary + ". Runs: nlm " + cmd.name + " " + cmd.argsUsage),
+		ReadOnly:    mcpReadOnlyCommands[cmd.spec.ID],
+		Destructive: mcpDestructiveCommands[cmd.spec.ID],
+	}
+	if operands, ok := mcpOperands(cmd); ok {
+		tool.Operands = operands
+	} else {
+		tool.Args = cmd.argsUsage
+	}
+	for _, f := range commandFlagsForSurface(cmd.spec, cmd.surfaceSpec) {
+		if f.Name == yesFlag.Name {
+			// Tools cannot answer confirmation prompts.
+			tool.Fixed = append(tool.Fixed, "--yes")
+		}
+		if f.Visibility != flagVisible || f.PassThrough || mcpSkippedFlags[f.Name] {
+			continue
+		}
+		if mcpLocalFileFlags[f.Name] && (!localFiles || tool.ReadOnly) {
+			// A read-only tool keeps its promise even under --local-files.
+			continue
+		}
+		tool.Flags = append(tool.Flags, nlmmcp.CommandFlag{
+			Name:        f.Name,
+			Description: f.Description,
+			Bool:        f.Value == "",
+		})
+	}
+	return tool
+}
+
+// mcpOperands maps a command with a single form of plain named operands
+// onto tool properties. Commands with literals or several forms report
+// false and take their positionals as a list instead.
+func mcpOperands(cmd command) ([]nlmmcp.CommandOperand, bool) {
+	forms := cmd.spec.Forms
+	if cmd.surfaceSpec.Forms != nil {
+		forms = cmd.surfaceSpec.Forms
+	}
+	var visible []commandForm
+	for _, form := range forms {
+		if !form.Hidden {
+			visible = append(visible, form)
+		}
+	}
+	if len(visible) > 1 {
+		return nil, false
+	}
+	var operands []nlmmcp.CommandOperand
+	for _, form := range visible {
+		for _, part := range form.Parts {
+			if part.Hidden {
+				continue
+			}
+			if part.Literal != "" || part.Virtual || part.Cardinality == cardinalityZeroOrMore && len(form.Parts) == 1 {
+				return nil, false
+			}
+			name, ok := mcpOperandNames[part.Name]
+			if !ok {
+				name = strings.ReplaceAll(part.Name, "-", "_")
+			}
+			operands = append(operands, nlmmcp.CommandOperand{
+				Name:        name,
+				Description: firstNonEmpty(part.Usage, part.Placeholder, part.Name),
+				Required:    part.Cardinality == cardinalityRequired || part.Cardinality == cardinalityOneOrMore,
+				Repeated:    part.Cardinality == cardinalityOneOrMore || part.Cardinality == cardinalityZeroOrMore,
+			})
+		}
+	}
+	return operands, true
+}
+
+// runMCPCommand runs this binary with args and returns its output. The
+// child inherits the server's credentials, replaced by those sel names.
+// Running in a separate process keeps command output off the stdio
+// transport and concurrent calls apart.
+func runMCPCommand(ctx context.Context, sel nlmmcp.Selection, args []string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	env, err := mcpCommandEnv(sel)
+	if err != nil {
+		return "", err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	msg := strings.TrimSpace(stderr.String())
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && msg != "" {
+			// The child's exit code is its exit class; keep it so the
+			// tool error reports the same class the CLI would.
+			return "", exitclass.With(exitclass.Class(exitErr.ExitCode()),
+				fmt.Errorf("nlm %s: %s (exit %d)", strings.Join(args, " "), msg, exitErr.ExitCode()))
+		}
+		return "", fmt.Errorf("nlm %s: %w", strings.Join(args, " "), err)
+	}
+	if stdout.Len() == 0 {
+		return msg, nil
+	}
+	return stdout.String(), nil
+}
+
+func mcpCommandEnv(sel nlmmcp.Selection) ([]string, error) {
+	env := os.Environ()
+	set := func(key, value string) {
+		if value != "" {
+			env = append(env, key+"="+value)
+		}
+	}
+	set("NLM_AUTH_TOKEN", authToken)
+	set("NLM_COOKIES", cookies)
+	set("NLM_AUTHUSER", authUser)
+	set("NLM_ACCOUNT", account)
+	// An encrypted store's passphrase, typed once into the server, lets
+	// commands read it without a terminal. The child takes it out of its
+	// environment at startup, so nothing the child runs inherits it.
+	set("NLM_CREDENTIAL_PASSPHRASE", typedPassphrase())
+	if sel.Profile != "" {
+		values, err := readStoredProfile(sel.Profile)
+		if err != nil {
+			return nil, err
+		}
+		// Refreshed credentials are written back to the profile.
+		env = append(env, "NLM_ACCOUNT="+sel.Profile)
+		set("NLM_AUTH_TOKEN", values["NLM_AUTH_TOKEN"])
+		set("NLM_COOKIES", values["NLM_COOKIES"])
+		env = append(env, "NLM_AUTHUSER="+authuser.Normalize(values["NLM_AUTHUSER"]))
+	}
+	if sel.AuthUser != "" {
+		env = append(env, "NLM_AUTHUSER="+authuser.Normalize(sel.AuthUser))
+	}
+	return env, nil
+}
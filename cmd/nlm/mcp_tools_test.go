@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tmc/nlm/internal/nlmmcp"
+)
+
+func TestMCPCommandToolsCoverStableCommands(t *testing.T) {
+	names := make(map[string]bool)
+	paths := make(map[string]bool)
+	for _, tool := range mcpCommandTools(true) {
+		if names[tool.Name] {
+			t.Errorf("duplicate tool name %q", tool.Name)
+		}
+		names[tool.Name] = true
+		paths[strings.Join(tool.Path, " ")] = true
+	}
+	covered := make(map[commandID]bool)
+	for _, cmd := range commands {
+		if cmd.surface == surfaceStable && !cmd.hidden && paths[cmd.name] {
+			covered[cmd.spec.ID] = true
+		}
+	}
+	stable := make(map[commandID]bool)
+	for _, cmd := range commands {
+		if cmd.surface != surfaceStable || cmd.hidden {
+			continue
+		}
+		stable[cmd.spec.ID] = true
+		if _, excluded := mcpExcludedCommands[cmd.spec.ID]; !excluded && !covered[cmd.spec.ID] {
+			t.Errorf("stable command %q has no MCP tool; add one or list it in mcpExcludedCommands", cmd.name)
+		}
+	}
+	for name, ids := range map[string][]commandID{
+		"mcpExcludedCommands":    slices.Collect(maps.Keys(mcpExcludedCommands)),
+		"mcpLocalFileCommands":   slices.Collect(maps.Keys(mcpLocalFileCommands)),
+		"mcpReadOnlyCommands":    slices.Collect(maps.Keys(mcpReadOnlyCommands)),
+		"mcpDestructiveCommands": slices.Collect(maps.Keys(mcpDestructiveCommands)),
+	} {
+		for _, id := range ids {
+			if !stable[id] {
+				t.Errorf("%s lists %q, which is not a stable command", name, id)
+			}
+		}
+	}
+}
+
+// TestMCPCommandToolsStayOffTheServer checks what a caller can reach on the
+// server's machine: without --local-files no tool reads or writes local
+// files, and in either mode no tool runs commands and no read-only tool
+// writes files.
+func TestMCPCommandToolsStayOffTheServer(t *testing.T) {
+	specFlags := make(map[string]map[string]flagSpec)
+	for _, cmd := range commands {
+		flags := make(map[string]flagSpec)
+		for _, f := range commandFlagsForSurface(cmd.spec, cmd.surfaceSpec) {
+			flags[f.Name] = f
+		}
+		specFlags[strings.NewReplacer(" ", "_", "-", "_").Replace(cmd.name)] = flags
+	}
+	for _, localFiles := range []bool{false, true} {
+		tools := make(map[string]nlmmcp.CommandTool)
+		for _, tool := range mcpCommandTools(localFiles) {
+			tools[tool.Name] = tool
+			for _, f := range tool.Flags {
+				value := specFlags[tool.Name][f.Name].Value
+				switch {
+				case value == "command":
+					t.Errorf("local files %v: %s takes --%s, which runs a command", localFiles, tool.Name, f.Name)
+				case tool.ReadOnly && (value == "file" || value == "dir"):
+					t.Errorf("local files %v: read-only %s takes --%s <%s>", localFiles, tool.Name, f.Name, value)
+				case !localFiles && mcpLocalFileFlags[f.Name]:
+					t.Errorf("%s takes --%s without --local-files", tool.Name, f.Name)
+				}
+			}
+		}
+		for _, name := range []string{"source_sync", "source_add", "deck_download", "audio_download"} {
+			if _, ok := tools[name]; ok != localFiles {
+				t.Errorf("local files %v: %s registered = %v", localFiles, name, ok)
+			}
+		}
+		if _, ok := tools["generate_chat"]; ok {
+			t.Error("generate_chat is a tool; the chat tool replaces it")
+		}
+		if !tools["chat_search"].ReadOnly {
+			t.Error("chat_search is not read-only")
+		}
+		if tool := tools["label_unlabeled"]; tool.ReadOnly {
+			t.Error("label_unlabeled assigns labels but is read-only")
+		}
+	}
+}
+
+func TestMCPCommandToolArguments(t *testing.T) {
+	var got []string
+	server := nlmmcp.New(nil, &nlmmcp.Options{
+		Commands: mcpCommandTools(true),
+		RunCommand: func(_ context.Context, _ nlmmcp.Selection, args []string) (string, error) {
+			got = args
+			return "", nil
+		},
+	})
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	listed, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotations := make(map[string]*mcp.ToolAnnotations)
+	for _, tool := range listed.Tools {
+		annotations[tool.Name] = tool.Annotations
+	}
+	if a := annotations["label_list"]; a == nil || !a.ReadOnlyHint {
+		t.Errorf("label_list annotations = %+v, want read-only", a)
+	}
+	if a := annotations["artifact_delete"]; a == nil || a.DestructiveHint == nil || !*a.DestructiveHint {
+		t.Errorf("artifact_delete annotations = %+v, want destructive", a)
+	}
+
+	for _, tt := range []struct {
+		tool string
+		args map[string]any
+		want []string
+	}{
+		{"label_create", map[string]any{"notebook_id": "nb", "name": "Docs", "json": true},
+			[]string{"label", "create", "--json=true", "nb", "Docs"}},
+		{"source_sync", map[string]any{"args": []string{"nb", "docs"}, "dry_run": true, "exclude": "*.tmp"},
+			[]string{"source", "sync", "--dry-run=true", "--exclude=*.tmp", "nb", "docs"}},
+		{"label_delete", map[string]any{"notebook_id": "nb", "labels": []string{"l1", "l2"}},
+			[]string{"label", "delete", "nb", "l1", "l2"}},
+		{"artifact_delete", map[string]any{"artifact_id": "a1"},
+			[]string{"artifact", "delete", "--yes", "a1"}},
+	} {
+		got = nil
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: tt.tool, Arguments: tt.args})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.tool, err)
+		}
+		if res.IsError {
+			t.Fatalf("%s: %v", tt.tool, res.Content)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s ran %q, want %q", tt.tool, got, tt.want)
+		}
+		inv, _, err := parseFlagPlacementInvocation(got...)
+		if err != nil {
+			t.Errorf("%s: parse %q: %v", tt.tool, got, err)
+			continue
+		}
+		if _, err := parseBoundCommand(inv.cmd, inv.name, inv.args, inv.globals); err != nil {
+			t.Errorf("%s: parse %q: %v", tt.tool, got, err)
+		}
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "label_create", Arguments: map[string]any{"notebook_id": "nb", "bogus": 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError {
+		t.Error("unknown argument: want error result")
+	}
+}
+
+func TestMCPPromptsFindCommandTools(t *testing.T) {
+	server := nlmmcp.New(nil, &nlmmcp.Options{
+		Commands: mcpCommandTools(true),
+		RunCommand: func(context.Context, nlmmcp.Selection, []string) (string, error) {
+			return "", nil
+		},
+	})
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	for _, tt := range []struct {
+		prompt string
+		args   map[string]string
+		tools  []string
+	}{
+		{"summarize-repo", map[string]string{"notebook_id": "nb", "path": "."}, []string{"source_sync_status", "source_sync"}},
+		{"study-guide", map[string]string{"notebook_id": "nb"}, []string{"create_report"}},
+		{"podcast-brief", map[string]string{"notebook_id": "nb"}, []string{"audio_suggestions"}},
+	} {
+		res, err := cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: tt.prompt, Arguments: tt.args})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.prompt, err)
+		}
+		text := res.Messages[0].Content.(*mcp.TextContent).Text
+		for _, tool := range tt.tools {
+			if !strings.Contains(text, "`"+tool+"`") {
+				t.Errorf("%s plan does not use %s:\n%s", tt.prompt, tool, text)
+			}
+		}
+	}
+}
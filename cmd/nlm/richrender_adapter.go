@@ -68,6 +68,10 @@ func renderChatMarkdown(w io.Writer, doc chatDocument, ctx chatRenderContext) er
 	return richrender.RenderChatMarkdown(w, doc, ctx)
 }
 
+func renderAnswerNoteMarkdown(w io.Writer, m chatDocMessage, ctx chatRenderContext) error {
+	return richrender.RenderAnswerNoteMarkdown(w, m, ctx)
+}
+
 func renderChatText(out, status io.Writer, doc chatDocument, mode citationRenderMode, ctx chatRenderContext) error {
 	return richrender.RenderChatText(out, status, doc, mode, ctx)
 }
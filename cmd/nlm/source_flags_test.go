@@ -164,6 +164,27 @@ func TestParseSourceSyncArgs(t *testing.T) {
 	}
 }
 
+func TestSourceSyncStatusRoutesBeforeSync(t *testing.T) {
+	name, _, rest, ok := findCommand([]string{"source", "sync", "status", "nb", "./docs"})
+	if !ok || name != "source sync status" {
+		t.Fatalf("findCommand = %q, %v; want source sync status", name, ok)
+	}
+	parsed, err := parseSourceCommand(name, append(rest, "--json", "--extract", "ipynb"), globalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := decodeSourceSyncArgs(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.NotebookID != "nb" || !args.Options.JSON || !reflect.DeepEqual(args.Options.Extract, []string{"ipynb"}) {
+		t.Fatalf("status args = %+v", args)
+	}
+	if _, err := parseSourceCommand(name, []string{"nb", "--force"}, globalOptions{}); err == nil {
+		t.Error("status accepted sync-only --force")
+	}
+}
+
 func TestParseSourcePackArgs(t *testing.T) {
 	parsed, err := parseSourceCommand("source pack", []string{"./docs", "--chunk", "2", "--name", "bundle"}, globalOptions{})
 	if err != nil {
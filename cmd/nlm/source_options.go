@@ -1,5 +1,7 @@
 package main
 
+import "github.com/tmc/nlm/nlmsync"
+
 // sourceAddOptions controls source ingestion after command decoding.
 type sourceAddOptions struct {
 	Name            string
@@ -26,6 +28,12 @@ type syncOptions struct {
 	IncludeUntracked bool
 	Parallel         int
 	PreProcess       string
+	Extract          []string
+	Rev              string
+	Since            string
+	Labels           []nlmsync.LabelRule
+	PerFile          bool
+	Repair           bool
 }
 
 type syncPackOptions struct {
@@ -34,4 +42,7 @@ type syncPackOptions struct {
 	Chunk      int
 	Exclude    []string
 	PreProcess string
+	Extract    []string
+	Rev        string
+	Since      string
 }
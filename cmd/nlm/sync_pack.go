@@ -30,6 +30,9 @@ func runSyncPack(args []string, opts syncPackOptions) error {
 		Name:       opts.Name,
 		Exclude:    opts.Exclude,
 		PreProcess: opts.PreProcess,
+		Extract:    opts.Extract,
+		Rev:        opts.Rev,
+		Since:      opts.Since,
 	}
 	chunks, names, err := nlmsync.Pack(paths, packOpts)
 	if err != nil {
@@ -32,6 +32,7 @@ type BrowserAuth struct {
 	sessionID       string // Captured session ID for Jules API
 	blParam         string // Build label parameter for Jules API
 	signalerAuth    string // Captured signaler Authorization header for punctual APIs
+	cookieExpiry    map[string]time.Time
 	sourcePath      string // Source path parameter for Jules API
 	rtParam         string // RT parameter for Jules API
 }
@@ -43,6 +44,9 @@ type AuthData struct {
 	SessionID    string
 	BLParam      string // Build label parameter for Jules API
 	SignalerAuth string // Authorization header for NotebookLM signaler APIs
+	// CookieExpiry holds the expiry of each persistent cookie in Cookies.
+	// Session cookies have no entry.
+	CookieExpiry map[string]time.Time
 	SourcePath   string // Source path parameter for Jules API
 	RTParam      string // RT parameter for Jules API
 }
@@ -66,6 +70,7 @@ func (ba *BrowserAuth) GetAuthData(opts ...Option) (*AuthData, error) {
 		SessionID:    ba.sessionID,
 		BLParam:      ba.blParam,
 		SignalerAuth: ba.signalerAuth,
+		CookieExpiry: ba.cookieExpiry,
 		SourcePath:   ba.sourcePath,
 		RTParam:      ba.rtParam,
 	}, nil
@@ -1314,10 +1319,15 @@ func (ba *BrowserAuth) tryExtractAuth(ctx context.Context) (token, cookies strin
 			}
 
 			var cookieStrs []string
+			expiry := make(map[string]time.Time)
 			for _, ck := range cks {
 				cookieStrs = append(cookieStrs, fmt.Sprintf("%s=%s", ck.Name, ck.Value))
+				if !ck.Session && ck.Expires > 0 {
+					expiry[ck.Name] = time.Unix(int64(ck.Expires), 0)
+				}
 			}
 			cookies = strings.Join(cookieStrs, "; ")
+			ba.cookieExpiry = expiry
 			return nil
 		}),
 	)
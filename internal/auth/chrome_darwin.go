@@ -156,3 +156,8 @@ func getBraveProfilePath() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, "Library", "Application Support", "BraveSoftware", "Brave-Browser")
 }
+
+func getFirefoxRoots() []string {
+	home, _ := os.UserHomeDir()
+	return []string{filepath.Join(home, "Library", "Application Support", "Firefox")}
+}
@@ -47,3 +47,14 @@ func getBraveProfilePath() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".config", "BraveSoftware", "Brave-Browser")
 }
+
+// getFirefoxRoots returns the directories that may hold Firefox's
+// profiles.ini: the classic, snap, and Flatpak installs.
+func getFirefoxRoots() []string {
+	home, _ := os.UserHomeDir()
+	return []string{
+		filepath.Join(home, ".mozilla", "firefox"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+		filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"),
+	}
+}
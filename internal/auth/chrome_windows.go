@@ -75,3 +75,12 @@ func getBraveProfilePath() string {
 	}
 	return filepath.Join(localAppData, "BraveSoftware", "Brave-Browser", "User Data")
 }
+
+func getFirefoxRoots() []string {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		home, _ := os.UserHomeDir()
+		appData = filepath.Join(home, "AppData", "Roaming")
+	}
+	return []string{filepath.Join(appData, "Mozilla", "Firefox")}
+}
@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cookie is a browser cookie read from a cookies.txt file or a Firefox
+// profile.
+type Cookie struct {
+	Domain  string
+	Path    string
+	Name    string
+	Value   string
+	Secure  bool
+	Expires time.Time // zero for session cookies
+}
+
+// ReadCookiesTxt reads cookies in the Netscape cookies.txt format written
+// by curl, wget and browser export extensions: one cookie per line with
+// tab-separated domain, subdomain flag, path, secure flag, expiry in Unix
+// seconds, name and value. Lines prefixed with #HttpOnly_ are cookies;
+// other lines starting with # are comments.
+func ReadCookiesTxt(r io.Reader) ([]Cookie, error) {
+	var cookies []Cookie
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimRight(s.Text(), "\r")
+		text = strings.TrimPrefix(text, "#HttpOnly_")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) == 6 {
+			// Some exporters drop the empty value column.
+			fields = append(fields, "")
+		}
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("cookies.txt line %d: want 7 tab-separated fields, got %d", line, len(fields))
+		}
+		expires, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("cookies.txt line %d: bad expiry %q", line, fields[4])
+		}
+		cookies = append(cookies, Cookie{
+			Domain:  fields[0],
+			Path:    fields[2],
+			Secure:  strings.EqualFold(fields[3], "TRUE"),
+			Expires: unixTime(int64(expires)),
+			Name:    fields[5],
+			Value:   fields[6],
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read cookies.txt: %w", err)
+	}
+	return cookies, nil
+}
+
+// ReadFirefoxCookies reads the cookies in a Firefox profile directory's
+// cookies.sqlite, including those still in its write-ahead log. Firefox
+// stores cookie values unencrypted.
+func ReadFirefoxCookies(profileDir string) ([]Cookie, error) {
+	db, err := openSQLite(filepath.Join(profileDir, "cookies.sqlite"))
+	if err != nil {
+		return nil, fmt.Errorf("open firefox cookies: %w", err)
+	}
+	columns, rows, err := db.table("moz_cookies")
+	if err != nil {
+		return nil, fmt.Errorf("read firefox cookies: %w", err)
+	}
+	index := make(map[string]int)
+	for i, name := range columns {
+		index[name] = i
+	}
+	for _, name := range []string{"name", "value", "host", "path", "expiry", "isSecure"} {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("read firefox cookies: moz_cookies has no %s column", name)
+		}
+	}
+	text := func(row []any, column string) string {
+		switch v := row[index[column]].(type) {
+		case string:
+			return v
+		case []byte:
+			return string(v)
+		}
+		return ""
+	}
+	number := func(row []any, column string) int64 {
+		v, _ := row[index[column]].(int64)
+		return v
+	}
+	var cookies []Cookie
+	for _, row := range rows {
+		expiry := number(row, "expiry")
+		if expiry > 1e11 {
+			// Firefox 136 and later store milliseconds.
+			expiry /= 1000
+		}
+		cookies = append(cookies, Cookie{
+			Domain:  text(row, "host"),
+			Path:    text(row, "path"),
+			Name:    text(row, "name"),
+			Value:   text(row, "value"),
+			Secure:  number(row, "isSecure") != 0,
+			Expires: unixTime(expiry),
+		})
+	}
+	return cookies, nil
+}
+
+// FirefoxProfileDir finds a Firefox profile directory. name may be a
+// directory holding cookies.sqlite, a profile name or directory name from
+// profiles.ini, or empty for the default profile.
+func FirefoxProfileDir(name string) (string, error) {
+	if name != "" {
+		if _, err := os.Stat(filepath.Join(name, "cookies.sqlite")); err == nil {
+			return name, nil
+		}
+	}
+	var tried []string
+	for _, root := range getFirefoxRoots() {
+		data, err := os.ReadFile(filepath.Join(root, "profiles.ini"))
+		if err != nil {
+			continue
+		}
+		tried = append(tried, root)
+		if dir := findFirefoxProfile(root, data, name); dir != "" {
+			return dir, nil
+		}
+	}
+	if len(tried) == 0 {
+		return "", errors.New("no Firefox profiles.ini found; pass the profile directory instead")
+	}
+	if name == "" {
+		return "", fmt.Errorf("no default Firefox profile in %s", strings.Join(tried, ", "))
+	}
+	return "", fmt.Errorf("Firefox profile %q not found in %s", name, strings.Join(tried, ", "))
+}
+
+// findFirefoxProfile looks name up in the profiles.ini data found in root.
+// With an empty name it returns the profile the newest install uses by
+// default, else the one marked Default=1.
+func findFirefoxProfile(root string, ini []byte, name string) string {
+	type profile struct {
+		name, path       string
+		relative, isDflt bool
+	}
+	var profiles []profile
+	var installDefault string
+	var cur *profile
+	section := ""
+	for line := range strings.Lines(string(ini)) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			cur = nil
+			if strings.HasPrefix(section, "Profile") {
+				profiles = append(profiles, profile{relative: true})
+				cur = &profiles[len(profiles)-1]
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(section, "Install") && key == "Default" && installDefault == "":
+			installDefault = value
+		case cur == nil:
+		case key == "Name":
+			cur.name = value
+		case key == "Path":
+			cur.path = value
+		case key == "IsRelative":
+			cur.relative = value == "1"
+		case key == "Default":
+			cur.isDflt = value == "1"
+		}
+	}
+	dir := func(p profile) string {
+		if p.relative {
+			return filepath.Join(root, filepath.FromSlash(p.path))
+		}
+		return p.path
+	}
+	for _, p := range profiles {
+		switch {
+		case name != "" && (strings.EqualFold(p.name, name) || filepath.Base(p.path) == name):
+			return dir(p)
+		case name == "" && installDefault != "" && p.path == installDefault:
+			return dir(p)
+		}
+	}
+	if name == "" {
+		for _, p := range profiles {
+			if p.isDflt {
+				return dir(p)
+			}
+		}
+	}
+	return ""
+}
+
+// NotebookLMCookies returns the unexpired cookies a browser would send to
+// NotebookLM, the same set extractAuthData reads through the DevTools
+// protocol.
+func NotebookLMCookies(cookies []Cookie, now time.Time) []Cookie {
+	u, _ := url.Parse(appOrigin)
+	host := u.Hostname()
+	var matched []Cookie
+	for _, c := range cookies {
+		domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+		if domain != host && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	return matched
+}
+
+// CookieHeader renders cookies as a Cookie request header.
+func CookieHeader(cookies []Cookie) string {
+	parts := make([]string, len(cookies))
+	for i, c := range cookies {
+		parts[i] = c.Name + "=" + c.Value
+	}
+	return strings.Join(parts, "; ")
+}
+
+// CookieExpiry returns the expiry of each persistent cookie, in the form
+// of AuthData.CookieExpiry.
+func CookieExpiry(cookies []Cookie) map[string]time.Time {
+	expiry := make(map[string]time.Time)
+	for _, c := range cookies {
+		if !c.Expires.IsZero() {
+			expiry[c.Name] = c.Expires
+		}
+	}
+	return expiry
+}
+
+// HasSessionCookies reports whether cookies include any of the Google
+// sign-in cookies extractAuthData requires.
+func HasSessionCookies(cookies []Cookie) bool {
+	for _, c := range cookies {
+		switch c.Name {
+		case "SID", "HSID", "SSID", "APISID":
+			return true
+		}
+	}
+	return false
+}
+
+func unixTime(secs int64) time.Time {
+	if secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var importNow = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func cookieNames(cookies []Cookie) []string {
+	var names []string
+	for _, c := range cookies {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestReadCookiesTxt(t *testing.T) {
+	f, err := os.Open("testdata/cookies.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cookies, err := ReadCookiesTxt(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != 7 {
+		t.Fatalf("read %d cookies, want 7", len(cookies))
+	}
+	if c := cookies[1]; c.Name != "HSID" || c.Domain != ".google.com" || c.Secure {
+		t.Errorf("#HttpOnly_ cookie = %+v", c)
+	}
+	if c := cookies[4]; c.Name != "OSID" || !c.Expires.IsZero() {
+		t.Errorf("session cookie = %+v, want zero Expires", c)
+	}
+
+	got := NotebookLMCookies(cookies, importNow)
+	if want := []string{"SID", "HSID", "SAPISID", "OSID"}; !slices.Equal(cookieNames(got), want) {
+		t.Errorf("NotebookLMCookies = %v, want %v", cookieNames(got), want)
+	}
+	if header := CookieHeader(got); header != "SID=sid-value; HSID=hsid-value; SAPISID=sapisid-value; OSID=osid-value" {
+		t.Errorf("CookieHeader = %q", header)
+	}
+	expiry := CookieExpiry(got)
+	if len(expiry) != 3 || expiry["SID"].Unix() != 4102444800 {
+		t.Errorf("CookieExpiry = %v", expiry)
+	}
+	if !HasSessionCookies(got) {
+		t.Error("HasSessionCookies = false")
+	}
+
+	if _, err := ReadCookiesTxt(strings.NewReader(".google.com\tTRUE\t/\n")); err == nil {
+		t.Error("short line: want error")
+	}
+}
+
+func TestReadFirefoxCookies(t *testing.T) {
+	dir, err := FirefoxProfileDir("testdata/firefox/Profiles/x1y2z3.default-release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies, err := ReadFirefoxCookies(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != 208 {
+		t.Errorf("read %d cookies, want 208", len(cookies))
+	}
+
+	got := NotebookLMCookies(cookies, importNow)
+	// SSID was written after the last checkpoint and is only in the WAL.
+	if want := []string{"SID", "HSID", "SAPISID", "__Secure-1PSID", "OSID", "SSID"}; !slices.Equal(cookieNames(got), want) {
+		t.Fatalf("NotebookLMCookies = %v, want %v", cookieNames(got), want)
+	}
+	if sid := got[0]; sid.Expires.Unix() != 4102444800 || !sid.Secure || sid.Domain != ".google.com" {
+		t.Errorf("SID = %+v; want millisecond expiry read as 4102444800", sid)
+	}
+	if v := got[3].Value; v != strings.Repeat("p", 3000) {
+		t.Errorf("overflowing value has %d bytes, want 3000", len(v))
+	}
+}
+
+func TestFindFirefoxProfile(t *testing.T) {
+	root := filepath.Join("testdata", "firefox")
+	ini, err := os.ReadFile(filepath.Join(root, "profiles.ini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "Profiles/x1y2z3.default-release"},
+		{"default", "Profiles/q9w8e7.default"},
+		{"Default-Release", "Profiles/x1y2z3.default-release"},
+		{"q9w8e7.default", "Profiles/q9w8e7.default"},
+		{"missing", ""},
+	}
+	for _, tt := range tests {
+		want := tt.want
+		if want != "" {
+			want = filepath.Join(root, filepath.FromSlash(want))
+		}
+		if got := findFirefoxProfile(root, ini, tt.name); got != want {
+			t.Errorf("findFirefoxProfile(%q) = %q, want %q", tt.name, got, want)
+		}
+	}
+}
+
+func TestParseCreateTable(t *testing.T) {
+	columns, rowid := parseCreateTable(`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '', name TEXT, "value" TEXT, sameSite INTEGER DEFAULT 0, CONSTRAINT moz_uniqueid UNIQUE (name, originAttributes))`)
+	if want := []string{"id", "originAttributes", "name", "value", "sameSite"}; !slices.Equal(columns, want) {
+		t.Errorf("columns = %v, want %v", columns, want)
+	}
+	if rowid != 0 {
+		t.Errorf("rowid column = %d, want 0", rowid)
+	}
+}
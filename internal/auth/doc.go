@@ -3,4 +3,7 @@
 // Supported browsers include Chrome, Brave, Edge, and Chromium on
 // macOS, Linux, and Windows. Cookies are decrypted using the platform's
 // keychain or secret store.
+//
+// Cookies can also be imported from a Netscape cookies.txt file or read
+// from a Firefox profile's cookies.sqlite.
 package auth
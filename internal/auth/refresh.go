@@ -40,6 +40,7 @@ type NotebookLMPageState struct {
 	GSessionID string
 	SessionID  string
 	BLParam    string
+	AuthToken  string // SNlM0e, the page's XSRF token
 }
 
 // NewRefreshClient creates a new refresh client
@@ -263,6 +264,7 @@ func parseNotebookLMPageState(body []byte) NotebookLMPageState {
 		),
 		SessionID: extractNotebookLMJSONStringField(body, "FdrFJe"),
 		BLParam:   extractNotebookLMJSONStringField(body, "cfb2h"),
+		AuthToken: extractNotebookLMJSONStringField(body, "SNlM0e"),
 	}
 }
 
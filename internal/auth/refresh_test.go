@@ -134,7 +134,7 @@ func TestParseNotebookLMPageState(t *testing.T) {
 <!doctype html>
 <html>
 <head><script>
-window.WIZ_global_data = {"FdrFJe":"-8344731930921376674","cfb2h":"boq_labs-tailwind-frontend_20260406.14_p0"};
+window.WIZ_global_data = {"FdrFJe":"-8344731930921376674","SNlM0e":"AJpMio2_test-token:1760788800000","cfb2h":"boq_labs-tailwind-frontend_20260406.14_p0"};
 </script></head>
 <body>{"gsessionid":"LsWt3iCG3ezhLlQau_BO2Gu853yG1uLi0RnZlSwqVfg"}</body>
 </html>`)
@@ -149,6 +149,9 @@ window.WIZ_global_data = {"FdrFJe":"-8344731930921376674","cfb2h":"boq_labs-tail
 	if got.BLParam != "boq_labs-tailwind-frontend_20260406.14_p0" {
 		t.Fatalf("BLParam = %q, want %q", got.BLParam, "boq_labs-tailwind-frontend_20260406.14_p0")
 	}
+	if got.AuthToken != "AJpMio2_test-token:1760788800000" {
+		t.Fatalf("AuthToken = %q, want %q", got.AuthToken, "AJpMio2_test-token:1760788800000")
+	}
 }
 
 func TestValidateNotebookLMPageURL(t *testing.T) {
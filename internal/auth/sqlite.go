@@ -0,0 +1,411 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// This file reads tables from SQLite databases, enough to get cookies out of
+// a browser profile without a SQLite library. It understands table b-trees,
+// overflow pages, and the write-ahead log, and reads UTF-8 databases only.
+
+const sqliteMagic = "SQLite format 3\x00"
+
+// sqliteDB is a read-only SQLite database held in memory.
+type sqliteDB struct {
+	data     []byte
+	pageSize int
+	usable   int
+	wal      map[uint32][]byte // latest committed WAL copy of each page
+}
+
+// openSQLite reads the database at path together with its write-ahead log,
+// if any, so rows not yet checkpointed are visible.
+func openSQLite(path string) (*sqliteDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 100 || string(data[:16]) != sqliteMagic {
+		return nil, fmt.Errorf("%s: not a SQLite database", path)
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("%s: bad page size %d", path, pageSize)
+	}
+	if enc := binary.BigEndian.Uint32(data[56:60]); enc > 1 {
+		return nil, fmt.Errorf("%s: unsupported text encoding %d", path, enc)
+	}
+	db := &sqliteDB{
+		data:     data,
+		pageSize: pageSize,
+		usable:   pageSize - int(data[20]),
+	}
+	wal, err := os.ReadFile(path + "-wal")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	db.wal = readWAL(wal, pageSize)
+	return db, nil
+}
+
+// readWAL returns the pages of every committed transaction in a WAL file.
+// Frames whose salts differ from the header's are left over from before
+// the log was last reset and are ignored.
+func readWAL(wal []byte, pageSize int) map[uint32][]byte {
+	pages := make(map[uint32][]byte)
+	if len(wal) < 32 {
+		return pages
+	}
+	if magic := binary.BigEndian.Uint32(wal); magic&^1 != 0x377f0682 {
+		return pages
+	}
+	if int(binary.BigEndian.Uint32(wal[8:12])) != pageSize {
+		return pages
+	}
+	salt := wal[16:24]
+	pending := make(map[uint32][]byte)
+	for off := 32; off+24+pageSize <= len(wal); off += 24 + pageSize {
+		frame := wal[off : off+24]
+		if !bytes.Equal(frame[8:16], salt) {
+			break
+		}
+		pending[binary.BigEndian.Uint32(frame)] = wal[off+24 : off+24+pageSize]
+		if binary.BigEndian.Uint32(frame[4:8]) != 0 {
+			for n, page := range pending {
+				pages[n] = page
+			}
+			clear(pending)
+		}
+	}
+	return pages
+}
+
+func (db *sqliteDB) page(n uint32) ([]byte, error) {
+	if page, ok := db.wal[n]; ok {
+		return page, nil
+	}
+	start := (int(n) - 1) * db.pageSize
+	if n == 0 || start+db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("sqlite: page %d out of range", n)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// table returns the column names and rows of the named table.
+func (db *sqliteDB) table(name string) ([]string, [][]any, error) {
+	var root uint32
+	var sql string
+	err := db.walkTable(1, func(_ int64, rec []any) error {
+		if len(rec) < 5 || rec[0] != "table" || !strings.EqualFold(fmt.Sprint(rec[1]), name) {
+			return nil
+		}
+		n, _ := rec[3].(int64)
+		root = uint32(n)
+		sql, _ = rec[4].(string)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if root == 0 {
+		return nil, nil, fmt.Errorf("sqlite: no table %s", name)
+	}
+	columns, rowidColumn := parseCreateTable(sql)
+	var rows [][]any
+	err = db.walkTable(root, func(rowid int64, rec []any) error {
+		row := make([]any, len(columns))
+		copy(row, rec)
+		if rowidColumn >= 0 && rowidColumn < len(row) {
+			row[rowidColumn] = rowid
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	return columns, rows, err
+}
+
+// walkTable calls fn with each row of the table b-tree rooted at root.
+func (db *sqliteDB) walkTable(root uint32, fn func(rowid int64, rec []any) error) error {
+	return db.walkPage(root, fn, 0)
+}
+
+func (db *sqliteDB) walkPage(n uint32, fn func(int64, []any) error, depth int) error {
+	if depth > 64 {
+		return errors.New("sqlite: b-tree too deep")
+	}
+	page, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if n == 1 {
+		hdr = 100
+	}
+	if hdr+8 > len(page) {
+		return fmt.Errorf("sqlite: page %d truncated", n)
+	}
+	kind := page[hdr]
+	cells := int(binary.BigEndian.Uint16(page[hdr+3:]))
+	ptrs := hdr + 8
+	if kind == 0x05 {
+		ptrs = hdr + 12
+	}
+	if ptrs+2*cells > len(page) {
+		return fmt.Errorf("sqlite: page %d truncated", n)
+	}
+	for i := range cells {
+		off := int(binary.BigEndian.Uint16(page[ptrs+2*i:]))
+		if off >= len(page) {
+			return fmt.Errorf("sqlite: page %d: bad cell offset", n)
+		}
+		cell := page[off:]
+		switch kind {
+		case 0x05: // interior table page
+			if len(cell) < 4 {
+				return fmt.Errorf("sqlite: page %d: bad cell", n)
+			}
+			if err := db.walkPage(binary.BigEndian.Uint32(cell), fn, depth+1); err != nil {
+				return err
+			}
+		case 0x0d: // leaf table page
+			rowid, payload, err := db.leafCell(cell)
+			if err != nil {
+				return fmt.Errorf("sqlite: page %d: %w", n, err)
+			}
+			rec, err := parseRecord(payload)
+			if err != nil {
+				return fmt.Errorf("sqlite: page %d: %w", n, err)
+			}
+			if err := fn(rowid, rec); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("sqlite: page %d is not a table page (type %d)", n, kind)
+		}
+	}
+	if kind == 0x05 {
+		return db.walkPage(binary.BigEndian.Uint32(page[hdr+8:]), fn, depth+1)
+	}
+	return nil
+}
+
+// leafCell returns the rowid and full payload of a table leaf cell,
+// following its overflow pages.
+func (db *sqliteDB) leafCell(cell []byte) (int64, []byte, error) {
+	size, n := readVarint(cell)
+	if n == 0 || size > math.MaxInt32 {
+		return 0, nil, errors.New("bad payload size")
+	}
+	rowid, m := readVarint(cell[n:])
+	if m == 0 {
+		return 0, nil, errors.New("bad rowid")
+	}
+	cell = cell[n+m:]
+	total := int(size)
+	local := db.localPayload(total)
+	if local > len(cell) {
+		return 0, nil, errors.New("cell truncated")
+	}
+	payload := append([]byte(nil), cell[:local]...)
+	if local == total {
+		return int64(rowid), payload, nil
+	}
+	if local+4 > len(cell) {
+		return 0, nil, errors.New("cell truncated")
+	}
+	next := binary.BigEndian.Uint32(cell[local:])
+	for visited := 0; len(payload) < total; visited++ {
+		if next == 0 || visited > len(db.data)/db.pageSize+len(db.wal) {
+			return 0, nil, errors.New("overflow chain broken")
+		}
+		page, err := db.page(next)
+		if err != nil {
+			return 0, nil, err
+		}
+		chunk := page[4:db.usable]
+		if rest := total - len(payload); len(chunk) > rest {
+			chunk = chunk[:rest]
+		}
+		payload = append(payload, chunk...)
+		next = binary.BigEndian.Uint32(page)
+	}
+	return int64(rowid), payload, nil
+}
+
+// localPayload is how many bytes of a table leaf payload of the given size
+// are stored in the cell itself.
+func (db *sqliteDB) localPayload(size int) int {
+	u := db.usable
+	maxLocal := u - 35
+	if size <= maxLocal {
+		return size
+	}
+	minLocal := (u-12)*32/255 - 23
+	k := minLocal + (size-minLocal)%(u-4)
+	if k <= maxLocal {
+		return k
+	}
+	return minLocal
+}
+
+// parseRecord decodes a record into int64, float64, string, []byte and
+// nil values.
+func parseRecord(payload []byte) ([]any, error) {
+	hdrSize, n := readVarint(payload)
+	if n == 0 || hdrSize > uint64(len(payload)) {
+		return nil, errors.New("bad record header")
+	}
+	var types []uint64
+	for pos := n; pos < int(hdrSize); {
+		t, m := readVarint(payload[pos:hdrSize])
+		if m == 0 {
+			return nil, errors.New("bad record header")
+		}
+		types = append(types, t)
+		pos += m
+	}
+	body := payload[hdrSize:]
+	values := make([]any, len(types))
+	for i, t := range types {
+		size := serialSize(t)
+		if size > uint64(len(body)) {
+			return nil, errors.New("record truncated")
+		}
+		v := body[:size]
+		body = body[size:]
+		switch {
+		case t == 0:
+			values[i] = nil
+		case t >= 1 && t <= 6:
+			values[i] = readInt(v)
+		case t == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(v))
+		case t == 8:
+			values[i] = int64(0)
+		case t == 9:
+			values[i] = int64(1)
+		case t >= 12 && t%2 == 0:
+			values[i] = append([]byte(nil), v...)
+		case t >= 13:
+			values[i] = string(v)
+		default:
+			return nil, fmt.Errorf("bad serial type %d", t)
+		}
+	}
+	return values, nil
+}
+
+// serialSize is the body size of a value of serial type t. It stays in
+// uint64 so a corrupt type cannot wrap to a negative length.
+func serialSize(t uint64) uint64 {
+	switch {
+	case t <= 4:
+		return t
+	case t == 5:
+		return 6
+	case t == 6, t == 7:
+		return 8
+	case t < 12:
+		return 0
+	}
+	return (t - 12) / 2
+}
+
+// readInt reads a big-endian two's-complement integer of 1 to 8 bytes.
+func readInt(b []byte) int64 {
+	var v int64
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, c := range b {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
+
+// readVarint reads a SQLite varint, returning 0 bytes read if b is too
+// short.
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
+
+var (
+	createTableBody = regexp.MustCompile(`(?s)^[^(]*\((.*)\)[^)]*$`)
+	tableConstraint = regexp.MustCompile(`(?i)^(constraint|primary\s+key|unique|check|foreign\s+key)\b`)
+)
+
+// parseCreateTable returns the column names declared by a CREATE TABLE
+// statement and the index of its INTEGER PRIMARY KEY column, whose values
+// are the rowids, or -1.
+func parseCreateTable(sql string) ([]string, int) {
+	m := createTableBody.FindStringSubmatch(sql)
+	if m == nil {
+		return nil, -1
+	}
+	var columns []string
+	rowid := -1
+	for _, def := range splitColumnDefs(m[1]) {
+		def = strings.TrimSpace(def)
+		if def == "" || tableConstraint.MatchString(def) {
+			continue
+		}
+		fields := strings.Fields(def)
+		name := strings.Trim(fields[0], "\"`[]")
+		rest := strings.ToUpper(strings.Join(fields[1:], " "))
+		if strings.HasPrefix(rest, "INTEGER PRIMARY KEY") {
+			rowid = len(columns)
+		}
+		columns = append(columns, name)
+	}
+	return columns, rowid
+}
+
+// splitColumnDefs splits a CREATE TABLE body at the commas outside
+// parentheses and quotes.
+func splitColumnDefs(body string) []string {
+	var defs []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			defs = append(defs, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(defs, body[start:])
+}
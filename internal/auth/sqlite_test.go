@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseRecord(t *testing.T) {
+	// Header: size 4, then an 8-bit int, a 3-byte string, and NULL.
+	payload := []byte{4, 1, 13 + 2*3, 0, 42, 'a', 'b', 'c'}
+	got, err := parseRecord(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []any{int64(42), "abc", nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRecord = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseRecordMalformed(t *testing.T) {
+	maxVarint := bytes.Repeat([]byte{0xff}, 9)
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{"empty", nil},
+		{"header size past payload", []byte{9, 1}},
+		{"header size overflows int", append(append([]byte(nil), maxVarint...), 1)},
+		{"serial type overflows int", append(append([]byte{10}, maxVarint...), 0)},
+		{"blob past body", []byte{2, 12 + 2*4, 'a'}},
+		{"truncated type varint", []byte{2, 0x81}},
+		{"reserved serial type", []byte{2, 10}},
+	}
+	for _, tt := range tests {
+		if _, err := parseRecord(tt.payload); err == nil {
+			t.Errorf("%s: parseRecord(%x) succeeded, want error", tt.name, tt.payload)
+		}
+	}
+}
+
+func TestLeafCellMalformed(t *testing.T) {
+	db := &sqliteDB{data: make([]byte, 4096), pageSize: 4096, usable: 4096}
+	tests := []struct {
+		name string
+		cell []byte
+	}{
+		{"empty", nil},
+		{"payload size overflows int", append(bytes.Repeat([]byte{0xff}, 9), 1)},
+		{"payload size past int32", append([]byte{0x88, 0x80, 0x80, 0x80, 0x00}, 1)},
+		{"missing rowid", []byte{3}},
+		{"payload past cell", []byte{100, 1, 'a'}},
+		{"overflow chain broken", append([]byte{0x82, 0x80, 0x80, 0x00, 1}, make([]byte, 4000)...)},
+	}
+	for _, tt := range tests {
+		if _, _, err := db.leafCell(tt.cell); err == nil {
+			t.Errorf("%s: leafCell(%x...) succeeded, want error", tt.name, tt.cell[:min(len(tt.cell), 12)])
+		}
+	}
+}
+
+// TestSQLiteCorruptCell overwrites varints in the first schema cell of a
+// real cookie database with the largest 9-byte varint and checks the
+// reader reports an error rather than panicking.
+func TestSQLiteCorruptCell(t *testing.T) {
+	data, err := os.ReadFile("testdata/firefox/Profiles/x1y2z3.default-release/cookies.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	// Page 1 holds the schema table; its b-tree header follows the 100-byte
+	// file header, and the cell pointer array follows that.
+	cell := int(binary.BigEndian.Uint16(data[108:]))
+	_, n := readVarint(data[cell:])
+	_, m := readVarint(data[cell+n:])
+	for _, tt := range []struct {
+		name string
+		off  int
+	}{
+		{"payload size", cell},
+		{"record header size", cell + n + m},
+	} {
+		corrupt := append([]byte(nil), data...)
+		copy(corrupt[tt.off:], bytes.Repeat([]byte{0xff}, 9))
+		db := &sqliteDB{data: corrupt, pageSize: pageSize, usable: pageSize - int(data[20])}
+		if _, _, err := db.table("moz_cookies"); err == nil {
+			t.Errorf("%s corrupted: table succeeded, want error", tt.name)
+		}
+	}
+}
@@ -154,8 +154,35 @@ func buildRPCData(rpc RPC) []interface{} {
 	}
 }
 
-// Execute performs the batch execute request
+// Execute performs the batch execute request. With a CredentialSource, a
+// request that fails authentication is retried once with the credentials
+// the source refreshes.
 func (c *Client) Execute(ctx context.Context, rpcs []RPC) (*Response, error) {
+	if c.credentials == nil {
+		return c.execute(ctx, rpcs, Credentials{AuthToken: c.config.AuthToken, Cookies: c.config.Cookies})
+	}
+	creds, err := c.credentials.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get credentials: %w", err)
+	}
+	resp, err := c.execute(ctx, rpcs, creds)
+	if err == nil || !IsAuthFailure(err) {
+		return resp, err
+	}
+	fresh, rerr := c.credentials.Refresh(ctx, creds)
+	if rerr != nil {
+		return nil, fmt.Errorf("%w (refresh credentials: %v)", err, rerr)
+	}
+	if fresh == creds {
+		return nil, err
+	}
+	if c.config.Debug {
+		fmt.Printf("\nRetrying request with refreshed credentials...\n")
+	}
+	return c.execute(ctx, rpcs, fresh)
+}
+
+func (c *Client) execute(ctx context.Context, rpcs []RPC, creds Credentials) (*Response, error) {
 	u, err := url.Parse(fmt.Sprintf("https://%s/_/%s/data/batchexecute", c.config.Host, c.config.App))
 	if err != nil {
 		return nil, fmt.Errorf("parse url: %w", err)
@@ -202,11 +229,11 @@ func (c *Client) Execute(ctx context.Context, rpcs []RPC) (*Response, error) {
 	// f.req first, then at, with trailing &
 	formBody := fmt.Sprintf("f.req=%s&at=%s&",
 		url.QueryEscape(string(reqBody)),
-		url.QueryEscape(c.config.AuthToken))
+		url.QueryEscape(creds.AuthToken))
 
 	if c.config.Debug {
 		// Safely display auth token with conservative masking
-		token := c.config.AuthToken
+		token := creds.AuthToken
 		var tokenDisplay string
 		if len(token) <= 8 {
 			// For very short tokens, mask completely
@@ -227,7 +254,7 @@ func (c *Client) Execute(ctx context.Context, rpcs []RPC) (*Response, error) {
 		// Mask auth token in request body display
 		maskedBody := fmt.Sprintf("f.req=%s&at=%s&",
 			url.QueryEscape(string(reqBody)),
-			url.QueryEscape(maskSensitiveValue(c.config.AuthToken)))
+			url.QueryEscape(maskSensitiveValue(creds.AuthToken)))
 		fmt.Printf("\nRequest Body:\n%s\n", maskedBody)
 		fmt.Printf("\nDecoded Request Body:\n%s\n", string(reqBody))
 	}
@@ -242,7 +269,7 @@ func (c *Client) Execute(ctx context.Context, rpcs []RPC) (*Response, error) {
 		for k, v := range c.config.Headers {
 			fmt.Printf("%s: %s\n", k, v)
 		}
-		fmt.Printf("Cookie: %s\n\n", c.config.Cookies)
+		fmt.Printf("Cookie: %s\n\n", creds.Cookies)
 		fmt.Printf("Body:\n%s\n", formBody)
 		fmt.Printf("\nDecoded f.req:\n%s\n", string(reqBody))
 		fmt.Printf("=== END REQUEST DUMP ===\n\n")
@@ -259,10 +286,10 @@ func (c *Client) Execute(ctx context.Context, rpcs []RPC) (*Response, error) {
 	for k, v := range c.config.Headers {
 		req.Header.Set(k, v)
 	}
-	req.Header.Set("cookie", c.config.Cookies)
+	req.Header.Set("cookie", creds.Cookies)
 
 	// Add Authorization header with SAPISID hash if available
-	if sapisid := extractSAPISID(c.config.Cookies); sapisid != "" {
+	if sapisid := extractSAPISID(creds.Cookies); sapisid != "" {
 		origin := fmt.Sprintf("https://%s", c.config.Host)
 		authHeader := generateSAPISIDHASH(sapisid, origin)
 		req.Header.Set("authorization", authHeader)
@@ -686,6 +713,48 @@ func WithTraceHook(hook func(Trace)) Option {
 	}
 }
 
+// Credentials are the auth token and cookies sent with a request.
+type Credentials struct {
+	AuthToken string
+	Cookies   string
+}
+
+// CredentialSource supplies the credentials for each request in place of
+// Config.AuthToken and Config.Cookies. Implementations must be safe for
+// concurrent use.
+type CredentialSource interface {
+	// Credentials returns the credentials for the next request.
+	Credentials(ctx context.Context) (Credentials, error)
+	// Refresh is called after the server rejects rejected as
+	// unauthenticated. It returns the credentials to retry with; returning
+	// rejected unchanged means there is nothing better to try.
+	Refresh(ctx context.Context, rejected Credentials) (Credentials, error)
+}
+
+// WithCredentialSource makes the client ask src for credentials on every
+// request.
+func WithCredentialSource(src CredentialSource) Option {
+	return func(c *Client) {
+		c.credentials = src
+	}
+}
+
+// IsAuthFailure reports whether err shows the server rejecting a request's
+// credentials: an HTTP 401 or an authentication-class API error.
+func IsAuthFailure(err error) bool {
+	if errors.Is(err, ErrUnauthorized) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.ErrorCode != nil {
+		return apiErr.ErrorCode.Type == ErrorTypeAuthentication
+	}
+	return apiErr.HTTPStatus == http.StatusUnauthorized
+}
+
 // Config holds the configuration for batch execute
 type Config struct {
 	Host      string
@@ -715,11 +784,12 @@ type Config struct {
 
 // Client handles batchexecute operations
 type Client struct {
-	config     Config
-	httpClient *http.Client
-	debug      func(format string, args ...interface{})
-	reqid      *ReqIDGenerator
-	traceHook  func(Trace)
+	config      Config
+	httpClient  *http.Client
+	debug       func(format string, args ...interface{})
+	reqid       *ReqIDGenerator
+	traceHook   func(Trace)
+	credentials CredentialSource
 }
 
 // NewClient creates a new batchexecute client
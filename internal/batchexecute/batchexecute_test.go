@@ -327,6 +327,79 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+type testCredentialSource struct {
+	current   Credentials
+	token     string // the token Refresh switches to
+	refreshes int
+}
+
+func (s *testCredentialSource) Credentials(context.Context) (Credentials, error) {
+	return s.current, nil
+}
+
+func (s *testCredentialSource) Refresh(_ context.Context, rejected Credentials) (Credentials, error) {
+	s.refreshes++
+	s.current = Credentials{AuthToken: s.token, Cookies: rejected.Cookies}
+	return s.current, nil
+}
+
+func TestExecuteRefreshesCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+			return
+		}
+		if r.Form.Get("at") != "fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got := r.Header.Get("cookie"); got != "SID=1" {
+			t.Errorf("cookie = %q, want SID=1", got)
+		}
+		fmt.Fprintf(w, `)]}'
+
+[["wrb.fr","VUsiyb","[1]",null,null,null,"generic"]]`)
+	}))
+	defer server.Close()
+
+	config := Config{
+		Host:      strings.TrimPrefix(server.URL, "http://"),
+		App:       "notebooklm",
+		AuthToken: "unused",
+		UseHTTP:   true,
+	}
+	src := &testCredentialSource{current: Credentials{AuthToken: "stale", Cookies: "SID=1"}, token: "fresh"}
+	client := NewClient(config, WithHTTPClient(server.Client()), WithCredentialSource(src))
+	rpc := RPC{ID: "VUsiyb", Index: "generic"}
+
+	response, err := client.Execute(context.Background(), []RPC{rpc})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if string(response.Data) != "[1]" {
+		t.Errorf("response data = %s, want [1]", response.Data)
+	}
+	if src.refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", src.refreshes)
+	}
+
+	if _, err := client.Execute(context.Background(), []RPC{rpc}); err != nil {
+		t.Fatalf("Execute with refreshed credentials: %v", err)
+	}
+	if src.refreshes != 1 {
+		t.Errorf("refreshes after second call = %d, want 1", src.refreshes)
+	}
+
+	src.current.AuthToken, src.token = "revoked", "revoked"
+	_, err = client.Execute(context.Background(), []RPC{rpc})
+	if !IsAuthFailure(err) {
+		t.Errorf("Execute with a source that cannot refresh: err = %v, want an auth failure", err)
+	}
+	if src.refreshes != 2 {
+		t.Errorf("refreshes after rejected refresh = %d, want 2", src.refreshes)
+	}
+}
+
 func TestExecuteCancellation(t *testing.T) {
 	started := make(chan struct{})
 	release := make(chan struct{})
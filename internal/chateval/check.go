@@ -0,0 +1,64 @@
+package chateval
+
+import (
+	"fmt"
+	"strings"
+)
+
+// check returns the expectations of c that answer and cites miss, in the
+// order the suite lists them.
+func (c Case) check(answer string, cites []Citation) []string {
+	if answer == "" {
+		return []string{"empty answer"}
+	}
+	var failures []string
+	lower := strings.ToLower(answer)
+	for _, phrase := range c.Contains {
+		if !strings.Contains(lower, strings.ToLower(phrase)) {
+			failures = append(failures, fmt.Sprintf("does not contain %q", phrase))
+		}
+	}
+	for _, re := range c.patterns {
+		if !re.MatchString(answer) {
+			failures = append(failures, fmt.Sprintf("does not match %q", re.String()))
+		}
+	}
+	for _, want := range c.Cites {
+		if !citesTitle(cites, want) {
+			failures = append(failures, fmt.Sprintf("does not cite %q", want))
+		}
+	}
+	if c.MinCitations != nil {
+		if n := distinctCitations(cites); n < *c.MinCitations {
+			failures = append(failures, fmt.Sprintf("has %d citations, want at least %d", n, *c.MinCitations))
+		}
+	}
+	for _, phrase := range c.Forbid {
+		if strings.Contains(lower, strings.ToLower(phrase)) {
+			failures = append(failures, fmt.Sprintf("contains forbidden %q", phrase))
+		}
+	}
+	return failures
+}
+
+// citesTitle reports whether a cited source's title contains want,
+// ignoring case.
+func citesTitle(cites []Citation, want string) bool {
+	want = strings.ToLower(want)
+	for _, c := range cites {
+		if strings.Contains(strings.ToLower(c.Title), want) {
+			return true
+		}
+	}
+	return false
+}
+
+// distinctCitations counts the [N] markers behind cites. One marker can
+// ground several answer ranges, so it can appear more than once.
+func distinctCitations(cites []Citation) int {
+	seen := map[int]bool{}
+	for _, c := range cites {
+		seen[c.Index] = true
+	}
+	return len(seen)
+}
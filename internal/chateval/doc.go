@@ -0,0 +1,23 @@
+// Package chateval runs a question set against a notebook and checks every
+// answer against the case's expectations, so a notebook can be
+// regression-tested as its sources change.
+//
+// A suite is a YAML file of cases. Each case holds a prompt and what its
+// answer must satisfy: phrases it contains or must not contain, patterns it
+// matches, sources it cites, and a minimum number of citations:
+//
+//	defaults:
+//	  min_citations: 1
+//	  forbid: ["not mentioned in the sources"]
+//	cases:
+//	  - name: install
+//	    prompt: How do I install the CLI?
+//	    contains: ["go install"]
+//	    matches: ['nlm@v\d+']
+//	    cites: ["README"]
+//
+// Run asks each question in a new conversation through StreamChat, resolves
+// the answer's citations to source titles and, through sourcecite, to
+// locations in the cited source, and checks the expectations. WriteJUnit and
+// WriteMarkdown report the results for CI and for people.
+package chateval
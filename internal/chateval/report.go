@@ -0,0 +1,180 @@
+package chateval
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes r as JUnit XML, one testcase per case, with the answer
+// and its citations as the case's system-out.
+func WriteJUnit(w io.Writer, r *Report) error {
+	_, failed, errored := r.Counts()
+	suite := junitSuite{
+		Name:      r.Suite,
+		Tests:     len(r.Results),
+		Failures:  failed,
+		Errors:    errored,
+		Time:      junitSeconds(r.Duration),
+		Timestamp: r.Started.UTC().Format(time.RFC3339),
+	}
+	for _, res := range r.Results {
+		tc := junitCase{
+			Name:      res.Case.Name,
+			Classname: r.Suite,
+			Time:      junitSeconds(res.Duration),
+		}
+		switch {
+		case res.Err != nil:
+			tc.Error = &junitMessage{Message: res.Err.Error()}
+		case len(res.Failures) > 0:
+			tc.Failure = &junitMessage{
+				Message: res.Failures[0],
+				Body:    strings.Join(res.Failures, "\n"),
+			}
+		}
+		if res.Err == nil {
+			tc.SystemOut = caseTranscript(res)
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	doc := junitSuites{
+		Name:     r.Suite,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// caseTranscript is the prompt, answer and citations of a case as plain
+// text.
+func caseTranscript(res Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Prompt: %s\n\n%s\n", res.Case.Prompt, res.Answer)
+	if len(res.Citations) > 0 {
+		b.WriteString("\nCitations:\n")
+		for _, c := range res.Citations {
+			fmt.Fprintf(&b, "  [%d] %s\n", c.Index, citationLabel(c))
+		}
+	}
+	return b.String()
+}
+
+// citationLabel names a citation's source and, when resolved, where in it.
+func citationLabel(c Citation) string {
+	label := c.Title
+	if label == "" {
+		label = c.SourceID
+	}
+	if c.Location != "" {
+		label += " (" + c.Location + ")"
+	}
+	return label
+}
+
+// WriteMarkdown writes r as a Markdown report: a summary table, then each
+// case with its failures, answer and citations.
+func WriteMarkdown(w io.Writer, r *Report) error {
+	passed, failed, errored := r.Counts()
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.Suite)
+	fmt.Fprintf(&b, "Notebook `%s`, run %s in %s.\n\n", r.NotebookID, r.Started.UTC().Format(time.RFC3339), r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "%d passed, %d failed, %d errors of %d cases.\n\n", passed, failed, errored, len(r.Results))
+	b.WriteString("| Case | Result | Citations | Time |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, res := range r.Results {
+		fmt.Fprintf(&b, "| %s | %s | %d | %s |\n", markdownCell(res.Case.Name), resultWord(res), distinctCitations(res.Citations), res.Duration.Round(time.Millisecond))
+	}
+	for _, res := range r.Results {
+		fmt.Fprintf(&b, "\n## %s: %s\n\n", resultWord(res), res.Case.Name)
+		fmt.Fprintf(&b, "> %s\n", strings.ReplaceAll(res.Case.Prompt, "\n", "\n> "))
+		if res.Err != nil {
+			fmt.Fprintf(&b, "\nError: %s\n", res.Err)
+			continue
+		}
+		if len(res.Failures) > 0 {
+			b.WriteString("\n")
+			for _, f := range res.Failures {
+				fmt.Fprintf(&b, "- %s\n", f)
+			}
+		}
+		fmt.Fprintf(&b, "\n%s\n", res.Answer)
+		if len(res.Citations) > 0 {
+			b.WriteString("\nCitations:\n\n")
+			for _, c := range res.Citations {
+				fmt.Fprintf(&b, "- [%d] %s\n", c.Index, citationLabel(c))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// resultWord is the one-word outcome of a case.
+func resultWord(res Result) string {
+	switch {
+	case res.Err != nil:
+		return "ERROR"
+	case len(res.Failures) > 0:
+		return "FAIL"
+	default:
+		return "PASS"
+	}
+}
+
+// markdownCell escapes the characters that would end a table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,289 @@
+package chateval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/nlm/internal/sourcecite"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// Client is the part of *notebooklm.Client a run uses.
+type Client interface {
+	StreamChat(ctx context.Context, req notebooklm.ChatRequest, callback func(notebooklm.ChatChunk) bool) error
+	GetProject(ctx context.Context, projectID string) (*notebooklm.Notebook, error)
+	LoadSourceText(ctx context.Context, sourceID, notebookID string) (notebooklm.LoadSourceText, error)
+}
+
+// Options configures a run.
+type Options struct {
+	NotebookID string
+	// SourceIDs restricts every question to these sources; empty asks
+	// against the whole notebook.
+	SourceIDs []string
+	// Concurrency bounds the questions in flight; values below 1 mean 1.
+	Concurrency int
+	// Progress, when set, is called as each case finishes, one call at a
+	// time, in completion order.
+	Progress func(Result)
+	// ConversationID, when set, names the conversation a case is asked in.
+	// Left unset, each case gets a fresh random one; a test replaying a
+	// recording sets it so requests are reproducible.
+	ConversationID func(Case) string
+}
+
+// Report is the outcome of a run, with one result per case in suite order.
+type Report struct {
+	Suite      string
+	NotebookID string
+	Started    time.Time
+	Duration   time.Duration
+	Results    []Result
+}
+
+// Result is the outcome of one case.
+type Result struct {
+	Case      Case
+	Answer    string
+	Citations []Citation
+	// Failures lists the expectations the answer missed.
+	Failures []string
+	// Err is set when the question could not be asked; the expectations
+	// were not checked.
+	Err      error
+	Duration time.Duration
+}
+
+// Passed reports whether the case was asked and met every expectation.
+func (r Result) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+// Citation is one citation of an answer, resolved to its source.
+type Citation struct {
+	Index    int    // the [N] marker in the answer
+	SourceID string // the notebook source cited
+	Title    string
+	Excerpt  string
+	// Location is the cited span as file:line:col, set when sourcecite
+	// resolved it to a file inside the source.
+	Location string
+	// Status is sourcecite's verdict, or "unresolved" when the source text
+	// could not be loaded.
+	Status string
+	Reason string
+}
+
+// Counts returns the number of cases that passed, failed an expectation,
+// and could not be asked.
+func (r *Report) Counts() (passed, failed, errored int) {
+	for _, res := range r.Results {
+		switch {
+		case res.Err != nil:
+			errored++
+		case len(res.Failures) > 0:
+			failed++
+		default:
+			passed++
+		}
+	}
+	return passed, failed, errored
+}
+
+// Run asks every case of s and checks its answer. It returns an error only
+// when ctx ends the run early; a case that cannot be asked is recorded in
+// its Result.
+func Run(ctx context.Context, c Client, s *Suite, opts Options) (*Report, error) {
+	r := &runner{
+		c:       c,
+		opts:    opts,
+		sources: map[string]*sourceText{},
+	}
+	r.loadTitles(ctx)
+
+	report := &Report{
+		Suite:      s.Name,
+		NotebookID: opts.NotebookID,
+		Started:    time.Now(),
+		Results:    make([]Result, len(s.Cases)),
+	}
+	concurrency := max(opts.Concurrency, 1)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var progress sync.Mutex
+	for i, tc := range s.Cases {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			// Cases never asked are reported as errors, so a cut-short
+			// report still lists every case.
+			for j := i; j < len(s.Cases); j++ {
+				report.Results[j] = Result{Case: s.Cases[j], Err: err}
+			}
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res := r.runCase(ctx, tc)
+			report.Results[i] = res
+			if opts.Progress != nil {
+				progress.Lock()
+				opts.Progress(res)
+				progress.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	report.Duration = time.Since(report.Started)
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+type runner struct {
+	c      Client
+	opts   Options
+	titles map[string]string
+
+	mu      sync.Mutex
+	sources map[string]*sourceText
+}
+
+// sourceText is a source body loaded at most once per run, however many
+// cases cite it.
+type sourceText struct {
+	once sync.Once
+	body notebooklm.LoadSourceText
+	err  error
+}
+
+// loadTitles maps the notebook's source IDs to titles. Without them the
+// cites expectations can only match titles the citations carry themselves.
+func (r *runner) loadTitles(ctx context.Context) {
+	nb, err := r.c.GetProject(ctx, r.opts.NotebookID)
+	if err != nil {
+		return
+	}
+	r.titles = make(map[string]string, len(nb.Sources))
+	for _, src := range nb.Sources {
+		if id := src.GetSourceId().GetSourceId(); id != "" {
+			r.titles[id] = src.GetTitle()
+		}
+	}
+}
+
+func (r *runner) runCase(ctx context.Context, tc Case) Result {
+	start := time.Now()
+	res := Result{Case: tc}
+	req := notebooklm.ChatRequest{
+		ProjectID: r.opts.NotebookID,
+		Prompt:    tc.Prompt,
+		SourceIDs: r.opts.SourceIDs,
+	}
+	if r.opts.ConversationID != nil {
+		req.ConversationID = r.opts.ConversationID(tc)
+	}
+	var answer strings.Builder
+	var thinking string
+	var cites []notebooklm.Citation
+	err := r.c.StreamChat(ctx, req, func(chunk notebooklm.ChatChunk) bool {
+		switch chunk.Phase {
+		case notebooklm.ChatChunkThinking:
+			thinking = chunk.Text
+		case notebooklm.ChatChunkAnswer:
+			answer.WriteString(chunk.Text)
+			if len(chunk.Citations) > 0 {
+				cites = chunk.Citations
+			}
+		}
+		return true
+	})
+	res.Duration = time.Since(start)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Answer = strings.TrimSpace(answer.String())
+	if res.Answer == "" {
+		// A stream with no phase tags can classify a whole answer as
+		// thinking; the trace is then the answer.
+		res.Answer = strings.TrimSpace(thinking)
+	}
+	for _, c := range cites {
+		res.Citations = append(res.Citations, r.resolve(ctx, c))
+	}
+	res.Failures = tc.check(res.Answer, res.Citations)
+	res.Duration = time.Since(start)
+	return res
+}
+
+// resolve names a citation's source and locates its span in the source
+// text through sourcecite.
+func (r *runner) resolve(ctx context.Context, c notebooklm.Citation) Citation {
+	sourceID := c.ParentSourceID
+	if sourceID == "" {
+		sourceID = c.SourceID
+	}
+	out := Citation{
+		Index:    c.SourceIndex,
+		SourceID: sourceID,
+		Title:    r.titles[sourceID],
+		Excerpt:  c.Excerpt,
+	}
+	if out.Title == "" {
+		out.Title = c.Title
+	}
+
+	body, err := r.sourceText(ctx, sourceID)
+	if err != nil {
+		out.Status, out.Reason = "unresolved", err.Error()
+		return out
+	}
+	start, end := c.SourceStart, c.SourceEnd
+	if start >= end {
+		// Older frames carry no source range; fall back as richrender does.
+		start, end = c.StartChar, c.EndChar
+	}
+	resolved := sourcecite.ResolveCitation(body, sourcecite.NativeCitation{
+		SourceID:    sourceID,
+		SourceTitle: out.Title,
+		StartChar:   start,
+		EndChar:     end,
+		Confidence:  c.Confidence,
+	}, c.Excerpt)
+	out.Status, out.Reason = string(resolved.Status), resolved.Reason
+	// A file named after the source itself means the resolver found no
+	// member file to point into; the title already says as much.
+	if resolved.Status == sourcecite.StatusOK && resolved.File != "" && resolved.File != body.Title && resolved.Line > 0 {
+		out.Location = fmt.Sprintf("%s:%d", resolved.File, resolved.Line)
+		if resolved.Column > 0 {
+			out.Location += fmt.Sprintf(":%d", resolved.Column)
+		}
+	}
+	return out
+}
+
+func (r *runner) sourceText(ctx context.Context, sourceID string) (notebooklm.LoadSourceText, error) {
+	r.mu.Lock()
+	st := r.sources[sourceID]
+	if st == nil {
+		st = &sourceText{}
+		r.sources[sourceID] = st
+	}
+	r.mu.Unlock()
+	st.once.Do(func() {
+		st.body, st.err = r.c.LoadSourceText(ctx, sourceID, r.opts.NotebookID)
+		if st.err != nil {
+			st.err = fmt.Errorf("load source text: %w", st.err)
+		}
+	})
+	return st.body, st.err
+}
@@ -0,0 +1,245 @@
+package chateval
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/httprr"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+const evalSource = "" +
+	"-- README.md --\n" +
+	"Install with go install github.com/tmc/nlm/cmd/nlm@latest.\n" +
+	"Run nlm source sync after editing.\n"
+
+// fakeClient answers every prompt from answers, citing the README source.
+type fakeClient struct {
+	answers map[string]string
+
+	inFlight, peak atomic.Int32
+	mu             sync.Mutex
+	loads          int
+}
+
+func (f *fakeClient) StreamChat(ctx context.Context, req notebooklm.ChatRequest, callback func(notebooklm.ChatChunk) bool) error {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		peak := f.peak.Load()
+		if n <= peak || f.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	answer, ok := f.answers[req.Prompt]
+	if !ok {
+		return errors.New("stream failed")
+	}
+	start := strings.Index(evalSource, "Install")
+	cite := notebooklm.Citation{
+		SourceIndex:    1,
+		SourceID:       "chunk-1",
+		ParentSourceID: "src-readme",
+		Excerpt:        "Install with go install",
+		SourceStart:    start,
+		SourceEnd:      start + len("Install with go install"),
+	}
+	callback(notebooklm.ChatChunk{Phase: notebooklm.ChatChunkThinking, Text: "Looking"})
+	half := len(answer) / 2
+	callback(notebooklm.ChatChunk{Phase: notebooklm.ChatChunkAnswer, Text: answer[:half]})
+	callback(notebooklm.ChatChunk{Phase: notebooklm.ChatChunkAnswer, Text: answer[half:], Citations: []notebooklm.Citation{cite, cite}})
+	return nil
+}
+
+func (f *fakeClient) GetProject(ctx context.Context, projectID string) (*notebooklm.Notebook, error) {
+	return &notebooklm.Notebook{Sources: []*pb.Source{{
+		SourceId: &pb.SourceId{SourceId: "src-readme"},
+		Title:    "nlm README",
+	}}}, nil
+}
+
+func (f *fakeClient) LoadSourceText(ctx context.Context, sourceID, notebookID string) (notebooklm.LoadSourceText, error) {
+	f.mu.Lock()
+	f.loads++
+	f.mu.Unlock()
+	return notebooklm.LoadSourceText{
+		SourceID:  sourceID,
+		Title:     "nlm README",
+		Fragments: []notebooklm.TextFragment{{Start: 0, End: len(evalSource), Text: evalSource}},
+	}, nil
+}
+
+func TestRun(t *testing.T) {
+	s, err := Parse([]byte(`
+cases:
+  - name: install
+    prompt: How do I install it?
+    contains: [go install]
+    matches: ['@latest']
+    cites: [readme]
+    min_citations: 1
+  - name: strict
+    prompt: How do I install it?
+    cites: [changelog]
+    min_citations: 2
+    forbid: [INSTALL WITH]
+  - name: broken
+    prompt: Does this fail?
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &fakeClient{answers: map[string]string{
+		"How do I install it?": "Install with go install github.com/tmc/nlm/cmd/nlm@latest [1].",
+	}}
+	var progress []string
+	report, err := Run(context.Background(), c, s, Options{
+		NotebookID:  "nb",
+		Concurrency: 2,
+		Progress:    func(r Result) { progress = append(progress, r.Case.Name) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 3 {
+		t.Errorf("progress saw %v, want every case", progress)
+	}
+	if peak := c.peak.Load(); peak > 2 {
+		t.Errorf("%d questions in flight, want at most 2", peak)
+	}
+	if c.loads != 1 {
+		t.Errorf("source text loaded %d times, want once", c.loads)
+	}
+
+	install, strict, broken := report.Results[0], report.Results[1], report.Results[2]
+	if !install.Passed() {
+		t.Errorf("install failed: %v", install.Failures)
+	}
+	if got := install.Citations[0]; got.Title != "nlm README" || got.Location != "README.md:1:1" {
+		t.Errorf("citation = %+v, want nlm README at README.md:1:1", got)
+	}
+	wantStrict := []string{
+		`does not cite "changelog"`,
+		"has 1 citations, want at least 2",
+		`contains forbidden "INSTALL WITH"`,
+	}
+	if strings.Join(strict.Failures, "\n") != strings.Join(wantStrict, "\n") {
+		t.Errorf("strict failures = %q, want %q", strict.Failures, wantStrict)
+	}
+	if broken.Err == nil {
+		t.Error("broken case has no error")
+	}
+	if p, f, e := report.Counts(); p != 1 || f != 1 || e != 1 {
+		t.Errorf("Counts() = %d, %d, %d; want 1, 1, 1", p, f, e)
+	}
+}
+
+func TestReports(t *testing.T) {
+	report := &Report{
+		Suite:      "docs",
+		NotebookID: "nb",
+		Results: []Result{
+			{Case: Case{Name: "ok", Prompt: "p1"}, Answer: "fine", Citations: []Citation{{Index: 1, Title: "README", Location: "README.md:2:1"}}},
+			{Case: Case{Name: "bad | case", Prompt: "p2"}, Answer: "wrong", Failures: []string{`does not contain "x"`, `does not cite "y"`}},
+			{Case: Case{Name: "err", Prompt: "p3"}, Err: errors.New("stream failed")},
+		},
+	}
+
+	var junit bytes.Buffer
+	if err := WriteJUnit(&junit, report); err != nil {
+		t.Fatal(err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(junit.Bytes(), &doc); err != nil {
+		t.Fatalf("JUnit output does not parse: %v\n%s", err, junit.String())
+	}
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Errors != 1 {
+		t.Errorf("testsuites counts = %d/%d/%d, want 3/1/1", doc.Tests, doc.Failures, doc.Errors)
+	}
+	cases := doc.Suites[0].Cases
+	if cases[1].Failure == nil || cases[1].Failure.Message != `does not contain "x"` {
+		t.Errorf("failing testcase = %+v", cases[1])
+	}
+	if cases[2].Error == nil || cases[2].Error.Message != "stream failed" {
+		t.Errorf("erroring testcase = %+v", cases[2])
+	}
+	if !strings.Contains(cases[0].SystemOut, "[1] README (README.md:2:1)") {
+		t.Errorf("system-out = %q, want the citation", cases[0].SystemOut)
+	}
+
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"1 passed, 1 failed, 1 errors of 3 cases.",
+		`| bad \| case | FAIL | 0 |`,
+		"## ERROR: err",
+		"Error: stream failed",
+		"- [1] README (README.md:2:1)",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Markdown report lacks %q:\n%s", want, md.String())
+		}
+	}
+}
+
+// TestRunRecorded replays a recorded run against a live notebook. Record
+// with NLM_AUTH_TOKEN, NLM_COOKIES and NLM_EVAL_NOTEBOOK set:
+//
+//	go test -run TestRunRecorded -httprecord=. ./internal/chateval/
+func TestRunRecorded(t *testing.T) {
+	httprr.SkipIfNoNLMCredentialsOrRecording(t)
+	httpClient := httprr.CreateNLMTestClient(t, http.DefaultTransport)
+
+	notebookID := os.Getenv("NLM_EVAL_NOTEBOOK")
+	if notebookID == "" {
+		notebookID = "6c313fd7-049a-4475-aa0f-0fb3ee8de65f"
+	}
+	client := notebooklm.New(
+		notebooklm.Credentials{AuthToken: envOr("NLM_AUTH_TOKEN", "test-auth-token"), Cookies: envOr("NLM_COOKIES", "test-cookies")},
+		notebooklm.WithHTTPClient(httpClient),
+		notebooklm.WithStreamHTTPClient(httpClient),
+	)
+	s, err := Parse([]byte(`
+cases:
+  - name: summary
+    prompt: Summarize this notebook in two sentences.
+    min_citations: 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Run(context.Background(), client, s, Options{
+		NotebookID: notebookID,
+		// Replay matches requests in order, and the chat body carries the
+		// conversation ID, so both must be fixed.
+		Concurrency: 1,
+		ConversationID: func(c Case) string {
+			return fmt.Sprintf("00000000-0000-4000-8000-%012d", len(c.Name))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := report.Results[0]; !res.Passed() {
+		t.Errorf("summary: err=%v failures=%v\nanswer: %s", res.Err, res.Failures, res.Answer)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,145 @@
+package chateval
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a parsed question set.
+type Suite struct {
+	// Name identifies the suite in reports; Load sets it from the file name.
+	Name string `yaml:"name"`
+	// Defaults apply to every case: its phrase, pattern and source lists
+	// are added to each case's, and its min_citations applies to cases that
+	// set none.
+	Defaults Expect `yaml:"defaults"`
+	Cases    []Case `yaml:"cases"`
+}
+
+// Case is one question and the expectations its answer must meet.
+type Case struct {
+	Name   string `yaml:"name"`
+	Prompt string `yaml:"prompt"`
+	Expect `yaml:",inline"`
+}
+
+// Expect lists what an answer must satisfy. Phrases match ignoring case;
+// patterns are Go regular expressions, so (?i) makes one ignore case.
+type Expect struct {
+	// Contains lists phrases the answer must contain.
+	Contains []string `yaml:"contains"`
+	// Matches lists patterns the answer must match.
+	Matches []string `yaml:"matches"`
+	// Cites lists sources the answer must cite, each matched against the
+	// cited sources' titles as a substring.
+	Cites []string `yaml:"cites"`
+	// MinCitations is the least number of distinct [N] markers the answer
+	// must carry.
+	MinCitations *int `yaml:"min_citations"`
+	// Forbid lists phrases the answer must not contain.
+	Forbid []string `yaml:"forbid"`
+
+	patterns []*regexp.Regexp
+}
+
+// Load reads and validates the suite in path.
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Name == "" {
+		s.Name = suiteName(path)
+	}
+	return s, nil
+}
+
+// Parse decodes and validates a suite. Unknown keys are errors, so a
+// misspelled expectation fails loudly instead of never being checked.
+func Parse(data []byte) (*Suite, error) {
+	var s Suite
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no cases")
+		}
+		return nil, err
+	}
+	if len(s.Cases) == 0 {
+		return nil, errors.New("no cases")
+	}
+	if s.Defaults.MinCitations != nil && *s.Defaults.MinCitations < 0 {
+		return nil, errors.New("defaults: min_citations must not be negative")
+	}
+	seen := map[string]bool{}
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		c.Prompt = strings.TrimSpace(c.Prompt)
+		if c.Prompt == "" {
+			return nil, fmt.Errorf("case %d: no prompt", i+1)
+		}
+		if c.Name == "" {
+			c.Name = defaultCaseName(i, c.Prompt)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("case %d: duplicate name %q", i+1, c.Name)
+		}
+		seen[c.Name] = true
+		c.Expect = c.Expect.merge(s.Defaults)
+		if c.MinCitations != nil && *c.MinCitations < 0 {
+			return nil, fmt.Errorf("case %q: min_citations must not be negative", c.Name)
+		}
+		for _, expr := range c.Matches {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("case %q: matches: %w", c.Name, err)
+			}
+			c.patterns = append(c.patterns, re)
+		}
+	}
+	return &s, nil
+}
+
+// merge adds the suite defaults to e.
+func (e Expect) merge(defaults Expect) Expect {
+	out := Expect{
+		Contains:     append(append([]string(nil), e.Contains...), defaults.Contains...),
+		Matches:      append(append([]string(nil), e.Matches...), defaults.Matches...),
+		Cites:        append(append([]string(nil), e.Cites...), defaults.Cites...),
+		Forbid:       append(append([]string(nil), e.Forbid...), defaults.Forbid...),
+		MinCitations: e.MinCitations,
+	}
+	if out.MinCitations == nil {
+		out.MinCitations = defaults.MinCitations
+	}
+	return out
+}
+
+// defaultCaseName names an unnamed case after its prompt.
+func defaultCaseName(i int, prompt string) string {
+	name := strings.Join(strings.Fields(prompt), " ")
+	if r := []rune(name); len(r) > 60 {
+		name = strings.TrimSpace(string(r[:59])) + "…"
+	}
+	return fmt.Sprintf("%d. %s", i+1, name)
+}
+
+// suiteName derives a suite name from its file name: questions.yaml names
+// the suite "questions".
+func suiteName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
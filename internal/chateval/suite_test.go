@@ -0,0 +1,78 @@
+package chateval
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(`
+defaults:
+  min_citations: 1
+  forbid: ["I don't know"]
+cases:
+  - name: install
+    prompt: How do I install it?
+    contains: ["go install"]
+    matches: ['nlm@v\d+']
+  - prompt: "  What does   sync do?  "
+    cites: [README]
+    min_citations: 0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Cases) != 2 {
+		t.Fatalf("got %d cases, want 2", len(s.Cases))
+	}
+	install := s.Cases[0]
+	if !reflect.DeepEqual(install.Forbid, []string{"I don't know"}) || *install.MinCitations != 1 {
+		t.Errorf("install did not inherit defaults: %+v", install.Expect)
+	}
+	if len(install.patterns) != 1 {
+		t.Errorf("install has %d compiled patterns, want 1", len(install.patterns))
+	}
+	sync := s.Cases[1]
+	if sync.Name != "2. What does sync do?" {
+		t.Errorf("unnamed case got name %q", sync.Name)
+	}
+	if *sync.MinCitations != 0 {
+		t.Errorf("min_citations 0 was overridden by the default: %d", *sync.MinCitations)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name, yaml, want string
+	}{
+		{"empty", "", "no cases"},
+		{"no prompt", "cases: [{name: a}]", "no prompt"},
+		{"duplicate", "cases: [{name: a, prompt: x}, {name: a, prompt: y}]", "duplicate name"},
+		{"unknown key", "cases: [{prompt: x, contain: [y]}]", "contain"},
+		{"bad pattern", "cases: [{prompt: x, matches: ['(']}]", "matches"},
+		{"negative", "cases: [{prompt: x, min_citations: -1}]", "negative"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.yaml))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Parse error = %v, want one mentioning %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLoadNamesSuite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs-questions.yaml")
+	if err := os.WriteFile(path, []byte("cases: [{prompt: x}]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "docs-questions" {
+		t.Errorf("suite name = %q, want docs-questions", s.Name)
+	}
+}
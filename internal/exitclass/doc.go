@@ -0,0 +1,4 @@
+// Package exitclass classifies nlm errors into the stable failure classes
+// that the CLI reports as exit codes and the MCP server attaches to tool
+// errors, so scripts and agents can decide whether to retry.
+package exitclass
@@ -0,0 +1,227 @@
+package exitclass
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/tmc/nlm/internal/batchexecute"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// Class is a failure class. Its value is the CLI exit code; keep the
+// values and names stable, since scripts and agents branch on them.
+//
+//	1 generic error (default for unclassified failures)
+//	2 bad arguments (flag parser, malformed input)
+//	3 auth required / auth failed
+//	4 not found (notebook, source, artifact)
+//	5 permanent precondition (source-cap reached, quota exhausted, deleted)
+//	6 transient server / network / 5xx / rate limit
+//	7 resource busy / still generating (poll-in-progress)
+type Class int
+
+const (
+	Generic      Class = 1
+	BadArgs      Class = 2
+	Auth         Class = 3
+	NotFound     Class = 4
+	Precondition Class = 5
+	Transient    Class = 6
+	Busy         Class = 7
+)
+
+// String returns a short, stable, machine-parseable name for c, or "" for
+// classes without a distinct name (including Generic).
+func (c Class) String() string {
+	switch c {
+	case BadArgs:
+		return "bad-args"
+	case Auth:
+		return "auth"
+	case NotFound:
+		return "not-found"
+	case Precondition:
+		return "precondition"
+	case Transient:
+		return "transient"
+	case Busy:
+		return "busy"
+	default:
+		return ""
+	}
+}
+
+// Retryable reports whether repeating the same request later may succeed.
+func (c Class) Retryable() bool {
+	return c == Transient || c == Busy
+}
+
+type classError struct {
+	class Class
+	err   error
+}
+
+func (e *classError) Error() string { return e.err.Error() }
+func (e *classError) Unwrap() error { return e.err }
+
+// With marks err as belonging to class, for callers that know more than Of
+// can infer from the error itself. It returns nil if err is nil.
+func With(class Class, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classError{class: class, err: err}
+}
+
+// Of classifies err. Order of checks matters: classes set by With win, the
+// typed api sentinels are more specific than the batchexecute.APIError
+// classification, and IsAuthentication runs last because it folds in legacy
+// string-matching cases that predate the structured ErrorType
+// classification. Of returns Generic for errors it cannot place, including
+// nil.
+func Of(err error) Class {
+	if err == nil {
+		return Generic
+	}
+	var ce *classError
+	if errors.As(err, &ce) {
+		return ce.class
+	}
+
+	// Typed api-layer sentinels for states batchexecute cannot disambiguate.
+	switch {
+	case errors.Is(err, notebooklm.ErrAuthExpired):
+		return Auth
+	case errors.Is(err, notebooklm.ErrSourceCapReached),
+		errors.Is(err, notebooklm.ErrSourceTooLarge),
+		errors.Is(err, notebooklm.ErrNotebookCapReached):
+		return Precondition
+	case errors.Is(err, notebooklm.ErrArtifactGenerating),
+		errors.Is(err, notebooklm.ErrResearchPolling):
+		return Busy
+	case errors.Is(err, notebooklm.ErrNotebookNotAccessible),
+		errors.Is(err, notebooklm.ErrArtifactNotFound),
+		errors.Is(err, notebooklm.ErrNoteNotFound):
+		return NotFound
+	}
+
+	// Structured batchexecute.APIError classification.
+	var apiErr *batchexecute.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.ErrorCode != nil {
+			switch apiErr.ErrorCode.Type {
+			case batchexecute.ErrorTypeAuthentication:
+				return Auth
+			case batchexecute.ErrorTypeAuthorization,
+				batchexecute.ErrorTypePermissionDenied:
+				return NotFound
+			case batchexecute.ErrorTypeNotFound:
+				return NotFound
+			case batchexecute.ErrorTypeResourceExhausted,
+				batchexecute.ErrorTypeAlreadyExists:
+				return Precondition
+			case batchexecute.ErrorTypeRateLimit,
+				batchexecute.ErrorTypeServerError,
+				batchexecute.ErrorTypeUnavailable,
+				batchexecute.ErrorTypeNetworkError,
+				batchexecute.ErrorTypeDeadlineExceeded:
+				return Transient
+			case batchexecute.ErrorTypeInvalidInput:
+				// Code 9 ("Failed precondition") is a server state/policy
+				// rejection — notebook at the source limit, transient artifact
+				// state, upstream busy — not malformed client input. Classify
+				// it as a precondition so it doesn't read as a bad-args/size
+				// problem (the caller can't fix it by reshaping the request).
+				if apiErr.ErrorCode.Code == 9 {
+					return Precondition
+				}
+				return BadArgs
+			case batchexecute.ErrorTypeUnknown:
+				return Generic
+			}
+		}
+		// HTTPStatus fallback for APIErrors without a parsed ErrorCode.
+		switch {
+		case apiErr.HTTPStatus == 401:
+			return Auth
+		case apiErr.HTTPStatus == 403:
+			return NotFound
+		case apiErr.HTTPStatus == 404:
+			return NotFound
+		case apiErr.HTTPStatus == 429:
+			return Transient
+		case apiErr.HTTPStatus >= 500 && apiErr.HTTPStatus <= 599:
+			return Transient
+		case apiErr.HTTPStatus >= 400 && apiErr.HTTPStatus <= 499:
+			return BadArgs
+		}
+	}
+
+	if IsAuthentication(err) {
+		return Auth
+	}
+
+	return Generic
+}
+
+// IsAuthentication reports whether err is related to authentication.
+func IsAuthentication(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *batchexecute.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.ErrorCode != nil {
+			switch apiErr.ErrorCode.Type {
+			case batchexecute.ErrorTypeAuthentication:
+				return true
+			case batchexecute.ErrorTypeAuthorization,
+				batchexecute.ErrorTypePermissionDenied,
+				batchexecute.ErrorTypeNotFound:
+				return false
+			}
+		}
+		switch apiErr.HTTPStatus {
+		case 401:
+			return true
+		case 403, 404:
+			return false
+		}
+	}
+
+	// Check for batchexecute unauthorized error
+	if errors.Is(err, batchexecute.ErrUnauthorized) {
+		return true
+	}
+
+	// Check for common authentication error messages
+	errorStr := strings.ToLower(err.Error())
+	authKeywords := []string{
+		"unauthenticated",
+		"authentication",
+		"unauthorized",
+		"api error 16", // Google API authentication error
+		"error 16",
+		"status: 401",
+		"session invalid",
+		"invalid session",
+		"session expired",
+		"expired session",
+		"login required",
+		"auth required",
+		"invalid credentials",
+		"token expired",
+		"expired token",
+		"cookie invalid",
+		"invalid cookie",
+	}
+
+	for _, keyword := range authKeywords {
+		if strings.Contains(errorStr, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package exitclass
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"nil", nil, Generic},
+		{"plain", errors.New("boom"), Generic},
+		{"sentinel", fmt.Errorf("wrap: %w", notebooklm.ErrArtifactGenerating), Busy},
+		{"with", With(NotFound, errors.New("missing")), NotFound},
+		{"with wins over sentinel", With(Precondition, notebooklm.ErrArtifactGenerating), Precondition},
+		{"wrapped with", fmt.Errorf("call: %w", With(Transient, errors.New("reset"))), Transient},
+		{"auth keyword", errors.New("session expired"), Auth},
+	}
+	for _, tt := range tests {
+		if got := Of(tt.err); got != tt.want {
+			t.Errorf("%s: Of(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClassNames(t *testing.T) {
+	if With(Busy, nil) != nil {
+		t.Error("With(Busy, nil) != nil")
+	}
+	for c, want := range map[Class]string{Generic: "", BadArgs: "bad-args", Busy: "busy", Class(42): ""} {
+		if got := c.String(); got != want {
+			t.Errorf("Class(%d).String() = %q, want %q", c, got, want)
+		}
+	}
+	if !Transient.Retryable() || !Busy.Retryable() || Auth.Retryable() {
+		t.Error("only transient and busy classes are retryable")
+	}
+}
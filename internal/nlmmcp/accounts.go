@@ -0,0 +1,411 @@
+package nlmmcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// accountProperty is the tool argument that chooses an account.
+const accountProperty = "account"
+
+// missTTL is how long a notebook no account lists is routed to the default
+// account before the accounts' notebook lists are fetched again.
+const missTTL = time.Minute
+
+// Account is a set of credentials the server can act as.
+type Account struct {
+	// Name is how the account argument of a tool call refers to it.
+	Name string
+
+	// Selection names the credentials Client was built from; it is
+	// passed to Options.RunCommand for calls made as this account.
+	Selection Selection
+
+	Client *notebooklm.Client
+}
+
+type accountKey struct{}
+
+// accountSet holds the accounts a server acts as and remembers which one
+// owns each notebook it has seen. The first account is the default.
+type accountSet struct {
+	accounts []Account
+	now      func() time.Time
+
+	mu        sync.Mutex
+	notebooks map[string]int       // notebook ID -> index into accounts
+	misses    map[string]time.Time // notebook ID -> when no account listed it
+}
+
+// newAccountSet returns opts.Accounts, or client alone as the default
+// account when there are none.
+func newAccountSet(client *notebooklm.Client, opts *Options) *accountSet {
+	accounts := opts.Accounts
+	if len(accounts) == 0 {
+		accounts = []Account{{Name: "default", Selection: opts.Selection, Client: client}}
+	}
+	return &accountSet{
+		accounts:  accounts,
+		now:       time.Now,
+		notebooks: make(map[string]int),
+		misses:    make(map[string]time.Time),
+	}
+}
+
+func (s *accountSet) multi() bool { return len(s.accounts) > 1 }
+
+func (s *accountSet) names() []string {
+	names := make([]string, len(s.accounts))
+	for i, a := range s.accounts {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// selected returns the account a request chose or was routed to.
+func (s *accountSet) selected(ctx context.Context) (Account, bool) {
+	a, ok := ctx.Value(accountKey{}).(Account)
+	return a, ok
+}
+
+// account returns the request's account, or the default.
+func (s *accountSet) account(ctx context.Context) Account {
+	if a, ok := s.selected(ctx); ok {
+		return a
+	}
+	return s.accounts[0]
+}
+
+// client returns the client for the request's account.
+func (s *accountSet) client(ctx context.Context) *notebooklm.Client {
+	return s.account(ctx).Client
+}
+
+// remember records that the request's account owns a notebook, so later
+// calls naming it are routed there.
+func (s *accountSet) remember(ctx context.Context, notebookID string) {
+	s.rememberAs(s.account(ctx).Name, notebookID)
+}
+
+func (s *accountSet) rememberAs(name, notebookID string) {
+	if !s.multi() || notebookID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notebooks[notebookID] = slices.Index(s.names(), name)
+	delete(s.misses, notebookID)
+}
+
+// resolve returns the account named by name or, when name is empty, the
+// one that owns notebookID. It reports false when neither picks one, in
+// which case the call goes to the default account.
+func (s *accountSet) resolve(ctx context.Context, name, notebookID string) (Account, bool, error) {
+	if name != "" {
+		if i := slices.Index(s.names(), name); i >= 0 {
+			return s.accounts[i], true, nil
+		}
+		return Account{}, false, fmt.Errorf("unknown account %q (have %s)", name, strings.Join(s.names(), ", "))
+	}
+	if !s.multi() || notebookID == "" {
+		return Account{}, false, nil
+	}
+	s.pruneMisses()
+	if a, ok := s.owner(notebookID); ok {
+		return a, true, nil
+	}
+	if s.missed(notebookID) {
+		return Account{}, false, nil
+	}
+	// An unseen notebook: list every account's notebooks and look again.
+	// Accounts that fail to list are skipped; the call itself will report
+	// the failure if it lands on one. Only recently viewed notebooks are
+	// listed, so older ones are found in none; remember the miss so calls
+	// naming them do not list every account each time.
+	for _, a := range s.accounts {
+		_, _ = s.listNotebooks(ctx, a)
+	}
+	if a, ok := s.owner(notebookID); ok {
+		return a, true, nil
+	}
+	s.mu.Lock()
+	s.misses[notebookID] = s.now()
+	s.mu.Unlock()
+	return Account{}, false, nil
+}
+
+// missed reports whether notebookID was found in no account's notebook
+// list within the last missTTL.
+func (s *accountSet) missed(notebookID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.misses[notebookID]
+	return ok && s.now().Sub(t) < missTTL
+}
+
+// pruneMisses drops the misses older than missTTL, so notebooks named once
+// and never again do not accumulate.
+func (s *accountSet) pruneMisses() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for id, t := range s.misses {
+		if now.Sub(t) >= missTTL {
+			delete(s.misses, id)
+		}
+	}
+}
+
+// fellBack handles the result of a call naming a notebook no account
+// lists, which ran as the default account. If the call failed the error
+// says where the call went and how to send it elsewhere. A success is not
+// taken as ownership, which is recorded only from a listing; until one
+// shows the owner, the cached miss routes calls to the default account.
+func (s *accountSet) fellBack(notebookID string, result mcp.Result, err error) (mcp.Result, error) {
+	def := s.accounts[0].Name
+	note := fmt.Sprintf("notebook %s is not among the recently viewed notebooks of any account, so the call ran as the default account %s; pass account to choose another (have %s)",
+		notebookID, def, strings.Join(s.names(), ", "))
+	if err != nil {
+		return result, fmt.Errorf("%w (%s)", err, note)
+	}
+	if res, ok := result.(*mcp.CallToolResult); ok && res.IsError {
+		res.Content = append(res.Content, &mcp.TextContent{Text: note})
+		return res, nil
+	}
+	return result, nil
+}
+
+func (s *accountSet) owner(notebookID string) (Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.notebooks[notebookID]
+	if !ok {
+		return Account{}, false
+	}
+	return s.accounts[i], true
+}
+
+// listNotebooks lists an account's recently viewed notebooks and records
+// it as their owner.
+func (s *accountSet) listNotebooks(ctx context.Context, a Account) ([]*notebooklm.Notebook, error) {
+	if a.Client == nil {
+		return nil, fmt.Errorf("account %s: no client", a.Name)
+	}
+	notebooks, err := a.Client.ListRecentlyViewedProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", a.Name, err)
+	}
+	for _, nb := range notebooks {
+		s.rememberAs(a.Name, nb.GetProjectId())
+	}
+	return notebooks, nil
+}
+
+// accountNotebook is a notebook and the account it was listed from.
+type accountNotebook struct {
+	Account  string
+	Notebook *notebooklm.Notebook
+}
+
+// listAllNotebooks lists the notebooks of the request's chosen account or,
+// when it chose none, of every account.
+func (s *accountSet) listAllNotebooks(ctx context.Context) ([]accountNotebook, error) {
+	accounts := s.accounts
+	if a, ok := s.selected(ctx); ok {
+		accounts = []Account{a}
+	}
+	var out []accountNotebook
+	for _, a := range accounts {
+		notebooks, err := s.listNotebooks(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		for _, nb := range notebooks {
+			out = append(out, accountNotebook{Account: a.Name, Notebook: nb})
+		}
+	}
+	return out, nil
+}
+
+// middleware routes tool calls and resource reads to an account. A tool
+// call's account argument wins, and is removed before the tool sees its
+// arguments; otherwise a notebook_id or resource URI picks the account
+// that owns the notebook, falling back to the default account when none
+// lists it. With several accounts, listed tools advertise the account
+// argument.
+func (s *accountSet) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		var (
+			name, notebookID string
+			err              error
+		)
+		switch r := req.(type) {
+		case *mcp.CallToolRequest:
+			name, notebookID, r.Params.Arguments, err = takeAccountArgument(r.Params.Arguments)
+			if err != nil {
+				return toolErrorResult(badArgs(err)), nil
+			}
+		case *mcp.ReadResourceRequest:
+			ref, _ := parseResourceURI(r.Params.URI)
+			notebookID = ref.NotebookID
+		}
+		a, ok, err := s.resolve(ctx, name, notebookID)
+		if err != nil {
+			return toolErrorResult(badArgs(err)), nil
+		}
+		if ok {
+			ctx = context.WithValue(ctx, accountKey{}, a)
+		}
+		result, err := next(ctx, method, req)
+		if !ok && s.multi() && notebookID != "" {
+			result, err = s.fellBack(notebookID, result, err)
+		}
+		if list, ok := result.(*mcp.ListToolsResult); ok && err == nil && s.multi() {
+			for i, t := range list.Tools {
+				list.Tools[i] = s.withAccountArgument(t)
+			}
+		}
+		return result, err
+	}
+}
+
+// takeAccountArgument removes the account argument from a tool call's
+// arguments and returns it with the call's notebook_id.
+func takeAccountArgument(arguments json.RawMessage) (name, notebookID string, rest json.RawMessage, err error) {
+	var args map[string]json.RawMessage
+	if len(arguments) == 0 || json.Unmarshal(arguments, &args) != nil {
+		return "", "", arguments, nil
+	}
+	_ = json.Unmarshal(args["notebook_id"], &notebookID)
+	raw, ok := args[accountProperty]
+	if !ok {
+		return "", notebookID, arguments, nil
+	}
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return "", "", nil, errors.New("account must be a string")
+	}
+	delete(args, accountProperty)
+	rest, err = json.Marshal(args)
+	return name, notebookID, rest, err
+}
+
+// withAccountArgument returns a copy of t whose input schema includes the
+// account property.
+func (s *accountSet) withAccountArgument(t *mcp.Tool) *mcp.Tool {
+	schema, ok := t.InputSchema.(*jsonschema.Schema)
+	if !ok {
+		return t
+	}
+	schema = schema.CloneSchemas()
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]*jsonschema.Schema)
+	}
+	enum := make([]any, len(s.accounts))
+	for i, name := range s.names() {
+		enum[i] = name
+	}
+	schema.Properties[accountProperty] = &jsonschema.Schema{
+		Type:        "string",
+		Enum:        enum,
+		Description: fmt.Sprintf("Account to act as (default %s). Calls naming a notebook_id go to the account that owns it.", s.accounts[0].Name),
+	}
+	copied := *t
+	copied.InputSchema = schema
+	return &copied
+}
+
+// routedClient serves resources from the request's account, and lists
+// notebooks across all of them.
+type routedClient struct{ s *accountSet }
+
+func (c routedClient) ListRecentlyViewedProjects(ctx context.Context) ([]*notebooklm.Notebook, error) {
+	var all []*notebooklm.Notebook
+	for _, a := range c.s.accounts {
+		notebooks, err := c.s.listNotebooks(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, notebooks...)
+	}
+	return all, nil
+}
+
+func (c routedClient) GetProject(ctx context.Context, notebookID string) (*notebooklm.Notebook, error) {
+	return c.s.client(ctx).GetProject(ctx, notebookID)
+}
+
+func (c routedClient) GetNotes(ctx context.Context, notebookID string) ([]*notebooklm.Note, error) {
+	return c.s.client(ctx).GetNotes(ctx, notebookID)
+}
+
+func (c routedClient) LoadSourceText(ctx context.Context, sourceID, notebookID string) (notebooklm.LoadSourceText, error) {
+	return c.s.client(ctx).LoadSourceText(ctx, sourceID, notebookID)
+}
+
+func (c routedClient) DownloadSourceImage(ctx context.Context, imageURL string) ([]byte, string, error) {
+	return c.s.client(ctx).DownloadSourceImage(ctx, imageURL)
+}
+
+type accountInfo struct {
+	Name          string `json:"name"`
+	Default       bool   `json:"default,omitempty"`
+	Profile       string `json:"profile,omitempty" jsonschema:"Stored credential profile the account uses"`
+	AuthUser      string `json:"authuser,omitempty" jsonschema:"Google account index or email the account uses"`
+	Tier          int    `json:"tier,omitempty"`
+	NotebookLimit int    `json:"notebook_limit,omitempty"`
+	SourceLimit   int    `json:"source_limit,omitempty" jsonschema:"Maximum sources per notebook"`
+	Error         string `json:"error,omitempty" jsonschema:"Why the account's status could not be read, such as expired credentials"`
+}
+
+type listAccountsInput struct{}
+
+type listAccountsOutput struct {
+	Accounts []accountInfo `json:"accounts"`
+}
+
+// accountStatusClient is the subset of notebooklm.Client list_accounts uses.
+type accountStatusClient interface {
+	GetAccountStatus(context.Context) (*notebooklm.AccountStatus, error)
+}
+
+func registerAccountTools(r *toolRegistry, accounts *accountSet) {
+	addTool(r, &mcp.Tool{
+		Name:        "list_accounts",
+		Description: "List the Google accounts this server can act as, with each account's tier and limits. Pass an account's name as the account argument of other tools to act as it.",
+		Annotations: readOnlyAnnotations,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input listAccountsInput) (*mcp.CallToolResult, listAccountsOutput, error) {
+		out := listAccountsOutput{Accounts: make([]accountInfo, len(accounts.accounts))}
+		for i, a := range accounts.accounts {
+			out.Accounts[i] = describeAccount(ctx, a, a.Client, i == 0)
+		}
+		return nil, out, nil
+	})
+}
+
+func describeAccount(ctx context.Context, a Account, c accountStatusClient, isDefault bool) accountInfo {
+	info := accountInfo{
+		Name:     a.Name,
+		Default:  isDefault,
+		Profile:  a.Selection.Profile,
+		AuthUser: a.Selection.AuthUser,
+	}
+	status, err := c.GetAccountStatus(ctx)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Tier = status.Tier
+	info.NotebookLimit = status.NotebookLimit
+	info.SourceLimit = status.SourceLimit
+	return info
+}
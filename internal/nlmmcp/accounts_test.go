@@ -0,0 +1,301 @@
+package nlmmcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// listingClient returns a client whose notebook list holds the given IDs.
+func listingClient(t *testing.T, notebookIDs ...string) *notebooklm.Client {
+	t.Helper()
+	return countingClient(t, new(atomic.Int32), notebookIDs...)
+}
+
+// countingClient is listingClient, counting the lists in calls.
+func countingClient(t *testing.T, calls *atomic.Int32, notebookIDs ...string) *notebooklm.Client {
+	t.Helper()
+	var projects []any
+	for _, id := range notebookIDs {
+		projects = append(projects, []any{"Notebook " + id, nil, id, "📘"})
+	}
+	payload, err := json.Marshal([]any{projects})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(string(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := fmt.Sprintf(")]}'\n\n[[\"wrb.fr\",\"wXbhsf\",%s,null,null,null,\"generic\"]]", data)
+	return notebooklm.New(notebooklm.Credentials{AuthToken: "t", Cookies: "c"},
+		notebooklm.WithHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if got := req.URL.Query().Get("rpcids"); got != "wXbhsf" {
+				return nil, fmt.Errorf("unexpected rpc %s", got)
+			}
+			calls.Add(1)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		})}))
+}
+
+func connectAccounts(t *testing.T, selections chan<- Selection) *mcp.ClientSession {
+	t.Helper()
+	return connectOptions(t, &Options{
+		Accounts: []Account{
+			{Name: "work", Selection: Selection{Profile: "work"}, Client: listingClient(t, "nb-w1", "nb-w2")},
+			{Name: "1", Selection: Selection{AuthUser: "1"}, Client: listingClient(t, "nb-p1")},
+		},
+		Commands: []CommandTool{{
+			Name:     "source_sync",
+			Path:     []string{"source", "sync"},
+			Operands: []CommandOperand{{Name: "notebook_id", Required: true}},
+		}},
+		RunCommand: func(_ context.Context, sel Selection, _ []string) (string, error) {
+			selections <- sel
+			return "ok", nil
+		},
+	})
+}
+
+func TestAccountsListNotebooks(t *testing.T) {
+	t.Parallel()
+
+	cs := connectAccounts(t, nil)
+	ctx := context.Background()
+	tests := []struct {
+		args map[string]any
+		want []string
+	}{
+		{map[string]any{}, []string{"work/nb-w1", "work/nb-w2", "1/nb-p1"}},
+		{map[string]any{"account": "1"}, []string{"1/nb-p1"}},
+	}
+	for _, tt := range tests {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "list_notebooks", Arguments: tt.args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsError {
+			t.Fatalf("list_notebooks(%v) failed: %v", tt.args, res.Content[0].(*mcp.TextContent).Text)
+		}
+		var page pageResult[notebookSummary]
+		data, _ := json.Marshal(res.StructuredContent)
+		if err := json.Unmarshal(data, &page); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, nb := range page.Items {
+			got = append(got, nb.Account+"/"+nb.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("list_notebooks(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestAccountsRouteCalls(t *testing.T) {
+	t.Parallel()
+
+	selections := make(chan Selection, 1)
+	cs := connectAccounts(t, selections)
+	ctx := context.Background()
+	tests := []struct {
+		args map[string]any
+		want Selection
+	}{
+		{map[string]any{"notebook_id": "nb-p1"}, Selection{AuthUser: "1"}},
+		{map[string]any{"notebook_id": "nb-w2"}, Selection{Profile: "work"}},
+		{map[string]any{"notebook_id": "nb-unknown"}, Selection{Profile: "work"}},
+		{map[string]any{"notebook_id": "nb-w1", "account": "1"}, Selection{AuthUser: "1"}},
+	}
+	for _, tt := range tests {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "source_sync", Arguments: tt.args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsError {
+			t.Fatalf("source_sync(%v) failed: %v", tt.args, res.Content[0].(*mcp.TextContent).Text)
+		}
+		if got := <-selections; got != tt.want {
+			t.Errorf("source_sync(%v) ran as %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "source_sync", Arguments: map[string]any{"notebook_id": "nb-w1", "account": "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError || res.Meta["exit_class"] != "bad-args" {
+		t.Errorf("unknown account: error %v, meta %v", res.IsError, res.Meta)
+	}
+}
+
+func TestAccountsCacheMisses(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	s := newAccountSet(nil, &Options{Accounts: []Account{
+		{Name: "work", Client: countingClient(t, &calls, "nb-w1")},
+		{Name: "1", Client: countingClient(t, &calls, "nb-p1")},
+	}})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+	tests := []struct {
+		notebookID string
+		advance    time.Duration
+		wantOK     bool
+		wantCalls  int32
+	}{
+		{"nb-old", 0, false, 2},
+		{"nb-old", missTTL / 2, false, 2},
+		{"nb-p1", 0, true, 2},
+		{"nb-old", missTTL, false, 4},
+		{"nb-other", 0, false, 6},
+		{"nb-p1", missTTL, true, 6},
+	}
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		_, ok, err := s.resolve(ctx, "", tt.notebookID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.wantOK || calls.Load() != tt.wantCalls {
+			t.Errorf("resolve(%s) after %v = %v with %d lists, want %v with %d", tt.notebookID, tt.advance, ok, calls.Load(), tt.wantOK, tt.wantCalls)
+		}
+	}
+	// Resolving any notebook drops expired misses, not only its own.
+	if len(s.misses) != 0 {
+		t.Errorf("misses = %v after they expired, want none", s.misses)
+	}
+}
+
+func TestAccountsFallBack(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	cs := connectOptions(t, &Options{
+		Accounts: []Account{
+			{Name: "work", Selection: Selection{Profile: "work"}, Client: countingClient(t, &calls, "nb-w1")},
+			{Name: "1", Selection: Selection{AuthUser: "1"}, Client: countingClient(t, &calls, "nb-p1")},
+		},
+		// The command is marked read-only so that resource refreshes after
+		// its calls do not add to the listings counted below.
+		Commands: []CommandTool{{
+			Name:     "source_sync",
+			Path:     []string{"source", "sync"},
+			Operands: []CommandOperand{{Name: "notebook_id", Required: true}},
+			ReadOnly: true,
+		}},
+		RunCommand: func(_ context.Context, _ Selection, args []string) (string, error) {
+			if slices.Contains(args, "nb-missing") {
+				return "", errors.New("notebook not found")
+			}
+			return "ok", nil
+		},
+	})
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "source_sync", Arguments: map[string]any{"notebook_id": "nb-missing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text []string
+	for _, c := range res.Content {
+		text = append(text, c.(*mcp.TextContent).Text)
+	}
+	if got := strings.Join(text, "\n"); !res.IsError || !strings.Contains(got, "default account work; pass account") {
+		t.Errorf("source_sync(nb-missing) = %q, want error naming the default account", got)
+	}
+
+	// The miss is cached, so a second call is routed without listing.
+	for range 2 {
+		res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "source_sync", Arguments: map[string]any{"notebook_id": "nb-old"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsError {
+			t.Fatalf("source_sync(nb-old) failed: %v", res.Content[0].(*mcp.TextContent).Text)
+		}
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("listed notebooks %d times, want 4", got)
+	}
+
+	// A success as the default account does not make it the owner; only
+	// a listing does.
+	s := newAccountSet(nil, &Options{Accounts: []Account{{Name: "work"}, {Name: "1"}}})
+	if _, err := s.fellBack("nb-old", &mcp.CallToolResult{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := s.owner("nb-old"); ok {
+		t.Errorf("owner(nb-old) = %s after a fallback call, want none", a.Name)
+	}
+}
+
+func TestAccountsAdvertiseArgument(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range []*Options{{}, {Accounts: []Account{{Name: "a"}, {Name: "b"}}}} {
+		cs := connectOptions(t, opts)
+		result, err := cs.ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tool := range result.Tools {
+			props, _ := tool.InputSchema.(map[string]any)["properties"].(map[string]any)
+			prop, ok := props["account"].(map[string]any)
+			if multi := len(opts.Accounts) > 1; ok != multi {
+				t.Errorf("%d accounts: %s has account argument = %v", len(opts.Accounts), tool.Name, ok)
+				continue
+			}
+			if ok && fmt.Sprint(prop["enum"]) != "[a b]" {
+				t.Errorf("%s: account enum = %v, want [a b]", tool.Name, prop["enum"])
+			}
+		}
+	}
+}
+
+type fakeAccountStatus struct {
+	status *notebooklm.AccountStatus
+	err    error
+}
+
+func (f fakeAccountStatus) GetAccountStatus(context.Context) (*notebooklm.AccountStatus, error) {
+	return f.status, f.err
+}
+
+func TestDescribeAccount(t *testing.T) {
+	t.Parallel()
+
+	a := Account{Name: "work", Selection: Selection{Profile: "work", AuthUser: "2"}}
+	got := describeAccount(context.Background(), a, fakeAccountStatus{status: &notebooklm.AccountStatus{Tier: 2, NotebookLimit: 500, SourceLimit: 300}}, true)
+	want := accountInfo{Name: "work", Default: true, Profile: "work", AuthUser: "2", Tier: 2, NotebookLimit: 500, SourceLimit: 300}
+	if got != want {
+		t.Errorf("describeAccount() = %+v, want %+v", got, want)
+	}
+
+	got = describeAccount(context.Background(), a, fakeAccountStatus{err: errors.New("session expired")}, false)
+	if got.Error != "session expired" || got.Tier != 0 {
+		t.Errorf("describeAccount() with error = %+v", got)
+	}
+}
@@ -0,0 +1,229 @@
+package nlmmcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/exitclass"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// defaultMaxEmbedBytes bounds the artifact payloads export_artifact embeds
+// in its result; larger ones are written to Options.ExportDir.
+const defaultMaxEmbedBytes = 8 << 20
+
+// artifactExporter is the subset of notebooklm.Client export_artifact uses.
+type artifactExporter interface {
+	artifactFetcher
+	DownloadAudioOverview(context.Context, string) (*notebooklm.AudioOverviewResult, error)
+}
+
+type exportArtifactInput struct {
+	NotebookID string `json:"notebook_id"`
+	ArtifactID string `json:"artifact_id"`
+	Format     string `json:"format,omitempty" jsonschema:"File format: pdf or pptx for slide decks, md for reports (default pdf for slide decks, md for reports); audio is exported as returned"`
+}
+
+type exportArtifactOutput struct {
+	ID       string `json:"id"`
+	Title    string `json:"title,omitempty"`
+	Type     string `json:"type"`
+	MIMEType string `json:"mime_type"`
+	Size     int    `json:"size" jsonschema:"File size in bytes"`
+	URI      string `json:"uri" jsonschema:"URI of the embedded resource or, for files written to the export directory, their file:// URL"`
+	Path     string `json:"path,omitempty" jsonschema:"Local path, set when the file was written to the export directory"`
+}
+
+// exportConfig holds the server-wide export settings.
+type exportConfig struct {
+	Dir           string
+	MaxEmbedBytes int
+}
+
+func newExportConfig(opts *Options) exportConfig {
+	cfg := exportConfig{Dir: opts.ExportDir, MaxEmbedBytes: opts.MaxEmbedBytes}
+	if cfg.MaxEmbedBytes <= 0 {
+		cfg.MaxEmbedBytes = defaultMaxEmbedBytes
+	}
+	return cfg
+}
+
+// exportMIMETypes maps export formats to the MIME types clients expect.
+var exportMIMETypes = map[string]string{
+	"pdf":  "application/pdf",
+	"pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"md":   markdownMIMEType,
+	"csv":  "text/csv",
+	"json": "application/json",
+	"mp3":  "audio/mpeg",
+	"m4a":  "audio/mp4",
+	"wav":  "audio/wav",
+	"mp4":  "video/mp4",
+	"png":  "image/png",
+}
+
+func artifactURI(notebookID, artifactID string) string {
+	return notebookURI(notebookID) + "/artifact/" + artifactID
+}
+
+func registerExportTool(r *toolRegistry, accounts *accountSet, opts *Options) {
+	cfg := newExportConfig(opts)
+	addTool(r, &mcp.Tool{
+		Name:        "export_artifact",
+		Description: "Return a ready artifact's file (slide deck PDF or PPTX, report Markdown, audio overview) as an embedded resource, or as a file:// link when it is too large to embed.",
+		Annotations: readOnlyAnnotations,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input exportArtifactInput) (*mcp.CallToolResult, exportArtifactOutput, error) {
+		result, out, err := exportArtifact(ctx, accounts.client(ctx), input, cfg)
+		if err != nil {
+			return nil, exportArtifactOutput{}, fmt.Errorf("failed to export artifact: %w", err)
+		}
+		return result, out, nil
+	})
+}
+
+// exportArtifact downloads a ready artifact and returns it embedded, or
+// linked from cfg.Dir when it exceeds cfg.MaxEmbedBytes.
+func exportArtifact(ctx context.Context, c artifactExporter, input exportArtifactInput, cfg exportConfig) (*mcp.CallToolResult, exportArtifactOutput, error) {
+	var out exportArtifactOutput
+	if input.NotebookID == "" {
+		return nil, out, badArgs(fmt.Errorf("notebook_id is required"))
+	}
+	if input.ArtifactID == "" {
+		return nil, out, badArgs(fmt.Errorf("artifact_id is required"))
+	}
+	artifact, err := fetchArtifact(ctx, c, input.NotebookID, input.ArtifactID)
+	if err != nil {
+		return nil, out, fmt.Errorf("get artifact: %w", err)
+	}
+	switch artifact.GetState() {
+	case pb.ArtifactState_ARTIFACT_STATE_READY:
+	case pb.ArtifactState_ARTIFACT_STATE_FAILED:
+		return nil, out, exitclass.With(exitclass.Precondition, fmt.Errorf("artifact %s failed to generate", input.ArtifactID))
+	default:
+		return nil, out, exitclass.With(exitclass.Busy, fmt.Errorf("artifact %s is %s; call wait_for_artifact first",
+			input.ArtifactID, artifactStateLabel(artifact.GetState())))
+	}
+
+	data, format, err := readArtifactBytes(ctx, c, input.NotebookID, artifact, input.Format)
+	if err != nil {
+		return nil, out, err
+	}
+	out = exportArtifactOutput{
+		ID:       artifact.GetArtifactId(),
+		Title:    artifact.GetTitle(),
+		Type:     artifactTypeLabel(artifact.GetType()),
+		MIMEType: exportMIMETypes[format],
+		Size:     len(data),
+		URI:      artifactURI(input.NotebookID, artifact.GetArtifactId()),
+	}
+	if out.MIMEType == "" {
+		out.MIMEType = "application/octet-stream"
+	}
+	summary := fmt.Sprintf("%s %q (%s, %d bytes)", out.Type, out.Title, out.MIMEType, out.Size)
+
+	if len(data) <= cfg.MaxEmbedBytes {
+		return &mcp.CallToolResult{Content: []mcp.Content{
+			&mcp.TextContent{Text: summary},
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+				URI:      out.URI,
+				MIMEType: out.MIMEType,
+				Blob:     data,
+			}},
+		}}, out, nil
+	}
+	if cfg.Dir == "" {
+		return nil, exportArtifactOutput{}, exitclass.With(exitclass.Precondition, fmt.Errorf(
+			"%s exceeds the %d-byte embed limit and the server has no export directory (start it with --export-dir)",
+			summary, cfg.MaxEmbedBytes))
+	}
+	path, err := writeExportFile(cfg.Dir, artifact.GetArtifactId()+"."+format, data)
+	if err != nil {
+		return nil, exportArtifactOutput{}, err
+	}
+	out.Path = path
+	out.URI = (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	size := int64(len(data))
+	return &mcp.CallToolResult{Content: []mcp.Content{
+		&mcp.TextContent{Text: summary + ", written to " + path},
+		&mcp.ResourceLink{
+			URI:      out.URI,
+			Name:     filepath.Base(path),
+			Title:    out.Title,
+			MIMEType: out.MIMEType,
+			Size:     &size,
+		},
+	}}, out, nil
+}
+
+// readArtifactBytes downloads an artifact's file and reports its format.
+// Audio overviews are fetched per notebook; everything else is read from
+// the artifact's rendered download links.
+func readArtifactBytes(ctx context.Context, c artifactExporter, notebookID string, artifact *pb.Artifact, format string) ([]byte, string, error) {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	switch artifact.GetType() {
+	case pb.ArtifactType_ARTIFACT_TYPE_AUDIO_OVERVIEW:
+		audio, err := c.DownloadAudioOverview(ctx, notebookID)
+		if err != nil {
+			return nil, "", fmt.Errorf("download audio overview: %w", err)
+		}
+		if audio.AudioID != "" && audio.AudioID != artifact.GetArtifactId() {
+			return nil, "", fmt.Errorf("artifact %s is not the notebook's current audio overview (%s)", artifact.GetArtifactId(), audio.AudioID)
+		}
+		data, err := audio.AudioBytes()
+		if err != nil {
+			return nil, "", fmt.Errorf("decode audio overview: %w", err)
+		}
+		return data, audioFormat(data), nil
+	case pb.ArtifactType_ARTIFACT_TYPE_REPORT:
+		if format == "" {
+			format = "md"
+		}
+	default:
+		if format == "" {
+			format = "pdf"
+		}
+	}
+	var buf bytes.Buffer
+	if err := c.ReadArtifactFile(ctx, artifact.GetArtifactId(), format, &buf); err != nil {
+		if errors.Is(err, notebooklm.ErrArtifactGenerating) {
+			return nil, "", fmt.Errorf("artifact %s has no downloadable %s file", artifact.GetArtifactId(), format)
+		}
+		return nil, "", fmt.Errorf("read artifact file: %w", err)
+	}
+	return buf.Bytes(), format, nil
+}
+
+// audioFormat names the container of downloaded audio by sniffing it.
+func audioFormat(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "audio/wave":
+		return "wav"
+	case "video/mp4":
+		return "m4a"
+	default:
+		return "mp3"
+	}
+}
+
+func writeExportFile(dir, name string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create export directory: %w", err)
+	}
+	path, err := filepath.Abs(filepath.Join(dir, name))
+	if err != nil {
+		return "", fmt.Errorf("resolve export path: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("write export file: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,129 @@
+package nlmmcp
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+type fakeArtifactExporter struct {
+	fakeArtifactFetcher
+	audio *notebooklm.AudioOverviewResult
+}
+
+func (f *fakeArtifactExporter) DownloadAudioOverview(context.Context, string) (*notebooklm.AudioOverviewResult, error) {
+	return f.audio, nil
+}
+
+func readyExporter(typ pb.ArtifactType, text string) *fakeArtifactExporter {
+	return &fakeArtifactExporter{fakeArtifactFetcher: fakeArtifactFetcher{
+		lists: [][]*pb.Artifact{{testArtifact(pb.ArtifactState_ARTIFACT_STATE_READY, typ)}},
+		text:  text,
+	}}
+}
+
+func TestExportArtifactEmbedsBlob(t *testing.T) {
+	t.Parallel()
+
+	exporter := readyExporter(pb.ArtifactType_ARTIFACT_TYPE_8, "%PDF-1.7")
+	result, _, err := exportArtifact(context.Background(), exporter, exportArtifactInput{
+		NotebookID: "nb1",
+		ArtifactID: "a1",
+	}, exportConfig{MaxEmbedBytes: 1 << 10})
+	if err != nil {
+		t.Fatalf("exportArtifact() error = %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("content = %d items, want 2", len(result.Content))
+	}
+	embedded, ok := result.Content[1].(*mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("content[1] = %T, want *mcp.EmbeddedResource", result.Content[1])
+	}
+	res := embedded.Resource
+	if res.URI != "nlm://notebook/nb1/artifact/a1" || res.MIMEType != "application/pdf" || string(res.Blob) != "%PDF-1.7" {
+		t.Errorf("resource = %+v", res)
+	}
+}
+
+func TestExportArtifactWritesLargeFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	exporter := readyExporter(pb.ArtifactType_ARTIFACT_TYPE_REPORT, "# Report\n\nBody")
+	result, _, err := exportArtifact(context.Background(), exporter, exportArtifactInput{
+		NotebookID: "nb1",
+		ArtifactID: "a1",
+	}, exportConfig{Dir: dir, MaxEmbedBytes: 4})
+	if err != nil {
+		t.Fatalf("exportArtifact() error = %v", err)
+	}
+	link, ok := result.Content[1].(*mcp.ResourceLink)
+	if !ok {
+		t.Fatalf("content[1] = %T, want *mcp.ResourceLink", result.Content[1])
+	}
+	path := filepath.Join(dir, "a1.md")
+	if link.URI != "file://"+filepath.ToSlash(path) || link.MIMEType != "text/markdown" || *link.Size != 14 {
+		t.Errorf("link = %+v", link)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "# Report\n\nBody" {
+		t.Errorf("exported file = %q, %v", data, err)
+	}
+}
+
+func TestExportArtifactWithoutExportDir(t *testing.T) {
+	t.Parallel()
+
+	exporter := readyExporter(pb.ArtifactType_ARTIFACT_TYPE_8, "%PDF-1.7")
+	_, _, err := exportArtifact(context.Background(), exporter, exportArtifactInput{
+		NotebookID: "nb1",
+		ArtifactID: "a1",
+	}, exportConfig{MaxEmbedBytes: 4})
+	if err == nil || !strings.Contains(err.Error(), "--export-dir") {
+		t.Fatalf("err = %v, want a hint to configure --export-dir", err)
+	}
+}
+
+func TestExportArtifactAudio(t *testing.T) {
+	t.Parallel()
+
+	exporter := readyExporter(pb.ArtifactType_ARTIFACT_TYPE_AUDIO_OVERVIEW, "")
+	exporter.audio = &notebooklm.AudioOverviewResult{
+		AudioID:   "a1",
+		AudioData: base64.StdEncoding.EncodeToString([]byte("ID3\x04audio")),
+	}
+	result, _, err := exportArtifact(context.Background(), exporter, exportArtifactInput{
+		NotebookID: "nb1",
+		ArtifactID: "a1",
+	}, exportConfig{MaxEmbedBytes: 1 << 10})
+	if err != nil {
+		t.Fatalf("exportArtifact() error = %v", err)
+	}
+	res := result.Content[1].(*mcp.EmbeddedResource).Resource
+	if res.MIMEType != "audio/mpeg" || string(res.Blob) != "ID3\x04audio" {
+		t.Errorf("resource = %+v", res)
+	}
+}
+
+func TestExportArtifactNotReady(t *testing.T) {
+	t.Parallel()
+
+	exporter := &fakeArtifactExporter{fakeArtifactFetcher: fakeArtifactFetcher{
+		lists: [][]*pb.Artifact{{testArtifact(pb.ArtifactState_ARTIFACT_STATE_CREATING, pb.ArtifactType_ARTIFACT_TYPE_8)}},
+	}}
+	_, _, err := exportArtifact(context.Background(), exporter, exportArtifactInput{
+		NotebookID: "nb1",
+		ArtifactID: "a1",
+	}, exportConfig{MaxEmbedBytes: 1 << 10})
+	if err == nil || !strings.Contains(err.Error(), "wait_for_artifact") {
+		t.Fatalf("err = %v, want a not-ready error", err)
+	}
+}
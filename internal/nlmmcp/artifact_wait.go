@@ -0,0 +1,244 @@
+package nlmmcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/exitclass"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+const (
+	defaultArtifactPollInterval = 2 * time.Second
+	maxArtifactPollInterval     = 30 * time.Second
+	defaultArtifactMaxWait      = 15 * time.Minute
+
+	// maxArtifactText bounds the exported text returned for a report.
+	maxArtifactText = 256 << 10
+)
+
+// artifactFetcher is the subset of notebooklm.Client waitForArtifact uses.
+type artifactFetcher interface {
+	ListArtifacts(context.Context, string) ([]*pb.Artifact, error)
+	GetArtifact(context.Context, string) (*pb.Artifact, error)
+	GetArtifactDownloadURLs(context.Context, string) ([]string, error)
+	ReadArtifactFile(ctx context.Context, artifactID, format string, w io.Writer) error
+}
+
+type waitForArtifactInput struct {
+	NotebookID     string `json:"notebook_id,omitempty" jsonschema:"Notebook containing the artifact; polling is cheaper with it, and it is required when the server is limited to notebooks"`
+	ArtifactID     string `json:"artifact_id"`
+	PollIntervalMS int    `json:"poll_interval_ms,omitempty" jsonschema:"Milliseconds before the first re-poll (default 2000); later polls back off to 30 seconds"`
+	MaxWaitSeconds int    `json:"max_wait_seconds,omitempty" jsonschema:"Maximum blocking time in seconds (default 900)"`
+}
+
+type artifactOutput struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title,omitempty"`
+	Type          string   `json:"type"`
+	State         string   `json:"state" jsonschema:"ARTIFACT_STATE_READY once generation has finished"`
+	ViewerURL     string   `json:"viewer_url,omitempty"`
+	DownloadURLs  []string `json:"download_urls,omitempty" jsonschema:"Rendered files; fetching them needs NotebookLM cookies, so use export_artifact to get the contents"`
+	Text          string   `json:"text,omitempty" jsonschema:"Exported Markdown, for reports"`
+	WaitedSeconds int      `json:"waited_seconds"`
+}
+
+// startedArtifact is what a create tool knows about the artifact it
+// started.
+type startedArtifact struct {
+	ID      string
+	Title   string
+	Type    pb.ArtifactType
+	Message string
+}
+
+type artifactWaitProgress struct {
+	Value   float64
+	Message string
+}
+
+// waitForArtifact polls an artifact with backoff until it is ready, fails,
+// or the wait runs out, reporting each poll to notify.
+func waitForArtifact(
+	ctx context.Context,
+	fetcher artifactFetcher,
+	input waitForArtifactInput,
+	notify func(artifactWaitProgress) error,
+) (*artifactOutput, error) {
+	if input.ArtifactID == "" {
+		return nil, badArgs(fmt.Errorf("artifact_id is required"))
+	}
+	if input.PollIntervalMS < 0 {
+		return nil, badArgs(fmt.Errorf("poll_interval_ms must be non-negative"))
+	}
+	if input.MaxWaitSeconds < 0 {
+		return nil, badArgs(fmt.Errorf("max_wait_seconds must be non-negative"))
+	}
+
+	interval := defaultArtifactPollInterval
+	if input.PollIntervalMS != 0 {
+		interval = time.Duration(input.PollIntervalMS) * time.Millisecond
+	}
+	maxWait := defaultArtifactMaxWait
+	if input.MaxWaitSeconds != 0 {
+		maxWait = time.Duration(input.MaxWaitSeconds) * time.Second
+	}
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		artifact, err := fetchArtifact(ctx, fetcher, input.NotebookID, input.ArtifactID)
+		if err != nil && !errors.Is(err, notebooklm.ErrArtifactNotFound) {
+			return nil, fmt.Errorf("get artifact: %w", err)
+		}
+		state := "not listed yet"
+		if artifact != nil {
+			state = artifactStateLabel(artifact.GetState())
+			switch artifact.GetState() {
+			case pb.ArtifactState_ARTIFACT_STATE_READY:
+				result := artifactResult(ctx, fetcher, artifact)
+				result.WaitedSeconds = int(time.Since(start).Seconds())
+				if notify != nil {
+					if err := notify(artifactWaitProgress{Value: float64(attempt), Message: "artifact ready"}); err != nil {
+						return nil, fmt.Errorf("notify completion: %w", err)
+					}
+				}
+				return result, nil
+			case pb.ArtifactState_ARTIFACT_STATE_FAILED:
+				return nil, exitclass.With(exitclass.Precondition, fmt.Errorf("artifact %s failed to generate", input.ArtifactID))
+			}
+		}
+		if notify != nil {
+			if err := notify(artifactWaitProgress{
+				Value:   float64(attempt),
+				Message: fmt.Sprintf("artifact %s after %s", state, time.Since(start).Round(time.Second)),
+			}); err != nil {
+				return nil, fmt.Errorf("notify progress: %w", err)
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			err := fmt.Errorf("wait for artifact %s (last state %s): %w", input.ArtifactID, state, ctx.Err())
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// The artifact is still generating; a later wait may succeed.
+				err = exitclass.With(exitclass.Busy, err)
+			}
+			return nil, err
+		}
+		interval = min(interval*3/2, maxArtifactPollInterval)
+	}
+}
+
+// fetchArtifact looks the artifact up in its notebook when one is given,
+// which avoids GetArtifact's scan of every notebook on its slow path.
+func fetchArtifact(ctx context.Context, fetcher artifactFetcher, notebookID, artifactID string) (*pb.Artifact, error) {
+	if notebookID == "" {
+		return fetcher.GetArtifact(ctx, artifactID)
+	}
+	artifacts, err := fetcher.ListArtifacts(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	for _, artifact := range artifacts {
+		if artifact.GetArtifactId() == artifactID {
+			return artifact, nil
+		}
+	}
+	return nil, notebooklm.ErrArtifactNotFound
+}
+
+// artifactResult describes a ready artifact: its download links and, for
+// reports, the exported Markdown. Both are best effort.
+func artifactResult(ctx context.Context, fetcher artifactFetcher, artifact *pb.Artifact) *artifactOutput {
+	result := &artifactOutput{
+		ID:        artifact.GetArtifactId(),
+		Title:     artifact.GetTitle(),
+		Type:      artifactTypeLabel(artifact.GetType()),
+		State:     artifactStateLabel(artifact.GetState()),
+		ViewerURL: artifact.GetViewerUrl(),
+	}
+	seen := make(map[string]bool)
+	addURL := func(u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			result.DownloadURLs = append(result.DownloadURLs, u)
+		}
+	}
+	if video := artifact.GetVideoPreview(); video != nil {
+		addURL(video.GetVideoUrl())
+		for _, v := range video.GetDownloadVariants() {
+			addURL(v.GetUrl())
+		}
+	}
+	for _, v := range artifact.GetNote().GetDownloadVariants() {
+		addURL(v.GetUrl())
+	}
+	if urls, err := fetcher.GetArtifactDownloadURLs(ctx, result.ID); err == nil {
+		for _, u := range urls {
+			addURL(u)
+		}
+	}
+	if artifact.GetType() == pb.ArtifactType_ARTIFACT_TYPE_REPORT {
+		var buf bytes.Buffer
+		if err := fetcher.ReadArtifactFile(ctx, result.ID, "md", &buf); err == nil {
+			text := buf.String()
+			if len(text) > maxArtifactText {
+				text = text[:maxArtifactText] + "\n\n[truncated]"
+			}
+			result.Text = text
+		}
+	}
+	return result
+}
+
+// artifactProgressNotifier returns a notify function that sends MCP
+// progress notifications when the request asked for them.
+func artifactProgressNotifier(ctx context.Context, req *mcp.CallToolRequest) func(artifactWaitProgress) error {
+	token := req.Params.GetProgressToken()
+	return func(progress artifactWaitProgress) error {
+		if token == nil {
+			return nil
+		}
+		return req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Progress:      progress.Value,
+			Message:       progress.Message,
+		})
+	}
+}
+
+// startedArtifactResult finishes a create tool: the started message, or
+// with wait set, the artifact once it is ready.
+func startedArtifactResult(ctx context.Context, req *mcp.CallToolRequest, fetcher artifactFetcher, notebookID string, started startedArtifact, wait bool, maxWaitSeconds int) (*mcp.CallToolResult, artifactOutput, error) {
+	if !wait {
+		return textResult(started.Message), artifactOutput{
+			ID:    started.ID,
+			Title: started.Title,
+			Type:  artifactTypeLabel(started.Type),
+			State: artifactStateLabel(pb.ArtifactState_ARTIFACT_STATE_CREATING),
+		}, nil
+	}
+	if started.ID == "" {
+		return nil, artifactOutput{}, fmt.Errorf("%s; no artifact id was returned to wait on", started.Message)
+	}
+	result, err := waitForArtifact(ctx, fetcher, waitForArtifactInput{
+		NotebookID:     notebookID,
+		ArtifactID:     started.ID,
+		MaxWaitSeconds: maxWaitSeconds,
+	}, artifactProgressNotifier(ctx, req))
+	if err != nil {
+		return nil, artifactOutput{}, fmt.Errorf("%s; %w", started.Message, err)
+	}
+	return nil, *result, nil
+}
@@ -0,0 +1,119 @@
+package nlmmcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// fakeArtifactFetcher serves one artifact list per ListArtifacts call.
+type fakeArtifactFetcher struct {
+	lists [][]*pb.Artifact
+	calls int
+	urls  []string
+	text  string
+}
+
+func (f *fakeArtifactFetcher) ListArtifacts(context.Context, string) ([]*pb.Artifact, error) {
+	i := f.calls
+	f.calls++
+	if i >= len(f.lists) {
+		return nil, errors.New("unexpected poll")
+	}
+	return f.lists[i], nil
+}
+
+func (f *fakeArtifactFetcher) GetArtifact(ctx context.Context, id string) (*pb.Artifact, error) {
+	artifacts, err := f.ListArtifacts(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range artifacts {
+		if a.GetArtifactId() == id {
+			return a, nil
+		}
+	}
+	return nil, notebooklm.ErrArtifactNotFound
+}
+
+func (f *fakeArtifactFetcher) GetArtifactDownloadURLs(context.Context, string) ([]string, error) {
+	return f.urls, nil
+}
+
+func (f *fakeArtifactFetcher) ReadArtifactFile(_ context.Context, _, _ string, w io.Writer) error {
+	_, err := io.WriteString(w, f.text)
+	return err
+}
+
+func testArtifact(state pb.ArtifactState, typ pb.ArtifactType) *pb.Artifact {
+	return &pb.Artifact{ArtifactId: "a1", Title: "Report", Type: typ, State: state}
+}
+
+func TestWaitForArtifact(t *testing.T) {
+	t.Parallel()
+
+	report := pb.ArtifactType_ARTIFACT_TYPE_REPORT
+	fetcher := &fakeArtifactFetcher{
+		lists: [][]*pb.Artifact{
+			nil, // not listed yet
+			{testArtifact(pb.ArtifactState_ARTIFACT_STATE_CREATING, report)},
+			{testArtifact(pb.ArtifactState_ARTIFACT_STATE_READY, report)},
+		},
+		urls: []string{"https://example.com/report.md"},
+		text: "# Report",
+	}
+	var progress []artifactWaitProgress
+	got, err := waitForArtifact(context.Background(), fetcher, waitForArtifactInput{
+		NotebookID:     "nb1",
+		ArtifactID:     "a1",
+		PollIntervalMS: 1,
+	}, func(p artifactWaitProgress) error {
+		progress = append(progress, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("waitForArtifact() error = %v", err)
+	}
+	if got.State != "ARTIFACT_STATE_READY" || got.Text != "# Report" || len(got.DownloadURLs) != 1 {
+		t.Errorf("result = %+v", got)
+	}
+	if fetcher.calls != 3 || len(progress) != 3 {
+		t.Fatalf("polls = %d, progress = %d, want 3 each", fetcher.calls, len(progress))
+	}
+	if !strings.HasPrefix(progress[0].Message, "artifact not listed yet") ||
+		!strings.HasPrefix(progress[1].Message, "artifact ARTIFACT_STATE_CREATING") ||
+		progress[2].Message != "artifact ready" {
+		t.Errorf("progress = %+v", progress)
+	}
+}
+
+func TestWaitForArtifactFailed(t *testing.T) {
+	t.Parallel()
+
+	fetcher := &fakeArtifactFetcher{lists: [][]*pb.Artifact{
+		{testArtifact(pb.ArtifactState_ARTIFACT_STATE_FAILED, pb.ArtifactType_ARTIFACT_TYPE_AUDIO_OVERVIEW)},
+	}}
+	_, err := waitForArtifact(context.Background(), fetcher, waitForArtifactInput{ArtifactID: "a1"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to generate") {
+		t.Fatalf("err = %v, want generation failure", err)
+	}
+}
+
+func TestWaitForArtifactStopsOnContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fetcher := &fakeArtifactFetcher{lists: [][]*pb.Artifact{
+		{testArtifact(pb.ArtifactState_ARTIFACT_STATE_CREATING, pb.ArtifactType_ARTIFACT_TYPE_AUDIO_OVERVIEW)},
+	}}
+	_, err := waitForArtifact(ctx, fetcher, waitForArtifactInput{NotebookID: "nb1", ArtifactID: "a1"}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
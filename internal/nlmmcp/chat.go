@@ -0,0 +1,159 @@
+package nlmmcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tmc/nlm/internal/richrender"
+	"github.com/tmc/nlm/internal/sourceselect"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// chatClient is the subset of notebooklm.Client the chat tool uses.
+type chatClient interface {
+	sourceselect.Client
+	GetConversationHistory(ctx context.Context, projectID, conversationID string) ([]notebooklm.ChatMessage, error)
+	StreamChat(ctx context.Context, req notebooklm.ChatRequest, callback func(notebooklm.ChatChunk) bool) error
+}
+
+type chatInput struct {
+	NotebookID     string   `json:"notebook_id"`
+	Prompt         string   `json:"prompt"`
+	ConversationID string   `json:"conversation_id,omitempty" jsonschema:"Conversation to continue, as returned by an earlier call; omit to start a new one"`
+	SourceIDs      []string `json:"source_ids,omitempty" jsonschema:"Source IDs to ground the answer in; defaults to all notebook sources"`
+	SourceMatch    string   `json:"source_match,omitempty" jsonschema:"Regular expression over source titles and IDs; matching sources are added to the selection"`
+	LabelMatch     string   `json:"label_match,omitempty" jsonschema:"Regular expression over label names; sources with a matching label are added to the selection"`
+}
+
+type chatOutput struct {
+	ConversationID string         `json:"conversation_id"`
+	Answer         string         `json:"answer"`
+	Citations      []chatCitation `json:"citations,omitempty"`
+	FollowUps      []string       `json:"follow_ups,omitempty"`
+	SourceIDs      []string       `json:"source_ids,omitempty" jsonschema:"Sources the answer was restricted to; empty when all sources were used"`
+}
+
+type chatCitation struct {
+	Index      int     `json:"index" jsonschema:"The [N] marker in the answer text"`
+	SourceID   string  `json:"source_id,omitempty" jsonschema:"Notebook source ID the cited passage belongs to"`
+	Title      string  `json:"title,omitempty"`
+	Excerpt    string  `json:"excerpt,omitempty"`
+	StartChar  int     `json:"start_char" jsonschema:"Start offset of the cited range in the answer"`
+	EndChar    int     `json:"end_char" jsonschema:"End offset of the cited range in the answer"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+type chatProgress struct {
+	Value   float64
+	Message string
+}
+
+// chat sends input.Prompt and collects the streamed answer. notify is called
+// with each new stretch of answer text; its Value is the answer length so
+// far, so it only increases.
+func chat(ctx context.Context, client chatClient, input chatInput, notify func(chatProgress) error) (chatOutput, error) {
+	if input.NotebookID == "" {
+		return chatOutput{}, badArgs(fmt.Errorf("notebook_id is required"))
+	}
+	if strings.TrimSpace(input.Prompt) == "" {
+		return chatOutput{}, badArgs(fmt.Errorf("prompt is required"))
+	}
+
+	project, err := client.GetProject(ctx, input.NotebookID)
+	if err != nil {
+		return chatOutput{}, fmt.Errorf("get notebook: %w", err)
+	}
+	titles := make(map[string]string, len(project.GetSources()))
+	for _, src := range project.GetSources() {
+		titles[src.GetSourceId().GetSourceId()] = strings.TrimSpace(src.GetTitle())
+	}
+	sourceIDs, err := sourceselect.Select(ctx, client, input.NotebookID, sourceselect.Options{
+		SourceMatch: input.SourceMatch,
+		LabelMatch:  input.LabelMatch,
+	}, input.SourceIDs, nil, io.Discard)
+	if err != nil {
+		return chatOutput{}, err
+	}
+
+	req := notebooklm.ChatRequest{
+		ProjectID:      input.NotebookID,
+		Prompt:         input.Prompt,
+		SourceIDs:      sourceIDs,
+		ConversationID: input.ConversationID,
+	}
+	if req.ConversationID == "" {
+		// Mint the ID here so it can be returned for follow-up calls.
+		req.ConversationID = uuid.New().String()
+	} else if msgs, err := client.GetConversationHistory(ctx, input.NotebookID, req.ConversationID); err == nil {
+		// The server history is authoritative for the sequence number. When
+		// it cannot be fetched the conversation continues by ID alone.
+		for i := len(msgs) - 1; i >= 0; i-- {
+			req.History = append(req.History, msgs[i])
+		}
+		req.SeqNum = len(msgs) + 1
+	}
+
+	renderer := richrender.NewStreamRenderer(io.Discard, io.Discard, richrender.StreamOptions{
+		Mode: richrender.CitationModeOff,
+	})
+	var notifyErr error
+	sent := 0
+	err = client.StreamChat(ctx, req, func(chunk notebooklm.ChatChunk) bool {
+		renderer.WriteChunk(chunk)
+		answer := renderer.Answer()
+		if notify == nil || len(answer) <= sent {
+			return true
+		}
+		if notifyErr = notify(chatProgress{Value: float64(len(answer)), Message: answer[sent:]}); notifyErr != nil {
+			return false
+		}
+		sent = len(answer)
+		return true
+	})
+	renderer.Finish()
+	if notifyErr != nil {
+		return chatOutput{}, fmt.Errorf("notify progress: %w", notifyErr)
+	}
+	if err != nil {
+		return chatOutput{}, fmt.Errorf("stream chat: %w", err)
+	}
+	if ctx.Err() != nil {
+		return chatOutput{}, ctx.Err()
+	}
+
+	answer := strings.TrimSpace(renderer.Answer())
+	if answer == "" {
+		// The stream parser files an answer that opens with a bold header
+		// under thinking when the server sends no phase tag.
+		answer = strings.TrimSpace(renderer.Thinking())
+	}
+	if answer == "" {
+		return chatOutput{}, fmt.Errorf("empty response; check the notebook's sources with list_sources")
+	}
+	out := chatOutput{
+		ConversationID: req.ConversationID,
+		Answer:         answer,
+		FollowUps:      renderer.FollowUps(),
+		SourceIDs:      sourceIDs,
+	}
+	resolveTitle := func(id string) string { return titles[id] }
+	for _, c := range renderer.Citations() {
+		cite := chatCitation{
+			Index:      c.SourceIndex,
+			SourceID:   c.ParentSourceID,
+			Title:      c.Title,
+			Excerpt:    c.Excerpt,
+			StartChar:  c.StartChar,
+			EndChar:    c.EndChar,
+			Confidence: c.Confidence,
+		}
+		if cite.Title == "" {
+			cite.Title = richrender.CitationTitle(c, resolveTitle)
+		}
+		out.Citations = append(out.Citations, cite)
+	}
+	return out, nil
+}
@@ -0,0 +1,136 @@
+package nlmmcp
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+type fakeChatClient struct {
+	project *notebooklm.Notebook
+	labels  []notebooklm.Label
+	history []notebooklm.ChatMessage
+	chunks  []notebooklm.ChatChunk
+	req     notebooklm.ChatRequest
+}
+
+func (f *fakeChatClient) GetProject(context.Context, string) (*notebooklm.Notebook, error) {
+	return f.project, nil
+}
+
+func (f *fakeChatClient) GetLabels(context.Context, string) ([]notebooklm.Label, error) {
+	return f.labels, nil
+}
+
+func (f *fakeChatClient) GetConversationHistory(context.Context, string, string) ([]notebooklm.ChatMessage, error) {
+	return f.history, nil
+}
+
+func (f *fakeChatClient) StreamChat(ctx context.Context, req notebooklm.ChatRequest, callback func(notebooklm.ChatChunk) bool) error {
+	f.req = req
+	for _, chunk := range f.chunks {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !callback(chunk) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func newFakeChatClient() *fakeChatClient {
+	return &fakeChatClient{
+		project: &notebooklm.Notebook{Sources: []*pb.Source{
+			{SourceId: &pb.SourceId{SourceId: "s-spec"}, Title: "spec/api.md"},
+			{SourceId: &pb.SourceId{SourceId: "s-impl"}, Title: "impl/server.go"},
+		}},
+		labels: []notebooklm.Label{{LabelID: "l1", Name: "Server", SourceIDs: []string{"s-impl"}}},
+		chunks: []notebooklm.ChatChunk{
+			{Phase: notebooklm.ChatChunkThinking, Text: "**Reading**"},
+			{Phase: notebooklm.ChatChunkAnswer, Text: "The API "},
+			{Phase: notebooklm.ChatChunkAnswer, Text: "is versioned [1].", Citations: []notebooklm.Citation{{
+				SourceIndex: 1, SourceID: "chunk-9", ParentSourceID: "s-spec",
+				StartChar: 8, EndChar: 25, Excerpt: "All routes carry /v1.", Confidence: 0.9,
+			}}, FollowUps: []string{"Which versions exist?"}},
+		},
+	}
+}
+
+func TestChatCollectsAnswerCitationsAndProgress(t *testing.T) {
+	t.Parallel()
+
+	fc := newFakeChatClient()
+	var progress []chatProgress
+	out, err := chat(context.Background(), fc, chatInput{NotebookID: "nb", Prompt: "How is the API versioned?"}, func(p chatProgress) error {
+		progress = append(progress, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Answer != "The API is versioned [1]." {
+		t.Errorf("answer = %q", out.Answer)
+	}
+	if out.ConversationID == "" || fc.req.ConversationID != out.ConversationID {
+		t.Errorf("conversation_id = %q, request used %q", out.ConversationID, fc.req.ConversationID)
+	}
+	want := []chatCitation{{Index: 1, SourceID: "s-spec", Title: "spec/api.md", Excerpt: "All routes carry /v1.", StartChar: 8, EndChar: 25, Confidence: 0.9}}
+	if !reflect.DeepEqual(out.Citations, want) {
+		t.Errorf("citations = %+v, want %+v", out.Citations, want)
+	}
+	if !reflect.DeepEqual(out.FollowUps, []string{"Which versions exist?"}) {
+		t.Errorf("follow_ups = %v", out.FollowUps)
+	}
+	wantProgress := []chatProgress{{Value: 8, Message: "The API "}, {Value: 25, Message: "is versioned [1]."}}
+	if !reflect.DeepEqual(progress, wantProgress) {
+		t.Errorf("progress = %+v, want %+v", progress, wantProgress)
+	}
+}
+
+func TestChatSelectorsAndConversation(t *testing.T) {
+	t.Parallel()
+
+	fc := newFakeChatClient()
+	fc.history = []notebooklm.ChatMessage{
+		{Role: 1, Content: "first question"},
+		{Role: 2, Content: "first answer"},
+	}
+	out, err := chat(context.Background(), fc, chatInput{
+		NotebookID:     "nb",
+		Prompt:         "And the server?",
+		ConversationID: "conv-1",
+		SourceMatch:    "^spec/",
+		LabelMatch:     "^Server$",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"s-spec", "s-impl"}; !reflect.DeepEqual(fc.req.SourceIDs, want) || !reflect.DeepEqual(out.SourceIDs, want) {
+		t.Errorf("source IDs = %v (output %v), want %v", fc.req.SourceIDs, out.SourceIDs, want)
+	}
+	if out.ConversationID != "conv-1" || fc.req.SeqNum != 3 {
+		t.Errorf("conversation = %q seq %d, want conv-1 seq 3", out.ConversationID, fc.req.SeqNum)
+	}
+	if len(fc.req.History) != 2 || fc.req.History[0].Content != "first answer" {
+		t.Errorf("history not newest-first: %+v", fc.req.History)
+	}
+
+	if _, err := chat(context.Background(), fc, chatInput{NotebookID: "nb", Prompt: "q", SourceMatch: "^none/"}, nil); err == nil || !strings.Contains(err.Error(), "matched no sources") {
+		t.Errorf("unmatched selector: err = %v", err)
+	}
+}
+
+func TestChatCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := chat(ctx, newFakeChatClient(), chatInput{NotebookID: "nb", Prompt: "q"}, nil); err == nil {
+		t.Fatal("cancelled chat: want error")
+	}
+}
@@ -0,0 +1,267 @@
+package nlmmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CommandTool describes a CLI command exposed as a tool. Its input schema
+// is built from Operands and Flags, and a call is turned back into command
+// line arguments for Options.RunCommand.
+type CommandTool struct {
+	Name        string
+	Path        []string // command path, such as ["source", "sync"]
+	Description string
+
+	// Operands are the command's positional arguments in command-line
+	// order. Commands whose positionals do not map onto named operands set
+	// Args to their usage instead, and take a single "args" array.
+	Operands []CommandOperand
+	Args     string
+
+	Flags []CommandFlag
+	Fixed []string // arguments always passed after Path, such as --yes
+
+	ReadOnly    bool
+	Destructive bool
+}
+
+// CommandOperand is a positional argument of a CommandTool.
+type CommandOperand struct {
+	Name        string // input property name
+	Description string
+	Required    bool
+	Repeated    bool
+}
+
+// CommandFlag is a flag of a CommandTool. Its input property is the flag
+// name with dashes replaced by underscores.
+type CommandFlag struct {
+	Name        string // flag name without leading dashes
+	Description string
+	Bool        bool
+}
+
+// CommandRunner runs the CLI with args, using the credentials sel names,
+// and returns what it printed.
+type CommandRunner func(ctx context.Context, sel Selection, args []string) (string, error)
+
+const commandArgsProperty = "args"
+
+// commandOutput is the structured result of a command tool.
+type commandOutput struct {
+	Output string `json:"output" jsonschema:"What the command printed"`
+}
+
+func flagProperty(f CommandFlag) string {
+	return strings.ReplaceAll(f.Name, "-", "_")
+}
+
+func (t CommandTool) inputSchema() *jsonschema.Schema {
+	schema := &jsonschema.Schema{Type: "object", Properties: make(map[string]*jsonschema.Schema)}
+	if t.Args != "" {
+		schema.Properties[commandArgsProperty] = &jsonschema.Schema{
+			Type:        "array",
+			Items:       &jsonschema.Schema{Type: "string"},
+			Description: "Positional arguments, as on the command line: " + t.Args,
+		}
+	}
+	for _, op := range t.Operands {
+		prop := &jsonschema.Schema{Type: "string", Description: op.Description}
+		if op.Repeated {
+			prop = &jsonschema.Schema{Type: "array", Items: &jsonschema.Schema{Type: "string"}, Description: op.Description}
+		}
+		schema.Properties[op.Name] = prop
+		if op.Required {
+			schema.Required = append(schema.Required, op.Name)
+		}
+	}
+	for _, f := range t.Flags {
+		typ := "string"
+		if f.Bool {
+			typ = "boolean"
+		}
+		schema.Properties[flagProperty(f)] = &jsonschema.Schema{Type: typ, Description: f.Description}
+	}
+	return schema
+}
+
+func (t CommandTool) annotations() *mcp.ToolAnnotations {
+	switch {
+	case t.ReadOnly:
+		return readOnlyAnnotations
+	case t.Destructive:
+		return destructiveAnnotations
+	}
+	return mutatingAnnotations
+}
+
+// commandArgs turns tool arguments into command-line arguments: the path,
+// the fixed arguments, then flags in --name=value form, then positionals.
+// The CLI parses flags after positionals too, so a positional that starts
+// with "-" is rejected rather than passed on, where it could set a flag
+// the tool leaves out.
+func (t CommandTool) commandArgs(raw json.RawMessage) ([]string, error) {
+	var input map[string]any
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &input); err != nil {
+			return nil, fmt.Errorf("decode arguments: %w", err)
+		}
+	}
+	known := make(map[string]bool)
+	args := append(append([]string(nil), t.Path...), t.Fixed...)
+	for _, f := range t.Flags {
+		name := flagProperty(f)
+		known[name] = true
+		v, ok := input[name]
+		if !ok || v == nil {
+			continue
+		}
+		if f.Bool {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a boolean", name)
+			}
+			args = append(args, fmt.Sprintf("--%s=%t", f.Name, b))
+			continue
+		}
+		s, err := stringArgument(name, v)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--"+f.Name+"="+s)
+	}
+
+	var positionals []string
+	if t.Args != "" {
+		known[commandArgsProperty] = true
+		values, err := stringsArgument(commandArgsProperty, input[commandArgsProperty])
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPositionals(commandArgsProperty, values...); err != nil {
+			return nil, err
+		}
+		positionals = values
+	}
+	for _, op := range t.Operands {
+		known[op.Name] = true
+		v, ok := input[op.Name]
+		if !ok || v == nil {
+			if op.Required {
+				return nil, fmt.Errorf("%s is required", op.Name)
+			}
+			continue
+		}
+		if op.Repeated {
+			values, err := stringsArgument(op.Name, v)
+			if err != nil {
+				return nil, err
+			}
+			if op.Required && len(values) == 0 {
+				return nil, fmt.Errorf("%s is required", op.Name)
+			}
+			if err := checkPositionals(op.Name, values...); err != nil {
+				return nil, err
+			}
+			positionals = append(positionals, values...)
+			continue
+		}
+		s, err := stringArgument(op.Name, v)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPositionals(op.Name, s); err != nil {
+			return nil, err
+		}
+		positionals = append(positionals, s)
+	}
+	for name := range input {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+	}
+	return append(args, positionals...), nil
+}
+
+// checkPositionals rejects values the CLI would read as flags.
+func checkPositionals(name string, values ...string) error {
+	for _, v := range values {
+		if strings.HasPrefix(v, "-") {
+			return fmt.Errorf("%s: %q starts with \"-\"; pass flags as named arguments", name, v)
+		}
+	}
+	return nil
+}
+
+func stringArgument(name string, v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("%s must be a string", name)
+}
+
+func stringsArgument(name string, v any) ([]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		s, err := stringArgument(name, item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// registerCommandTools adds a tool for each command. It runs before the
+// built-in tools are registered, so a built-in tool of the same name
+// replaces the generated one.
+func registerCommandTools(r *toolRegistry, opts *Options, accounts *accountSet) {
+	if opts.RunCommand == nil {
+		return
+	}
+	outputSchema, err := jsonschema.For[commandOutput](nil)
+	if err != nil {
+		panic(fmt.Sprintf("command output schema: %v", err))
+	}
+	for _, cmd := range opts.Commands {
+		tool := &mcp.Tool{
+			Name:         cmd.Name,
+			Description:  cmd.Description,
+			InputSchema:  cmd.inputSchema(),
+			OutputSchema: outputSchema,
+			Annotations:  cmd.annotations(),
+		}
+		r.add(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args, err := cmd.commandArgs(req.Params.Arguments)
+			if err != nil {
+				return toolErrorResult(badArgs(err)), nil
+			}
+			out, err := opts.RunCommand(ctx, accounts.account(ctx).Selection, args)
+			if err != nil {
+				return toolErrorResult(err), nil
+			}
+			if strings.TrimSpace(out) == "" {
+				out = "ok"
+			}
+			res := textResult(out)
+			res.StructuredContent = commandOutput{Output: out}
+			return res, nil
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package nlmmcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCommandToolArgs(t *testing.T) {
+	tool := CommandTool{
+		Name:     "source_read",
+		Path:     []string{"source", "read"},
+		Operands: []CommandOperand{{Name: "notebook_id", Required: true}, {Name: "source_id", Required: true}},
+		Flags:    []CommandFlag{{Name: "format"}, {Name: "dry-run", Bool: true}},
+		Fixed:    []string{"--yes"},
+	}
+	got, err := tool.commandArgs(json.RawMessage(`{"notebook_id":"nb","source_id":"s1","format":"markdown","dry_run":false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"source", "read", "--yes", "--format=markdown", "--dry-run=false", "nb", "s1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+
+	schema := tool.inputSchema()
+	if schema.Properties["dry_run"].Type != "boolean" || !reflect.DeepEqual(schema.Required, []string{"notebook_id", "source_id"}) {
+		t.Errorf("schema = %+v", schema)
+	}
+
+	for _, bad := range []string{
+		`{"notebook_id":"nb"}`,
+		`{"notebook_id":"nb","source_id":"s1","dry_run":"yes"}`,
+		`{"notebook_id":"nb","source_id":"s1","other":1}`,
+	} {
+		if _, err := tool.commandArgs(json.RawMessage(bad)); err == nil {
+			t.Errorf("commandArgs(%s): want error", bad)
+		}
+	}
+}
+
+func TestCommandToolArgsRejectFlags(t *testing.T) {
+	single := CommandTool{Path: []string{"sync-pack"}, Operands: []CommandOperand{{Name: "path", Required: true}}}
+	repeated := CommandTool{Path: []string{"rm"}, Operands: []CommandOperand{{Name: "notebook_ids", Required: true, Repeated: true}}}
+	args := CommandTool{Path: []string{"artifact", "export"}, Args: "<artifact-id>"}
+	tests := []struct {
+		tool  CommandTool
+		input string
+	}{
+		{single, `{"path":"--pre-process=touch /tmp/x"}`},
+		{single, `{"path":"-o"}`},
+		{repeated, `{"notebook_ids":["nb","--output=/tmp/x"]}`},
+		{args, `{"args":["abc","--output=/tmp/x"]}`},
+		{args, `{"args":["-"]}`},
+	}
+	for _, tt := range tests {
+		if got, err := tt.tool.commandArgs(json.RawMessage(tt.input)); err == nil {
+			t.Errorf("%v commandArgs(%s) = %q, want error", tt.tool.Path, tt.input, got)
+		}
+	}
+	if _, err := args.commandArgs(json.RawMessage(`{"args":["abc","a-b"]}`)); err != nil {
+		t.Errorf("commandArgs with a dash inside an argument: %v", err)
+	}
+}
+
+func TestCommandToolsMutatingFromAnnotations(t *testing.T) {
+	opts := &Options{
+		Commands: []CommandTool{
+			{Name: "source_read", Path: []string{"source", "read"}, ReadOnly: true},
+			{Name: "source_rename", Path: []string{"source", "rename"}},
+			{Name: "source_delete", Path: []string{"source", "delete"}, Destructive: true},
+		},
+		RunCommand: func(context.Context, Selection, []string) (string, error) { return "", nil },
+	}
+	r := newToolRegistry(mcp.NewServer(&mcp.Implementation{Name: "test"}, nil), nil)
+	accounts := newAccountSet(nil, opts)
+	registerCommandTools(r, opts, accounts)
+	registerTools(r, accounts)
+
+	want := map[string]bool{
+		"source_read":    false,
+		"source_rename":  true,
+		"source_delete":  true,
+		"list_notebooks": false,
+		"delete_source":  true,
+		"create_note":    true,
+	}
+	for name, mutating := range want {
+		if got, ok := r.mutating[name]; !ok || got != mutating {
+			t.Errorf("mutating[%q] = %v, %v; want %v", name, got, ok, mutating)
+		}
+	}
+}
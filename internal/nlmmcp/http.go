@@ -0,0 +1,221 @@
+package nlmmcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// Headers a client may send when opening a session to choose its
+// credentials. They are honored only when the session's bearer token does
+// not already fix a selection.
+const (
+	ProfileHeader  = "X-NLM-Profile"
+	AuthUserHeader = "X-NLM-Authuser"
+)
+
+// HTTPPath is where the streamable HTTP transport is served; /healthz
+// answers unauthenticated liveness probes.
+const HTTPPath = "/mcp"
+
+// Selection names the credentials an HTTP session uses. The zero value
+// selects the server's default credentials.
+type Selection struct {
+	Profile  string // a stored credential profile
+	AuthUser string // a Google account index or email
+}
+
+// HTTPOptions configures NewHTTPHandler and ServeHTTP.
+type HTTPOptions struct {
+	// Tokens maps each accepted bearer token to the credentials its
+	// sessions use. A token mapped to the zero Selection lets the client
+	// choose with ProfileHeader and AuthUserHeader. With no tokens,
+	// requests are not authenticated.
+	Tokens map[string]Selection
+
+	// NewClient returns the client for a selection. It is called once per
+	// distinct selection; sessions with the same selection share a client.
+	NewClient func(Selection) (*notebooklm.Client, error)
+
+	// Server configures each session's server. Its Selection is replaced
+	// by the session's, and its Accounts are dropped.
+	Server Options
+
+	// ShutdownTimeout bounds how long ServeHTTP waits for open requests
+	// once its context is done. Zero means 10 seconds.
+	ShutdownTimeout time.Duration
+}
+
+type (
+	sessionClientKey    struct{}
+	sessionSelectionKey struct{}
+)
+
+type httpHandler struct {
+	opts HTTPOptions
+
+	mu      sync.Mutex
+	clients map[Selection]*notebooklm.Client
+}
+
+// NewHTTPHandler returns a handler serving the MCP server over the
+// streamable HTTP transport at HTTPPath, and a health check at /healthz.
+// Each session gets its own server, bound to the client its selection
+// names when the session is opened.
+func NewHTTPHandler(opts HTTPOptions) http.Handler {
+	h := &httpHandler{opts: opts, clients: make(map[Selection]*notebooklm.Client)}
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(h.server, nil)
+	handler = h.selectClient(handler)
+	if len(opts.Tokens) > 0 {
+		handler = auth.RequireBearerToken(h.verifyToken, nil)(handler)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(HTTPPath, handler)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// ServeHTTP serves NewHTTPHandler on ln until ctx is done, then shuts down
+// gracefully. It refuses to serve without bearer tokens on a listener that
+// is reachable from other hosts.
+func ServeHTTP(ctx context.Context, ln net.Listener, opts HTTPOptions) error {
+	if len(opts.Tokens) == 0 && !isLoopback(ln.Addr()) {
+		ln.Close()
+		return fmt.Errorf("refusing to serve %s without a bearer token; configure a token or listen on a loopback address", ln.Addr())
+	}
+	srv := &http.Server{
+		Handler:           NewHTTPHandler(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	timeout := opts.ShutdownTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// Event streams stay open until their client goes away, so a shutdown
+	// that outlives the timeout closes them.
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// verifyToken accepts the configured tokens. The token's digest is the
+// user ID, which the transport uses to keep a session to one token.
+func (h *httpHandler) verifyToken(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+	for want, sel := range h.opts.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 {
+			continue
+		}
+		sum := sha256.Sum256([]byte(want))
+		return &auth.TokenInfo{
+			UserID:     hex.EncodeToString(sum[:8]),
+			Expiration: time.Now().Add(time.Hour),
+			Extra:      map[string]any{"selection": sel},
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown bearer token", auth.ErrInvalidToken)
+}
+
+// selectClient resolves the client for requests that open a session.
+// Requests within a session keep the client it was opened with.
+func (h *httpHandler) selectClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Mcp-Session-Id") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sel, err := requestSelection(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		client, err := h.client(sel)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ctx := context.WithValue(r.Context(), sessionClientKey{}, client)
+		ctx = context.WithValue(ctx, sessionSelectionKey{}, sel)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestSelection returns the selection a token fixes, or the one the
+// request's headers name when the token leaves it open.
+func requestSelection(r *http.Request) (Selection, error) {
+	headers := Selection{
+		Profile:  r.Header.Get(ProfileHeader),
+		AuthUser: r.Header.Get(AuthUserHeader),
+	}
+	info := auth.TokenInfoFromContext(r.Context())
+	if info == nil {
+		return headers, nil
+	}
+	fixed, _ := info.Extra["selection"].(Selection)
+	if fixed == (Selection{}) {
+		return headers, nil
+	}
+	if headers != (Selection{}) && headers != fixed {
+		return Selection{}, fmt.Errorf("bearer token is bound to its credentials; drop the %s and %s headers", ProfileHeader, AuthUserHeader)
+	}
+	return fixed, nil
+}
+
+func (h *httpHandler) client(sel Selection) (*notebooklm.Client, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if c, ok := h.clients[sel]; ok {
+		return c, nil
+	}
+	c, err := h.opts.NewClient(sel)
+	if err != nil {
+		return nil, err
+	}
+	h.clients[sel] = c
+	return c, nil
+}
+
+func (h *httpHandler) server(r *http.Request) *mcp.Server {
+	client, _ := r.Context().Value(sessionClientKey{}).(*notebooklm.Client)
+	if client == nil {
+		return nil
+	}
+	opts := h.opts.Server
+	opts.Selection, _ = r.Context().Value(sessionSelectionKey{}).(Selection)
+	// A session acts only as the credentials it was opened with.
+	opts.Accounts = nil
+	return New(client, &opts)
+}
@@ -0,0 +1,164 @@
+package nlmmcp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// headerTransport adds fixed headers to every request.
+type headerTransport map[string]string
+
+func (t headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	for k, v := range t {
+		r.Header.Set(k, v)
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newTestHTTPServer(t *testing.T, tokens map[string]Selection) (*httptest.Server, func() []Selection) {
+	t.Helper()
+	var mu sync.Mutex
+	var selected []Selection
+	srv := httptest.NewServer(NewHTTPHandler(HTTPOptions{
+		Tokens: tokens,
+		NewClient: func(sel Selection) (*notebooklm.Client, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			selected = append(selected, sel)
+			return notebooklm.New(notebooklm.Credentials{AuthToken: "t", Cookies: "c"}), nil
+		},
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []Selection {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Selection(nil), selected...)
+	}
+}
+
+func connectHTTP(ctx context.Context, srv *httptest.Server, headers map[string]string) (*mcp.ClientSession, error) {
+	transport := &mcp.StreamableClientTransport{
+		Endpoint:   srv.URL + HTTPPath,
+		HTTPClient: &http.Client{Transport: headerTransport(headers)},
+	}
+	return mcp.NewClient(&mcp.Implementation{Name: "client"}, nil).Connect(ctx, transport, nil)
+}
+
+func TestHTTPHealth(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := newTestHTTPServer(t, map[string]Selection{"secret": {}})
+	resp, err := http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok\n" {
+		t.Errorf("healthz = %d %q, want 200 \"ok\\n\"", resp.StatusCode, body)
+	}
+}
+
+func TestHTTPBearerTokenAndSelection(t *testing.T) {
+	t.Parallel()
+
+	srv, selected := newTestHTTPServer(t, map[string]Selection{
+		"open":  {},
+		"bound": {Profile: "work"},
+	})
+	ctx := context.Background()
+
+	if _, err := connectHTTP(ctx, srv, nil); err == nil {
+		t.Error("connect without token: want error")
+	}
+	if _, err := connectHTTP(ctx, srv, map[string]string{"Authorization": "Bearer wrong"}); err == nil {
+		t.Error("connect with unknown token: want error")
+	}
+	if _, err := connectHTTP(ctx, srv, map[string]string{
+		"Authorization": "Bearer bound",
+		AuthUserHeader:  "2",
+	}); err == nil {
+		t.Error("bound token with a different selection: want error")
+	}
+
+	for _, headers := range []map[string]string{
+		{"Authorization": "Bearer open", AuthUserHeader: "1"},
+		{"Authorization": "Bearer bound"},
+		{"Authorization": "Bearer open", AuthUserHeader: "1"},
+	} {
+		cs, err := connectHTTP(ctx, srv, headers)
+		if err != nil {
+			t.Fatalf("connect %v: %v", headers, err)
+		}
+		tools, err := cs.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tools.Tools) == 0 {
+			t.Error("no tools listed")
+		}
+		cs.Close()
+	}
+	got := selected()
+	if len(got) != 2 || got[0] != (Selection{AuthUser: "1"}) || got[1] != (Selection{Profile: "work"}) {
+		t.Errorf("clients created for %+v, want one each for authuser 1 and profile work", got)
+	}
+}
+
+func TestServeHTTPRequiresTokenOffLoopback(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	err = ServeHTTP(context.Background(), ln, HTTPOptions{})
+	if err == nil || !strings.Contains(err.Error(), "without a bearer token") {
+		t.Errorf("err = %v, want refusal", err)
+	}
+}
+
+func TestServeHTTPShutdown(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- ServeHTTP(ctx, ln, HTTPOptions{ShutdownTimeout: time.Second}) }()
+
+	url := "http://" + ln.Addr().String() + "/healthz"
+	var resp *http.Response
+	for range 50 {
+		if resp, err = http.Get(url); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	cancel()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("ServeHTTP = %v, want nil after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeHTTP did not return after cancel")
+	}
+}
@@ -0,0 +1,136 @@
+package nlmmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Policy restricts what a server exposes. The zero value, like a nil
+// *Policy, allows everything.
+type Policy struct {
+	// ReadOnly registers only the tools annotated as read-only.
+	ReadOnly bool
+
+	// Tools, when set, registers only the tools matching one of these
+	// path.Match patterns, such as "list_*".
+	Tools []string
+
+	// Deny leaves out the tools matching one of these patterns. It applies
+	// after Tools.
+	Deny []string
+
+	// Notebooks, when set, confines tools and resources to these notebook
+	// IDs. Tools that name no notebook, other than list_notebooks and
+	// list_accounts, are refused, since what they touch cannot be checked.
+	Notebooks []string
+}
+
+// Validate reports malformed tool patterns.
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, pattern := range slices.Concat(p.Tools, p.Deny) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad tool pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// allowsTool reports whether t should be registered.
+func (p *Policy) allowsTool(t *mcp.Tool) bool {
+	if p == nil {
+		return true
+	}
+	if p.ReadOnly && (t.Annotations == nil || !t.Annotations.ReadOnlyHint) {
+		return false
+	}
+	if len(p.Tools) > 0 && !matchesAny(p.Tools, t.Name) {
+		return false
+	}
+	return !matchesAny(p.Deny, t.Name)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) scoped() bool { return p != nil && len(p.Notebooks) > 0 }
+
+// allowsNotebook reports whether the notebook is in scope.
+func (p *Policy) allowsNotebook(id string) bool {
+	return !p.scoped() || slices.Contains(p.Notebooks, id)
+}
+
+// scopeMiddleware enforces policy.Notebooks on tool calls, prompts and
+// resource requests. list_notebooks filters its own results.
+func scopeMiddleware(policy *Policy) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		if !policy.scoped() {
+			return next
+		}
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch r := req.(type) {
+			case *mcp.CallToolRequest:
+				if err := policy.checkToolCall(r.Params.Name, r.Params.Arguments); err != nil {
+					return toolErrorResult(badArgs(err)), nil
+				}
+			case *mcp.GetPromptRequest:
+				if id := r.Params.Arguments["notebook_id"]; id != "" && !policy.allowsNotebook(id) {
+					return nil, fmt.Errorf("notebook %s is outside this server's scope", id)
+				}
+			case *mcp.ReadResourceRequest:
+				if err := policy.checkResource(r.Params.URI); err != nil {
+					return nil, err
+				}
+			case *mcp.SubscribeRequest:
+				if err := policy.checkResource(r.Params.URI); err != nil {
+					return nil, err
+				}
+			}
+			result, err := next(ctx, method, req)
+			if list, ok := result.(*mcp.ListResourcesResult); ok && err == nil {
+				list.Resources = slices.DeleteFunc(list.Resources, func(res *mcp.Resource) bool {
+					return policy.checkResource(res.URI) != nil
+				})
+			}
+			return result, err
+		}
+	}
+}
+
+func (p *Policy) checkToolCall(name string, arguments json.RawMessage) error {
+	if name == "list_notebooks" || name == "list_accounts" {
+		return nil
+	}
+	var args struct {
+		NotebookID string `json:"notebook_id"`
+	}
+	_ = json.Unmarshal(arguments, &args)
+	if args.NotebookID == "" {
+		return fmt.Errorf("%s is unavailable: this server is limited to notebooks %v", name, p.Notebooks)
+	}
+	if !p.allowsNotebook(args.NotebookID) {
+		return fmt.Errorf("notebook %s is outside this server's scope", args.NotebookID)
+	}
+	return nil
+}
+
+func (p *Policy) checkResource(uri string) error {
+	ref, ok := parseResourceURI(uri)
+	if !ok || !p.allowsNotebook(ref.NotebookID) {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	return nil
+}
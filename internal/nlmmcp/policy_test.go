@@ -0,0 +1,109 @@
+package nlmmcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func connectPolicy(t *testing.T, policy *Policy) *mcp.ClientSession {
+	t.Helper()
+	return connectOptions(t, &Options{Policy: policy})
+}
+
+func connectOptions(t *testing.T, opts *Options) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	ss, err := New(nil, opts).Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func TestPolicyAllowsTool(t *testing.T) {
+	t.Parallel()
+
+	read := &mcp.Tool{Name: "list_sources", Annotations: readOnlyAnnotations}
+	del := &mcp.Tool{Name: "delete_source", Annotations: destructiveAnnotations}
+	chat := &mcp.Tool{Name: "chat", Annotations: mutatingAnnotations}
+	tests := []struct {
+		name   string
+		policy *Policy
+		tool   *mcp.Tool
+		want   bool
+	}{
+		{"nil policy", nil, del, true},
+		{"read-only keeps reads", &Policy{ReadOnly: true}, read, true},
+		{"read-only drops writes", &Policy{ReadOnly: true}, chat, false},
+		{"allowlist match", &Policy{Tools: []string{"list_*", "chat"}}, chat, true},
+		{"allowlist miss", &Policy{Tools: []string{"list_*", "chat"}}, del, false},
+		{"deny glob", &Policy{Deny: []string{"delete_*"}}, del, false},
+		{"deny after allow", &Policy{Tools: []string{"*"}, Deny: []string{"chat"}}, chat, false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.allowsTool(tt.tool); got != tt.want {
+			t.Errorf("%s: allowsTool(%s) = %v, want %v", tt.name, tt.tool.Name, got, tt.want)
+		}
+	}
+	if err := (&Policy{Deny: []string{"["}}).Validate(); err == nil {
+		t.Error("Validate with a malformed pattern: want error")
+	}
+}
+
+func TestPolicyRegistersTools(t *testing.T) {
+	t.Parallel()
+
+	cs := connectPolicy(t, &Policy{ReadOnly: true, Deny: []string{"get_*"}})
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools.Tools) == 0 {
+		t.Fatal("no tools registered")
+	}
+	for _, tool := range tools.Tools {
+		if !tool.Annotations.ReadOnlyHint || strings.HasPrefix(tool.Name, "get_") {
+			t.Errorf("tool %s registered despite the policy", tool.Name)
+		}
+	}
+}
+
+func TestPolicyScopesNotebooks(t *testing.T) {
+	t.Parallel()
+
+	cs := connectPolicy(t, &Policy{Notebooks: []string{"nb1"}})
+	ctx := context.Background()
+	for _, tt := range []struct {
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"delete_notebook", map[string]any{"notebook_id": "nb2"}, "outside this server's scope"},
+		{"create_notebook", map[string]any{"title": "x"}, "create_notebook is unavailable"},
+		{"rename_artifact", map[string]any{"artifact_id": "a", "new_title": "t"}, "rename_artifact is unavailable"},
+	} {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: tt.tool, Arguments: tt.args})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.tool, err)
+		}
+		if !res.IsError || !strings.Contains(res.Content[0].(*mcp.TextContent).Text, tt.want) {
+			t.Errorf("%s: result = %+v, want error containing %q", tt.tool, res.Content, tt.want)
+		}
+	}
+	if _, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "nlm://notebook/nb2"}); err == nil {
+		t.Error("read out-of-scope notebook resource: want error")
+	}
+	if err := cs.Subscribe(ctx, &mcp.SubscribeParams{URI: "nlm://notebook/nb2/source/s1"}); err == nil {
+		t.Error("subscribe to out-of-scope source: want error")
+	}
+}
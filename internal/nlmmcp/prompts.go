@@ -0,0 +1,219 @@
+package nlmmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// workflowPrompt is a prompt that expands into a numbered tool plan.
+type workflowPrompt struct {
+	prompt *mcp.Prompt
+
+	// tools and commands name the built-in and CLI command tools the plan
+	// cannot do without; the prompt is left out when one is unavailable.
+	tools    []string
+	commands []string
+
+	// plan returns the steps. hasCommand reports whether an optional CLI
+	// command tool is available.
+	plan func(args map[string]string, hasCommand func(tool string) bool) []string
+}
+
+var notebookArgument = &mcp.PromptArgument{
+	Name:        "notebook_id",
+	Description: "Notebook to work in",
+	Required:    true,
+}
+
+var workflowPrompts = []workflowPrompt{
+	{
+		prompt: &mcp.Prompt{
+			Name:        "research-and-import",
+			Title:       "Research and import",
+			Description: "Run deep research on a topic, then import the best sources it finds into the notebook.",
+			Arguments: []*mcp.PromptArgument{
+				notebookArgument,
+				{Name: "topic", Description: "What to research", Required: true},
+				{Name: "max_sources", Description: "Most sources to import (default 10)"},
+			},
+		},
+		tools: []string{"start_deep_research", "watch_deep_research", "list_sources", "add_source_url"},
+		plan: func(args map[string]string, _ func(string) bool) []string {
+			nb := args["notebook_id"]
+			return []string{
+				toolStep("start_deep_research", map[string]any{"notebook_id": nb, "query": args["topic"]}) +
+					" Note the research_id it returns.",
+				toolStep("watch_deep_research", map[string]any{"notebook_id": nb, "research_id": "<research_id>"}) +
+					" Wait for it to finish; do not poll in a loop.",
+				toolStep("list_sources", map[string]any{"notebook_id": nb}) +
+					" Note the URLs already in the notebook.",
+				fmt.Sprintf("Pick up to %s of the most relevant sources from the research result that are not already present, and add each with ", argOr(args, "max_sources", "10")) +
+					toolStep("add_source_url", map[string]any{"notebook_id": nb, "url": "<url>"}),
+				"Report which sources were imported, which were skipped and why, and a short summary of the research findings.",
+			}
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "study-guide",
+			Title:       "Study guide",
+			Description: "Create flashcards, a quiz and a study guide report for a notebook.",
+			Arguments: []*mcp.PromptArgument{
+				notebookArgument,
+				{Name: "topic", Description: "Topic to focus on (default: the whole notebook)"},
+			},
+		},
+		tools: []string{"create_app_artifact", "chat", "create_note", "wait_for_artifact"},
+		plan: func(args map[string]string, has func(string) bool) []string {
+			nb := args["notebook_id"]
+			focus := "Cover the key concepts across all sources."
+			if topic := args["topic"]; topic != "" {
+				focus = "Focus on " + topic + "."
+			}
+			steps := []string{
+				toolStep("create_app_artifact", map[string]any{"notebook_id": nb, "type": "flashcards", "instructions": focus}),
+				toolStep("create_app_artifact", map[string]any{"notebook_id": nb, "type": "quiz", "instructions": focus}),
+			}
+			if has("create_report") {
+				steps = append(steps, toolStep("create_report", map[string]any{"args": []string{nb, "Study Guide", focus}}))
+			} else {
+				steps = append(steps,
+					toolStep("chat", map[string]any{"notebook_id": nb, "prompt": "Write a study guide with key terms, a summary of each main idea, and review questions. " + focus}),
+					"Save the answer with "+toolStep("create_note", map[string]any{"notebook_id": nb, "title": "Study Guide", "content": "<answer>"}))
+			}
+			return append(steps,
+				"For each artifact started above, "+toolStep("wait_for_artifact", map[string]any{"notebook_id": nb, "artifact_id": "<artifact_id>"}),
+				"Report the IDs, titles, and links of everything created.")
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "summarize-repo",
+			Title:       "Summarize a repository",
+			Description: "Sync a local repository into the notebook, then summarize it with citations.",
+			Arguments: []*mcp.PromptArgument{
+				notebookArgument,
+				{Name: "path", Description: "Repository directory on the server's machine", Required: true},
+				{Name: "question", Description: "What to ask about the repository (default: an architecture overview)"},
+			},
+		},
+		tools:    []string{"chat"},
+		commands: []string{"source_sync"},
+		plan: func(args map[string]string, has func(string) bool) []string {
+			nb, dir := args["notebook_id"], args["path"]
+			var steps []string
+			if has("source_sync_status") {
+				steps = append(steps, toolStep("source_sync_status", map[string]any{"args": []string{nb, dir}})+
+					" Review what will change; stop and ask if it would upload far more than expected.")
+			}
+			question := argOr(args, "question", "Give an architecture overview of this repository: its purpose, main packages and how they fit together, and the entry points a new contributor should read first.")
+			return append(steps,
+				toolStep("source_sync", map[string]any{"args": []string{nb, dir}}),
+				toolStep("chat", map[string]any{"notebook_id": nb, "prompt": question + " Cite the files you draw on."}),
+				"Present the answer with its citations, naming the cited source for each.")
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "podcast-brief",
+			Title:       "Podcast brief",
+			Description: "Pick an audio overview angle from the notebook's suggestions and generate a brief podcast.",
+			Arguments: []*mcp.PromptArgument{
+				notebookArgument,
+				{Name: "topic", Description: "Angle to favor (default: the strongest suggestion)"},
+				{Name: "length", Description: "Audio length: default, short, or long (default short)"},
+			},
+		},
+		tools: []string{"create_audio_overview", "wait_for_artifact"},
+		plan: func(args map[string]string, has func(string) bool) []string {
+			nb := args["notebook_id"]
+			var steps []string
+			instructions := argOr(args, "topic", "<chosen suggestion>")
+			if has("audio_suggestions") {
+				pick := "Choose the strongest suggestion."
+				if topic := args["topic"]; topic != "" {
+					pick = "Choose the suggestion closest to " + strconv.Quote(topic) + ", or use the topic itself if none fits."
+				}
+				steps = append(steps, toolStep("audio_suggestions", map[string]any{"notebook_id": nb, "json": true})+" "+pick)
+				instructions = "<chosen suggestion>"
+			}
+			return append(steps,
+				toolStep("create_audio_overview", map[string]any{
+					"notebook_id":  nb,
+					"instructions": instructions,
+					"audio_type":   "brief",
+					"length":       argOr(args, "length", "short"),
+				}),
+				toolStep("wait_for_artifact", map[string]any{"notebook_id": nb, "artifact_id": "<artifact_id>"})+" Generation takes several minutes.",
+				"Report the angle chosen and the finished audio overview's links.")
+		},
+	},
+}
+
+func argOr(args map[string]string, name, def string) string {
+	if v := strings.TrimSpace(args[name]); v != "" {
+		return v
+	}
+	return def
+}
+
+// toolStep renders a call as `tool {"arg": ...}`. Placeholders in angle
+// brackets stand for values from earlier steps.
+func toolStep(tool string, args map[string]any) string {
+	data, _ := json.Marshal(args)
+	return fmt.Sprintf("Call `%s` with `%s`.", tool, data)
+}
+
+// registerPrompts adds the workflow prompts whose required tools the server
+// has. Built-in tools are checked against the policy as if they changed
+// state, since every plan does.
+func registerPrompts(server *mcp.Server, opts *Options) {
+	hasCommand := func(name string) bool {
+		if opts.RunCommand == nil {
+			return false
+		}
+		for _, cmd := range opts.Commands {
+			if cmd.Name == name {
+				return opts.Policy.allowsTool(&mcp.Tool{Name: name, Annotations: cmd.annotations()})
+			}
+		}
+		return false
+	}
+	for _, wp := range workflowPrompts {
+		available := true
+		for _, name := range wp.tools {
+			available = available && opts.Policy.allowsTool(&mcp.Tool{Name: name, Annotations: mutatingAnnotations})
+		}
+		for _, name := range wp.commands {
+			available = available && hasCommand(name)
+		}
+		if !available {
+			continue
+		}
+		server.AddPrompt(wp.prompt, func(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			args := req.Params.Arguments
+			for _, arg := range wp.prompt.Arguments {
+				if arg.Required && strings.TrimSpace(args[arg.Name]) == "" {
+					return nil, fmt.Errorf("%s: argument %q is required", wp.prompt.Name, arg.Name)
+				}
+			}
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s Follow this plan, running each tool call in order and reporting errors instead of guessing:\n\n", wp.prompt.Description)
+			for i, step := range wp.plan(args, hasCommand) {
+				fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+			}
+			return &mcp.GetPromptResult{
+				Description: wp.prompt.Description,
+				Messages: []*mcp.PromptMessage{{
+					Role:    "user",
+					Content: &mcp.TextContent{Text: b.String()},
+				}},
+			}, nil
+		})
+	}
+}
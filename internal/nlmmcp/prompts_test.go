@@ -0,0 +1,120 @@
+package nlmmcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func promptNames(t *testing.T, cs *mcp.ClientSession) []string {
+	t.Helper()
+	res, err := cs.ListPrompts(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range res.Prompts {
+		names = append(names, p.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func getPromptText(t *testing.T, cs *mcp.ClientSession, name string, args map[string]string) (string, error) {
+	t.Helper()
+	res, err := cs.GetPrompt(context.Background(), &mcp.GetPromptParams{Name: name, Arguments: args})
+	if err != nil {
+		return "", err
+	}
+	return res.Messages[0].Content.(*mcp.TextContent).Text, nil
+}
+
+func TestPromptsUseRegisteredTools(t *testing.T) {
+	t.Parallel()
+
+	cs := connectPolicy(t, nil)
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		registered[tool.Name] = true
+	}
+	for _, wp := range workflowPrompts {
+		for _, name := range wp.tools {
+			if !registered[name] {
+				t.Errorf("prompt %s requires unknown tool %q", wp.prompt.Name, name)
+			}
+		}
+	}
+
+	want := []string{"podcast-brief", "research-and-import", "study-guide"}
+	if got := promptNames(t, cs); !slices.Equal(got, want) {
+		t.Errorf("prompts = %v, want %v", got, want)
+	}
+	text, err := getPromptText(t, cs, "research-and-import", map[string]string{"notebook_id": "nb1", "topic": "tidal power"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"1. Call `start_deep_research` with `{\"notebook_id\":\"nb1\",\"query\":\"tidal power\"}`.",
+		"Pick up to 10 ",
+		"`add_source_url`",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("research-and-import plan missing %q:\n%s", want, text)
+		}
+	}
+	if _, err := getPromptText(t, cs, "research-and-import", map[string]string{"notebook_id": "nb1"}); err == nil {
+		t.Error("missing topic: want error")
+	}
+}
+
+func TestPromptsWithCommandTools(t *testing.T) {
+	t.Parallel()
+
+	cs := connectOptions(t, &Options{
+		Commands: []CommandTool{
+			{Name: "source_sync", Path: []string{"source", "sync"}, Args: "<notebook-id> [path...]"},
+			{Name: "create_report", Path: []string{"create-report"}, Args: "<notebook-id> <report-type>"},
+		},
+		RunCommand: func(context.Context, Selection, []string) (string, error) { return "", nil },
+	})
+	want := []string{"podcast-brief", "research-and-import", "study-guide", "summarize-repo"}
+	if got := promptNames(t, cs); !slices.Equal(got, want) {
+		t.Errorf("prompts = %v, want %v", got, want)
+	}
+	text, err := getPromptText(t, cs, "study-guide", map[string]string{"notebook_id": "nb1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "`create_report`") || strings.Contains(text, "`create_note`") {
+		t.Errorf("study-guide plan should use create_report:\n%s", text)
+	}
+	text, err = getPromptText(t, cs, "summarize-repo", map[string]string{"notebook_id": "nb1", "path": "/src/app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "Call `source_sync` with `{\"args\":[\"nb1\",\"/src/app\"]}`.") {
+		t.Errorf("summarize-repo plan:\n%s", text)
+	}
+}
+
+func TestPromptsFollowPolicy(t *testing.T) {
+	t.Parallel()
+
+	if got := promptNames(t, connectPolicy(t, &Policy{ReadOnly: true})); len(got) != 0 {
+		t.Errorf("read-only prompts = %v, want none", got)
+	}
+	cs := connectPolicy(t, &Policy{Notebooks: []string{"nb1"}})
+	if _, err := getPromptText(t, cs, "podcast-brief", map[string]string{"notebook_id": "nb2"}); err == nil {
+		t.Error("out-of-scope notebook: want error")
+	}
+	if _, err := getPromptText(t, cs, "podcast-brief", map[string]string{"notebook_id": "nb1"}); err != nil {
+		t.Errorf("in-scope notebook: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/tmc/nlm/internal/exitclass"
 	"github.com/tmc/nlm/notebooklm"
 )
 
@@ -30,16 +31,16 @@ func watchDeepResearch(
 	notify func(researchWatchProgress) error,
 ) (*notebooklm.DeepResearchResult, error) {
 	if input.NotebookID == "" {
-		return nil, fmt.Errorf("notebook_id is required")
+		return nil, badArgs(fmt.Errorf("notebook_id is required"))
 	}
 	if input.ResearchID == "" {
-		return nil, fmt.Errorf("research_id is required")
+		return nil, badArgs(fmt.Errorf("research_id is required"))
 	}
 	if input.PollIntervalMS < 0 {
-		return nil, fmt.Errorf("poll_interval_ms must be non-negative")
+		return nil, badArgs(fmt.Errorf("poll_interval_ms must be non-negative"))
 	}
 	if input.MaxWaitSeconds < 0 {
-		return nil, fmt.Errorf("max_wait_seconds must be non-negative")
+		return nil, badArgs(fmt.Errorf("max_wait_seconds must be non-negative"))
 	}
 
 	interval := defaultResearchPollInterval
@@ -83,7 +84,12 @@ func watchDeepResearch(
 		case <-timer.C:
 		case <-ctx.Done():
 			timer.Stop()
-			return nil, fmt.Errorf("wait for deep research: %w", ctx.Err())
+			err := fmt.Errorf("wait for deep research: %w", ctx.Err())
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// Research is still running; poll or watch again later.
+				err = exitclass.With(exitclass.Busy, err)
+			}
+			return nil, err
 		}
 	}
 }
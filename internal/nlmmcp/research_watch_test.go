@@ -157,8 +157,8 @@ func TestServerListsWatchDeepResearch(t *testing.T) {
 	if err != nil {
 		t.Fatalf("list tools: %v", err)
 	}
-	if len(result.Tools) != 25 {
-		t.Fatalf("tool count = %d, want 25", len(result.Tools))
+	if len(result.Tools) != 28 {
+		t.Fatalf("tool count = %d, want 28", len(result.Tools))
 	}
 	removed := map[string]bool{
 		"generate_summarize":    true,
@@ -0,0 +1,328 @@
+package nlmmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tmc/nlm/internal/richrender"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+const (
+	resourcePrefix   = "nlm://notebook/"
+	markdownMIMEType = "text/markdown"
+)
+
+// resourceClient is the subset of notebooklm.Client the resources read.
+type resourceClient interface {
+	ListRecentlyViewedProjects(context.Context) ([]*notebooklm.Notebook, error)
+	GetProject(context.Context, string) (*notebooklm.Notebook, error)
+	GetNotes(context.Context, string) ([]*notebooklm.Note, error)
+	LoadSourceText(ctx context.Context, sourceID, notebookID string) (notebooklm.LoadSourceText, error)
+	DownloadSourceImage(context.Context, string) ([]byte, string, error)
+}
+
+// resourceURI identifies a notebook, or a source or note within one.
+type resourceURI struct {
+	NotebookID string
+	SourceID   string
+	NoteID     string
+}
+
+func notebookURI(notebookID string) string { return resourcePrefix + notebookID }
+
+func sourceURI(notebookID, sourceID string) string {
+	return notebookURI(notebookID) + "/source/" + sourceID
+}
+
+func noteURI(notebookID, noteID string) string {
+	return notebookURI(notebookID) + "/note/" + noteID
+}
+
+// parseResourceURI accepts the three nlm:// forms the server registers.
+func parseResourceURI(uri string) (resourceURI, bool) {
+	rest, ok := strings.CutPrefix(uri, resourcePrefix)
+	if !ok {
+		return resourceURI{}, false
+	}
+	parts := strings.Split(rest, "/")
+	for _, p := range parts {
+		if p == "" {
+			return resourceURI{}, false
+		}
+	}
+	switch {
+	case len(parts) == 1:
+		return resourceURI{NotebookID: parts[0]}, true
+	case len(parts) == 3 && parts[1] == "source":
+		return resourceURI{NotebookID: parts[0], SourceID: parts[2]}, true
+	case len(parts) == 3 && parts[1] == "note":
+		return resourceURI{NotebookID: parts[0], NoteID: parts[2]}, true
+	}
+	return resourceURI{}, false
+}
+
+// resourceCatalog keeps the server's concrete resource list in step with
+// the recently viewed notebooks and their sources. The SDK only announces
+// list changes when resources are added or removed, so refreshing the
+// catalog is also how mutating tools reach subscribed clients.
+type resourceCatalog struct {
+	server *mcp.Server
+	client resourceClient
+
+	// changing names the tools whose success may change what a notebook,
+	// source, or note resource reads as, or which of them exist.
+	changing map[string]bool
+
+	mu     sync.Mutex
+	listed map[string]string // URI -> title
+}
+
+// registerResources adds the resource templates, and follows successful
+// calls of the tools named in changing with resource updates.
+func registerResources(server *mcp.Server, client resourceClient, changing map[string]bool) *resourceCatalog {
+	c := &resourceCatalog{server: server, client: client, changing: changing, listed: make(map[string]string)}
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "notebook",
+		Title:       "NotebookLM notebook",
+		Description: "A notebook's title and the resource URIs of its sources and notes.",
+		URITemplate: resourcePrefix + "{id}",
+		MIMEType:    markdownMIMEType,
+	}, c.readNotebook)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "source",
+		Title:       "NotebookLM source",
+		Description: "A source's indexed text, rendered as Markdown.",
+		URITemplate: resourcePrefix + "{id}/source/{sid}",
+		MIMEType:    markdownMIMEType,
+	}, c.readSource)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "note",
+		Title:       "NotebookLM note",
+		Description: "A note rendered as Markdown, with citations.",
+		URITemplate: resourcePrefix + "{id}/note/{nid}",
+		MIMEType:    markdownMIMEType,
+	}, c.readNote)
+	server.AddReceivingMiddleware(c.middleware)
+	return c
+}
+
+// middleware refreshes the catalog before resources/list, and after a
+// successful resource-changing tool call notifies subscribers of the
+// resources it touched.
+func (c *resourceCatalog) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		switch method {
+		case "resources/list":
+			if err := c.refresh(ctx, ""); err != nil {
+				return nil, fmt.Errorf("list notebooks: %w", err)
+			}
+		case "tools/call":
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || !c.changing[call.Params.Name] {
+				break
+			}
+			result, err := next(ctx, method, req)
+			if err == nil {
+				if r, ok := result.(*mcp.CallToolResult); !ok || !r.IsError {
+					c.toolChanged(ctx, call.Params.Arguments)
+				}
+			}
+			return result, err
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// toolChanged sends resource updates for the notebook, source, or note a
+// tool call named, then refreshes the list. Failures here are not the
+// tool's: the call already succeeded, so they are dropped.
+func (c *resourceCatalog) toolChanged(ctx context.Context, arguments json.RawMessage) {
+	var args struct {
+		NotebookID string `json:"notebook_id"`
+		SourceID   string `json:"source_id"`
+		NoteID     string `json:"note_id"`
+	}
+	_ = json.Unmarshal(arguments, &args)
+	if args.NotebookID != "" {
+		uris := []string{notebookURI(args.NotebookID)}
+		if args.SourceID != "" {
+			uris = append(uris, sourceURI(args.NotebookID, args.SourceID))
+		}
+		if args.NoteID != "" {
+			uris = append(uris, noteURI(args.NotebookID, args.NoteID))
+		}
+		for _, uri := range uris {
+			_ = c.server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		}
+	}
+	_ = c.refresh(ctx, args.NotebookID)
+}
+
+// refresh lists the recently viewed notebooks and adds or removes
+// resources to match. The touched notebook is re-added even when its
+// listing is unchanged, so clients hear about changes such as a new note
+// that the listing does not show.
+func (c *resourceCatalog) refresh(ctx context.Context, touched string) error {
+	notebooks, err := c.client.ListRecentlyViewedProjects(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seen := make(map[string]bool)
+	add := func(r *mcp.Resource, h mcp.ResourceHandler, force bool) {
+		seen[r.URI] = true
+		if title, ok := c.listed[r.URI]; ok && title == r.Title && !force {
+			return
+		}
+		c.listed[r.URI] = r.Title
+		c.server.AddResource(r, h)
+	}
+	for _, nb := range notebooks {
+		id := nb.GetProjectId()
+		if id == "" {
+			continue
+		}
+		add(&mcp.Resource{
+			URI:         notebookURI(id),
+			Name:        id,
+			Title:       strings.TrimSpace(nb.GetEmoji() + " " + nb.GetTitle()),
+			Description: fmt.Sprintf("NotebookLM notebook with %d sources", len(nb.GetSources())),
+			MIMEType:    markdownMIMEType,
+		}, c.readNotebook, id == touched)
+		for _, src := range nb.GetSources() {
+			sid := src.GetSourceId().GetSourceId()
+			if sid == "" {
+				continue
+			}
+			add(&mcp.Resource{
+				URI:      sourceURI(id, sid),
+				Name:     sid,
+				Title:    src.GetTitle(),
+				MIMEType: markdownMIMEType,
+			}, c.readSource, false)
+		}
+	}
+	var stale []string
+	for uri := range c.listed {
+		if !seen[uri] {
+			stale = append(stale, uri)
+			delete(c.listed, uri)
+		}
+	}
+	if len(stale) > 0 {
+		c.server.RemoveResources(stale...)
+	}
+	return nil
+}
+
+func (c *resourceCatalog) readNotebook(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	ref, ok := parseResourceURI(uri)
+	if !ok || ref.SourceID != "" || ref.NoteID != "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	project, err := c.client.GetProject(ctx, ref.NotebookID)
+	if err != nil {
+		return nil, fmt.Errorf("get notebook %s: %w", ref.NotebookID, err)
+	}
+	notes, err := c.client.GetNotes(ctx, ref.NotebookID)
+	if err != nil {
+		return nil, fmt.Errorf("get notes for %s: %w", ref.NotebookID, err)
+	}
+	return markdownContents(uri, notebookMarkdown(ref.NotebookID, project, notes)), nil
+}
+
+func (c *resourceCatalog) readSource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	ref, ok := parseResourceURI(uri)
+	if !ok || ref.SourceID == "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	body, err := c.client.LoadSourceText(ctx, ref.SourceID, ref.NotebookID)
+	if err != nil {
+		return nil, fmt.Errorf("load source %s: %w", ref.SourceID, err)
+	}
+	if len(body.Fragments) == 0 {
+		return nil, fmt.Errorf("source %s has no indexed text body", ref.SourceID)
+	}
+	text, err := richrender.SourceMarkdown(body, func(imageURL string) ([]byte, string, error) {
+		return c.client.DownloadSourceImage(ctx, imageURL)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return markdownContents(uri, text), nil
+}
+
+func (c *resourceCatalog) readNote(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	ref, ok := parseResourceURI(uri)
+	if !ok || ref.NoteID == "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	notes, err := c.client.GetNotes(ctx, ref.NotebookID)
+	if err != nil {
+		return nil, fmt.Errorf("get notes for %s: %w", ref.NotebookID, err)
+	}
+	for _, note := range notes {
+		if note.GetNoteId() != ref.NoteID {
+			continue
+		}
+		var b strings.Builder
+		if err := richrender.RenderNoteMarkdown(&b, richrender.NoteDocumentFromAPI(note)); err != nil {
+			return nil, err
+		}
+		return markdownContents(uri, b.String()), nil
+	}
+	return nil, mcp.ResourceNotFoundError(uri)
+}
+
+// notebookMarkdown is the notebook resource body: a heading and a link to
+// every source and note resource, so a client can follow them.
+func notebookMarkdown(notebookID string, project *notebooklm.Notebook, notes []*notebooklm.Note) string {
+	var b strings.Builder
+	title := strings.TrimSpace(project.GetEmoji() + " " + project.GetTitle())
+	if title == "" {
+		title = notebookID
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "## Sources\n\n")
+	if len(project.GetSources()) == 0 {
+		b.WriteString("(none)\n")
+	}
+	for _, src := range project.GetSources() {
+		sid := src.GetSourceId().GetSourceId()
+		fmt.Fprintf(&b, "- [%s](%s)\n", src.GetTitle(), sourceURI(notebookID, sid))
+	}
+	fmt.Fprintf(&b, "\n## Notes\n\n")
+	if len(notes) == 0 {
+		b.WriteString("(none)\n")
+	}
+	for _, note := range notes {
+		fmt.Fprintf(&b, "- [%s](%s)\n", note.GetTitle(), noteURI(notebookID, note.GetNoteId()))
+	}
+	return b.String()
+}
+
+func markdownContents(uri, text string) *mcp.ReadResourceResult {
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{
+		URI:      uri,
+		MIMEType: markdownMIMEType,
+		Text:     text,
+	}}}
+}
+
+// checkResourceSubscription accepts subscriptions to any URI the server
+// can read; the SDK tracks the subscribers.
+func checkResourceSubscription(uri string) error {
+	if _, ok := parseResourceURI(uri); !ok {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package nlmmcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/notebooklm"
+)
+
+type fakeResourceClient struct {
+	mu       sync.Mutex
+	projects []*notebooklm.Notebook
+	notes    []*notebooklm.Note
+	bodies   map[string]notebooklm.LoadSourceText
+}
+
+func (f *fakeResourceClient) ListRecentlyViewedProjects(context.Context) ([]*notebooklm.Notebook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.projects, nil
+}
+
+func (f *fakeResourceClient) GetProject(_ context.Context, id string) (*notebooklm.Notebook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.projects {
+		if p.GetProjectId() == id {
+			return p, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeResourceClient) GetNotes(context.Context, string) ([]*notebooklm.Note, error) {
+	return f.notes, nil
+}
+
+func (f *fakeResourceClient) LoadSourceText(_ context.Context, sourceID, _ string) (notebooklm.LoadSourceText, error) {
+	return f.bodies[sourceID], nil
+}
+
+func (f *fakeResourceClient) DownloadSourceImage(context.Context, string) ([]byte, string, error) {
+	return []byte("png"), "image/png", nil
+}
+
+func (f *fakeResourceClient) deleteSource(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := f.projects[0]
+	kept := p.Sources[:0]
+	for _, s := range p.Sources {
+		if s.GetSourceId().GetSourceId() != id {
+			kept = append(kept, s)
+		}
+	}
+	p.Sources = kept
+}
+
+func newFakeResourceClient() *fakeResourceClient {
+	return &fakeResourceClient{
+		projects: []*notebooklm.Notebook{{
+			ProjectId: "nb1",
+			Title:     "Research",
+			Sources: []*pb.Source{
+				{SourceId: &pb.SourceId{SourceId: "s1"}, Title: "Paper"},
+				{SourceId: &pb.SourceId{SourceId: "s2"}, Title: "Blog"},
+			},
+		}},
+		notes: []*notebooklm.Note{{Note: &pb.Note{NoteId: "n1", Title: "Summary", ContentText: "Key points."}}},
+		bodies: map[string]notebooklm.LoadSourceText{
+			"s1": {SourceID: "s1", Title: "Paper", Fragments: []notebooklm.TextFragment{
+				{Start: 0, End: 5, Text: "Intro", Bold: true},
+				{Start: 9, End: 13, Text: "x++\n", Code: true, Language: "go"},
+			}},
+		},
+	}
+}
+
+// connectResources serves the resources for fc with one resource-changing
+// tool, and returns a connected client session.
+func connectResources(t *testing.T, fc *fakeResourceClient, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, &mcp.ServerOptions{
+		SubscribeHandler: func(_ context.Context, req *mcp.SubscribeRequest) error {
+			return checkResourceSubscription(req.Params.URI)
+		},
+		UnsubscribeHandler: func(_ context.Context, req *mcp.UnsubscribeRequest) error {
+			return checkResourceSubscription(req.Params.URI)
+		},
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "delete_source"}, func(_ context.Context, _ *mcp.CallToolRequest, input deleteSourceInput) (*mcp.CallToolResult, any, error) {
+		fc.deleteSource(input.SourceID)
+		return textResult("deleted"), nil, nil
+	})
+	registerResources(server, fc, map[string]bool{"delete_source": true})
+
+	st, ct := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client"}, opts).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func TestParseResourceURI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		uri  string
+		want resourceURI
+		ok   bool
+	}{
+		{"nlm://notebook/nb1", resourceURI{NotebookID: "nb1"}, true},
+		{"nlm://notebook/nb1/source/s1", resourceURI{NotebookID: "nb1", SourceID: "s1"}, true},
+		{"nlm://notebook/nb1/note/n1", resourceURI{NotebookID: "nb1", NoteID: "n1"}, true},
+		{"nlm://notebook/", resourceURI{}, false},
+		{"nlm://notebook/nb1/source/", resourceURI{}, false},
+		{"nlm://notebook/nb1/artifact/a1", resourceURI{}, false},
+		{"file:///tmp/x", resourceURI{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseResourceURI(tt.uri)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseResourceURI(%q) = %+v, %v; want %+v, %v", tt.uri, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestResourcesListAndRead(t *testing.T) {
+	t.Parallel()
+
+	cs := connectResources(t, newFakeResourceClient(), nil)
+	ctx := context.Background()
+
+	list, err := cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, r := range list.Resources {
+		uris = append(uris, r.URI)
+	}
+	want := "nlm://notebook/nb1 nlm://notebook/nb1/source/s1 nlm://notebook/nb1/source/s2"
+	if got := strings.Join(uris, " "); got != want {
+		t.Errorf("resources = %s, want %s", got, want)
+	}
+
+	read := func(uri string) string {
+		t.Helper()
+		res, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Fatalf("read %s: %v", uri, err)
+		}
+		return res.Contents[0].Text
+	}
+	if got := read("nlm://notebook/nb1"); !strings.Contains(got, "# Research") ||
+		!strings.Contains(got, "- [Blog](nlm://notebook/nb1/source/s2)") ||
+		!strings.Contains(got, "- [Summary](nlm://notebook/nb1/note/n1)") {
+		t.Errorf("notebook resource:\n%s", got)
+	}
+	if got, want := read("nlm://notebook/nb1/source/s1"), "**Intro**\n\n```go\nx++\n```\n\n"; got != want {
+		t.Errorf("source resource = %q, want %q", got, want)
+	}
+	if got := read("nlm://notebook/nb1/note/n1"); !strings.HasPrefix(got, "# Summary\n\nKey points.") {
+		t.Errorf("note resource:\n%s", got)
+	}
+	if _, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "nlm://notebook/nb1/note/missing"}); err == nil {
+		t.Error("missing note: want error")
+	}
+}
+
+func TestResourceChangingToolNotifies(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var updated []string
+	listChanged := make(chan struct{}, 8)
+	fc := newFakeResourceClient()
+	cs := connectResources(t, fc, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			updated = append(updated, req.Params.URI)
+		},
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+			listChanged <- struct{}{}
+		},
+	})
+	ctx := context.Background()
+	if _, err := cs.ListResources(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Drain the announcement of the initial listing.
+	select {
+	case <-listChanged:
+	case <-time.After(5 * time.Second):
+	}
+	for _, uri := range []string{"nlm://notebook/nb1", "nlm://notebook/nb1/source/s2"} {
+		if err := cs.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+			t.Fatalf("subscribe %s: %v", uri, err)
+		}
+	}
+	if err := cs.Subscribe(ctx, &mcp.SubscribeParams{URI: "nlm://elsewhere"}); err == nil {
+		t.Error("subscribe to a foreign URI: want error")
+	}
+
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "delete_source",
+		Arguments: map[string]any{"notebook_id": "nb1", "source_id": "s2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-listChanged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no resources/list_changed after delete_source")
+	}
+	mu.Lock()
+	got := strings.Join(updated, " ")
+	mu.Unlock()
+	if want := "nlm://notebook/nb1 nlm://notebook/nb1/source/s2"; got != want {
+		t.Errorf("updated = %s, want %s", got, want)
+	}
+
+	list, err := cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range list.Resources {
+		if r.URI == "nlm://notebook/nb1/source/s2" {
+			t.Error("deleted source still listed")
+		}
+	}
+}
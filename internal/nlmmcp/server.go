@@ -26,27 +26,82 @@ var (
 
 func boolPtr(v bool) *bool { return &v }
 
+// Options configures New. A nil *Options registers the built-in tools and
+// resources with no restrictions.
+type Options struct {
+	// Impl describes the server; nil uses a default.
+	Impl *mcp.Implementation
+
+	// Policy restricts the tools and notebooks the server exposes.
+	Policy *Policy
+
+	// Commands are CLI commands to expose as tools alongside the built-in
+	// ones, run with RunCommand. They are left out when RunCommand is nil.
+	Commands   []CommandTool
+	RunCommand CommandRunner
+
+	// Selection names the credentials client was built from; it is passed
+	// to RunCommand.
+	Selection Selection
+
+	// ExportDir receives export_artifact files larger than MaxEmbedBytes,
+	// which are returned as file:// links. Without it such exports fail.
+	ExportDir string
+
+	// MaxEmbedBytes is the largest file export_artifact embeds in its
+	// result; zero means 8 MiB.
+	MaxEmbedBytes int
+
+	// Accounts, when set, are the accounts tool calls may act as; the
+	// first is the default, and the client passed to New is unused. With
+	// more than one, every tool takes an optional account argument, and
+	// calls naming a notebook go to the account that owns it.
+	Accounts []Account
+}
+
 // New returns an MCP server for NotebookLM operations.
-func New(client *notebooklm.Client, impl *mcp.Implementation) *mcp.Server {
+func New(client *notebooklm.Client, opts *Options) *mcp.Server {
+	if opts == nil {
+		opts = &Options{}
+	}
+	impl := opts.Impl
 	if impl == nil {
 		impl = &mcp.Implementation{
 			Name:    "nlm-mcp",
 			Version: "devel",
 		}
 	}
-
 	server := mcp.NewServer(impl, &mcp.ServerOptions{
 		Instructions: `NotebookLM MCP server.
 
 Use list_notebooks to discover notebook IDs.
 Most tools require a notebook_id argument.
-Mutating tools change NotebookLM state directly.`,
+Mutating tools change NotebookLM state directly.
+
+Notebooks, sources, and notes are also readable as resources:
+nlm://notebook/{id}, nlm://notebook/{id}/source/{sid}, and
+nlm://notebook/{id}/note/{nid}.`,
+		SubscribeHandler: func(_ context.Context, req *mcp.SubscribeRequest) error {
+			return checkResourceSubscription(req.Params.URI)
+		},
+		UnsubscribeHandler: func(_ context.Context, req *mcp.UnsubscribeRequest) error {
+			return checkResourceSubscription(req.Params.URI)
+		},
 	})
-	registerTools(server, client)
+	accounts := newAccountSet(client, opts)
+	tools := newToolRegistry(server, opts.Policy)
+	registerCommandTools(tools, opts, accounts)
+	registerTools(tools, accounts)
+	registerAccountTools(tools, accounts)
+	registerExportTool(tools, accounts, opts)
+	registerResources(server, routedClient{accounts}, tools.mutating)
+	registerPrompts(server, opts)
+	server.AddReceivingMiddleware(accounts.middleware)
+	server.AddReceivingMiddleware(scopeMiddleware(opts.Policy))
 	return server
 }
 
 // Run serves the NotebookLM MCP server on stdio.
-func Run(ctx context.Context, client *notebooklm.Client, impl *mcp.Implementation) error {
-	return New(client, impl).Run(ctx, &mcp.StdioTransport{})
+func Run(ctx context.Context, client *notebooklm.Client, opts *Options) error {
+	return New(client, opts).Run(ctx, &mcp.StdioTransport{})
 }
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/exitclass"
 	"github.com/tmc/nlm/notebooklm"
 )
 
@@ -57,12 +59,14 @@ type listArtifactsInput struct {
 }
 
 type createAudioOverviewInput struct {
-	NotebookID   string   `json:"notebook_id"`
-	Instructions string   `json:"instructions,omitempty"`
-	Length       string   `json:"length,omitempty" jsonschema:"Audio length: default, short, or long"`
-	Language     string   `json:"language,omitempty" jsonschema:"Language code (default en)"`
-	AudioType    string   `json:"audio_type,omitempty" jsonschema:"Audio style: deep-dive, brief, critique, or debate"`
-	SourceIDs    []string `json:"source_ids,omitempty" jsonschema:"Optional source IDs; defaults to all notebook sources"`
+	NotebookID     string   `json:"notebook_id"`
+	Instructions   string   `json:"instructions,omitempty"`
+	Length         string   `json:"length,omitempty" jsonschema:"Audio length: default, short, or long"`
+	Language       string   `json:"language,omitempty" jsonschema:"Language code (default en)"`
+	AudioType      string   `json:"audio_type,omitempty" jsonschema:"Audio style: deep-dive, brief, critique, or debate"`
+	SourceIDs      []string `json:"source_ids,omitempty" jsonschema:"Optional source IDs; defaults to all notebook sources"`
+	Wait           bool     `json:"wait,omitempty" jsonschema:"Block until generation finishes, sending progress notifications, and return the finished artifact"`
+	MaxWaitSeconds int      `json:"max_wait_seconds,omitempty" jsonschema:"With wait, the maximum blocking time in seconds (default 900)"`
 }
 
 type getAudioOverviewInput struct {
@@ -98,30 +102,31 @@ type addSourceURLInput struct {
 	URL        string `json:"url"`
 }
 
-type generateChatInput struct {
-	NotebookID string `json:"notebook_id"`
-	Prompt     string `json:"prompt"`
-}
-
 type createVideoOverviewInput struct {
-	NotebookID   string   `json:"notebook_id"`
-	Instructions string   `json:"instructions"`
-	Style        string   `json:"style,omitempty" jsonschema:"Video style: auto, classic, or whiteboard"`
-	Language     string   `json:"language,omitempty" jsonschema:"Language code (default en)"`
-	AudioType    string   `json:"audio_type,omitempty" jsonschema:"Content style: brief, deep-dive, critique, or debate"`
-	SourceIDs    []string `json:"source_ids,omitempty" jsonschema:"Optional source IDs; defaults to all notebook sources"`
+	NotebookID     string   `json:"notebook_id"`
+	Instructions   string   `json:"instructions"`
+	Style          string   `json:"style,omitempty" jsonschema:"Video style: auto, classic, or whiteboard"`
+	Language       string   `json:"language,omitempty" jsonschema:"Language code (default en)"`
+	AudioType      string   `json:"audio_type,omitempty" jsonschema:"Content style: brief, deep-dive, critique, or debate"`
+	SourceIDs      []string `json:"source_ids,omitempty" jsonschema:"Optional source IDs; defaults to all notebook sources"`
+	Wait           bool     `json:"wait,omitempty" jsonschema:"Block until generation finishes, sending progress notifications, and return the finished artifact"`
+	MaxWaitSeconds int      `json:"max_wait_seconds,omitempty" jsonschema:"With wait, the maximum blocking time in seconds (default 900)"`
 }
 
 type createSlideDeckInput struct {
-	NotebookID   string `json:"notebook_id"`
-	Instructions string `json:"instructions"`
+	NotebookID     string `json:"notebook_id"`
+	Instructions   string `json:"instructions"`
+	Wait           bool   `json:"wait,omitempty" jsonschema:"Block until generation finishes, sending progress notifications, and return the finished artifact"`
+	MaxWaitSeconds int    `json:"max_wait_seconds,omitempty" jsonschema:"With wait, the maximum blocking time in seconds (default 900)"`
 }
 
 type createAppArtifactInput struct {
-	NotebookID   string   `json:"notebook_id"`
-	Type         string   `json:"type" jsonschema:"App type: prototype, mindmap, or canvas"`
-	Instructions string   `json:"instructions"`
-	SourceIDs    []string `json:"source_ids,omitempty" jsonschema:"Optional source IDs; defaults to all notebook sources"`
+	NotebookID     string   `json:"notebook_id"`
+	Type           string   `json:"type" jsonschema:"App type: prototype, mindmap, or canvas"`
+	Instructions   string   `json:"instructions"`
+	SourceIDs      []string `json:"source_ids,omitempty" jsonschema:"Optional source IDs; defaults to all notebook sources"`
+	Wait           bool     `json:"wait,omitempty" jsonschema:"Block until generation finishes, sending progress notifications, and return the finished artifact"`
+	MaxWaitSeconds int      `json:"max_wait_seconds,omitempty" jsonschema:"With wait, the maximum blocking time in seconds (default 900)"`
 }
 
 type readNoteInput struct {
@@ -159,7 +164,8 @@ type notebookSummary struct {
 	ID        string `json:"id"`
 	Title     string `json:"title"`
 	Emoji     string `json:"emoji,omitempty"`
-	CreatedAt string `json:"created_at,omitempty"`
+	CreatedAt string `json:"created_at,omitempty" jsonschema:"Creation time in RFC 3339 form"`
+	Account   string `json:"account,omitempty" jsonschema:"Account that owns the notebook, set when the server has several"`
 }
 
 type sourceSummary struct {
@@ -167,67 +173,120 @@ type sourceSummary struct {
 	Title string `json:"title"`
 }
 
+type addedSource struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
 type noteSummary struct {
 	ID    string `json:"id"`
 	Title string `json:"title"`
 }
 
+type noteContent struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content" jsonschema:"Plain-text projection of the note"`
+}
+
 type artifactSummary struct {
 	ID    string `json:"id"`
 	Type  string `json:"type"`
 	State string `json:"state"`
 }
 
+type renamedArtifact struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
 type audioOverviewSummary struct {
 	ID      string `json:"id"`
 	Title   string `json:"title"`
 	IsReady bool   `json:"is_ready"`
 }
 
+type sharedAudio struct {
+	Public   bool   `json:"public"`
+	ShareURL string `json:"share_url,omitempty" jsonschema:"Public URL, set when public is true"`
+}
+
+type instructionsOutput struct {
+	Instructions string `json:"instructions" jsonschema:"Custom chat instructions; empty when none are set"`
+}
+
+type deletedOutput struct {
+	ID string `json:"id" jsonschema:"ID of the deleted item"`
+}
+
+// deepResearchOutput is notebooklm.DeepResearchResult without its raw
+// search plan.
+type deepResearchOutput struct {
+	ResearchID     string                      `json:"research_id"`
+	ConversationID string                      `json:"conversation_id,omitempty"`
+	Done           bool                        `json:"done"`
+	Query          string                      `json:"query,omitempty"`
+	Report         string                      `json:"report,omitempty" jsonschema:"Research report in Markdown, set when done"`
+	Sources        []notebooklm.ResearchSource `json:"sources,omitempty"`
+}
+
+func researchOutput(r *notebooklm.DeepResearchResult) deepResearchOutput {
+	return deepResearchOutput{
+		ResearchID:     r.ResearchID,
+		ConversationID: r.ConversationID,
+		Done:           r.Done,
+		Query:          r.Query,
+		Report:         r.Report,
+		Sources:        r.Sources,
+	}
+}
+
 type pageResult[T any] struct {
 	Items      []T  `json:"items"`
-	Total      int  `json:"total"`
+	Total      int  `json:"total" jsonschema:"Number of items across all pages"`
 	Offset     int  `json:"offset"`
 	Limit      int  `json:"limit"`
-	Returned   int  `json:"returned"`
+	Returned   int  `json:"returned" jsonschema:"Number of items in this page"`
 	HasMore    bool `json:"has_more"`
-	NextOffset int  `json:"next_offset,omitempty"`
+	NextOffset int  `json:"next_offset,omitempty" jsonschema:"Offset of the next page, set when has_more is true"`
 }
 
-func registerTools(server *mcp.Server, client *notebooklm.Client) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerTools(r *toolRegistry, accounts *accountSet) {
+	policy := r.policy
+	addTool(r, &mcp.Tool{
 		Name:        "list_notebooks",
-		Description: "List recently viewed notebooks. Results are paginated; use limit and offset to page through them.",
+		Description: "List recently viewed notebooks. Results are paginated; use limit and offset to page through them. When the server has several accounts, notebooks from all of them are listed, each with its account.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input listNotebooksInput) (*mcp.CallToolResult, any, error) {
-		notebooks, err := client.ListRecentlyViewedProjects(context.Background())
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input listNotebooksInput) (*mcp.CallToolResult, pageResult[notebookSummary], error) {
+		notebooks, err := accounts.listAllNotebooks(ctx)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to list notebooks: %v", err)), nil, nil
+			return nil, pageResult[notebookSummary]{}, fmt.Errorf("failed to list notebooks: %w", err)
 		}
 
 		out := make([]notebookSummary, 0, len(notebooks))
-		for _, notebook := range notebooks {
-			item := notebookSummary{
-				ID:    notebook.ProjectId,
-				Title: notebook.Title,
-				Emoji: notebook.Emoji,
+		for _, listed := range notebooks {
+			if !policy.allowsNotebook(listed.Notebook.ProjectId) {
+				continue
 			}
-			if meta := notebook.GetMetadata(); meta != nil && meta.GetCreateTime() != nil {
-				item.CreatedAt = meta.GetCreateTime().AsTime().Format("2006-01-02T15:04:05Z07:00")
+			item := summarizeNotebook(listed.Notebook)
+			if accounts.multi() {
+				item.Account = listed.Account
 			}
 			out = append(out, item)
 		}
-		return jsonResult(paginate(out, input.Limit, input.Offset)), nil, nil
+		return nil, paginate(out, input.Limit, input.Offset), nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "list_sources",
 		Description: "List sources in a notebook. Results are paginated; use limit and offset to page through them.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input listSourcesInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input listSourcesInput) (*mcp.CallToolResult, pageResult[sourceSummary], error) {
+		client := accounts.client(ctx)
 		project, err := client.GetProject(context.Background(), input.NotebookID)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to get project: %v", err)), nil, nil
+			return nil, pageResult[sourceSummary]{}, fmt.Errorf("failed to get project: %w", err)
 		}
 
 		out := make([]sourceSummary, 0, len(project.Sources))
@@ -241,17 +300,18 @@ func registerTools(server *mcp.Server, client *notebooklm.Client) {
 				Title: source.Title,
 			})
 		}
-		return jsonResult(paginate(out, input.Limit, input.Offset)), nil, nil
+		return nil, paginate(out, input.Limit, input.Offset), nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "list_notes",
 		Description: "List notes in a notebook. Results are paginated; use limit and offset to page through them.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input listNotesInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input listNotesInput) (*mcp.CallToolResult, pageResult[noteSummary], error) {
+		client := accounts.client(ctx)
 		notes, err := client.GetNotes(context.Background(), input.NotebookID)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to get notes: %v", err)), nil, nil
+			return nil, pageResult[noteSummary]{}, fmt.Errorf("failed to get notes: %w", err)
 		}
 
 		out := make([]noteSummary, 0, len(notes))
@@ -261,53 +321,58 @@ func registerTools(server *mcp.Server, client *notebooklm.Client) {
 				Title: note.GetTitle(),
 			})
 		}
-		return jsonResult(paginate(out, input.Limit, input.Offset)), nil, nil
+		return nil, paginate(out, input.Limit, input.Offset), nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "create_note",
 		Description: "Create a note in a notebook.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input createNoteInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input createNoteInput) (*mcp.CallToolResult, noteSummary, error) {
+		client := accounts.client(ctx)
 		note, err := client.CreateNote(context.Background(), input.NotebookID, input.Title, input.Content)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to create note: %v", err)), nil, nil
+			return nil, noteSummary{}, fmt.Errorf("failed to create note: %w", err)
 		}
 
-		return textResult(fmt.Sprintf("created note %q (id: %s)", note.GetTitle(), note.GetNoteId())), nil, nil
+		out := noteSummary{ID: note.GetNoteId(), Title: note.GetTitle()}
+		return textResult(fmt.Sprintf("created note %q (id: %s)", out.Title, out.ID)), out, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "add_source_text",
 		Description: "Add text content as a source to a notebook.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input addSourceTextInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input addSourceTextInput) (*mcp.CallToolResult, addedSource, error) {
+		client := accounts.client(ctx)
 		sourceID, err := client.AddSourceFromText(context.Background(), input.NotebookID, input.Content, input.Title)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to add source: %v", err)), nil, nil
+			return nil, addedSource{}, fmt.Errorf("failed to add source: %w", err)
 		}
-		return textResult(fmt.Sprintf("added source %q (id: %s)", input.Title, sourceID)), nil, nil
+		return textResult(fmt.Sprintf("added source %q (id: %s)", input.Title, sourceID)), addedSource{ID: sourceID, Title: input.Title}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "delete_note",
 		Description: "Delete a note from a notebook.",
 		Annotations: destructiveAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input deleteNoteInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input deleteNoteInput) (*mcp.CallToolResult, deletedOutput, error) {
+		client := accounts.client(ctx)
 		if err := client.DeleteNotes(context.Background(), input.NotebookID, []string{input.NoteID}); err != nil {
-			return errorResult(fmt.Sprintf("failed to delete note: %v", err)), nil, nil
+			return nil, deletedOutput{}, fmt.Errorf("failed to delete note: %w", err)
 		}
-		return textResult(fmt.Sprintf("deleted note %s", input.NoteID)), nil, nil
+		return textResult(fmt.Sprintf("deleted note %s", input.NoteID)), deletedOutput{ID: input.NoteID}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "list_artifacts",
 		Description: "List artifacts in a notebook. Results are paginated; use limit and offset to page through them.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input listArtifactsInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input listArtifactsInput) (*mcp.CallToolResult, pageResult[artifactSummary], error) {
+		client := accounts.client(ctx)
 		artifacts, err := client.ListArtifacts(context.Background(), input.NotebookID)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to list artifacts: %v", err)), nil, nil
+			return nil, pageResult[artifactSummary]{}, fmt.Errorf("failed to list artifacts: %w", err)
 		}
 
 		out := make([]artifactSummary, 0, len(artifacts))
@@ -318,21 +383,22 @@ func registerTools(server *mcp.Server, client *notebooklm.Client) {
 				State: artifactStateLabel(artifact.State),
 			})
 		}
-		return jsonResult(paginate(out, input.Limit, input.Offset)), nil, nil
+		return nil, paginate(out, input.Limit, input.Offset), nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "create_audio_overview",
 		Description: "Create a new audio overview.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input createAudioOverviewInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input createAudioOverviewInput) (*mcp.CallToolResult, artifactOutput, error) {
+		client := accounts.client(ctx)
 		length, err := parseMCPAudioLength(input.Length)
 		if err != nil {
-			return errorResult(err.Error()), nil, nil
+			return nil, artifactOutput{}, badArgs(err)
 		}
 		audioType, err := parseMCPAudioType(input.AudioType, pb.AudioType_AUDIO_TYPE_DEEP_DIVE)
 		if err != nil {
-			return errorResult(err.Error()), nil, nil
+			return nil, artifactOutput{}, badArgs(err)
 		}
 		result, err := client.CreateAudioOverviewWithOptions(context.Background(), input.NotebookID, notebooklm.CreateAudioOverviewOptions{
 			Instructions: input.Instructions,
@@ -342,69 +408,80 @@ func registerTools(server *mcp.Server, client *notebooklm.Client) {
 			SourceIDs:    input.SourceIDs,
 		})
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to create audio overview: %v", err)), nil, nil
+			return nil, artifactOutput{}, fmt.Errorf("failed to create audio overview: %w", err)
 		}
-		return textResult(fmt.Sprintf("started audio overview %q (id: %s)", result.Title, result.AudioID)), nil, nil
+		started := startedArtifact{
+			ID:      result.AudioID,
+			Title:   result.Title,
+			Type:    pb.ArtifactType_ARTIFACT_TYPE_AUDIO_OVERVIEW,
+			Message: fmt.Sprintf("started audio overview %q (id: %s)", result.Title, result.AudioID),
+		}
+		return startedArtifactResult(ctx, req, client, input.NotebookID, started, input.Wait, input.MaxWaitSeconds)
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "get_audio_overview",
 		Description: "Get audio overview status and details.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input getAudioOverviewInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input getAudioOverviewInput) (*mcp.CallToolResult, audioOverviewSummary, error) {
+		client := accounts.client(ctx)
 		result, err := client.GetAudioOverview(context.Background(), input.NotebookID)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to get audio overview: %v", err)), nil, nil
+			return nil, audioOverviewSummary{}, fmt.Errorf("failed to get audio overview: %w", err)
 		}
-		return jsonResult(audioOverviewSummary{
+		return nil, audioOverviewSummary{
 			ID:      result.AudioID,
 			Title:   result.Title,
 			IsReady: result.IsReady,
-		}), nil, nil
+		}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "rename_artifact",
 		Description: "Rename an artifact.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input renameArtifactInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input renameArtifactInput) (*mcp.CallToolResult, renamedArtifact, error) {
+		client := accounts.client(ctx)
 		if _, err := client.RenameArtifact(context.Background(), input.ArtifactID, input.NewTitle); err != nil {
-			return errorResult(fmt.Sprintf("failed to rename artifact: %v", err)), nil, nil
+			return nil, renamedArtifact{}, fmt.Errorf("failed to rename artifact: %w", err)
 		}
-		return textResult(fmt.Sprintf("renamed artifact %s to %q", input.ArtifactID, input.NewTitle)), nil, nil
+		out := renamedArtifact{ID: input.ArtifactID, Title: input.NewTitle}
+		return textResult(fmt.Sprintf("renamed artifact %s to %q", out.ID, out.Title)), out, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "share_audio",
 		Description: "Share an audio overview and return its public URL when enabled.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input shareAudioInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input shareAudioInput) (*mcp.CallToolResult, sharedAudio, error) {
+		client := accounts.client(ctx)
 		option := notebooklm.SharePrivate
 		if input.Public {
 			option = notebooklm.SharePublic
 		}
 		result, err := client.ShareAudio(context.Background(), input.NotebookID, option)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to share audio: %v", err)), nil, nil
+			return nil, sharedAudio{}, fmt.Errorf("failed to share audio: %w", err)
 		}
 		if !result.IsPublic {
-			return textResult("audio sharing disabled (private)"), nil, nil
+			return textResult("audio sharing disabled (private)"), sharedAudio{}, nil
 		}
-		return textResult(result.ShareURL), nil, nil
+		return textResult(result.ShareURL), sharedAudio{Public: true, ShareURL: result.ShareURL}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "create_video_overview",
 		Description: "Create a new video overview for a notebook.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input createVideoOverviewInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input createVideoOverviewInput) (*mcp.CallToolResult, artifactOutput, error) {
+		client := accounts.client(ctx)
 		style, err := parseMCPVideoStyle(input.Style)
 		if err != nil {
-			return errorResult(err.Error()), nil, nil
+			return nil, artifactOutput{}, badArgs(err)
 		}
 		audioType, err := parseMCPAudioType(input.AudioType, pb.AudioType_AUDIO_TYPE_BRIEF)
 		if err != nil {
-			return errorResult(err.Error()), nil, nil
+			return nil, artifactOutput{}, badArgs(err)
 		}
 		result, err := client.CreateVideoOverviewWithOptions(context.Background(), input.NotebookID, notebooklm.CreateVideoOverviewOptions{
 			Instructions: input.Instructions,
@@ -414,115 +491,152 @@ func registerTools(server *mcp.Server, client *notebooklm.Client) {
 			SourceIDs:    input.SourceIDs,
 		})
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to create video overview: %v", err)), nil, nil
+			return nil, artifactOutput{}, fmt.Errorf("failed to create video overview: %w", err)
+		}
+		started := startedArtifact{
+			ID:      result.VideoID,
+			Title:   result.Title,
+			Type:    pb.ArtifactType_ARTIFACT_TYPE_VIDEO_OVERVIEW,
+			Message: fmt.Sprintf("started video overview (id: %s)", result.VideoID),
 		}
-		return textResult(fmt.Sprintf("started video overview (id: %s)", result.VideoID)), nil, nil
+		return startedArtifactResult(ctx, req, client, input.NotebookID, started, input.Wait, input.MaxWaitSeconds)
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "create_app_artifact",
 		Description: "Create a generated app artifact (prototype, mindmap, or canvas).",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input createAppArtifactInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input createAppArtifactInput) (*mcp.CallToolResult, artifactOutput, error) {
+		client := accounts.client(ctx)
 		kind, err := notebooklm.ParseAppArtifactKind(input.Type)
 		if err != nil {
-			return errorResult(err.Error()), nil, nil
+			return nil, artifactOutput{}, badArgs(err)
 		}
 		artifactID, err := client.CreateAppArtifact(context.Background(), input.NotebookID, kind, input.Instructions, input.SourceIDs)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to create app artifact: %v", err)), nil, nil
+			return nil, artifactOutput{}, fmt.Errorf("failed to create app artifact: %w", err)
 		}
-		return textResult(fmt.Sprintf("started %s app artifact (id: %s)", kind.String(), artifactID)), nil, nil
+		started := startedArtifact{
+			ID:      artifactID,
+			Type:    pb.ArtifactType_ARTIFACT_TYPE_APP,
+			Message: fmt.Sprintf("started %s app artifact (id: %s)", kind.String(), artifactID),
+		}
+		return startedArtifactResult(ctx, req, client, input.NotebookID, started, input.Wait, input.MaxWaitSeconds)
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "create_slide_deck",
 		Description: "Create a slide deck from notebook sources.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input createSlideDeckInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input createSlideDeckInput) (*mcp.CallToolResult, artifactOutput, error) {
+		client := accounts.client(ctx)
 		artifactID, err := client.CreateSlideDeck(context.Background(), input.NotebookID, input.Instructions)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to create slide deck: %v", err)), nil, nil
+			return nil, artifactOutput{}, fmt.Errorf("failed to create slide deck: %w", err)
+		}
+		started := startedArtifact{
+			ID:      artifactID,
+			Type:    pb.ArtifactType_ARTIFACT_TYPE_8,
+			Message: fmt.Sprintf("started slide deck creation (artifact id: %s)", artifactID),
+		}
+		return startedArtifactResult(ctx, req, client, input.NotebookID, started, input.Wait, input.MaxWaitSeconds)
+	})
+
+	addTool(r, &mcp.Tool{
+		Name:        "wait_for_artifact",
+		Description: "Block until an artifact finishes generating, sending MCP progress notifications while it runs, and return it with its download URLs or, for reports, its text. Use this instead of polling list_artifacts.",
+		Annotations: readOnlyAnnotations,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input waitForArtifactInput) (*mcp.CallToolResult, artifactOutput, error) {
+		client := accounts.client(ctx)
+		result, err := waitForArtifact(ctx, client, input, artifactProgressNotifier(ctx, req))
+		if err != nil {
+			return nil, artifactOutput{}, fmt.Errorf("failed to wait for artifact: %w", err)
 		}
-		return textResult(fmt.Sprintf("started slide deck creation (artifact id: %s)", artifactID)), nil, nil
+		return nil, *result, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "read_note",
 		Description: "Read a specific note by ID from a notebook. Returns the note title and content.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input readNoteInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input readNoteInput) (*mcp.CallToolResult, noteContent, error) {
+		client := accounts.client(ctx)
 		notes, err := client.GetNotes(context.Background(), input.NotebookID)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to get notes: %v", err)), nil, nil
+			return nil, noteContent{}, fmt.Errorf("failed to get notes: %w", err)
 		}
 		for _, note := range notes {
 			if note.GetNoteId() == input.NoteID {
-				return jsonResult(map[string]string{
-					"id":      note.GetNoteId(),
-					"title":   note.GetTitle(),
-					"content": note.GetContentText(),
-				}), nil, nil
+				return nil, noteContent{
+					ID:      note.GetNoteId(),
+					Title:   note.GetTitle(),
+					Content: note.GetContentText(),
+				}, nil
 			}
 		}
-		return errorResult(fmt.Sprintf("note %s not found in notebook %s", input.NoteID, input.NotebookID)), nil, nil
+		return nil, noteContent{}, fmt.Errorf("note %s not found in notebook %s: %w", input.NoteID, input.NotebookID, notebooklm.ErrNoteNotFound)
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "set_instructions",
 		Description: "Set custom chat instructions (system prompt) for a notebook.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input setInstructionsInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input setInstructionsInput) (*mcp.CallToolResult, instructionsOutput, error) {
+		client := accounts.client(ctx)
 		if err := client.SetInstructions(context.Background(), input.NotebookID, input.Instructions); err != nil {
-			return errorResult(fmt.Sprintf("failed to set instructions: %v", err)), nil, nil
+			return nil, instructionsOutput{}, fmt.Errorf("failed to set instructions: %w", err)
 		}
-		return textResult("instructions updated"), nil, nil
+		return textResult("instructions updated"), instructionsOutput{Instructions: input.Instructions}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "get_instructions",
 		Description: "Get the current custom chat instructions (system prompt) for a notebook.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input getInstructionsInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input getInstructionsInput) (*mcp.CallToolResult, instructionsOutput, error) {
+		client := accounts.client(ctx)
 		prompt, err := client.GetInstructions(context.Background(), input.NotebookID)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to get instructions: %v", err)), nil, nil
+			return nil, instructionsOutput{}, fmt.Errorf("failed to get instructions: %w", err)
 		}
 		if prompt == "" {
-			return textResult("no custom instructions set"), nil, nil
+			return textResult("no custom instructions set"), instructionsOutput{}, nil
 		}
-		return textResult(prompt), nil, nil
+		return textResult(prompt), instructionsOutput{Instructions: prompt}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "start_deep_research",
 		Description: "Start a deep research session. Returns a research ID that can be used with poll_deep_research to check progress.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input startDeepResearchInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input startDeepResearchInput) (*mcp.CallToolResult, deepResearchOutput, error) {
+		client := accounts.client(ctx)
 		result, err := client.StartDeepResearch(context.Background(), input.NotebookID, input.Query)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to start deep research: %v", err)), nil, nil
+			return nil, deepResearchOutput{}, fmt.Errorf("failed to start deep research: %w", err)
 		}
-		return jsonResult(result), nil, nil
+		return nil, researchOutput(result), nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "poll_deep_research",
 		Description: "Poll an in-progress deep research session for results. Returns done=true with content when research is complete.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input pollDeepResearchInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input pollDeepResearchInput) (*mcp.CallToolResult, deepResearchOutput, error) {
+		client := accounts.client(ctx)
 		result, err := client.PollDeepResearch(context.Background(), input.NotebookID, input.ResearchID)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to poll deep research: %v", err)), nil, nil
+			return nil, deepResearchOutput{}, fmt.Errorf("failed to poll deep research: %w", err)
 		}
-		return jsonResult(result), nil, nil
+		return nil, researchOutput(result), nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "watch_deep_research",
 		Description: "Block until deep research completes, sending MCP progress notifications while it runs.",
 		Annotations: readOnlyAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input watchDeepResearchInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input watchDeepResearchInput) (*mcp.CallToolResult, deepResearchOutput, error) {
+		client := accounts.client(ctx)
 		token := req.Params.GetProgressToken()
 		notify := func(progress researchWatchProgress) error {
 			if token == nil {
@@ -536,72 +650,158 @@ func registerTools(server *mcp.Server, client *notebooklm.Client) {
 		}
 		result, err := watchDeepResearch(ctx, client, input, notify)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to watch deep research: %v", err)), nil, nil
+			return nil, deepResearchOutput{}, fmt.Errorf("failed to watch deep research: %w", err)
 		}
-		return jsonResult(result), nil, nil
+		return nil, researchOutput(result), nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "create_notebook",
 		Description: "Create a new notebook.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input createNotebookInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input createNotebookInput) (*mcp.CallToolResult, notebookSummary, error) {
+		client := accounts.client(ctx)
 		notebook, err := client.CreateProject(context.Background(), input.Title, input.Emoji)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to create notebook: %v", err)), nil, nil
+			return nil, notebookSummary{}, fmt.Errorf("failed to create notebook: %w", err)
 		}
-		return textResult(fmt.Sprintf("created notebook %q (id: %s)", notebook.Title, notebook.ProjectId)), nil, nil
+		accounts.remember(ctx, notebook.ProjectId)
+		return textResult(fmt.Sprintf("created notebook %q (id: %s)", notebook.Title, notebook.ProjectId)), summarizeNotebook(notebook), nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "delete_notebook",
 		Description: "Delete a notebook.",
 		Annotations: destructiveAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input deleteNotebookInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input deleteNotebookInput) (*mcp.CallToolResult, deletedOutput, error) {
+		client := accounts.client(ctx)
 		if err := client.DeleteProjects(context.Background(), []string{input.NotebookID}); err != nil {
-			return errorResult(fmt.Sprintf("failed to delete notebook: %v", err)), nil, nil
+			return nil, deletedOutput{}, fmt.Errorf("failed to delete notebook: %w", err)
 		}
-		return textResult(fmt.Sprintf("deleted notebook %s", input.NotebookID)), nil, nil
+		return textResult(fmt.Sprintf("deleted notebook %s", input.NotebookID)), deletedOutput{ID: input.NotebookID}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "delete_source",
 		Description: "Remove a source from a notebook.",
 		Annotations: destructiveAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input deleteSourceInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input deleteSourceInput) (*mcp.CallToolResult, deletedOutput, error) {
+		client := accounts.client(ctx)
 		if err := client.DeleteSources(context.Background(), input.NotebookID, []string{input.SourceID}); err != nil {
-			return errorResult(fmt.Sprintf("failed to delete source: %v", err)), nil, nil
+			return nil, deletedOutput{}, fmt.Errorf("failed to delete source: %w", err)
 		}
-		return textResult(fmt.Sprintf("deleted source %s from notebook %s", input.SourceID, input.NotebookID)), nil, nil
+		return textResult(fmt.Sprintf("deleted source %s from notebook %s", input.SourceID, input.NotebookID)), deletedOutput{ID: input.SourceID}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(r, &mcp.Tool{
 		Name:        "add_source_url",
 		Description: "Add a source from a URL.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input addSourceURLInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input addSourceURLInput) (*mcp.CallToolResult, addedSource, error) {
+		client := accounts.client(ctx)
 		sourceID, err := client.AddSourceFromURL(context.Background(), input.NotebookID, input.URL)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to add source: %v", err)), nil, nil
+			return nil, addedSource{}, fmt.Errorf("failed to add source: %w", err)
 		}
-		return textResult(fmt.Sprintf("added source from url (id: %s)", sourceID)), nil, nil
+		return textResult(fmt.Sprintf("added source from url (id: %s)", sourceID)), addedSource{ID: sourceID, URL: input.URL}, nil
 	})
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "generate_chat",
-		Description: "Generate a NotebookLM chat response from a prompt.",
+	addTool(r, &mcp.Tool{
+		Name:        "chat",
+		Description: "Ask the notebook a question and return the answer with its citations and suggested follow-ups. Pass conversation_id to continue a conversation, and source_ids, source_match, or label_match to restrict the sources used. Answer text is sent as MCP progress notifications while it streams.",
 		Annotations: mutatingAnnotations,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input generateChatInput) (*mcp.CallToolResult, any, error) {
-		response, err := client.GenerateFreeFormStreamed(context.Background(), input.NotebookID, input.Prompt, nil)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input chatInput) (*mcp.CallToolResult, chatOutput, error) {
+		client := accounts.client(ctx)
+		token := req.Params.GetProgressToken()
+		notify := func(progress chatProgress) error {
+			if token == nil {
+				return nil
+			}
+			return req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Progress:      progress.Value,
+				Message:       progress.Message,
+			})
+		}
+		out, err := chat(ctx, client, input, notify)
 		if err != nil {
-			return errorResult(fmt.Sprintf("failed to generate chat: %v", err)), nil, nil
+			return nil, chatOutput{}, fmt.Errorf("failed to chat: %w", err)
 		}
-		if response == nil || response.Chunk == "" {
-			return textResult("(no response received)"), nil, nil
+		return nil, out, nil
+	})
+}
+
+// toolRegistry adds tools to a server, leaving out those its policy
+// excludes. It remembers which of the added tools change NotebookLM state,
+// so the resource catalog can follow their calls with updates.
+type toolRegistry struct {
+	server   *mcp.Server
+	policy   *Policy
+	mutating map[string]bool
+}
+
+func newToolRegistry(server *mcp.Server, policy *Policy) *toolRegistry {
+	return &toolRegistry{server: server, policy: policy, mutating: make(map[string]bool)}
+}
+
+// add registers t with an untyped handler unless the policy leaves it out.
+// A later tool of the same name replaces an earlier one.
+func (r *toolRegistry) add(t *mcp.Tool, h mcp.ToolHandler) {
+	if r.allows(t) {
+		r.server.AddTool(t, h)
+	}
+}
+
+// allows reports whether t passes the policy, and if so records whether it
+// mutates: any tool not annotated read-only does.
+func (r *toolRegistry) allows(t *mcp.Tool) bool {
+	if !r.policy.allowsTool(t) {
+		return false
+	}
+	r.mutating[t.Name] = t.Annotations == nil || !t.Annotations.ReadOnlyHint
+	return true
+}
+
+// addTool registers a tool on r unless its policy leaves it out. The
+// tool's output schema is derived from Out, and h's output is returned as
+// structured content. A nil result from h gets out as indented JSON text;
+// an error becomes a toolErrorResult.
+func addTool[In, Out any](r *toolRegistry, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	if !r.allows(t) {
+		return
+	}
+	if t.OutputSchema == nil {
+		schema, err := jsonschema.For[Out](nil)
+		if err != nil {
+			panic(fmt.Sprintf("tool %q: output schema: %v", t.Name, err))
+		}
+		t.OutputSchema = schema
+	}
+	// The SDK fills StructuredContent for typed outputs even on error
+	// results, so h is adapted to an untyped handler that sets it itself.
+	mcp.AddTool(r.server, t, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error) {
+		res, out, err := h(ctx, req, input)
+		if err != nil {
+			return toolErrorResult(err), nil, nil
+		}
+		if res == nil {
+			res = jsonResult(out)
 		}
-		return textResult(response.Chunk), nil, nil
+		res.StructuredContent = out
+		return res, nil, nil
 	})
+}
 
+func summarizeNotebook(notebook *notebooklm.Notebook) notebookSummary {
+	item := notebookSummary{
+		ID:    notebook.ProjectId,
+		Title: notebook.Title,
+		Emoji: notebook.Emoji,
+	}
+	if meta := notebook.GetMetadata(); meta != nil && meta.GetCreateTime() != nil {
+		item.CreatedAt = meta.GetCreateTime().AsTime().Format("2006-01-02T15:04:05Z07:00")
+	}
+	return item
 }
 
 func textResult(text string) *mcp.CallToolResult {
@@ -629,6 +829,28 @@ func errorResult(text string) *mcp.CallToolResult {
 	}
 }
 
+// toolErrorResult reports err as a tool error. Errors with a distinct exit
+// class name it in the text, as the CLI does on stderr, and in the result's
+// _meta as exit_class and retryable.
+func toolErrorResult(err error) *mcp.CallToolResult {
+	class := exitclass.Of(err)
+	name := class.String()
+	if name == "" {
+		return errorResult(err.Error())
+	}
+	res := errorResult(fmt.Sprintf("%v\nexit-class=%s", err, name))
+	res.Meta = mcp.Meta{
+		"exit_class": name,
+		"retryable":  class.Retryable(),
+	}
+	return res
+}
+
+// badArgs marks err as a problem with the tool's arguments.
+func badArgs(err error) error {
+	return exitclass.With(exitclass.BadArgs, err)
+}
+
 func paginate[T any](items []T, limit, offset int) pageResult[T] {
 	limit = normalizeLimit(limit)
 	offset = normalizeOffset(offset)
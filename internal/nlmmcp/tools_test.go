@@ -1,9 +1,15 @@
 package nlmmcp
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/exitclass"
 )
 
 func TestPaginateDefaultsAndBounds(t *testing.T) {
@@ -91,3 +97,70 @@ func TestCreateOptionParsers(t *testing.T) {
 		t.Fatalf("style = %v, want whiteboard", style)
 	}
 }
+
+func TestToolsDeclareOutputSchemas(t *testing.T) {
+	t.Parallel()
+
+	cs := connectOptions(t, &Options{
+		Commands:   []CommandTool{{Name: "source_sync", Path: []string{"source", "sync"}, Args: "<notebook-id>"}},
+		RunCommand: func(context.Context, Selection, []string) (string, error) { return "", nil },
+	})
+	result, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range result.Tools {
+		schema, _ := tool.OutputSchema.(map[string]any)
+		if schema["type"] != "object" {
+			t.Errorf("%s: output schema = %v, want an object schema", tool.Name, tool.OutputSchema)
+		}
+	}
+}
+
+func TestToolResultsAreStructured(t *testing.T) {
+	t.Parallel()
+
+	cs := connectOptions(t, &Options{
+		Commands: []CommandTool{{Name: "source_sync", Path: []string{"source", "sync"}, Args: "<notebook-id>"}},
+		RunCommand: func(_ context.Context, _ Selection, args []string) (string, error) {
+			if args[len(args)-1] == "missing" {
+				return "", exitclass.With(exitclass.NotFound, errors.New("notebook missing not found"))
+			}
+			return "synced 3 files\n", nil
+		},
+	})
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "source_sync", Arguments: map[string]any{"args": []string{"nb1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(res.StructuredContent)
+	if res.IsError || string(data) != `{"output":"synced 3 files\n"}` {
+		t.Errorf("structured content = %s (error %v)", data, res.IsError)
+	}
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		class     string
+		retryable bool
+	}{
+		{"source_sync", map[string]any{"args": []string{"missing"}}, "not-found", false},
+		{"source_sync", map[string]any{"unknown": true}, "bad-args", false},
+		{"wait_for_artifact", map[string]any{"artifact_id": ""}, "bad-args", false},
+	}
+	for _, tt := range tests {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: tt.name, Arguments: tt.args})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		text := res.Content[0].(*mcp.TextContent).Text
+		if !res.IsError || res.StructuredContent != nil || !strings.HasSuffix(text, "exit-class="+tt.class) {
+			t.Errorf("%s(%v) = %q (error %v, structured %v)", tt.name, tt.args, text, res.IsError, res.StructuredContent)
+		}
+		if res.Meta["exit_class"] != tt.class || res.Meta["retryable"] != tt.retryable {
+			t.Errorf("%s(%v) meta = %v, want exit_class %s", tt.name, tt.args, res.Meta, tt.class)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package richrender
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// answerNoteExcerptBudget clips footnote excerpts when ExcerptBudget is 0.
+// A note keeps the evidence next to the answer, so it carries excerpts by
+// default, but short enough that the footnotes stay readable in the web UI.
+const answerNoteExcerptBudget = 300
+
+// renderAnswerNoteMarkdown writes one assistant turn as the Markdown body of
+// a notebook note. Each cited [N] marker in the answer becomes a footnote
+// reference, and each footnote names the source and quotes its excerpt, so
+// the citation structure survives outside the chat. Markers inside code
+// spans, and markers with no citation, are left as written.
+func renderAnswerNoteMarkdown(out io.Writer, m ChatMessage, ctx RenderContext) error {
+	budget := ctx.ExcerptBudget
+	if budget <= 0 {
+		budget = answerNoteExcerptBudget
+	}
+	body := m.Content
+	if shouldReflowFromTree(m.Rich, m.Content) {
+		if reflowed := flattenText(projectRichDocument(m.Rich)); reflowed != "" {
+			body = reflowed
+		}
+	}
+	order, groups := groupCitationsByIndex(m.Citations)
+
+	bw := &markdownWriter{w: out}
+	bw.line(strings.TrimSpace(footnoteMarkers(body, groups)))
+	if len(order) > 0 {
+		bw.blank()
+	}
+	for _, idx := range order {
+		var parts []string
+		for _, c := range groups[idx] {
+			parts = append(parts, answerNoteCitation(c, ctx, budget))
+		}
+		bw.linef("[^%d]: %s", idx, strings.Join(parts, "; "))
+	}
+	return bw.err
+}
+
+// footnoteMarkers rewrites [N], [N, M] and [N-M] markers outside code spans
+// as [^N] footnote references when every index they name is cited.
+func footnoteMarkers(body string, cited map[int][]notebooklm.Citation) string {
+	replace := func(text string) string {
+		return htmlMarkerRe.ReplaceAllStringFunc(text, func(marker string) string {
+			indices, ok := citationIndices(marker[1 : len(marker)-1])
+			if !ok {
+				return marker
+			}
+			var b strings.Builder
+			for _, index := range indices {
+				if len(cited[index]) == 0 {
+					return marker
+				}
+				fmt.Fprintf(&b, "[^%d]", index)
+			}
+			return b.String()
+		})
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range chatMarkdownCodePattern.FindAllStringIndex(body, -1) {
+		b.WriteString(replace(body[last:loc[0]]))
+		b.WriteString(body[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(replace(body[last:]))
+	return b.String()
+}
+
+// answerNoteCitation formats one source of a footnote: its title (or short
+// handle) and, when the citation carries one, its excerpt on one line.
+func answerNoteCitation(c notebooklm.Citation, ctx RenderContext, budget int) string {
+	name := collapseWhitespace(ctx.citationSourceTitle(c))
+	if name == "" {
+		name = shortSourceID(c.SourceID)
+	}
+	if name == "" {
+		name = "source"
+	}
+	s := "**" + mdEscape(name) + "**"
+	if excerpt := truncateExcerpt(c.Excerpt, budget); excerpt != "" {
+		s += ": “" + mdEscape(excerpt) + "”"
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package richrender
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+func TestRenderAnswerNoteMarkdown(t *testing.T) {
+	m := ChatMessage{
+		Role:    "assistant",
+		Content: "Cats purr [1]. Dogs bark [2, 3].\n\nIndexing `a[1]` is code. Unsupported [9].",
+		Citations: []notebooklm.Citation{
+			{SourceIndex: 1, SourceID: "chunk-aaaa1111", ParentSourceID: "src-cats", Excerpt: "Domestic cats\npurr when content."},
+			{SourceIndex: 2, SourceID: "chunk-bbbb2222", Title: "Dogs | a field guide", Excerpt: "Dogs bark to `alert`."},
+			{SourceIndex: 3, SourceID: "chunk-cccc3333"},
+		},
+	}
+	ctx := RenderContext{ResolveTitle: func(id string) string {
+		if id == "src-cats" {
+			return "Cats"
+		}
+		return ""
+	}}
+	var buf bytes.Buffer
+	if err := renderAnswerNoteMarkdown(&buf, m, ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := "Cats purr [^1]. Dogs bark [^2][^3].\n\nIndexing `a[1]` is code. Unsupported [9].\n" +
+		"\n" +
+		"[^1]: **Cats**: “Domestic cats purr when content.”\n" +
+		"[^2]: **Dogs \\| a field guide**: “Dogs bark to \\`alert\\`.”\n" +
+		"[^3]: **chunk-cc**\n"
+	if got := buf.String(); got != want {
+		t.Errorf("note body:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderAnswerNoteMarkdownClipsExcerpts(t *testing.T) {
+	m := ChatMessage{
+		Role:      "assistant",
+		Content:   "Long [1]",
+		Citations: []notebooklm.Citation{{SourceIndex: 1, Title: "Book", Excerpt: "abcdefghijklmnop"}},
+	}
+	var buf bytes.Buffer
+	if err := renderAnswerNoteMarkdown(&buf, m, RenderContext{ExcerptBudget: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Long [^1]\n\n[^1]: **Book**: “abcde…”\n"; buf.String() != want {
+		t.Errorf("note body = %q, want %q", buf.String(), want)
+	}
+}
@@ -2,6 +2,7 @@ package richrender
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"strings"
 
@@ -81,6 +82,12 @@ type ChatMessage struct {
 	// and headings. It is strictly additive: every renderer keeps flat Content as
 	// the floor and only consults Rich as a progressive enhancement.
 	Rich *RichDocument
+
+	// Scope describes how a user turn's sources were chosen (for example
+	// "/label Papers"), and ScopeSourceIDs lists them. Both are empty when
+	// the turn used the whole notebook or predates scope tracking.
+	Scope          string
+	ScopeSourceIDs []string
 }
 
 // RenderContext carries the per-render options and the optional resolution
@@ -108,6 +115,25 @@ type RenderContext struct {
 	Debug         io.Writer // optional destination for citation-resolution diagnostics
 }
 
+// scopeLine describes the sources a user turn was grounded on, naming
+// each by its resolved title when one is available; "" for no scope.
+func (ctx RenderContext) scopeLine(m ChatMessage) string {
+	if m.Scope == "" {
+		return ""
+	}
+	names := make([]string, 0, len(m.ScopeSourceIDs))
+	for _, id := range m.ScopeSourceIDs {
+		name := id
+		if ctx.ResolveTitle != nil {
+			if t := ctx.ResolveTitle(id); t != "" {
+				name = t
+			}
+		}
+		names = append(names, name)
+	}
+	return fmt.Sprintf("scope: %s — %s", m.Scope, strings.Join(names, ", "))
+}
+
 // citationSourceTitle returns the best display title for a citation: a resolved
 // notebook title when available, else the server-supplied Title, else "". All
 // three format renderers share this so titling never diverges between surfaces.
@@ -76,6 +76,7 @@ type htmlSpan struct {
 type htmlMessage struct {
 	Role    string       `json:"role"`
 	Content string       `json:"content"`
+	Scope   string       `json:"scope,omitempty"` // user turns: the sources the turn was grounded on
 	Markers []htmlMarker `json:"markers,omitempty"`
 }
 
@@ -213,7 +214,7 @@ func buildHTMLPayload(doc ChatDocument, ctx RenderContext) htmlPayload {
 
 	out := htmlPayload{Title: displayTitle(doc)}
 	for _, m := range doc.Messages {
-		hm := htmlMessage{Role: m.Role, Content: m.Content}
+		hm := htmlMessage{Role: m.Role, Content: m.Content, Scope: ctx.scopeLine(m)}
 		// The reasoning trace is not shipped in the JSON blob — it is
 		// server-rendered into a <template class="thinking-body"> instead (see
 		// buildAnswerTemplates), so the client clones it rather than reading it
@@ -690,6 +691,7 @@ header.doc .sub { color: var(--muted); font-size: 13px; }
   color: var(--faint); margin-bottom: 10px;
 }
 .turn.user .role { color: var(--accent-strong); }
+.turn.user .scope { max-width: var(--measure); margin-top: 6px; color: var(--muted); font-size: 12px; }
 
 .bubble.user {
   max-width: var(--measure);
@@ -1331,6 +1333,7 @@ header.doc .sub { color: var(--muted); font-size: 13px; }
 
       if (msg.role === "user") {
         turn.appendChild(el("div", "bubble user", msg.content));
+        if (msg.scope) turn.appendChild(el("div", "scope", msg.scope));
         root.appendChild(turn);
         return;
       }
@@ -27,6 +27,10 @@ func renderChatMarkdown(out io.Writer, doc ChatDocument, ctx RenderContext) erro
 			bw.blank()
 		}
 		bw.line(m.Content)
+		if line := ctx.scopeLine(m); line != "" {
+			bw.blank()
+			bw.line(mdEscape(line))
+		}
 		if m.Role == "assistant" && len(m.Citations) > 0 {
 			bw.blank()
 			if ctx.ExcerptBudget > 0 {
@@ -17,6 +17,9 @@ func renderChatText(out, status io.Writer, doc ChatDocument, mode CitationMode,
 		fmt.Fprintf(out, "[%s]\n", strings.ToUpper(message.Role))
 		if message.Role != "assistant" {
 			fmt.Fprintln(out, message.Content)
+			if line := ctx.scopeLine(message); line != "" {
+				fmt.Fprintf(out, "(%s)\n", line)
+			}
 			continue
 		}
 		if ctx.ShowThinking && message.Thinking != "" {
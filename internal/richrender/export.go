@@ -100,6 +100,12 @@ func RenderChatMarkdown(w io.Writer, doc ChatDocument, ctx RenderContext) error
 	return renderChatMarkdown(w, doc, ctx)
 }
 
+// RenderAnswerNoteMarkdown writes one assistant turn as a note body, with
+// its citations as Markdown footnotes.
+func RenderAnswerNoteMarkdown(w io.Writer, m ChatMessage, ctx RenderContext) error {
+	return renderAnswerNoteMarkdown(w, m, ctx)
+}
+
 // RenderChatText writes a terminal-friendly conversation and citation status.
 func RenderChatText(out, status io.Writer, doc ChatDocument, mode CitationMode, ctx RenderContext) error {
 	return renderChatText(out, status, doc, mode, ctx)
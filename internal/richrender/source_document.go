@@ -47,10 +47,10 @@ func (m sourceContentModel) ContentFragment(i int) ContentFragment {
 		RangeMismatch: f.RangeMismatch,
 		BlockStart:    f.BlockStart,
 	}
-	if !f.IsImage() {
-		if name, ok := sourceTxtarHeader(f.Text); ok {
-			fragment.MemberName = name
-		}
+	if f.IsImage() {
+		fragment.ImageAlt = sourceImageAlt(m.body.Fragments, i)
+	} else if name, ok := sourceTxtarHeader(f.Text); ok {
+		fragment.MemberName = name
 	}
 	return fragment
 }
@@ -0,0 +1,468 @@
+package richrender
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// SourceImageFetcher returns the bytes and content type of a source image so
+// the Markdown and HTML views can inline it as a data URI.
+type SourceImageFetcher func(imageURL string) ([]byte, string, error)
+
+// RenderSource emits a source body's fragments to emitter in reading order.
+func RenderSource(body notebooklm.LoadSourceText, emitter ContentEmitter) error {
+	return RenderContent(sourceContentModel{body: body}, emitter)
+}
+
+// SourceMarkdown renders a source body as Markdown for reading. Offset gaps
+// become reading-flow whitespace, code keeps its fences, archive members get
+// their own paragraphs, and images are inlined through fetchImage.
+func SourceMarkdown(body notebooklm.LoadSourceText, fetchImage SourceImageFetcher) (string, error) {
+	emitter := sourceMarkdownEmitter{
+		cursor:     firstSourceFragmentOffset(body.Fragments),
+		fetchImage: fetchImage,
+	}
+	if err := RenderSource(body, &emitter); err != nil {
+		return "", err
+	}
+	return emitter.out.String(), nil
+}
+
+type sourceMarkdownEmitter struct {
+	out        strings.Builder
+	cursor     int
+	inList     bool
+	fetchImage SourceImageFetcher
+}
+
+func (e *sourceMarkdownEmitter) EmitContent(fragment ContentFragment) error {
+	switch fragment.Kind {
+	case ContentMember:
+		e.inList = false
+		writeSourceBreak(&e.out)
+		e.out.WriteString(fragment.Text)
+		writeSourceBreak(&e.out)
+		e.cursor = fragment.End
+		return nil
+	case ContentCode:
+		e.inList = false
+		writeSourceBreak(&e.out)
+		fence := markdownCodeFence(fragment.Text)
+		e.out.WriteString(fence)
+		e.out.WriteString(markdownCodeLanguage(fragment.Language))
+		e.out.WriteByte('\n')
+		e.out.WriteString(fragment.Text)
+		if !strings.HasSuffix(fragment.Text, "\n") {
+			e.out.WriteByte('\n')
+		}
+		e.out.WriteString(fence)
+		writeSourceBreak(&e.out)
+		e.cursor = fragment.End
+		return nil
+	}
+	if fragment.BlockStart && fragment.ListMarker == "" && e.out.Len() > 0 && !strings.HasSuffix(e.out.String(), "\n\n") {
+		e.out.WriteString("\n\n")
+		e.cursor = fragment.Start
+	}
+	// The indexed HTML/table payload represents each Markdown table row as
+	// a separate fragment beginning with '|'. Its one-character offset gap
+	// is a space, not a newline, so preserve the row boundary only in the
+	// presentation-oriented Markdown view. Full remains offset-faithful.
+	if isMarkdownTableRow(fragment.Text) {
+		e.inList = false
+		if e.out.Len() > 0 && !strings.HasSuffix(e.out.String(), "\n") {
+			e.out.WriteByte('\n')
+		}
+		if strings.HasSuffix(e.out.String(), "\n") {
+			e.cursor = fragment.Start
+		}
+	}
+	if fragment.ListMarker != "" {
+		if e.out.Len() > 0 && !strings.HasSuffix(e.out.String(), "\n") {
+			e.out.WriteString("\n\n")
+		}
+		if !e.inList && e.out.Len() > 0 && !strings.HasSuffix(e.out.String(), "\n\n") {
+			e.out.WriteByte('\n')
+		}
+		e.out.WriteString(markdownListMarker(fragment.ListMarker))
+		e.out.WriteByte(' ')
+		e.out.WriteString(sourceMarkdownText(fragment))
+		e.out.WriteByte('\n')
+		e.cursor = fragment.End
+		e.inList = true
+		return nil
+	}
+	e.inList = false
+	writeSourceGap(&e.out, e.cursor, fragment.Start)
+	if fragment.Kind == ContentImage {
+		image, err := sourceImageDataURI(fragment, e.fetchImage)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&e.out, "![%s](%s)", fragment.ImageAlt, image)
+	} else {
+		e.out.WriteString(sourceMarkdownText(fragment))
+	}
+	e.cursor = fragment.End
+	return nil
+}
+
+func (*sourceMarkdownEmitter) FinishContent() error {
+	return nil
+}
+
+func sourceImageAlt(fragments []notebooklm.TextFragment, imageIndex int) string {
+	if imageIndex+1 >= len(fragments) {
+		return ""
+	}
+	next := fragments[imageIndex+1]
+	if next.IsImage() {
+		return ""
+	}
+	text := strings.TrimSpace(next.Text)
+	if !strings.HasPrefix(text, "Figure ") && !strings.HasPrefix(text, "Table ") && !strings.HasPrefix(text, "Chart ") {
+		return ""
+	}
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	const maxAlt = 240
+	if len(text) > maxAlt {
+		text = text[:maxAlt-1] + "…"
+	}
+	return text
+}
+
+func sourceMarkdownText(f ContentFragment) string {
+	text := normalizeMathNoise(f.Text)
+	if text != f.Text {
+		return text
+	}
+	return wrapMarkdownText(text, f.Bold, f.Italic)
+}
+
+func wrapMarkdownText(text string, bold, italic bool) string {
+	if (!bold && !italic) || text == "" {
+		return text
+	}
+	left := len(text) - len(strings.TrimLeft(text, " \t\n"))
+	right := len(text) - len(strings.TrimRight(text, " \t\n"))
+	if left+right >= len(text) {
+		return text
+	}
+	prefix := text[:left]
+	core := text[left : len(text)-right]
+	suffix := text[len(text)-right:]
+	marker := "_"
+	if bold {
+		marker = "**"
+	}
+	if bold && italic {
+		marker = "***"
+	}
+	return prefix + marker + core + marker + suffix
+}
+
+func markdownListMarker(marker string) string {
+	if marker == "•" || marker == "◦" || marker == "▪" {
+		return "-"
+	}
+	if strings.HasSuffix(marker, ".") {
+		return marker
+	}
+	return "-"
+}
+
+func markdownCodeFence(code string) string {
+	if strings.Contains(code, "```") {
+		return "````"
+	}
+	return "```"
+}
+
+func markdownCodeLanguage(language string) string {
+	language = strings.TrimSpace(language)
+	if language == "" {
+		return ""
+	}
+	for _, r := range language {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			continue
+		}
+		switch r {
+		case '+', '-', '_', '.', '#':
+			continue
+		}
+		return ""
+	}
+	return language
+}
+
+func sourceImageDataURI(f ContentFragment, fetchImage SourceImageFetcher) (string, error) {
+	if fetchImage == nil {
+		return "", fmt.Errorf("fetch source image %s: no image fetcher", f.ImageID)
+	}
+	data, contentType, err := fetchImage(f.ImageURL)
+	if err != nil {
+		return "", fmt.Errorf("fetch source image %s: %w", f.ImageID, err)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("fetch source image %s: got %q", f.ImageID, contentType)
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// SourceHTML writes a responsive reading view from the server's ordered
+// fragments. hizoJc does not expose page coordinates, so this deliberately
+// reconstructs document flow rather than trying to synthesize pixel layout.
+func SourceHTML(body notebooklm.LoadSourceText, fetchImage SourceImageFetcher) (string, error) {
+	emitter := sourceHTMLEmitter{
+		cursor:     firstSourceFragmentOffset(body.Fragments),
+		fetchImage: fetchImage,
+	}
+	emitter.out.WriteString("<!doctype html>\n<html><head><meta charset=utf-8><meta name=viewport content=\"width=device-width, initial-scale=1\"><title>")
+	emitter.out.WriteString(html.EscapeString(body.Title))
+	emitter.out.WriteString("</title><script>window.MathJax={tex:{inlineMath:[[\"$\",\"$\"],[\"\\\\(\",\"\\\\)\"]],displayMath:[[\"$$\",\"$$\"],[\"\\\\[\",\"\\\\]\"]]}};</script><script defer src=\"https://cdn.jsdelivr.net/npm/mathjax@3/es5/tex-mml-chtml.js\"></script><style>body{margin:0;background:#f6f7f8;color:#1f2328;font:18px/1.55 system-ui,sans-serif}main{max-width:52rem;margin:auto;padding:2rem;background:#fff;min-height:100vh}h1{line-height:1.2}p{margin:1em 0}img{display:block;max-width:100%;height:auto;margin:1.5em auto}table{border-collapse:collapse;display:block;max-width:100%;overflow:auto;margin:1.5em 0}th,td{border:1px solid #d0d7de;padding:.35em .6em;text-align:left}th{background:#f6f8fa}code{white-space:pre-wrap}pre{overflow:auto;padding:1rem;background:#f6f8fa;border-radius:.4rem}pre code{white-space:pre}.txtar-member{font-size:1rem}</style></head><body><main>")
+	if body.Title != "" {
+		emitter.out.WriteString("<h1>")
+		emitter.out.WriteString(html.EscapeString(body.Title))
+		emitter.out.WriteString("</h1>")
+	}
+	if err := RenderSource(body, &emitter); err != nil {
+		return "", err
+	}
+	return emitter.out.String(), nil
+}
+
+type sourceHTMLEmitter struct {
+	out        strings.Builder
+	prose      strings.Builder
+	rows       []string
+	listItems  []string
+	cursor     int
+	fetchImage SourceImageFetcher
+}
+
+func (e *sourceHTMLEmitter) flushProse() {
+	if e.prose.Len() == 0 {
+		return
+	}
+	writeHTMLProse(&e.out, e.prose.String())
+	e.prose.Reset()
+}
+
+func (e *sourceHTMLEmitter) flushTable() {
+	if len(e.rows) == 0 {
+		return
+	}
+	writeHTMLTable(&e.out, e.rows)
+	e.rows = nil
+}
+
+func (e *sourceHTMLEmitter) flushList() {
+	if len(e.listItems) == 0 {
+		return
+	}
+	e.out.WriteString("<ul>")
+	for _, item := range e.listItems {
+		e.out.WriteString("<li>")
+		e.out.WriteString(item)
+		e.out.WriteString("</li>")
+	}
+	e.out.WriteString("</ul>")
+	e.listItems = nil
+}
+
+func (e *sourceHTMLEmitter) EmitContent(fragment ContentFragment) error {
+	if fragment.BlockStart && fragment.ListMarker == "" {
+		e.flushTable()
+		e.flushList()
+		if e.prose.Len() > 0 && !strings.HasSuffix(e.prose.String(), "\n\n") {
+			e.prose.WriteString("\n\n")
+		}
+		e.cursor = fragment.Start
+	}
+	switch fragment.Kind {
+	case ContentImage:
+		e.flushProse()
+		e.flushTable()
+		e.flushList()
+		image, err := sourceImageDataURI(fragment, e.fetchImage)
+		if err != nil {
+			return err
+		}
+		e.out.WriteString("<img alt=\"")
+		e.out.WriteString(html.EscapeString(fragment.ImageAlt))
+		e.out.WriteString("\" src=\"")
+		e.out.WriteString(html.EscapeString(image))
+		e.out.WriteString("\">")
+	case ContentMember:
+		e.flushProse()
+		e.flushTable()
+		e.flushList()
+		e.out.WriteString("<hr><h2 class=\"txtar-member\"><code>")
+		e.out.WriteString(html.EscapeString(fragment.MemberName))
+		e.out.WriteString("</code></h2>")
+	case ContentCode:
+		e.flushProse()
+		e.flushTable()
+		e.flushList()
+		e.out.WriteString("<pre><code")
+		if language := markdownCodeLanguage(fragment.Language); language != "" {
+			e.out.WriteString(" class=\"language-")
+			e.out.WriteString(html.EscapeString(language))
+			e.out.WriteString("\"")
+		}
+		e.out.WriteString(">")
+		e.out.WriteString(html.EscapeString(fragment.Text))
+		e.out.WriteString("</code></pre>")
+	default:
+		text := normalizeMathNoise(fragment.Text)
+		if isMarkdownTableRow(text) {
+			e.flushProse()
+			e.flushList()
+			e.rows = append(e.rows, text)
+			e.cursor = fragment.End
+			return nil
+		}
+		e.flushTable()
+		if fragment.ListMarker != "" {
+			e.flushProse()
+			e.listItems = append(e.listItems, sourceHTMLText(text, fragment))
+			e.cursor = fragment.End
+			return nil
+		}
+		e.flushList()
+		writeSourceGap(&e.prose, e.cursor, fragment.Start)
+		e.prose.WriteString(sourceHTMLText(text, fragment))
+	}
+	e.cursor = fragment.End
+	return nil
+}
+
+func (e *sourceHTMLEmitter) FinishContent() error {
+	e.flushProse()
+	e.flushTable()
+	e.flushList()
+	e.out.WriteString("</main></body></html>\n")
+	return nil
+}
+
+func writeHTMLProse(out *strings.Builder, text string) {
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		out.WriteString("<p>")
+		out.WriteString(strings.ReplaceAll(paragraph, "\n", "<br>\n"))
+		out.WriteString("</p>")
+	}
+}
+
+func sourceHTMLText(text string, f ContentFragment) string {
+	text = html.EscapeString(text)
+	if f.Bold {
+		text = "<strong>" + text + "</strong>"
+	}
+	if f.Italic {
+		text = "<em>" + text + "</em>"
+	}
+	return text
+}
+
+func writeHTMLTable(out *strings.Builder, rows []string) {
+	out.WriteString("<table>")
+	for i, row := range rows {
+		if i == 1 && isMarkdownTableDivider(row) {
+			continue
+		}
+		cell := "td"
+		if i == 0 {
+			cell = "th"
+		}
+		out.WriteString("<tr>")
+		for _, value := range markdownTableCells(row) {
+			out.WriteString("<")
+			out.WriteString(cell)
+			out.WriteString(">")
+			out.WriteString(html.EscapeString(value))
+			out.WriteString("</")
+			out.WriteString(cell)
+			out.WriteString(">")
+		}
+		out.WriteString("</tr>")
+	}
+	out.WriteString("</table>")
+}
+
+func markdownTableCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+	parts := strings.Split(row, "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func isMarkdownTableDivider(row string) bool {
+	for _, cell := range markdownTableCells(row) {
+		cell = strings.Trim(cell, ":-")
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+var mathNoise = regexp.MustCompile(`([[:alpha:]\x{1D400}-\x{1D7FF}]) (subscript|superscript) ([[:alnum:]+-]+)`)
+
+// normalizeMathNoise repairs the only unambiguous flattened form observed in
+// HTML-derived sources: a math glyph followed by "subscript" or
+// "superscript" and one token. It intentionally leaves all other text alone.
+func normalizeMathNoise(text string) string {
+	if !strings.Contains(text, "subscript") && !strings.Contains(text, "superscript") {
+		return text
+	}
+	if !containsMathRune(text) {
+		return text
+	}
+	clean := mathNoise.ReplaceAllStringFunc(text, func(match string) string {
+		parts := strings.Fields(match)
+		if len(parts) != 3 {
+			return match
+		}
+		if parts[1] == "subscript" {
+			return parts[0] + "_{" + parts[2] + "}"
+		}
+		return parts[0] + "^{" + parts[2] + "}"
+	})
+	if clean == text {
+		return text
+	}
+	if strings.Contains(clean, "\n") {
+		return "$$\n" + clean + "\n$$"
+	}
+	return "$" + clean + "$"
+}
+
+func containsMathRune(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Sm, r) || (r >= 0x1D400 && r <= 0x1D7FF) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMarkdownTableRow(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), "|")
+}
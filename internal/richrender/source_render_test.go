@@ -0,0 +1,15 @@
+package richrender
+
+import "testing"
+
+func TestNormalizeMathNoise(t *testing.T) {
+	if got, want := normalizeMathNoise("𝑧 subscript 1 + 𝑧 superscript n"), "$𝑧_{1} + 𝑧^{n}$"; got != want {
+		t.Errorf("normalizeMathNoise = %q, want %q", got, want)
+	}
+	if got, want := normalizeMathNoise("a subscript is a label"), "a subscript is a label"; got != want {
+		t.Errorf("normalizeMathNoise prose = %q, want %q", got, want)
+	}
+	if got, want := normalizeMathNoise("𝑧 subscript 1\n𝑧 superscript n"), "$$\n𝑧_{1}\n𝑧^{n}\n$$"; got != want {
+		t.Errorf("normalizeMathNoise block = %q, want %q", got, want)
+	}
+}
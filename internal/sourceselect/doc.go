@@ -0,0 +1,4 @@
+// Package sourceselect resolves source and label selectors (explicit IDs,
+// title regular expressions, and label names) to the notebook source IDs a
+// command or tool should act on.
+package sourceselect
@@ -0,0 +1,249 @@
+package sourceselect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/nlm/notebooklm"
+)
+
+// Options holds the raw selector flags. SourceIDs and LabelIDs are
+// comma-separated lists; the match and exclude fields are regular
+// expressions over source titles and IDs, or label names.
+type Options struct {
+	SourceIDs     string
+	SourceMatch   string
+	SourceExclude string
+	LabelIDs      string
+	LabelMatch    string
+	LabelExclude  string
+}
+
+// Empty reports whether no selector is set.
+func (opts Options) Empty() bool {
+	return opts.SourceIDs == "" &&
+		opts.SourceMatch == "" &&
+		opts.SourceExclude == "" &&
+		opts.LabelIDs == "" &&
+		opts.LabelMatch == "" &&
+		opts.LabelExclude == ""
+}
+
+// Client is the subset of notebooklm.Client that Select reads.
+type Client interface {
+	GetProject(ctx context.Context, projectID string) (*notebooklm.Notebook, error)
+	GetLabels(ctx context.Context, projectID string) ([]notebooklm.Label, error)
+}
+
+// Select resolves opts against a notebook, fetching its sources and labels
+// only when a selector needs them. ids and labelIDs are the expanded
+// SourceIDs and LabelIDs lists.
+func Select(ctx context.Context, c Client, notebookID string, opts Options, ids, labelIDs []string, statusW io.Writer) ([]string, error) {
+	needsLabels := len(labelIDs) > 0 || opts.LabelMatch != "" || opts.LabelExclude != ""
+	needsSources := opts.SourceMatch != "" || opts.SourceExclude != "" || needsLabels
+
+	var labels []notebooklm.Label
+	var sources []Source
+	if needsSources {
+		p, err := c.GetProject(ctx, notebookID)
+		if err != nil {
+			return nil, fmt.Errorf("list sources for selectors: %w", err)
+		}
+		sources = make([]Source, 0, len(p.Sources))
+		for _, src := range p.Sources {
+			sources = append(sources, Source{
+				ID:    src.SourceId.GetSourceId(),
+				Title: strings.TrimSpace(src.Title),
+			})
+		}
+	}
+	if needsLabels {
+		ls, err := c.GetLabels(ctx, notebookID)
+		if err != nil {
+			return nil, fmt.Errorf("list labels for selectors: %w", err)
+		}
+		labels = ls
+	}
+	return Resolve(opts, ids, labelIDs, sources, labels, statusW)
+}
+
+// Source is the projection of a source needed by selector resolution.
+// Decoupled from pb.Source so Resolve is unit-testable without
+// constructing protobufs or mocking the API client.
+type Source struct {
+	ID    string
+	Title string
+}
+
+// Resolve is the pure resolution logic. flagIDs and flagLabelIDs are the
+// already-expanded source and label ID lists. statusW receives the
+// human-readable explanations (one line per active selector). Returns the
+// final ID list with order-preserved de-duplication, or nil when no
+// selector is set.
+func Resolve(opts Options, flagIDs, flagLabelIDs []string, sources []Source, labels []notebooklm.Label, statusW io.Writer) ([]string, error) {
+	if opts.Empty() && len(flagIDs) == 0 && len(flagLabelIDs) == 0 {
+		return nil, nil
+	}
+	sourceMatchRE, err := compileSelectorRegex("--source-match", opts.SourceMatch)
+	if err != nil {
+		return nil, err
+	}
+	sourceExcludeRE, err := compileSelectorRegex("--source-exclude", opts.SourceExclude)
+	if err != nil {
+		return nil, err
+	}
+	labelMatchRE, err := compileSelectorRegex("--label-match", opts.LabelMatch)
+	if err != nil {
+		return nil, err
+	}
+	labelExcludeRE, err := compileSelectorRegex("--label-exclude", opts.LabelExclude)
+	if err != nil {
+		return nil, err
+	}
+
+	includeAll := len(flagIDs) == 0 &&
+		len(flagLabelIDs) == 0 &&
+		sourceMatchRE == nil &&
+		labelMatchRE == nil
+	// When only excludes are set, the include set is "all known sources".
+	hasOnlyExcludes := includeAll && (sourceExcludeRE != nil || labelExcludeRE != nil)
+
+	includeSet := make(map[string]bool)
+	var includeOrder []string
+	add := func(id string) {
+		if id == "" || includeSet[id] {
+			return
+		}
+		includeSet[id] = true
+		includeOrder = append(includeOrder, id)
+	}
+
+	if hasOnlyExcludes {
+		for _, s := range sources {
+			add(s.ID)
+		}
+	} else if !includeAll {
+		for _, id := range flagIDs {
+			add(id)
+		}
+		if sourceMatchRE != nil {
+			matched := matchSources(sources, sourceMatchRE)
+			if len(matched) == 0 {
+				listAvailableSources(statusW, "--source-match", opts.SourceMatch, sources)
+				return nil, fmt.Errorf("--source-match matched no sources")
+			}
+			fmt.Fprintf(statusW, "--source-match %q: %d source(s)\n", opts.SourceMatch, len(matched))
+			for _, m := range matched {
+				fmt.Fprintf(statusW, "  %s\n", m.Title)
+				add(m.ID)
+			}
+		}
+		if len(flagLabelIDs) > 0 || labelMatchRE != nil {
+			labelHits := matchLabels(labels, flagLabelIDs, labelMatchRE)
+			if len(labelHits) == 0 && (len(flagLabelIDs) > 0 || labelMatchRE != nil) {
+				listAvailableLabels(statusW, opts, labels)
+				return nil, fmt.Errorf("label selectors matched no labels")
+			}
+			for _, l := range labelHits {
+				fmt.Fprintf(statusW, "label %q (%s): %d source(s)\n", l.Name, l.LabelID, len(l.SourceIDs))
+				for _, id := range l.SourceIDs {
+					add(id)
+				}
+			}
+		}
+	}
+
+	excludeIDs := make(map[string]bool)
+	if sourceExcludeRE != nil {
+		excluded := matchSources(sources, sourceExcludeRE)
+		fmt.Fprintf(statusW, "--source-exclude %q: %d source(s)\n", opts.SourceExclude, len(excluded))
+		for _, e := range excluded {
+			excludeIDs[e.ID] = true
+		}
+	}
+	if labelExcludeRE != nil {
+		excludedLabels := matchLabels(labels, nil, labelExcludeRE)
+		for _, l := range excludedLabels {
+			fmt.Fprintf(statusW, "--label-exclude %q matched label %q: %d source(s)\n", opts.LabelExclude, l.Name, len(l.SourceIDs))
+			for _, id := range l.SourceIDs {
+				excludeIDs[id] = true
+			}
+		}
+	}
+
+	if len(excludeIDs) == 0 {
+		return includeOrder, nil
+	}
+	out := make([]string, 0, len(includeOrder))
+	for _, id := range includeOrder {
+		if excludeIDs[id] {
+			continue
+		}
+		out = append(out, id)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("selectors resolved to empty set after exclusions")
+	}
+	return out, nil
+}
+
+func compileSelectorRegex(flag, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid regex: %w", flag, err)
+	}
+	return re, nil
+}
+
+func matchSources(sources []Source, re *regexp.Regexp) []Source {
+	var out []Source
+	for _, s := range sources {
+		if re.MatchString(s.Title) || re.MatchString(s.ID) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// matchLabels returns labels whose label_id is in includeIDs OR whose name
+// matches re. If both filters are empty, returns nil.
+func matchLabels(labels []notebooklm.Label, includeIDs []string, re *regexp.Regexp) []notebooklm.Label {
+	idSet := make(map[string]bool, len(includeIDs))
+	for _, id := range includeIDs {
+		idSet[id] = true
+	}
+	var out []notebooklm.Label
+	for _, l := range labels {
+		if idSet[l.LabelID] || (re != nil && re.MatchString(l.Name)) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func listAvailableSources(w io.Writer, flag, expr string, sources []Source) {
+	fmt.Fprintf(w, "%s %q matched no sources. Available titles:\n", flag, expr)
+	for _, s := range sources {
+		fmt.Fprintf(w, "  %s\n", s.Title)
+	}
+}
+
+func listAvailableLabels(w io.Writer, opts Options, labels []notebooklm.Label) {
+	switch {
+	case opts.LabelMatch != "" && len(opts.LabelIDs) > 0:
+		fmt.Fprintf(w, "--label-ids/--label-match matched no labels. Available labels:\n")
+	case opts.LabelMatch != "":
+		fmt.Fprintf(w, "--label-match %q matched no labels. Available labels:\n", opts.LabelMatch)
+	default:
+		fmt.Fprintf(w, "--label-ids matched no labels. Available labels:\n")
+	}
+	for _, l := range labels {
+		fmt.Fprintf(w, "  %s (%s)\n", l.Name, l.LabelID)
+	}
+}
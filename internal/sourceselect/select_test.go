@@ -1,4 +1,4 @@
-package main
+package sourceselect
 
 import (
 	"bytes"
@@ -9,8 +9,8 @@ import (
 	"github.com/tmc/nlm/notebooklm"
 )
 
-func TestResolveSelectorIDs(t *testing.T) {
-	srcs := []sourceSummary{
+func TestResolve(t *testing.T) {
+	srcs := []Source{
 		{ID: "src-spec-1", Title: "spec/architecture"},
 		{ID: "src-spec-2", Title: "spec/api"},
 		{ID: "src-impl-1", Title: "impl/server"},
@@ -25,7 +25,7 @@ func TestResolveSelectorIDs(t *testing.T) {
 
 	tests := []struct {
 		name               string
-		opts               selectorOptions
+		opts               Options
 		flagIDs            []string
 		labelIDs           []string
 		want               []string
@@ -34,53 +34,53 @@ func TestResolveSelectorIDs(t *testing.T) {
 	}{
 		{
 			name: "no selectors returns nil",
-			opts: selectorOptions{},
+			opts: Options{},
 			want: nil,
 		},
 		{
 			name:    "source-ids only",
-			opts:    selectorOptions{SourceIDs: "src-spec-1,src-impl-1"},
+			opts:    Options{SourceIDs: "src-spec-1,src-impl-1"},
 			flagIDs: []string{"src-spec-1", "src-impl-1"},
 			want:    []string{"src-spec-1", "src-impl-1"},
 		},
 		{
 			name:               "source-match only",
-			opts:               selectorOptions{SourceMatch: "^spec/"},
+			opts:               Options{SourceMatch: "^spec/"},
 			want:               []string{"src-spec-1", "src-spec-2", "src-draft-1"},
 			wantStatusContains: []string{"--source-match", "3 source(s)"},
 		},
 		{
 			name:               "source-match no hits errors and lists",
-			opts:               selectorOptions{SourceMatch: "^never/"},
+			opts:               Options{SourceMatch: "^never/"},
 			wantErr:            "--source-match matched no sources",
 			wantStatusContains: []string{"matched no sources", "spec/architecture"},
 		},
 		{
 			name:               "source-exclude alone is all-minus",
-			opts:               selectorOptions{SourceExclude: "draft"},
+			opts:               Options{SourceExclude: "draft"},
 			want:               []string{"src-spec-1", "src-spec-2", "src-impl-1", "src-impl-2"},
 			wantStatusContains: []string{"--source-exclude"},
 		},
 		{
 			name: "source-match plus source-exclude subtracts",
-			opts: selectorOptions{SourceMatch: "^spec/", SourceExclude: "draft"},
+			opts: Options{SourceMatch: "^spec/", SourceExclude: "draft"},
 			want: []string{"src-spec-1", "src-spec-2"},
 		},
 		{
 			name:    "exclude wins over include",
-			opts:    selectorOptions{SourceIDs: "src-draft-1,src-spec-1", SourceExclude: "draft"},
+			opts:    Options{SourceIDs: "src-draft-1,src-spec-1", SourceExclude: "draft"},
 			flagIDs: []string{"src-draft-1", "src-spec-1"},
 			want:    []string{"src-spec-1"},
 		},
 		{
 			name:               "label-match include OR semantics",
-			opts:               selectorOptions{LabelMatch: "^Testing$"},
+			opts:               Options{LabelMatch: "^Testing$"},
 			want:               []string{"src-impl-1", "src-impl-2"},
 			wantStatusContains: []string{"label \"Testing\""},
 		},
 		{
 			name:     "label-ids include unioned with label-match",
-			opts:     selectorOptions{LabelMatch: "^Testing$", LabelIDs: "lbl-rpc"},
+			opts:     Options{LabelMatch: "^Testing$", LabelIDs: "lbl-rpc"},
 			labelIDs: []string{"lbl-rpc"},
 			// Order follows the labels slice (Testing first, then RPC); src-impl-1
 			// appears once via the dedup map even though both labels include it.
@@ -88,40 +88,40 @@ func TestResolveSelectorIDs(t *testing.T) {
 		},
 		{
 			name:    "label-exclude removes tagged sources",
-			opts:    selectorOptions{SourceMatch: "^impl/", LabelExclude: "^Testing$"},
+			opts:    Options{SourceMatch: "^impl/", LabelExclude: "^Testing$"},
 			want:    nil,
 			wantErr: "selectors resolved to empty set after exclusions",
 		},
 		{
 			name: "label-exclude alone subtracts from all",
-			opts: selectorOptions{LabelExclude: "^Draft$"},
+			opts: Options{LabelExclude: "^Draft$"},
 			want: []string{"src-spec-1", "src-spec-2", "src-impl-1", "src-impl-2"},
 		},
 		{
 			name:               "label include with no match errors",
-			opts:               selectorOptions{LabelMatch: "^Nonexistent$"},
+			opts:               Options{LabelMatch: "^Nonexistent$"},
 			wantErr:            "label selectors matched no labels",
 			wantStatusContains: []string{"matched no labels", "Testing", "Draft"},
 		},
 		{
 			name:    "invalid source-match regex",
-			opts:    selectorOptions{SourceMatch: "[bad"},
+			opts:    Options{SourceMatch: "[bad"},
 			wantErr: "--source-match: invalid regex",
 		},
 		{
 			name:    "invalid label-exclude regex",
-			opts:    selectorOptions{LabelExclude: "[bad"},
+			opts:    Options{LabelExclude: "[bad"},
 			wantErr: "--label-exclude: invalid regex",
 		},
 		{
 			name:    "source-ids unioned with source-match",
-			opts:    selectorOptions{SourceIDs: "src-impl-2", SourceMatch: "^spec/"},
+			opts:    Options{SourceIDs: "src-impl-2", SourceMatch: "^spec/"},
 			flagIDs: []string{"src-impl-2"},
 			want:    []string{"src-impl-2", "src-spec-1", "src-spec-2", "src-draft-1"},
 		},
 		{
 			name: "exclusions only with empty include list still returns all-minus",
-			opts: selectorOptions{LabelExclude: "^Draft$", SourceExclude: "client"},
+			opts: Options{LabelExclude: "^Draft$", SourceExclude: "client"},
 			want: []string{"src-spec-1", "src-spec-2", "src-impl-1"},
 		},
 	}
@@ -129,7 +129,7 @@ func TestResolveSelectorIDs(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			got, err := resolveSelectorIDs(tt.opts, tt.flagIDs, tt.labelIDs, srcs, labels, &buf)
+			got, err := Resolve(tt.opts, tt.flagIDs, tt.labelIDs, srcs, labels, &buf)
 			if tt.wantErr != "" {
 				if err == nil {
 					t.Fatalf("want error %q, got nil; result=%v status=%q", tt.wantErr, got, buf.String())
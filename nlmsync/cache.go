@@ -6,11 +6,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
 // hashCache stores content hashes for change detection.
 // The zero value uses ~/.cache/nlm/sync/<notebookID>/ as the directory.
+//
+// Each entry holds the hash on its first line and, since sync status was
+// added, the ID of the source it was uploaded as on the second, so a part
+// renamed in the notebook can still be recognized. Entries written before
+// then hold only the hash.
 type hashCache struct {
 	dir string
 }
@@ -24,20 +30,35 @@ func newHashCache(notebookID string) *hashCache {
 // changed reports whether the content hash differs from the cached value.
 // Returns true if changed, no cache exists, or on any error.
 func (c *hashCache) changed(name, hash string) bool {
-	path := c.path(name)
-	data, err := os.ReadFile(path)
+	cached, _ := c.entry(name)
+	return cached == "" || cached != hash
+}
+
+// load returns the cached hash for the given source name, if any.
+func (c *hashCache) load(name string) string {
+	hash, _ := c.entry(name)
+	return hash
+}
+
+// entry returns the cached hash and source ID for the given source name.
+func (c *hashCache) entry(name string) (hash, sourceID string) {
+	data, err := os.ReadFile(c.path(name))
 	if err != nil {
-		return true
+		return "", ""
 	}
-	return string(data) != hash
+	hash, sourceID, _ = strings.Cut(strings.TrimRight(string(data), "\n"), "\n")
+	return hash, sourceID
 }
 
-// save stores the hash for the given source name.
-func (c *hashCache) save(name, hash string) error {
-	if err := os.MkdirAll(c.dir, 0o755); err != nil {
-		return err
-	}
-	return os.WriteFile(c.path(name), []byte(hash), 0o644)
+// save stores the hash for the given source name along with the ID of the
+// source now holding that content.
+func (c *hashCache) save(name, hash, sourceID string) error {
+	return writeFileAtomic(c.path(name), []byte(hash+"\n"+sourceID+"\n"))
+}
+
+// forget drops the cached hash so the next sync uploads name again.
+func (c *hashCache) forget(name string) {
+	_ = os.Remove(c.path(name))
 }
 
 func (c *hashCache) path(name string) string {
@@ -105,6 +126,11 @@ func (c *sourceCache) save(notebookID string, sources []Source) error {
 	return os.WriteFile(c.path(notebookID), data, 0o644)
 }
 
+// invalidate drops the cached list so the next load refetches it.
+func (c *sourceCache) invalidate(notebookID string) {
+	_ = os.Remove(c.path(notebookID))
+}
+
 // append adds a source to the cached list without re-fetching.
 func (c *sourceCache) append(notebookID string, src Source) {
 	sources, ok := c.load(notebookID)
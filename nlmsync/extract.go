@@ -0,0 +1,195 @@
+package nlmsync
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// An Extractor turns a file's raw bytes into the text that gets bundled,
+// such as a notebook's cells without their outputs. name is the txtar
+// member name. Extractors run in-process before any --pre-process command,
+// and their output is what gets hashed and uploaded.
+type Extractor func(name string, data []byte) ([]byte, error)
+
+type extractorEntry struct {
+	name string
+	fn   Extractor
+	keys []string
+}
+
+var extractorRegistry = struct {
+	sync.Mutex
+	m map[string]extractorEntry
+}{m: make(map[string]extractorEntry)}
+
+// RegisterExtractor makes fn selectable as name in Options.Extract. keys
+// are the file name suffixes (".ipynb", ".pb.go") or MIME types
+// ("text/html") the extractor handles. Registering a name again replaces
+// the earlier extractor.
+func RegisterExtractor(name string, fn Extractor, keys ...string) {
+	extractorRegistry.Lock()
+	defer extractorRegistry.Unlock()
+	extractorRegistry.m[name] = extractorEntry{name: name, fn: fn, keys: keys}
+}
+
+// Extractors returns the names of the registered extractors, sorted.
+func Extractors() []string {
+	extractorRegistry.Lock()
+	defer extractorRegistry.Unlock()
+	names := make([]string, 0, len(extractorRegistry.m))
+	for name := range extractorRegistry.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// extractorSet is the selected extractors indexed by key.
+type extractorSet struct {
+	suffixes []string // longest first, so ".pb.go" wins over ".go"
+	bySuffix map[string]Extractor
+	byMIME   map[string]Extractor
+}
+
+// selectExtractors resolves names, each of which may itself be a
+// comma-separated list, against the registry. It returns nil when no
+// extractor is selected.
+func selectExtractors(names []string) (*extractorSet, error) {
+	extractorRegistry.Lock()
+	defer extractorRegistry.Unlock()
+	var s *extractorSet
+	for _, list := range names {
+		for _, name := range strings.Split(list, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			e, ok := extractorRegistry.m[name]
+			if !ok {
+				known := make([]string, 0, len(extractorRegistry.m))
+				for k := range extractorRegistry.m {
+					known = append(known, k)
+				}
+				sort.Strings(known)
+				return nil, fmt.Errorf("unknown extractor %q (available: %s)", name, strings.Join(known, ", "))
+			}
+			if s == nil {
+				s = &extractorSet{bySuffix: make(map[string]Extractor), byMIME: make(map[string]Extractor)}
+			}
+			for _, key := range e.keys {
+				if strings.Contains(key, "/") {
+					s.byMIME[key] = e.fn
+				} else {
+					s.bySuffix[strings.ToLower(key)] = e.fn
+				}
+			}
+		}
+	}
+	if s != nil {
+		for suffix := range s.bySuffix {
+			s.suffixes = append(s.suffixes, suffix)
+		}
+		sort.Slice(s.suffixes, func(i, j int) bool { return len(s.suffixes[i]) > len(s.suffixes[j]) })
+	}
+	return s, nil
+}
+
+// extractFileName is the extractor manifest sync honors automatically, so a
+// checkout can carry its --extract choices the way .nlmlabels carries its
+// label rules.
+const extractFileName = ".nlmextract"
+
+// mergeExtractNames returns the explicit extractor names followed by any
+// read from .nlmextract files, found where .nlmignore files are. Each
+// non-empty, non-comment line names one extractor or a comma-separated
+// list, as --extract takes them; an unknown name is reported against the
+// file it came from.
+func mergeExtractNames(paths []string, names []string) ([]string, error) {
+	merged := append([]string(nil), names...)
+	for _, root := range configRoots(paths) {
+		name := filepath.Join(root, extractFileName)
+		got, err := readIgnoreFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := selectExtractors(got); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		merged = append(merged, got...)
+	}
+	return merged, nil
+}
+
+// bySuffixOf returns the extractor registered for name's longest matching
+// suffix.
+func (s *extractorSet) bySuffixOf(name string) Extractor {
+	lower := strings.ToLower(name)
+	for _, suffix := range s.suffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return s.bySuffix[suffix]
+		}
+	}
+	return nil
+}
+
+// extract runs the selected extractors over files. A file is matched by
+// name suffix first, then by MIME type. Matched files carry the extracted
+// text in Data; others are left to be read by bundle as usual. An
+// extractor error keeps the raw bytes and warns rather than failing the
+// sync, since one malformed notebook should not block thousands of files.
+func (s *extractorSet) extract(files []discovered) ([]discovered, error) {
+	if s == nil {
+		return files, nil
+	}
+	out := make([]discovered, len(files))
+	for i, f := range files {
+		out[i] = f
+		var data []byte
+		fn := s.bySuffixOf(f.Name)
+		if fn == nil && len(s.byMIME) > 0 {
+			// Files with an extension are typed by it alone, so a Markdown
+			// file that opens with an HTML comment is not mistaken for
+			// HTML; only extensionless files have their content sniffed.
+			ct := ""
+			if ext := path.Ext(f.Name); ext != "" {
+				ct = mime.TypeByExtension(ext)
+			} else {
+				var err error
+				if data, err = f.read(); err != nil {
+					return nil, fmt.Errorf("read %s: %w", f.Path, err)
+				}
+				ct = http.DetectContentType(data)
+			}
+			if mt, _, err := mime.ParseMediaType(ct); err == nil {
+				fn = s.byMIME[mt]
+			}
+		}
+		if fn == nil {
+			continue
+		}
+		if data == nil {
+			var err error
+			if data, err = f.read(); err != nil {
+				return nil, fmt.Errorf("read %s: %w", f.Path, err)
+			}
+		}
+		text, err := fn(f.Name, data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: extract %s: %v (bundling raw content)\n", f.Name, err)
+			out[i].Data = data
+			continue
+		}
+		if text == nil {
+			text = []byte{}
+		}
+		out[i].Data = text
+	}
+	return out, nil
+}
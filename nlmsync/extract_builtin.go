@@ -0,0 +1,276 @@
+package nlmsync
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func init() {
+	RegisterExtractor("ipynb", extractNotebook, ".ipynb", "application/x-ipynb+json")
+	RegisterExtractor("html", extractHTML, ".html", ".htm", ".xhtml", "text/html", "application/xhtml+xml")
+	RegisterExtractor("pbgo", extractGoDecls, ".pb.go")
+	RegisterExtractor("csv", extractTable, ".csv", ".tsv", "text/csv", "text/tab-separated-values")
+}
+
+// extractNotebook renders a Jupyter notebook as its Markdown cells and
+// fenced code cells, dropping outputs, execution counts, and metadata.
+func extractNotebook(_ string, data []byte) ([]byte, error) {
+	var nb struct {
+		Cells []struct {
+			CellType string          `json:"cell_type"`
+			Source   json.RawMessage `json:"source"`
+		} `json:"cells"`
+		Metadata struct {
+			KernelSpec struct {
+				Language string `json:"language"`
+			} `json:"kernelspec"`
+			LanguageInfo struct {
+				Name string `json:"name"`
+			} `json:"language_info"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, fmt.Errorf("parse notebook: %w", err)
+	}
+	lang := nb.Metadata.LanguageInfo.Name
+	if lang == "" {
+		lang = nb.Metadata.KernelSpec.Language
+	}
+	var b strings.Builder
+	for _, cell := range nb.Cells {
+		// nbformat stores source as a string or a list of lines.
+		var src string
+		if err := json.Unmarshal(cell.Source, &src); err != nil {
+			var lines []string
+			if err := json.Unmarshal(cell.Source, &lines); err != nil {
+				return nil, fmt.Errorf("parse %s cell source: %w", cell.CellType, err)
+			}
+			src = strings.Join(lines, "")
+		}
+		src = strings.TrimRight(src, "\n")
+		if strings.TrimSpace(src) == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		if cell.CellType == "code" {
+			fmt.Fprintf(&b, "```%s\n%s\n```\n", lang, src)
+		} else {
+			b.WriteString(src + "\n")
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// extractHTML reduces an HTML document to readable text: scripts, styles
+// and the head are dropped, block elements become line breaks, headings
+// and list items keep Markdown markers, and <pre> keeps its layout.
+func extractHTML(_ string, data []byte) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var w htmlText
+	w.node(doc, false)
+	return []byte(strings.TrimSpace(w.b.String()) + "\n"), nil
+}
+
+// htmlText accumulates text with whitespace collapsed, owing line breaks
+// and spaces until the next text arrives so none pile up at the edges.
+type htmlText struct {
+	b      strings.Builder
+	breaks int
+	space  bool
+}
+
+// brk owes at least n newlines before the next text.
+func (w *htmlText) brk(n int) {
+	if w.b.Len() > 0 && n > w.breaks {
+		w.breaks = n
+	}
+}
+
+func (w *htmlText) write(s string) {
+	if w.breaks > 0 {
+		w.b.WriteString(strings.Repeat("\n", w.breaks))
+		w.breaks, w.space = 0, false
+	}
+	if w.space && w.b.Len() > 0 {
+		w.b.WriteByte(' ')
+	}
+	w.space = false
+	w.b.WriteString(s)
+}
+
+func (w *htmlText) node(n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		if pre {
+			w.write(n.Data)
+			return
+		}
+		fields := strings.Fields(n.Data)
+		if len(fields) == 0 {
+			w.space = w.space || n.Data != ""
+			return
+		}
+		if strings.TrimLeft(n.Data, " \t\r\n") != n.Data {
+			w.space = true
+		}
+		w.write(strings.Join(fields, " "))
+		w.space = strings.TrimRight(n.Data, " \t\r\n") != n.Data
+		return
+	case html.ElementNode:
+	default:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			w.node(c, pre)
+		}
+		return
+	}
+
+	after := 0
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg:
+		return
+	case atom.Br:
+		w.brk(1)
+		return
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.brk(2)
+		w.write(strings.Repeat("#", int(n.Data[1]-'0')))
+		w.space = true
+		after = 2
+	case atom.Li:
+		w.brk(1)
+		w.write("-")
+		w.space = true
+		after = 1
+	case atom.Pre:
+		w.brk(2)
+		pre = true
+		after = 2
+	case atom.Td, atom.Th:
+		for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+			if s.DataAtom == atom.Td || s.DataAtom == atom.Th {
+				w.write(" |")
+				w.space = true
+				break
+			}
+		}
+	case atom.P, atom.Blockquote, atom.Table, atom.Ul, atom.Ol, atom.Dl, atom.Figure, atom.Hr:
+		w.brk(2)
+		after = 2
+	case atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main, atom.Nav,
+		atom.Aside, atom.Tr, atom.Dt, atom.Dd, atom.Figcaption, atom.Form, atom.Address:
+		w.brk(1)
+		after = 1
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c, pre)
+	}
+	w.brk(after)
+}
+
+// extractGoDecls reduces a generated Go file (typically *.pb.go) to its
+// declarations: function bodies and variable initializers such as raw
+// descriptors are dropped, leaving types, signatures, and doc comments.
+func extractGoDecls(name string, data []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	cmap := ast.NewCommentMap(fset, f, f.Comments)
+	decls := f.Decls[:0]
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			d.Body = nil
+		case *ast.GenDecl:
+			if d.Tok != token.VAR {
+				break
+			}
+			// A var without a type has nothing left to say once its
+			// value is gone.
+			specs := d.Specs[:0]
+			for _, s := range d.Specs {
+				if vs := s.(*ast.ValueSpec); vs.Type != nil {
+					vs.Values = nil
+					specs = append(specs, vs)
+				}
+			}
+			if len(specs) == 0 {
+				continue
+			}
+			d.Specs = specs
+		}
+		decls = append(decls, d)
+	}
+	f.Decls = decls
+	f.Comments = cmap.Filter(f).Comments()
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// extractTable renders CSV or TSV as a Markdown table whose first record
+// is the header. Ragged records are padded to the widest one.
+func extractTable(name string, data []byte) ([]byte, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if strings.HasSuffix(strings.ToLower(name), ".tsv") || isTabSeparated(data) {
+		r.Comma = '\t'
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	width := 0
+	for _, rec := range records {
+		width = max(width, len(rec))
+	}
+	var b strings.Builder
+	row := func(cells []string) {
+		b.WriteString("|")
+		for i := range width {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			cell = strings.ReplaceAll(strings.TrimSpace(cell), "|", `\|`)
+			cell = strings.ReplaceAll(strings.ReplaceAll(cell, "\r\n", "\n"), "\n", "<br>")
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	row(records[0])
+	b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+	for _, rec := range records[1:] {
+		row(rec)
+	}
+	return []byte(b.String()), nil
+}
+
+// isTabSeparated reports whether the first line of a file typed only by
+// MIME looks tab- rather than comma-separated.
+func isTabSeparated(data []byte) bool {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	return bytes.Contains(line, []byte("\t")) && !bytes.Contains(line, []byte(","))
+}
@@ -0,0 +1,165 @@
+package nlmsync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractNotebookDropsOutputs(t *testing.T) {
+	nb := `{
+ "cells": [
+  {"cell_type": "markdown", "source": ["# Title\n", "Some prose."]},
+  {"cell_type": "code", "execution_count": 3, "source": "print(1)\n",
+   "outputs": [{"output_type": "stream", "text": ["SECRET OUTPUT\n"]}]},
+  {"cell_type": "code", "source": []}
+ ],
+ "metadata": {"language_info": {"name": "python"}}
+}`
+	got, err := extractNotebook("a.ipynb", []byte(nb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Title\nSome prose.\n\n```python\nprint(1)\n```\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if _, err := extractNotebook("bad.ipynb", []byte("{")); err == nil {
+		t.Error("malformed notebook: want error")
+	}
+}
+
+func TestExtractHTML(t *testing.T) {
+	page := `<!DOCTYPE html><html><head><title>T</title><style>p{}</style></head>
+<body><h2>Intro</h2><p>Hello   <b>bold</b>
+world.</p><script>alert(1)</script>
+<ul><li>one</li><li>two</li></ul>
+<pre>  keep
+    this</pre>
+<table><tr><th>k</th><th>v</th></tr><tr><td>a</td><td>1</td></tr></table></body></html>`
+	got, err := extractHTML("a.html", []byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Intro\n\nHello bold world.\n\n- one\n- two\n\n  keep\n    this\n\nk | v\na | 1\n"
+	if string(got) != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestExtractGoDecls(t *testing.T) {
+	src := `package pb
+
+// Msg is a message.
+type Msg struct {
+	Name string
+}
+
+// GetName returns the name.
+func (x *Msg) GetName() string {
+	// body comment
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+var file_msg_proto_rawDesc = []byte{0x0a, 0x0b}
+
+var Typed int = 5
+`
+	got, err := extractGoDecls("msg.pb.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(got)
+	for _, want := range []string{"// Msg is a message.", "type Msg struct", "// GetName returns the name.", "func (x *Msg) GetName() string\n", "var Typed int\n"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q in:\n%s", want, s)
+		}
+	}
+	for _, bad := range []string{"body comment", "return x.Name", "rawDesc", "= 5"} {
+		if strings.Contains(s, bad) {
+			t.Errorf("unexpected %q in:\n%s", bad, s)
+		}
+	}
+}
+
+func TestExtractTable(t *testing.T) {
+	got, err := extractTable("a.csv", []byte("name,note\nann,\"a|b\"\nbob,\"two\nlines\",extra\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "| name | note |  |\n| --- | --- | --- |\n| ann | a\\|b |  |\n| bob | two<br>lines | extra |\n"
+	if string(got) != want {
+		t.Errorf("csv got:\n%s\nwant:\n%s", got, want)
+	}
+	got, err = extractTable("a.tsv", []byte("a\tb\n1\t2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "| a | b |\n| --- | --- |\n| 1 | 2 |\n"; string(got) != want {
+		t.Errorf("tsv got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPackExtractSelectsBySuffixAndMIME(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("page.html", "<p>from <i>html</i></p>")
+	write("INDEX", "<!DOCTYPE html><p>sniffed</p>")
+	write("notes.md", "<!-- keep --> markdown")
+	write("data.csv", "a,b\n1,2\n")
+
+	raw, _, err := Pack([]string{dir}, Options{Name: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, _, err := Pack([]string{dir}, Options{Name: "x", Extract: []string{"html"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(chunks[0])
+	for _, want := range []string{"-- page.html --\nfrom html\n", "-- INDEX --\nsniffed\n", "<!-- keep --> markdown", "a,b\n1,2"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if string(raw[0]) == got {
+		t.Error("extraction did not change the bundle, so it would not change the hash")
+	}
+
+	if _, _, err := Pack([]string{dir}, Options{Name: "x", Extract: []string{"html,nope"}}); err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Errorf("unknown extractor: err = %v", err)
+	}
+}
+
+func TestPackExtractFromManifest(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "page.html"), []byte("<p>from <i>html</i></p>"), 0o644)
+	os.WriteFile(filepath.Join(dir, "data.csv"), []byte("a,b\n1,2\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, extractFileName), []byte("# extractors for sync\n\nhtml\n"), 0o644)
+
+	chunks, _, err := Pack([]string{dir}, Options{Name: "x", Extract: []string{"csv"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(chunks[0])
+	for _, want := range []string{"-- page.html --\nfrom html\n", "| a | b |"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, extractFileName), []byte("html, nope\n"), 0o644)
+	_, _, err = Pack([]string{dir}, Options{Name: "x"})
+	if want := extractFileName + `: unknown extractor "nope"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Pack with a bad manifest: err = %v, want one containing %q", err, want)
+	}
+}
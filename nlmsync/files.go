@@ -13,9 +13,22 @@ import (
 // Names are kept relative to the user's bundle root (the git repo root when
 // available, else the discovery directory) so citations resolve to short,
 // portable paths instead of the syncing host's absolute layout.
+//
+// Data, when non-nil, holds contents that did not come from the working tree
+// (a blob read at --rev, or generated git log/diff text); Path then only
+// labels the entry in error messages.
 type discovered struct {
 	Path string
 	Name string
+	Data []byte
+}
+
+// read returns the entry's bytes, from Data when preloaded or from disk.
+func (f discovered) read() ([]byte, error) {
+	if f.Data != nil {
+		return f.Data, nil
+	}
+	return os.ReadFile(f.Path)
 }
 
 // gitFiles returns tracked files under dir using git ls-files.
@@ -229,11 +242,23 @@ func mergeIgnores(paths, exclude []string) ([]string, error) {
 // non-comment line is one pattern, using the same matching as --exclude. A
 // missing file is not an error; paths that are plain files are ignored here.
 func loadNlmignore(paths []string) ([]string, error) {
-	if paths == nil {
-		return nil, nil
+	var patterns []string
+	for _, root := range configRoots(paths) {
+		got, err := readIgnoreFile(filepath.Join(root, ignoreFileName))
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, got...)
 	}
+	return patterns, nil
+}
+
+// configRoots returns the directories sync reads per-checkout files such as
+// .nlmignore from: the git repo root of each directory in paths, or the
+// directory itself outside a checkout, each listed once.
+func configRoots(paths []string) []string {
 	seenRoot := make(map[string]bool)
-	var patterns []string
+	var roots []string
 	for _, p := range paths {
 		info, err := os.Stat(p)
 		if err != nil || !info.IsDir() {
@@ -247,13 +272,9 @@ func loadNlmignore(paths []string) ([]string, error) {
 			continue
 		}
 		seenRoot[root] = true
-		got, err := readIgnoreFile(filepath.Join(root, ignoreFileName))
-		if err != nil {
-			return nil, err
-		}
-		patterns = append(patterns, got...)
+		roots = append(roots, root)
 	}
-	return patterns, nil
+	return roots
 }
 
 // readIgnoreFile parses an ignore file into exclude patterns. Blank lines and
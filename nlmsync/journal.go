@@ -0,0 +1,296 @@
+package nlmsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oldSuffix marks a source renamed aside during a replace. It is deleted
+// once the new upload lands, or renamed back if the upload fails.
+const oldSuffix = " [old]"
+
+// journal records the intent of every in-flight replace so that a run
+// killed between renaming a part to "[old]" and deleting it can be
+// reconciled by the next run. It lives beside the hash cache as
+// journal.json, is rewritten atomically on each step, and is removed when
+// no entries remain.
+type journal struct {
+	path  string
+	mu    sync.Mutex
+	state journalState
+}
+
+type journalState struct {
+	PID     int                      `json:"pid"`
+	Started time.Time                `json:"started"`
+	Parts   map[string]*journalEntry `json:"parts"`
+}
+
+// journalEntry describes one part being replaced. OldID is the source
+// renamed aside, NewID the replacement once AddSource returns. PrevHash and
+// Hash are the hash cache values before and after, so recovery can put the
+// cache back in step with whatever the notebook ended up holding.
+type journalEntry struct {
+	OldID    string `json:"old_id"`
+	NewID    string `json:"new_id,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// openJournal loads the journal in dir. A missing or unreadable journal
+// yields an empty one; an unreadable file is reported because it may hide
+// stranded sources that --repair can still sweep up.
+func openJournal(dir string) *journal {
+	j := &journal{path: filepath.Join(dir, "journal.json")}
+	data, err := os.ReadFile(j.path)
+	if err == nil {
+		if err := json.Unmarshal(data, &j.state); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: ignoring corrupt sync journal %s: %v\n", j.path, err)
+			j.state = journalState{}
+		}
+	}
+	if j.state.Parts == nil {
+		j.state.Parts = make(map[string]*journalEntry)
+	}
+	return j
+}
+
+// pending returns the recorded entries sorted by part name.
+func (j *journal) pending() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	names := make([]string, 0, len(j.state.Parts))
+	for name := range j.state.Parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (j *journal) entry(name string) journalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if e := j.state.Parts[name]; e != nil {
+		return *e
+	}
+	return journalEntry{}
+}
+
+// begin records that name's current source is about to be renamed aside.
+func (j *journal) begin(name string, e journalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.state.Parts) == 0 {
+		j.state.PID = os.Getpid()
+		j.state.Started = time.Now().UTC()
+	}
+	j.state.Parts[name] = &e
+	return j.writeLocked()
+}
+
+// uploaded records the replacement's source ID.
+func (j *journal) uploaded(name, newID string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if e := j.state.Parts[name]; e != nil {
+		e.NewID = newID
+	}
+	return j.writeLocked()
+}
+
+// finish drops name's entry once the notebook and hash cache agree again.
+func (j *journal) finish(name string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.state.Parts, name)
+	return j.writeLocked()
+}
+
+func (j *journal) writeLocked() error {
+	if len(j.state.Parts) == 0 {
+		if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(j.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(j.path, data)
+}
+
+// writeFileAtomic writes data to a temp file in path's directory and
+// renames it into place, so a crash never leaves a truncated file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// recoverJournal reconciles the replaces an interrupted run left behind.
+// For each entry, a stranded "[old]" source is deleted when its
+// replacement made it into the notebook and renamed back otherwise, and
+// the hash cache is reset to match the content the notebook now holds.
+// With sweep set, "name [old]" sources the journal does not know about
+// (from a lost journal or a pre-journal version) are reconciled the same
+// way, without touching the hash cache, when sweep reports that sync owns
+// name; sources a user titled that way are left alone. Entries that fail
+// stay recorded.
+func recoverJournal(ctx context.Context, c Client, notebookID string, j *journal, hc *hashCache, sweep func(name string) bool, dryRun bool, out *outputWriter) (int, error) {
+	names := j.pending()
+	if len(names) == 0 && sweep == nil {
+		return 0, nil
+	}
+	sources, err := c.ListSources(ctx, notebookID)
+	if err != nil {
+		return 0, fmt.Errorf("list sources: %w", err)
+	}
+	byID := make(map[string]Source, len(sources))
+	byTitle := make(map[string]Source, len(sources))
+	for _, s := range sources {
+		byID[s.ID] = s
+		byTitle[s.Title] = s
+	}
+
+	type item struct {
+		name    string
+		entry   journalEntry
+		journal bool
+	}
+	var items []item
+	known := make(map[string]bool)
+	for _, name := range names {
+		e := j.entry(name)
+		items = append(items, item{name: name, entry: e, journal: true})
+		known[e.OldID] = true
+	}
+	if sweep != nil {
+		var stray []item
+		for _, s := range sources {
+			if known[s.ID] || !strings.HasSuffix(s.Title, oldSuffix) {
+				continue
+			}
+			name := strings.TrimSuffix(s.Title, oldSuffix)
+			if !sweep(name) {
+				continue
+			}
+			stray = append(stray, item{name: name, entry: journalEntry{OldID: s.ID}})
+		}
+		sort.Slice(stray, func(a, b int) bool { return stray[a].name < stray[b].name })
+		items = append(items, stray...)
+	}
+	if len(names) > 0 && !out.json {
+		fmt.Fprintf(os.Stderr, "recovering interrupted sync (pid %d, started %s)\n", j.state.PID, j.state.Started.Local().Format(time.RFC3339))
+	}
+
+	var errs []error
+	for _, it := range items {
+		old, oldLive := byID[it.entry.OldID]
+		cur, curLive := byTitle[it.name]
+		if curLive && cur.ID == it.entry.OldID {
+			// The rename never happened; nothing is stranded.
+			curLive = false
+		}
+		if oldLive && old.Title != it.name+oldSuffix {
+			// Restored already, or renamed by hand since: not ours to touch.
+			oldLive = false
+		}
+		switch {
+		case oldLive && curLive:
+			if !dryRun {
+				if err := c.DeleteSources(ctx, notebookID, []string{old.ID}); err != nil {
+					errs = append(errs, fmt.Errorf("delete stranded %q: %w", old.Title, err))
+					continue
+				}
+			}
+			out.emit(event{Action: "delete", Name: old.Title, OldID: old.ID, Reason: "stranded", DryRun: dryRun})
+		case oldLive:
+			if !dryRun {
+				if err := c.RenameSource(ctx, old.ID, it.name); err != nil {
+					errs = append(errs, fmt.Errorf("restore %q: %w", it.name, err))
+					continue
+				}
+			}
+			out.emit(event{Action: "restore", Name: it.name, SourceID: old.ID, Reason: "stranded", DryRun: dryRun})
+		}
+		if dryRun || !it.journal {
+			continue
+		}
+		switch {
+		case curLive && it.entry.NewID != "" && cur.ID == it.entry.NewID:
+			_ = hc.save(it.name, it.entry.Hash, it.entry.NewID)
+		case !curLive && it.entry.PrevHash != "":
+			_ = hc.save(it.name, it.entry.PrevHash, it.entry.OldID)
+		default:
+			// Unknown content under the title: make the next sync upload.
+			hc.forget(it.name)
+		}
+		if err := j.finish(it.name); err != nil {
+			errs = append(errs, fmt.Errorf("update sync journal: %w", err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return len(items), err
+	}
+	if len(items) > 0 {
+		// Titles changed under the source cache; let the next read refetch.
+		newSourceCache().invalidate(notebookID)
+	}
+	return len(items), nil
+}
+
+// Repair runs only the crash recovery half of Run: under the notebook's
+// sync lock it reconciles any interrupted run recorded in the journal and
+// sweeps up "name [old]" sources the journal does not cover. Only names
+// sync owns are swept: those the hash cache shows it uploaded, and the
+// parts of opts.Name when set.
+func Repair(ctx context.Context, c Client, notebookID string, opts Options, w io.Writer) error {
+	hc := newHashCache(notebookID)
+	if !opts.DryRun {
+		lock, err := lockNotebook(ctx, hc.dir)
+		if err != nil {
+			return err
+		}
+		defer lock.unlock()
+	}
+	out := &outputWriter{w: w, json: opts.JSON}
+	owned := func(name string) bool {
+		return hc.load(name) != "" || (opts.Name != "" && isPartOf(name, opts.Name))
+	}
+	n, err := recoverJournal(ctx, c, notebookID, openJournal(hc.dir), hc, owned, opts.DryRun, out)
+	if err != nil {
+		return err
+	}
+	if n == 0 && !opts.JSON {
+		fmt.Fprintln(os.Stderr, "nothing to repair")
+	}
+	return nil
+}
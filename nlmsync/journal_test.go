@@ -0,0 +1,218 @@
+package nlmsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// liveClient is a fakeClient whose renames and deletes change the source
+// list, so recovery can be checked against the resulting notebook.
+type liveClient struct {
+	*fakeClient
+}
+
+func (f *liveClient) RenameSource(ctx context.Context, id, title string) error {
+	f.fakeClient.RenameSource(ctx, id, title)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.sources {
+		if f.sources[i].ID == id {
+			f.sources[i].Title = title
+		}
+	}
+	return nil
+}
+
+func (f *liveClient) DeleteSources(ctx context.Context, nb string, ids []string) error {
+	f.fakeClient.DeleteSources(ctx, nb, ids)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kept := f.sources[:0]
+	for _, s := range f.sources {
+		if !slices.Contains(ids, s.ID) {
+			kept = append(kept, s)
+		}
+	}
+	f.sources = kept
+	return nil
+}
+
+func titles(sources []Source) map[string]string {
+	m := make(map[string]string, len(sources))
+	for _, s := range sources {
+		m[s.Title] = s.ID
+	}
+	return m
+}
+
+func TestLockNotebookWaitsForHolder(t *testing.T) {
+	dir := t.TempDir()
+	held, err := lockNotebook(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*lockPollInterval)
+	defer cancel()
+	if _, err := lockNotebook(ctx, dir); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second lock while held: err = %v, want deadline exceeded", err)
+	}
+
+	got := make(chan error, 1)
+	go func() {
+		l, err := lockNotebook(context.Background(), dir)
+		if err == nil {
+			l.unlock()
+		}
+		got <- err
+	}()
+	time.Sleep(lockPollInterval / 2)
+	held.unlock()
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter did not acquire the lock after release")
+	}
+}
+
+func TestRunRecoversCrashAfterUpload(t *testing.T) {
+	setupTestHome(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("new content"), 0o644)
+	opts := Options{Name: "docs"}
+	chunks, _, err := Pack([]string{dir}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(chunks[0]))
+
+	// The previous run uploaded the replacement and died before deleting
+	// the renamed original or updating the hash cache.
+	hc := newHashCache("nb")
+	hc.save("docs", "stale", "old-1")
+	j := openJournal(hc.dir)
+	j.begin("docs", journalEntry{OldID: "old-1", NewID: "new-1", PrevHash: "stale", Hash: hash})
+	fc := &liveClient{&fakeClient{sources: []Source{
+		{ID: "old-1", Title: "docs [old]"},
+		{ID: "new-1", Title: "docs"},
+	}}}
+
+	if err := Run(context.Background(), fc, "nb", []string{dir}, opts, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.deleted) != 1 || fc.deleted[0] != "old-1" {
+		t.Errorf("deleted = %v, want [old-1]", fc.deleted)
+	}
+	// The hash cache was restored to the content the replacement carries,
+	// so the sync itself had nothing left to upload.
+	if len(fc.uploaded) != 0 {
+		t.Errorf("uploaded %d sources after recovery, want 0", len(fc.uploaded))
+	}
+	if _, err := os.Stat(filepath.Join(hc.dir, "journal.json")); !os.IsNotExist(err) {
+		t.Errorf("journal left behind after recovery: %v", err)
+	}
+}
+
+func TestRepairRestoresRenamedSource(t *testing.T) {
+	setupTestHome(t)
+	// The previous run renamed the original aside and died before the
+	// upload finished, leaving only "docs [old]".
+	hc := newHashCache("nb")
+	j := openJournal(hc.dir)
+	j.begin("docs", journalEntry{OldID: "old-1", PrevHash: "prev", Hash: "next"})
+	hc.save("docs", "next", "")
+	hc.save("notes", "h", "notes-2")
+	fc := &liveClient{&fakeClient{sources: []Source{
+		{ID: "old-1", Title: "docs [old]"},
+		{ID: "other", Title: "notes [old]"},
+		{ID: "notes-2", Title: "notes"},
+		{ID: "user", Title: "Foo [old]"},
+	}}}
+
+	var buf bytes.Buffer
+	if err := Repair(context.Background(), fc, "nb", Options{JSON: true}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	got := titles(fc.sources)
+	if got["docs"] != "old-1" {
+		t.Errorf("docs not restored: %v", got)
+	}
+	// The sweep also clears "[old]" parts the journal never recorded.
+	if _, ok := got["notes [old]"]; ok || got["notes"] != "notes-2" {
+		t.Errorf("stray [old] not swept: %v", got)
+	}
+	// A source sync never uploaded is the user's, whatever its title.
+	if got["Foo [old]"] != "user" {
+		t.Errorf("user-named source swept: %v", got)
+	}
+	if h := hc.load("docs"); h != "prev" {
+		t.Errorf("hash cache = %q, want restored %q", h, "prev")
+	}
+	if len(openJournal(hc.dir).pending()) != 0 {
+		t.Error("journal still has entries after repair")
+	}
+	for _, want := range []string{`"action":"restore"`, `"action":"delete","name":"notes [old]"`} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("output missing %s:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRepairSweepsNamedParts(t *testing.T) {
+	setupTestHome(t)
+	fc := &liveClient{&fakeClient{sources: []Source{
+		{ID: "old-2", Title: "tree (pt2) [old]"},
+		{ID: "user", Title: "Foo [old]"},
+	}}}
+	if err := Repair(context.Background(), fc, "nb", Options{Name: "tree", JSON: true}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	got := titles(fc.sources)
+	if got["tree (pt2)"] != "old-2" || got["Foo [old]"] != "user" {
+		t.Errorf("after repair --name tree: %v, want only tree's part restored", got)
+	}
+}
+
+func TestRepairDryRunChangesNothing(t *testing.T) {
+	setupTestHome(t)
+	hc := newHashCache("nb")
+	openJournal(hc.dir).begin("docs", journalEntry{OldID: "old-1", Hash: "h"})
+	fc := &liveClient{&fakeClient{sources: []Source{{ID: "old-1", Title: "docs [old]"}}}}
+
+	if err := Repair(context.Background(), fc, "nb", Options{DryRun: true}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.renamed) != 0 || len(fc.deleted) != 0 {
+		t.Errorf("dry run mutated the notebook: renamed=%v deleted=%v", fc.renamed, fc.deleted)
+	}
+	if len(openJournal(hc.dir).pending()) != 1 {
+		t.Error("dry run cleared the journal")
+	}
+}
+
+func TestRunReplaceClearsJournal(t *testing.T) {
+	setupTestHome(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("v2"), 0o644)
+	fc := &liveClient{&fakeClient{sources: []Source{{ID: "orig", Title: "docs"}}}}
+
+	if err := Run(context.Background(), fc, "nb", []string{dir}, Options{Name: "docs"}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(fc.sources); len(got) != 1 || got["docs"] == "orig" {
+		t.Errorf("sources after replace = %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(newHashCache("nb").dir, "journal.json")); !os.IsNotExist(err) {
+		t.Errorf("journal left behind after a clean replace: %v", err)
+	}
+}
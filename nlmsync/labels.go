@@ -0,0 +1,234 @@
+package nlmsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/txtar"
+)
+
+// Label is a notebook label as returned by the server.
+type Label struct {
+	ID        string
+	Name      string
+	SourceIDs []string
+}
+
+// LabelAssigner is an optional capability: clients that implement it let
+// sync apply label rules from Options.Labels and .nlmlabels, creating
+// labels that do not exist yet and attaching each synced part to the
+// labels its member paths select.
+type LabelAssigner interface {
+	ListLabels(ctx context.Context, notebookID string) ([]Label, error)
+	CreateLabel(ctx context.Context, notebookID, name string) ([]Label, error)
+	AttachLabelSource(ctx context.Context, notebookID, labelID, sourceID string) error
+}
+
+// LabelRule assigns Label to every synced part containing a member whose
+// name matches Pattern. Patterns use filepath.Match syntax per path segment
+// plus "**" for any number of segments; a pattern without a '/' is also
+// tried against the basename, as --exclude patterns are.
+type LabelRule struct {
+	Pattern string
+	Label   string
+}
+
+// ParseLabelRule parses a "pattern=Label" flag value such as "docs/**=Docs".
+func ParseLabelRule(s string) (LabelRule, error) {
+	pattern, label, ok := strings.Cut(s, "=")
+	pattern, label = strings.TrimSpace(pattern), strings.TrimSpace(label)
+	if !ok || pattern == "" || label == "" {
+		return LabelRule{}, fmt.Errorf("invalid label rule %q: want pattern=Label", s)
+	}
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return LabelRule{}, fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+	}
+	return LabelRule{Pattern: pattern, Label: label}, nil
+}
+
+// labelsFileName is the label manifest sync honors automatically, so a
+// checkout can carry its labeling rules instead of every caller repeating
+// the same --label flags.
+const labelsFileName = ".nlmlabels"
+
+// mergeLabelRules returns the explicit rules followed by any rules read
+// from .nlmlabels files, found where .nlmignore files are. Labels are
+// collected from every matching rule, so the order only decides the order
+// labels are attached in.
+func mergeLabelRules(paths []string, rules []LabelRule) ([]LabelRule, error) {
+	merged := append([]LabelRule(nil), rules...)
+	for _, root := range configRoots(paths) {
+		got, err := readLabelsFile(filepath.Join(root, labelsFileName))
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, got...)
+	}
+	return merged, nil
+}
+
+// readLabelsFile parses a label manifest: one "pattern=Label" rule per
+// line, as --label takes them, with blank lines and '#' comments skipped.
+// A non-existent file yields no rules and no error.
+func readLabelsFile(name string) ([]LabelRule, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	var rules []LabelRule
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, err := ParseLabelRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// labelsFor returns the labels selected by rules for the given member
+// names, in rule order and without duplicates.
+func labelsFor(rules []LabelRule, members []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		if seen[r.Label] {
+			continue
+		}
+		for _, m := range members {
+			if matchLabelPattern(r.Pattern, m) {
+				seen[r.Label] = true
+				out = append(out, r.Label)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// matchLabelPattern reports whether name matches pattern, where "**"
+// spans zero or more whole path segments.
+func matchLabelPattern(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") && !strings.Contains(pattern, "**") {
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// partSuffix matches the "(part i/N)" suffix bundle gives each piece of an
+// oversize file, so label rules see the original member name.
+var partSuffix = regexp.MustCompile(` \(part \d+/\d+\)$`)
+
+// chunkMembers returns the original member names bundled into chunk.
+func chunkMembers(chunk []byte) []string {
+	ar := txtar.Parse(chunk)
+	names := make([]string, 0, len(ar.Files))
+	for _, f := range ar.Files {
+		names = append(names, partSuffix.ReplaceAllString(f.Name, ""))
+	}
+	return names
+}
+
+// applyLabels attaches every synced part to the labels rules select
+// for it, creating labels by name when the notebook lacks them. ids maps
+// part names to their current source IDs; parts missing from ids (a dry
+// run's pending uploads) are reported but not attached. Assignments that
+// already exist are left alone, so repeated syncs are quiet.
+func applyLabels(ctx context.Context, la LabelAssigner, notebookID string, chunks [][]byte, names []string, ids map[string]string, rules []LabelRule, dryRun bool, out *outputWriter) error {
+	existing, err := la.ListLabels(ctx, notebookID)
+	if err != nil {
+		return fmt.Errorf("list labels: %w", err)
+	}
+	byName := make(map[string]Label, len(existing))
+	for _, l := range existing {
+		key := strings.ToLower(l.Name)
+		if _, dup := byName[key]; !dup {
+			byName[key] = l
+		}
+	}
+
+	var errs []error
+	for i, chunk := range chunks {
+		name := names[i]
+		sourceID := ids[name]
+		for _, labelName := range labelsFor(rules, chunkMembers(chunk)) {
+			label, ok := byName[strings.ToLower(labelName)]
+			if ok && sourceID != "" && slices.Contains(label.SourceIDs, sourceID) {
+				continue
+			}
+			if dryRun || sourceID == "" {
+				out.emit(event{Action: "label", Name: name, Label: labelName, DryRun: true})
+				continue
+			}
+			if !ok {
+				label, err = createLabel(ctx, la, notebookID, labelName)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				byName[strings.ToLower(labelName)] = label
+			}
+			if err := la.AttachLabelSource(ctx, notebookID, label.ID, sourceID); err != nil {
+				errs = append(errs, fmt.Errorf("attach label %q to %q: %w", labelName, name, err))
+				continue
+			}
+			label.SourceIDs = append(label.SourceIDs, sourceID)
+			byName[strings.ToLower(labelName)] = label
+			out.emit(event{Action: "label", Name: name, SourceID: sourceID, Label: labelName})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// createLabel creates a label and finds it in the refreshed list the
+// server returns, since CreateLabel does not echo the new ID on its own.
+func createLabel(ctx context.Context, la LabelAssigner, notebookID, name string) (Label, error) {
+	labels, err := la.CreateLabel(ctx, notebookID, name)
+	if err != nil {
+		return Label{}, fmt.Errorf("create label %q: %w", name, err)
+	}
+	for _, l := range labels {
+		if strings.EqualFold(l.Name, name) {
+			return l, nil
+		}
+	}
+	return Label{}, fmt.Errorf("create label %q: label missing from server response", name)
+}
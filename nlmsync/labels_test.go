@@ -0,0 +1,262 @@
+package nlmsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMatchLabelPattern(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"docs/**", "docs/a.md", true},
+		{"docs/**", "docs/guide/b.md", true},
+		{"docs/**", "api/docs/a.md", false},
+		{"**/api/*.go", "internal/api/x.go", true},
+		{"**/api/*.go", "api/x.go", true},
+		{"**/api/*.go", "api/sub/x.go", false},
+		{"*.md", "docs/guide/b.md", true},
+		{"README.md", "README.md", true},
+		{"cmd/*/main.go", "cmd/nlm/main.go", true},
+		{"cmd/*/main.go", "cmd/nlm/sub/main.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchLabelPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchLabelPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseLabelRule(t *testing.T) {
+	r, err := ParseLabelRule("docs/** = Docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Pattern != "docs/**" || r.Label != "Docs" {
+		t.Errorf("rule = %+v", r)
+	}
+	for _, bad := range []string{"docs/**", "=Docs", "docs/**=", "[=Docs"} {
+		if _, err := ParseLabelRule(bad); err == nil {
+			t.Errorf("ParseLabelRule(%q): want error", bad)
+		}
+	}
+}
+
+// fakeAssignClient extends fakeClient with the LabelAssigner capability.
+type fakeAssignClient struct {
+	*fakeClient
+	labels  []Label
+	created []string
+}
+
+func (f *fakeAssignClient) ListLabels(_ context.Context, _ string) ([]Label, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Label(nil), f.labels...), nil
+}
+
+func (f *fakeAssignClient) CreateLabel(_ context.Context, _, name string) ([]Label, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, name)
+	f.labels = append(f.labels, Label{ID: "label-" + name, Name: name})
+	return append([]Label(nil), f.labels...), nil
+}
+
+func (f *fakeAssignClient) AttachLabelSource(_ context.Context, _, labelID, sourceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.labels {
+		if f.labels[i].ID == labelID {
+			f.labels[i].SourceIDs = append(f.labels[i].SourceIDs, sourceID)
+		}
+	}
+	return nil
+}
+
+func TestRunAppliesLabelRulesPerPart(t *testing.T) {
+	setupTestHome(t)
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "docs"), 0o755)
+	os.MkdirAll(filepath.Join(dir, "api"), 0o755)
+	os.WriteFile(filepath.Join(dir, "docs", "guide.md"), []byte(strings.Repeat("d", 400)), 0o644)
+	os.WriteFile(filepath.Join(dir, "api", "x.go"), []byte(strings.Repeat("a", 400)), 0o644)
+
+	fc := &fakeAssignClient{
+		fakeClient: &fakeClient{},
+		labels:     []Label{{ID: "label-docs", Name: "docs"}},
+	}
+	rules := []LabelRule{{Pattern: "docs/**", Label: "Docs"}, {Pattern: "api/**", Label: "API"}}
+	opts := Options{Name: "tree", MaxBytes: 600, Labels: rules}
+	var buf bytes.Buffer
+	if err := Run(context.Background(), fc, "nb", []string{dir}, opts, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.uploaded) != 2 {
+		t.Fatalf("uploads = %d, want one part per file", len(fc.uploaded))
+	}
+	// "Docs" reuses the existing "docs" label (names match case-insensitively);
+	// only "API" is created.
+	if strings.Join(fc.created, ",") != "API" {
+		t.Errorf("created = %v, want [API]", fc.created)
+	}
+	got := map[string][]string{}
+	for _, l := range fc.labels {
+		got[l.Name] = l.SourceIDs
+	}
+	partOf := func(member string) string {
+		for _, u := range fc.uploaded {
+			if strings.Contains(u.content, "-- "+member+" --") {
+				return "src-" + u.title
+			}
+		}
+		return ""
+	}
+	if ids := got["docs"]; len(ids) != 1 || ids[0] != partOf("docs/guide.md") {
+		t.Errorf("docs label sources = %v, want [%s]", ids, partOf("docs/guide.md"))
+	}
+	if ids := got["API"]; len(ids) != 1 || ids[0] != partOf("api/x.go") {
+		t.Errorf("API label sources = %v, want [%s]", ids, partOf("api/x.go"))
+	}
+
+	// A second, unchanged sync skips uploads and finds every assignment in
+	// place, so it attaches nothing new.
+	before := len(fc.labels[0].SourceIDs) + len(fc.labels[1].SourceIDs)
+	if err := Run(context.Background(), fc, "nb", []string{dir}, opts, &buf); err != nil {
+		t.Fatal(err)
+	}
+	after := len(fc.labels[0].SourceIDs) + len(fc.labels[1].SourceIDs)
+	if after != before {
+		t.Errorf("second sync attached %d more labels, want 0", after-before)
+	}
+}
+
+func TestRunLabelRulesRequireAssigner(t *testing.T) {
+	setupTestHome(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644)
+	opts := Options{Name: "x", Labels: []LabelRule{{Pattern: "*", Label: "All"}}}
+	fc := &fakeClient{}
+	if err := Run(context.Background(), fc, "nb", []string{dir}, opts, &bytes.Buffer{}); err == nil {
+		t.Fatal("want error for client without LabelAssigner")
+	}
+	if len(fc.uploaded) != 0 {
+		t.Errorf("uploaded %d sources before rejecting label rules", len(fc.uploaded))
+	}
+}
+
+// TestRunLabelsMixedSkipAndReplace re-syncs a tree where some parts are
+// unchanged and others changed, so skip decisions record source IDs while
+// replace uploads are still in flight. Run with -race; JSON output keeps
+// progress off os.Stderr, whose writes would order the two for the race
+// detector and hide the bug.
+func TestRunLabelsMixedSkipAndReplace(t *testing.T) {
+	setupTestHome(t)
+	dir := t.TempDir()
+	write := func(i int, body string) {
+		name := filepath.Join(dir, fmt.Sprintf("f%d.md", i))
+		if err := os.WriteFile(name, []byte(strings.Repeat(body, 400)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const files = 8
+	for i := range files {
+		write(i, "v1 ")
+	}
+	fc := &fakeAssignClient{fakeClient: &fakeClient{}}
+	opts := Options{Name: "tree", MaxBytes: 1500, Parallel: 4, JSON: true, Labels: []LabelRule{{Pattern: "*.md", Label: "Docs"}}}
+	if err := Run(context.Background(), fc, "nb", []string{dir}, opts, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.uploaded) != files {
+		t.Fatalf("uploads = %d, want one part per file", len(fc.uploaded))
+	}
+
+	for i := 0; i < files; i += 2 {
+		write(i, "v2 ")
+	}
+	var buf bytes.Buffer
+	if err := Run(context.Background(), fc, "nb", []string{dir}, opts, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(fc.uploaded) - files; got != files/2 {
+		t.Errorf("re-sync uploaded %d parts, want %d", got, files/2)
+	}
+	labels, _ := fc.ListLabels(context.Background(), "nb")
+	if len(labels) != 1 || len(labels[0].SourceIDs) != files {
+		t.Errorf("labels after re-sync = %+v, want Docs on all %d parts", labels, files)
+	}
+}
+
+func TestRunLabelRulesFromManifest(t *testing.T) {
+	setupTestHome(t)
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "docs"), 0o755)
+	os.WriteFile(filepath.Join(dir, "docs", "guide.md"), []byte("guide"), 0o644)
+	os.WriteFile(filepath.Join(dir, labelsFileName), []byte("# labels for sync\n\ndocs/** = Docs\n"), 0o644)
+
+	fc := &fakeAssignClient{fakeClient: &fakeClient{}}
+	if err := Run(context.Background(), fc, "nb", []string{dir}, Options{Name: "tree", JSON: true}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.labels) != 1 || fc.labels[0].Name != "Docs" || len(fc.labels[0].SourceIDs) != 1 {
+		t.Errorf("labels = %+v, want Docs from %s on the synced part", fc.labels, labelsFileName)
+	}
+
+	os.WriteFile(filepath.Join(dir, labelsFileName), []byte("docs/**\n"), 0o644)
+	_, _, err := Pack([]string{dir}, Options{Name: "tree"})
+	if want := labelsFileName + ":1: invalid label rule"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Pack with a bad manifest: err = %v, want one containing %q", err, want)
+	}
+}
+
+func TestRunPerFileLabelsAndOrphans(t *testing.T) {
+	setupTestHome(t)
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "docs"), 0o755)
+	os.MkdirAll(filepath.Join(dir, "api"), 0o755)
+	os.WriteFile(filepath.Join(dir, "docs", "guide.md"), []byte("guide"), 0o644)
+	os.WriteFile(filepath.Join(dir, "api", "x.go"), []byte("package api"), 0o644)
+
+	// A source another tool named under the same prefix, which sync never
+	// uploaded and so must not delete.
+	fc := &fakeAssignClient{fakeClient: &fakeClient{sources: []Source{{ID: "src-other", Title: "tree/notes.txt"}}}}
+	rules := []LabelRule{{Pattern: "docs/**", Label: "Docs"}, {Pattern: "*.go", Label: "Go"}}
+	opts := Options{Name: "tree", PerFile: true, JSON: true, Labels: rules}
+	if err := Run(context.Background(), fc, "nb", []string{dir}, opts, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, u := range fc.uploaded {
+		titles = append(titles, u.title)
+	}
+	slices.Sort(titles)
+	if got := strings.Join(titles, ","); got != "tree/api/x.go,tree/docs/guide.md" {
+		t.Errorf("uploaded %s, want one source per file", got)
+	}
+	got := map[string][]string{}
+	for _, l := range fc.labels {
+		got[l.Name] = l.SourceIDs
+	}
+	if ids := got["Docs"]; len(ids) != 1 || ids[0] != "src-tree/docs/guide.md" {
+		t.Errorf("Docs label sources = %v, want only the guide", ids)
+	}
+	if ids := got["Go"]; len(ids) != 1 || ids[0] != "src-tree/api/x.go" {
+		t.Errorf("Go label sources = %v, want only x.go", ids)
+	}
+
+	os.Remove(filepath.Join(dir, "api", "x.go"))
+	if err := Run(context.Background(), fc, "nb", []string{dir}, opts, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(fc.deleted, ","); got != "src-tree/api/x.go" {
+		t.Errorf("deleted %q, want only the removed file's source", got)
+	}
+}
@@ -0,0 +1,77 @@
+package nlmsync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// lockPollInterval is how often a waiting sync retries the notebook lock.
+const lockPollInterval = 250 * time.Millisecond
+
+// syncLock is an advisory, cross-process lock that serializes syncs of one
+// notebook, so a cron job and a developer (or two CI shards) cannot
+// interleave their rename/upload/delete sequences or hash cache writes.
+type syncLock struct {
+	f *os.File
+}
+
+// lockNotebook acquires the sync lock stored in dir, the notebook's hash
+// cache directory. It waits for a concurrent holder to finish, reporting
+// that holder once on stderr, and gives up when ctx is done.
+func lockNotebook(ctx context.Context, dir string) (*syncLock, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, ".lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open sync lock: %w", err)
+	}
+	waiting := false
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %w", path, err)
+		}
+		if ok {
+			break
+		}
+		if !waiting {
+			waiting = true
+			fmt.Fprintf(os.Stderr, "waiting for another sync of this notebook (%s) to finish\n", lockHolder(f))
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+	// Record the holder for the benefit of waiters; the lock itself is the
+	// kernel-level one, so a stale pid here is harmless.
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(fmt.Sprintf("pid %d\n", os.Getpid())), 0)
+	}
+	return &syncLock{f: f}, nil
+}
+
+// unlock releases the lock. The lock file itself is left in place:
+// removing it would let a waiter lock an unlinked inode.
+func (l *syncLock) unlock() {
+	unlockFile(l.f)
+	l.f.Close()
+}
+
+func lockHolder(f *os.File) string {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, 64))
+	if err != nil || len(data) == 0 {
+		return "unknown holder"
+	}
+	return strings.TrimSpace(string(data))
+}
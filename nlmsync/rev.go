@@ -0,0 +1,297 @@
+package nlmsync
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gitRevFiles returns the files under path as they exist at rev, reading
+// blob contents straight from the object database so no checkout is needed.
+// A directory expands to every blob beneath it, named relative to the repo
+// root like gitFiles; a plain file keeps its basename, matching discoverFiles.
+// Symlinks and submodule entries are skipped, as are non-regular files in the
+// working-tree walk.
+//
+// The path does not have to exist in the working tree: a file deleted since
+// rev can still be synced from it as long as its parent directory exists.
+func gitRevFiles(path, rev string) ([]discovered, error) {
+	dir, spec, isDir := path, ".", true
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		dir, spec, isDir = filepath.Dir(path), filepath.Base(path), false
+	}
+	root := gitRoot(dir)
+	if root == "" {
+		return nil, fmt.Errorf("--rev requires a git checkout: %s is not inside one", path)
+	}
+	commit, err := gitResolveCommit(dir, rev)
+	if err != nil {
+		return nil, fmt.Errorf("--rev: %w", err)
+	}
+	entries, err := gitLsTree(dir, commit, spec)
+	if err != nil {
+		return nil, fmt.Errorf("list %s at %s: %w", path, rev, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s does not exist at %s", path, rev)
+	}
+	oids := make([]string, len(entries))
+	for i, e := range entries {
+		oids[i] = e.oid
+	}
+	blobs, err := gitCatBlobs(root, oids)
+	if err != nil {
+		return nil, fmt.Errorf("read blobs at %s: %w", rev, err)
+	}
+	files := make([]discovered, len(entries))
+	for i, e := range entries {
+		name := e.name
+		if !isDir {
+			name = filepath.Base(e.name)
+		}
+		files[i] = discovered{
+			Path: rev + ":" + e.name,
+			Name: filepath.ToSlash(name),
+			Data: blobs[i],
+		}
+	}
+	return files, nil
+}
+
+// treeEntry is one blob listed by git ls-tree.
+type treeEntry struct {
+	oid  string
+	name string // repo-root-relative, slash-separated
+}
+
+// gitLsTree lists the regular-file blobs matching spec at commit, a hash
+// from gitResolveCommit. spec is interpreted relative to dir; names come back
+// repo-root-relative because of --full-name.
+func gitLsTree(dir, commit, spec string) ([]treeEntry, error) {
+	cmd := exec.Command("git", "ls-tree", "-r", "-z", "--full-name", commit, "--", spec)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git ls-tree: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("git ls-tree: %w", err)
+	}
+	var entries []treeEntry
+	for _, rec := range strings.Split(string(out), "\000") {
+		if rec == "" {
+			continue
+		}
+		// "<mode> SP <type> SP <object> TAB <file>"
+		meta, name, ok := strings.Cut(rec, "\t")
+		if !ok {
+			return nil, fmt.Errorf("git ls-tree: malformed entry %q", rec)
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("git ls-tree: malformed entry %q", rec)
+		}
+		mode, typ, oid := fields[0], fields[1], fields[2]
+		if typ != "blob" || mode == "120000" {
+			continue
+		}
+		entries = append(entries, treeEntry{oid: oid, name: name})
+	}
+	return entries, nil
+}
+
+// gitCatBlobs reads the given blobs through a single `git cat-file --batch`
+// process, which is equivalent to one `git show <oid>` per file without the
+// per-file process cost that matters for multi-thousand-file trees.
+func gitCatBlobs(dir string, oids []string) ([][]byte, error) {
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	// Feed requests from a goroutine so a large batch cannot deadlock
+	// against git blocking on a full stdout pipe.
+	go func() {
+		w := bufio.NewWriter(stdin)
+		for _, oid := range oids {
+			fmt.Fprintln(w, oid)
+		}
+		w.Flush()
+		stdin.Close()
+	}()
+
+	r := bufio.NewReader(stdout)
+	blobs := make([][]byte, 0, len(oids))
+	var readErr error
+	for _, oid := range oids {
+		data, err := readCatFileObject(r, oid)
+		if err != nil {
+			readErr = err
+			break
+		}
+		blobs = append(blobs, data)
+	}
+	if readErr != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, readErr
+	}
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git cat-file: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	return blobs, nil
+}
+
+// readCatFileObject reads one "<oid> <type> <size>\n<content>\n" record.
+func readCatFileObject(r *bufio.Reader, oid string) ([]byte, error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: read header for %s: %w", oid, err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return nil, fmt.Errorf("git cat-file: object %s missing", oid)
+	}
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("git cat-file: malformed header %q", strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("git cat-file: malformed size in %q", strings.TrimSpace(header))
+	}
+	data := make([]byte, size+1) // trailing LF
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("git cat-file: read %s: %w", oid, err)
+	}
+	return data[:size], nil
+}
+
+// gitResolveCommit resolves rev to a full commit hash in the repo enclosing
+// dir, so a typo fails up front with git's own message instead of surfacing
+// as an empty tree. Callers pass the hash, never rev, on to other git
+// commands, and a rev starting with "-" is refused so it cannot be read as
+// an option.
+func gitResolveCommit(dir, rev string) (string, error) {
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("invalid revision %q", rev)
+	}
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// changesSourceName returns the title of the "changes since" source that
+// accompanies a sync of name when --since is set.
+func changesSourceName(name, since string) string {
+	return fmt.Sprintf("%s (changes since %s)", name, since)
+}
+
+// gitChanges builds the members of the "changes since" source: the commit
+// log for since..rev and a unified diff of the same range, both limited to
+// the synced paths. An empty rev means the working tree, so the diff also
+// shows uncommitted edits while the log stops at HEAD.
+func gitChanges(paths []string, since, rev string) ([]discovered, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("--since requires explicit paths")
+	}
+	var root string
+	var specs []string
+	for _, p := range paths {
+		dir, base := p, ""
+		if info, err := os.Stat(p); err != nil || !info.IsDir() {
+			dir, base = filepath.Dir(p), filepath.Base(p)
+		}
+		r := gitRoot(dir)
+		if r == "" {
+			return nil, fmt.Errorf("--since requires a git checkout: %s is not inside one", p)
+		}
+		if root == "" {
+			root = r
+		} else if r != root {
+			return nil, fmt.Errorf("--since requires all paths in one repository: %s is outside %s", p, root)
+		}
+		prefix, err := gitOutput(dir, "rev-parse", "--show-prefix")
+		if err != nil {
+			return nil, err
+		}
+		spec := strings.TrimSpace(prefix) + base
+		if spec == "" {
+			spec = "."
+		}
+		specs = append(specs, spec)
+	}
+
+	sinceCommit, err := gitResolveCommit(root, since)
+	if err != nil {
+		return nil, fmt.Errorf("--since: %w", err)
+	}
+	end := "HEAD"
+	var revCommit string
+	if rev != "" {
+		end = rev
+		if revCommit, err = gitResolveCommit(root, rev); err != nil {
+			return nil, fmt.Errorf("--rev: %w", err)
+		}
+	}
+	logArgs := append([]string{"log", "--no-color", "--format=medium", "--stat", sinceCommit + ".." + cmp.Or(revCommit, "HEAD"), "--"}, specs...)
+	log, err := gitOutput(root, logArgs...)
+	if err != nil {
+		return nil, err
+	}
+	diffArgs := []string{"diff", "--no-color", "--no-ext-diff", sinceCommit}
+	if revCommit != "" {
+		diffArgs = append(diffArgs, revCommit)
+	}
+	diffArgs = append(append(diffArgs, "--"), specs...)
+	diff, err := gitOutput(root, diffArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return []discovered{
+		{Path: "git log " + since + ".." + end, Name: "CHANGES.log", Data: []byte(log)},
+		{Path: "git diff " + since, Name: "CHANGES.diff", Data: []byte(diff)},
+	}, nil
+}
+
+// gitOutput runs git in dir and returns stdout, folding stderr into the
+// error so failures explain themselves.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
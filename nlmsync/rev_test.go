@@ -0,0 +1,174 @@
+package nlmsync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRevRepo creates a repository with two tagged commits: v1 holds
+// "one" in docs/a.txt, v2 holds "two" plus docs/b.txt. The working tree is
+// then edited to "three" without committing, so tests can tell the three
+// states apart.
+func newRevRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		// Disable commit/tag signing at the command level so a user's global
+		// gpg/ssh signing config doesn't reach into this throwaway repo and
+		// block on a passphrase prompt in the non-interactive subprocess.
+		argv := append([]string{"-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, args...)
+		cmd := exec.Command("git", argv...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	write("docs/a.txt", "one\n")
+	write("README.md", "readme\n")
+	run("add", "-A")
+	run("commit", "-q", "-m", "first release")
+	run("tag", "v1")
+	write("docs/a.txt", "two\n")
+	write("docs/b.txt", "added in v2\n")
+	run("add", "-A")
+	run("commit", "-q", "-m", "second release")
+	run("tag", "v2")
+	write("docs/a.txt", "three\n")
+	return dir
+}
+
+func TestGitRevFilesReadsCommitNotWorkingTree(t *testing.T) {
+	dir := newRevRepo(t)
+
+	files, err := gitRevFiles(filepath.Join(dir, "docs"), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files at v1, want 1: %+v", len(files), files)
+	}
+	if files[0].Name != "docs/a.txt" || string(files[0].Data) != "one\n" {
+		t.Errorf("v1 file = %q %q, want docs/a.txt %q", files[0].Name, files[0].Data, "one\n")
+	}
+
+	files, err = gitRevFiles(filepath.Join(dir, "docs", "a.txt"), "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "a.txt" || string(files[0].Data) != "two\n" {
+		t.Errorf("v2 single file = %+v, want a.txt %q", files, "two\n")
+	}
+
+	if _, err := gitRevFiles(filepath.Join(dir, "docs", "b.txt"), "v1"); err == nil {
+		t.Error("gitRevFiles of a file absent at v1: want error")
+	}
+}
+
+func TestPackRevDefaultsNameAndSince(t *testing.T) {
+	dir := newRevRepo(t)
+	docs := filepath.Join(dir, "docs")
+
+	chunks, names, err := Pack([]string{docs}, Options{Rev: "v2", Since: "v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantNames := []string{"docs@v2", "docs@v2 (changes since v1)"}
+	if strings.Join(names, "|") != strings.Join(wantNames, "|") {
+		t.Fatalf("names = %q, want %q", names, wantNames)
+	}
+	if !strings.Contains(string(chunks[0]), "two\n") || strings.Contains(string(chunks[0]), "three") {
+		t.Errorf("main chunk should hold v2 content only:\n%s", chunks[0])
+	}
+	changes := string(chunks[1])
+	for _, want := range []string{"-- CHANGES.log --", "second release", "-- CHANGES.diff --", "+two", "+added in v2"} {
+		if !strings.Contains(changes, want) {
+			t.Errorf("changes chunk missing %q:\n%s", want, changes)
+		}
+	}
+	if strings.Contains(changes, "first release") {
+		t.Errorf("changes log should stop at v1:\n%s", changes)
+	}
+}
+
+func TestPackSinceWorkingTreeIncludesUncommittedDiff(t *testing.T) {
+	dir := newRevRepo(t)
+
+	chunks, names, err := Pack([]string{filepath.Join(dir, "docs")}, Options{Name: "docs", Since: "v2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[1] != "docs (changes since v2)" {
+		t.Fatalf("names = %q", names)
+	}
+	if !strings.Contains(string(chunks[1]), "+three") {
+		t.Errorf("working-tree diff missing uncommitted edit:\n%s", chunks[1])
+	}
+}
+
+func TestRunSwitchingRevisionsReuploads(t *testing.T) {
+	setupTestHome(t)
+	dir := newRevRepo(t)
+	docs := filepath.Join(dir, "docs")
+	fc := &fakeClient{}
+	ctx := context.Background()
+
+	for i, rev := range []string{"v1", "v2", "v1", "v1"} {
+		var buf bytes.Buffer
+		if err := Run(ctx, fc, "nb-rev", []string{docs}, Options{Name: "docs", Rev: rev}, &buf); err != nil {
+			t.Fatalf("sync %d at %s: %v", i, rev, err)
+		}
+	}
+	// v1 uploads, v2 replaces, v1 replaces again, and only the final
+	// repeat of v1 is skipped by the hash cache.
+	if len(fc.uploaded) != 3 {
+		t.Fatalf("uploads = %d, want 3", len(fc.uploaded))
+	}
+	if got := fc.uploaded[2].content; !strings.Contains(got, "one\n") {
+		t.Errorf("third upload should carry v1 content, got:\n%s", got)
+	}
+}
+
+func TestRunRevRejectsStdinPaths(t *testing.T) {
+	setupTestHome(t)
+	err := Run(context.Background(), &fakeClient{}, "nb", nil, Options{Name: "x", Rev: "HEAD"}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "stdin") {
+		t.Fatalf("err = %v, want stdin rejection", err)
+	}
+}
+
+func TestGitRevFilesRejectsOptionRev(t *testing.T) {
+	dir := newRevRepo(t)
+	for _, rev := range []string{"--output=/tmp/x", "-p"} {
+		if _, err := gitRevFiles(filepath.Join(dir, "docs"), rev); err == nil || !strings.Contains(err.Error(), "invalid revision") {
+			t.Errorf("gitRevFiles(%q) error = %v, want invalid revision", rev, err)
+		}
+		if _, err := gitChanges([]string{filepath.Join(dir, "docs")}, "v1", rev); err == nil {
+			t.Errorf("gitChanges(rev %q) accepted an option-like revision", rev)
+		}
+	}
+}
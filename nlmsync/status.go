@@ -0,0 +1,122 @@
+package nlmsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// PartState classifies one synced part in a Status report.
+type PartState string
+
+const (
+	// StateUpToDate: the notebook holds the part under its title and the
+	// local bundle hashes to what was last uploaded.
+	StateUpToDate PartState = "up-to-date"
+	// StateChanged: the local bundle differs from what was last uploaded
+	// (or was never synced from this machine), so sync would replace it.
+	StateChanged PartState = "changed"
+	// StateMissing: no source holds the part, so sync would upload it.
+	StateMissing PartState = "missing"
+	// StateRenamed: the source last uploaded for the part still exists
+	// but under another title, so sync would upload a fresh copy.
+	StateRenamed PartState = "renamed"
+	// StateOrphaned: the notebook holds a part the local tree no longer
+	// produces, so sync would delete it.
+	StateOrphaned PartState = "orphaned"
+)
+
+// PartStatus is the drift state of one part.
+type PartStatus struct {
+	Name        string    `json:"name"`
+	State       PartState `json:"state"`
+	SourceID    string    `json:"source_id,omitempty"`
+	RemoteTitle string    `json:"remote_title,omitempty"`
+	Bytes       int       `json:"bytes,omitempty"`
+}
+
+// Drifted reports whether a sync would change the part.
+func (p PartStatus) Drifted() bool { return p.State != StateUpToDate }
+
+// Status compares what Run would upload for paths with the notebook
+// without changing either: it bundles and hashes locally, consults the
+// hash cache, and makes a single ListSources call. Parts are returned in
+// bundle order, followed by orphans sorted by title.
+func Status(ctx context.Context, c Client, notebookID string, paths []string, opts Options) ([]PartStatus, error) {
+	p, err := prepare(paths, opts)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := c.ListSources(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("list sources: %w", err)
+	}
+	byTitle := make(map[string]Source, len(sources))
+	byID := make(map[string]Source, len(sources))
+	for _, s := range sources {
+		byTitle[s.Title] = s
+		byID[s.ID] = s
+	}
+	hc := newHashCache(notebookID)
+
+	report := make([]PartStatus, 0, len(p.chunks))
+	local := make(map[string]bool, len(p.names))
+	for i, data := range p.chunks {
+		name := p.names[i]
+		local[name] = true
+		st := PartStatus{Name: name, Bytes: len(data)}
+		hash := fmt.Sprintf("%x", sha256.Sum256(data))
+		cached, cachedID := hc.entry(name)
+		if remote, ok := byTitle[name]; ok {
+			st.SourceID = remote.ID
+			st.State = StateChanged
+			if cached == hash {
+				st.State = StateUpToDate
+			}
+		} else if remote, ok := byID[cachedID]; ok {
+			st.State = StateRenamed
+			st.SourceID = remote.ID
+			st.RemoteTitle = remote.Title
+		} else {
+			st.State = StateMissing
+		}
+		report = append(report, st)
+	}
+
+	var orphans []PartStatus
+	for _, s := range sources {
+		if !local[s.Title] && p.owns(s.Title, hc) {
+			orphans = append(orphans, PartStatus{Name: s.Title, State: StateOrphaned, SourceID: s.ID})
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Name < orphans[j].Name })
+	return append(report, orphans...), nil
+}
+
+// WriteStatus prints a Status report: one line per part, or NDJSON
+// records when asJSON is set.
+func WriteStatus(w io.Writer, report []PartStatus, asJSON bool) error {
+	for _, st := range report {
+		if asJSON {
+			data, err := json.Marshal(st)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, string(data)); err != nil {
+				return err
+			}
+			continue
+		}
+		line := fmt.Sprintf("%-10s  %s", st.State, st.Name)
+		if st.RemoteTitle != "" {
+			line += fmt.Sprintf(" (now %q)", st.RemoteTitle)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
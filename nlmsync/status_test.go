@@ -0,0 +1,99 @@
+package nlmsync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatusReportsDrift(t *testing.T) {
+	setupTestHome(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte(strings.Repeat("a", 400)), 0o644)
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte(strings.Repeat("b", 400)), 0o644)
+	opts := Options{Name: "docs", MaxBytes: 600}
+	fc := &fakeClient{}
+	ctx := context.Background()
+	if err := Run(ctx, fc, "nb", []string{dir}, opts, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	states := func() map[string]PartStatus {
+		t.Helper()
+		listed := fc.listed
+		report, err := Status(ctx, fc, "nb", []string{dir}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fc.listed != listed+1 {
+			t.Errorf("Status made %d ListSources calls, want 1", fc.listed-listed)
+		}
+		m := make(map[string]PartStatus)
+		for _, st := range report {
+			m[st.Name] = st
+		}
+		return m
+	}
+
+	got := states()
+	if got["docs"].State != StateUpToDate || got["docs (pt2)"].State != StateUpToDate {
+		t.Fatalf("after sync: %+v", got)
+	}
+
+	// Local edit, remote rename, remote delete, and a stale extra part.
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte(strings.Repeat("A", 400)), 0o644)
+	fc.sources = []Source{
+		{ID: "src-docs", Title: "docs"},
+		{ID: "src-docs (pt2)", Title: "Docs, part two"},
+		{ID: "src-extra", Title: "docs (pt3)"},
+		{ID: "other", Title: "unrelated"},
+	}
+	got = states()
+	want := map[string]PartState{
+		"docs":       StateChanged,
+		"docs (pt2)": StateRenamed,
+		"docs (pt3)": StateOrphaned,
+	}
+	if len(got) != len(want) {
+		t.Errorf("report = %+v, want %d parts", got, len(want))
+	}
+	for name, state := range want {
+		if got[name].State != state {
+			t.Errorf("%s: state = %q, want %q", name, got[name].State, state)
+		}
+	}
+	if got["docs (pt2)"].RemoteTitle != "Docs, part two" {
+		t.Errorf("renamed part remote title = %q", got["docs (pt2)"].RemoteTitle)
+	}
+
+	fc.sources = nil
+	if got := states(); got["docs"].State != StateMissing {
+		t.Errorf("after remote delete: %+v", got["docs"])
+	}
+	if len(fc.uploaded) != 2 || len(fc.deleted) != 0 || len(fc.renamed) != 0 {
+		t.Errorf("Status mutated the notebook: %d uploads, %v deleted, %v renamed", len(fc.uploaded), fc.deleted, fc.renamed)
+	}
+}
+
+func TestWriteStatus(t *testing.T) {
+	report := []PartStatus{
+		{Name: "docs", State: StateUpToDate, SourceID: "s1"},
+		{Name: "docs (pt2)", State: StateRenamed, SourceID: "s2", RemoteTitle: "x"},
+	}
+	var buf bytes.Buffer
+	if err := WriteStatus(&buf, report, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "up-to-date  docs\nrenamed     docs (pt2) (now \"x\")\n"
+	if buf.String() != want {
+		t.Errorf("text:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	buf.Reset()
+	WriteStatus(&buf, report[1:], true)
+	if want := `{"name":"docs (pt2)","state":"renamed","source_id":"s2","remote_title":"x"}` + "\n"; buf.String() != want {
+		t.Errorf("json = %s", buf.String())
+	}
+}
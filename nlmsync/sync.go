@@ -59,6 +59,31 @@ type Options struct {
 	// file name is preserved as the txtar entry name; the command can use
 	// $NLM_FILE_NAME to read the original path.
 	PreProcess string
+	// Rev, if non-empty, bundles file contents as of that git revision
+	// (read with git ls-tree and the object database) instead of the working
+	// tree, so a release can be synced without checking it out. The default
+	// source name gains an "@rev" suffix so revisions sync side by side.
+	Rev string
+	// Since, if non-empty, adds a "<name> (changes since <rev>)" source
+	// holding the commit log and unified diff from Since to Rev (or to the
+	// working tree when Rev is empty), limited to the synced paths.
+	Since string
+	// Labels, if non-empty, attaches each synced part to the labels whose
+	// rules match one of its member paths, creating missing labels first.
+	// Rules from a .nlmlabels file at the repo root are added to these.
+	// When any rule applies, the client must implement LabelAssigner.
+	Labels []LabelRule
+	// PerFile uploads each file as its own source titled "<name>/<path>"
+	// instead of bundling them together, so each file gets the labels its
+	// own path selects and can be picked out on its own in chat.
+	PerFile bool
+	// Extract names registered extractors (see RegisterExtractor) to run
+	// in-process over matching files before bundling, e.g. "ipynb" or
+	// "html"; each entry may be a comma-separated list. Like PreProcess
+	// output, extracted text is what gets hashed and uploaded. Extractors
+	// run first, so a PreProcess command sees their output. Names listed
+	// in a .nlmextract file at the repo root are added to these.
+	Extract []string
 }
 
 func (o *Options) maxBytes() int {
@@ -83,74 +108,155 @@ func (o *Options) parallel() int {
 // discover/quote/bundle pipeline as Run but performs no network I/O.
 // Intended for preview (`nlm sync-pack`) and for tests.
 func Pack(paths []string, opts Options) (chunks [][]byte, names []string, err error) {
-	name, err := resolveName(opts.Name, paths)
+	p, err := prepare(paths, opts)
 	if err != nil {
 		return nil, nil, err
 	}
-	files, err := discoverFiles(paths, opts.IncludeUntracked)
+	return p.chunks, p.names, nil
+}
+
+// prepared is the network-free half of a sync: every chunk to upload, its
+// remote title, and the base names whose "(ptN)" parts the sync owns.
+type prepared struct {
+	chunks [][]byte
+	names  []string
+	bases  []string
+	// prefix, set in per-file mode, is the "<name>/" every per-file title
+	// starts with; see owns.
+	prefix string
+	rules  []LabelRule
+}
+
+// owns reports whether the remote source titled title belongs to this sync,
+// so a sync that no longer produces it should delete it. Beyond the parts
+// of each base name, a per-file sync owns the titles under its prefix that
+// the hash cache shows it uploaded, which covers files deleted since; the
+// cache check keeps it off sources someone else named alike.
+func (p *prepared) owns(title string, hc *hashCache) bool {
+	if isPartOfAny(title, p.bases) {
+		return true
+	}
+	if p.prefix == "" || !strings.HasPrefix(title, p.prefix) {
+		return false
+	}
+	return hc.load(title) != ""
+}
+
+// prepare discovers, filters, and bundles paths. With opts.Since it appends
+// the chunks of the "changes since" source after the main bundle's chunks.
+func prepare(paths []string, opts Options) (*prepared, error) {
+	name, err := resolveName(opts.Name, paths)
 	if err != nil {
-		return nil, nil, fmt.Errorf("discover files: %w", err)
+		return nil, err
+	}
+	if opts.Name == "" && opts.Rev != "" {
+		name += "@" + opts.Rev
+	}
+	extract, err := mergeExtractNames(paths, opts.Extract)
+	if err != nil {
+		return nil, err
+	}
+	extractors, err := selectExtractors(extract)
+	if err != nil {
+		return nil, err
+	}
+	files, err := discoverFilesAt(paths, opts.Rev, opts.IncludeUntracked)
+	if err != nil {
+		return nil, fmt.Errorf("discover files: %w", err)
 	}
 	excludes, err := mergeIgnores(paths, opts.Exclude)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	files, err = applyExcludes(files, excludes)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if len(files) == 0 {
-		return nil, nil, fmt.Errorf("no files found")
+		return nil, fmt.Errorf("no files found")
 	}
-	chunks, err = bundle(files, opts.maxBytes(), opts.PreProcess)
+	files, err = extractors.extract(files)
 	if err != nil {
-		return nil, nil, fmt.Errorf("bundle: %w", err)
+		return nil, err
 	}
-	return chunks, chunkNames(name, len(chunks)), nil
+	rules, err := mergeLabelRules(paths, opts.Labels)
+	if err != nil {
+		return nil, err
+	}
+	// The base name stays owned in per-file mode, so switching a sync to
+	// it deletes the bundle parts it replaces.
+	p := &prepared{bases: []string{name}, rules: rules}
+	if opts.PerFile {
+		p.prefix = name + "/"
+		for _, f := range files {
+			chunks, err := bundle([]discovered{f}, opts.maxBytes(), opts.PreProcess)
+			if err != nil {
+				return nil, fmt.Errorf("bundle: %w", err)
+			}
+			p.chunks = append(p.chunks, chunks...)
+			p.names = append(p.names, chunkNames(p.prefix+f.Name, len(chunks))...)
+		}
+	} else {
+		chunks, err := bundle(files, opts.maxBytes(), opts.PreProcess)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: %w", err)
+		}
+		p.chunks, p.names = chunks, chunkNames(name, len(chunks))
+	}
+	if opts.Since != "" && len(p.chunks) > 0 {
+		changes, err := gitChanges(paths, opts.Since, opts.Rev)
+		if err != nil {
+			return nil, err
+		}
+		// Generated text is bundled without PreProcess: the command was
+		// written for source files, not for git's log and diff output.
+		changeChunks, err := bundle(changes, opts.maxBytes(), "")
+		if err != nil {
+			return nil, fmt.Errorf("bundle changes: %w", err)
+		}
+		changesName := changesSourceName(name, opts.Since)
+		p.chunks = append(p.chunks, changeChunks...)
+		p.names = append(p.names, chunkNames(changesName, len(changeChunks))...)
+		p.bases = append(p.bases, changesName)
+	}
+	return p, nil
 }
 
 // Run syncs a single named source to a notebook.
 //
 // paths is a list of files and/or directories. Directories are expanded
 // via git ls-files. All files are bundled into txtar, chunked at
-// opts.MaxBytes, and uploaded as "name", "name (pt2)", etc.
+// opts.MaxBytes, and uploaded as "name", "name (pt2)", etc. With
+// opts.PerFile each file is bundled alone and uploaded as "name/path".
 //
 // If paths is nil, file paths are read from stdin (one per line).
+//
+// The hash cache is keyed by remote title and stores the content hash of
+// what was last uploaded under it, so switching opts.Rev back and forth
+// under one --name re-uploads whenever the bundled bytes differ, and two
+// revisions with identical trees are correctly treated as unchanged.
+//
+// Runs against the same notebook are serialized by an advisory lock beside
+// the hash cache, and every replace is recorded in a journal first. A run
+// that finds a journal left by a crashed predecessor reconciles it before
+// syncing; see Repair.
 func Run(ctx context.Context, c Client, notebookID string, paths []string, opts Options, w io.Writer) error {
-	name, err := resolveName(opts.Name, paths)
+	p, err := prepare(paths, opts)
 	if err != nil {
 		return err
 	}
-
-	// Discover files.
-	files, err := discoverFiles(paths, opts.IncludeUntracked)
-	if err != nil {
-		return fmt.Errorf("discover files: %w", err)
-	}
-	excludes, err := mergeIgnores(paths, opts.Exclude)
-	if err != nil {
-		return err
-	}
-	files, err = applyExcludes(files, excludes)
-	if err != nil {
-		return err
-	}
-	if len(files) == 0 {
-		return fmt.Errorf("no files found")
-	}
-
-	// Bundle into txtar chunks.
-	chunks, err := bundle(files, opts.maxBytes(), opts.PreProcess)
-	if err != nil {
-		return fmt.Errorf("bundle: %w", err)
+	var la LabelAssigner
+	if len(p.rules) > 0 {
+		var ok bool
+		if la, ok = c.(LabelAssigner); !ok {
+			return fmt.Errorf("label rules require a client that can manage labels")
+		}
 	}
+	chunks, names := p.chunks, p.names
 	if len(chunks) == 0 {
 		return fmt.Errorf("no text files found")
 	}
 
-	// Name each chunk.
-	names := chunkNames(name, len(chunks))
-
 	// Hash each chunk.
 	hashes := make([]string, len(chunks))
 	for i, data := range chunks {
@@ -158,9 +264,22 @@ func Run(ctx context.Context, c Client, notebookID string, paths []string, opts
 		hashes[i] = fmt.Sprintf("%x", h)
 	}
 
-	// Load caches.
+	// Load caches. Dry runs neither lock nor change state, so they only
+	// report what recovery would do.
 	hc := newHashCache(notebookID)
 	sc := newSourceCache()
+	out := &outputWriter{w: w, json: opts.JSON}
+	if !opts.DryRun {
+		lock, err := lockNotebook(ctx, hc.dir)
+		if err != nil {
+			return err
+		}
+		defer lock.unlock()
+	}
+	j := openJournal(hc.dir)
+	if _, err := recoverJournal(ctx, c, notebookID, j, hc, nil, opts.DryRun, out); err != nil {
+		return fmt.Errorf("recover interrupted sync: %w (retry with --repair)", err)
+	}
 
 	// Always fetch the live source list before a mutating sync. Hash-cache
 	// skips are only correct if the remote source still exists.
@@ -176,13 +295,12 @@ func Run(ctx context.Context, c Client, notebookID string, paths []string, opts
 		byTitle[s.Title] = s
 	}
 
-	out := &outputWriter{w: w, json: opts.JSON}
-
 	// Plan: walk all chunks once and decide each chunk's action up front so
 	// skip/dry-run output stays ordered. Real uploads run concurrently with
 	// bounded parallelism. Each chunk targets a unique remote name, so
 	// parallel uploads do not collide. State writes (hash cache, source
-	// cache, output) go through mu.
+	// cache, output, ids) go through mu, including writes from this loop,
+	// which run alongside earlier chunks' uploads.
 	//
 	// One chunk's failure must not abort the others: an in-flight rename or
 	// upload aborted by a sibling's error tends to leave the notebook in a
@@ -195,6 +313,7 @@ func Run(ctx context.Context, c Client, notebookID string, paths []string, opts
 		wg     sync.WaitGroup
 		errsMu sync.Mutex
 		errs   []error
+		ids    = make(map[string]string, len(chunks)) // part name -> source ID, for labeling
 	)
 	sem := make(chan struct{}, opts.parallel())
 
@@ -206,7 +325,10 @@ func Run(ctx context.Context, c Client, notebookID string, paths []string, opts
 		// Skip only when the hash is unchanged and the remote source is still
 		// present under the expected title.
 		if !opts.Force && exists && !hc.changed(chunkName, hash) {
+			mu.Lock()
+			ids[chunkName] = existing.ID
 			out.emit(event{Action: "skip", Name: chunkName, Reason: "unchanged"})
+			mu.Unlock()
 			continue
 		}
 
@@ -214,6 +336,9 @@ func Run(ctx context.Context, c Client, notebookID string, paths []string, opts
 			action := "upload"
 			if exists {
 				action = "replace"
+				mu.Lock()
+				ids[chunkName] = existing.ID
+				mu.Unlock()
 			}
 			out.emit(event{Action: action, Name: chunkName, Bytes: len(data), DryRun: true})
 			continue
@@ -237,11 +362,16 @@ func Run(ctx context.Context, c Client, notebookID string, paths []string, opts
 		go func() {
 			defer wg.Done()
 			defer func() { <-sem }()
-			if err := uploadChunk(ctx, c, notebookID, chunkName, data, hash, existing, exists, hc, sc, out, &mu); err != nil {
+			newID, err := uploadChunk(ctx, c, notebookID, chunkName, data, hash, existing, exists, hc, sc, j, out, &mu)
+			if err != nil {
 				errsMu.Lock()
 				errs = append(errs, err)
 				errsMu.Unlock()
+				return
 			}
+			mu.Lock()
+			ids[chunkName] = newID
+			mu.Unlock()
 		}()
 	}
 wait:
@@ -262,7 +392,7 @@ wait:
 		if activeNames[title] {
 			continue
 		}
-		if !isPartOf(title, name) {
+		if !p.owns(title, hc) {
 			continue
 		}
 		if opts.DryRun {
@@ -273,33 +403,47 @@ wait:
 			return fmt.Errorf("delete orphan %q: %w", title, err)
 		}
 		sc.remove(notebookID, src.ID)
+		hc.forget(title)
 		out.emit(event{Action: "delete", Name: title, OldID: src.ID, Reason: "orphan"})
 	}
 
+	if la != nil {
+		if err := applyLabels(ctx, la, notebookID, chunks, names, ids, p.rules, opts.DryRun, out); err != nil {
+			return fmt.Errorf("label: %w", err)
+		}
+	}
 	return nil
 }
 
-// uploadChunk uploads or replaces a single chunk. It is safe to call from
-// multiple goroutines because each chunk targets a unique remote name and
-// shared state is updated under mu.
-func uploadChunk(ctx context.Context, c Client, notebookID, chunkName string, data []byte, hash string, existing Source, exists bool, hc *hashCache, sc *sourceCache, out *outputWriter, mu *sync.Mutex) error {
+// uploadChunk uploads or replaces a single chunk and returns the new source
+// ID. It is safe to call from multiple goroutines because each chunk targets
+// a unique remote name and shared state is updated under mu.
+//
+// A replace is journaled before the rename and cleared only once the old
+// source is gone and the hash cache is updated, so an interruption at any
+// step leaves enough behind for recoverJournal to finish or undo it.
+func uploadChunk(ctx context.Context, c Client, notebookID, chunkName string, data []byte, hash string, existing Source, exists bool, hc *hashCache, sc *sourceCache, j *journal, out *outputWriter, mu *sync.Mutex) (string, error) {
 	if !exists {
 		newID, err := c.AddSource(ctx, notebookID, chunkName, strings.NewReader(string(data)))
 		if err != nil {
-			return fmt.Errorf("upload %q: %w", chunkName, err)
+			return "", fmt.Errorf("upload %q: %w", chunkName, err)
 		}
 		mu.Lock()
-		_ = hc.save(chunkName, hash)
+		_ = hc.save(chunkName, hash, newID)
 		sc.append(notebookID, Source{ID: newID, Title: chunkName})
 		out.emit(event{Action: "upload", Name: chunkName, SourceID: newID, Bytes: len(data)})
 		mu.Unlock()
-		return nil
+		return newID, nil
 	}
 
 	// Gap-free replacement: rename old → upload new → delete old.
-	oldName := chunkName + " [old]"
+	oldName := chunkName + oldSuffix
+	if err := j.begin(chunkName, journalEntry{OldID: existing.ID, PrevHash: hc.load(chunkName), Hash: hash}); err != nil {
+		return "", fmt.Errorf("write sync journal: %w", err)
+	}
 	if err := c.RenameSource(ctx, existing.ID, oldName); err != nil {
-		return fmt.Errorf("rename %q: %w", chunkName, err)
+		_ = j.finish(chunkName)
+		return "", fmt.Errorf("rename %q: %w", chunkName, err)
 	}
 
 	// Snapshot labels before delete; reattach after upload succeeds.
@@ -314,11 +458,18 @@ func uploadChunk(ctx context.Context, c Client, notebookID, chunkName string, da
 
 	newID, err := c.AddSource(ctx, notebookID, chunkName, strings.NewReader(string(data)))
 	if err != nil {
-		_ = c.RenameSource(ctx, existing.ID, chunkName)
-		return fmt.Errorf("upload %q: %w", chunkName, err)
+		// Keep the journal entry if the rename back fails too, so the
+		// next run (or --repair) restores the stranded "[old]" source.
+		if rerr := c.RenameSource(ctx, existing.ID, chunkName); rerr == nil {
+			_ = j.finish(chunkName)
+		}
+		return "", fmt.Errorf("upload %q: %w", chunkName, err)
 	}
+	_ = j.uploaded(chunkName, newID)
 
+	deleted := true
 	if err := c.DeleteSources(ctx, notebookID, []string{existing.ID}); err != nil {
+		deleted = false
 		fmt.Fprintf(os.Stderr, "warning: uploaded %s but failed to delete old %s: %v\n", newID, existing.ID, err)
 	}
 
@@ -338,12 +489,15 @@ func uploadChunk(ctx context.Context, c Client, notebookID, chunkName string, da
 	}
 
 	mu.Lock()
-	_ = hc.save(chunkName, hash)
+	_ = hc.save(chunkName, hash, newID)
+	if deleted {
+		_ = j.finish(chunkName)
+	}
 	sc.remove(notebookID, existing.ID)
 	sc.append(notebookID, Source{ID: newID, Title: chunkName})
 	out.emit(event{Action: "replace", Name: chunkName, SourceID: newID, OldID: existing.ID, Bytes: len(data)})
 	mu.Unlock()
-	return nil
+	return newID, nil
 }
 
 // resolveName determines the source name.
@@ -367,6 +521,27 @@ func resolveName(name string, paths []string) (string, error) {
 	return "", fmt.Errorf("--name is required when multiple paths or stdin are used")
 }
 
+// discoverFilesAt is discoverFiles for a git revision: with rev empty it
+// reads the working tree, otherwise every path is resolved at rev.
+// includeUntracked has no meaning for a commit and is ignored there.
+func discoverFilesAt(paths []string, rev string, includeUntracked bool) ([]discovered, error) {
+	if rev == "" {
+		return discoverFiles(paths, includeUntracked)
+	}
+	if paths == nil {
+		return nil, fmt.Errorf("--rev cannot read paths from stdin")
+	}
+	var files []discovered
+	for _, p := range paths {
+		got, err := gitRevFiles(p, rev)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, got...)
+	}
+	return files, nil
+}
+
 // discoverFiles expands paths into a flat list of discovered files. Each
 // entry carries both the on-disk path (for reading) and the member name
 // (used as the txtar entry name on the wire). Directories are expanded via
@@ -447,7 +622,7 @@ func bundle(files []discovered, maxBytes int, preProcess string) ([][]byte, erro
 	}
 
 	for _, f := range files {
-		data, err := os.ReadFile(f.Path)
+		data, err := f.read()
 		if err != nil {
 			return nil, fmt.Errorf("read %s: %w", f.Path, err)
 		}
@@ -723,6 +898,16 @@ func isPartOf(title, name string) bool {
 	return len(mid) > 0
 }
 
+// isPartOfAny reports whether title is a part of any of the base names.
+func isPartOfAny(title string, names []string) bool {
+	for _, name := range names {
+		if isPartOf(title, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // chunkNames returns the names for n chunks.
 // First chunk: "name", second: "name (pt2)", etc.
 func chunkNames(name string, n int) []string {
@@ -745,6 +930,7 @@ type event struct {
 	OldID    string `json:"old_id,omitempty"`
 	Bytes    int    `json:"bytes,omitempty"`
 	Reason   string `json:"reason,omitempty"`
+	Label    string `json:"label,omitempty"`
 	DryRun   bool   `json:"dry_run,omitempty"`
 }
 
@@ -782,5 +968,17 @@ func (o *outputWriter) emit(e event) {
 		} else {
 			fmt.Fprintf(os.Stderr, "  delete: %s %s (%s)\n", e.Name, e.OldID, e.Reason)
 		}
+	case "restore":
+		if e.DryRun {
+			fmt.Fprintf(os.Stderr, "  would restore: %s (%s)\n", e.Name, e.Reason)
+		} else {
+			fmt.Fprintf(os.Stderr, "  restore: %s %s (%s)\n", e.Name, e.SourceID, e.Reason)
+		}
+	case "label":
+		if e.DryRun {
+			fmt.Fprintf(os.Stderr, "  would label: %s += %s\n", e.Name, e.Label)
+		} else {
+			fmt.Fprintf(os.Stderr, "  label: %s += %s\n", e.Name, e.Label)
+		}
 	}
 }
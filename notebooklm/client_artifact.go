@@ -314,7 +314,11 @@ func (c *Client) downloadAuthed(ctx context.Context, fileURL string, w io.Writer
 	req.Header.Set("Sec-Fetch-Mode", "no-cors")
 	req.Header.Set("Sec-Fetch-Site", "cross-site")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/140.0.0.0 Safari/537.36")
-	cookies := c.rpc.Config.Cookies
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	cookies := creds.Cookies
 	if cookies != "" {
 		req.Header.Set("Cookie", cookies)
 	}
@@ -730,13 +730,17 @@ func (c *Client) downloadAudioFromURL(ctx context.Context, audioURL string) ([]b
 	req.Header.Set("Referer", "https://notebook.google.com/")
 
 	// Add authentication cookies from RPC client
-	if cookies := c.rpc.Config.Cookies; cookies != "" {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cookies := creds.Cookies; cookies != "" {
 		req.Header.Set("Cookie", cookies)
 	}
 
 	if c.config.Debug {
 		fmt.Printf("Full audio URL: %s\n", audioURL)
-		fmt.Printf("Using cookies: %v\n", c.rpc.Config.Cookies != "")
+		fmt.Printf("Using cookies: %v\n", creds.Cookies != "")
 	}
 
 	resp, err := client.Do(req)
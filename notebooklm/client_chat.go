@@ -353,7 +353,7 @@ func (c *Client) buildChatArgs(ctx context.Context, req ChatRequest) (string, er
 }
 
 // buildChatRequestBody builds the full HTTP form body for a chat request.
-func (c *Client) buildChatRequestBody(ctx context.Context, req ChatRequest) (string, error) {
+func (c *Client) buildChatRequestBody(ctx context.Context, req ChatRequest, authToken string) (string, error) {
 	innerJSON, err := c.buildChatArgs(ctx, req)
 	if err != nil {
 		return "", err
@@ -366,7 +366,6 @@ func (c *Client) buildChatRequestBody(ctx context.Context, req ChatRequest) (str
 	}
 
 	// Form body: f.req=<url-encoded-outer>&at=<auth-token>
-	authToken := c.rpc.Config.AuthToken
 	body := fmt.Sprintf("f.req=%s&at=%s",
 		url.QueryEscape(string(outerJSON)),
 		url.QueryEscape(authToken))
@@ -403,7 +402,13 @@ func (c *Client) doChat(ctx context.Context, req ChatRequest) (string, error) {
 
 // doChatStreamed sends a chat request and streams response chunks via callback.
 func (c *Client) doChatStreamed(ctx context.Context, req ChatRequest, callback func(chunk string) bool) error {
-	body, err := c.buildChatRequestBody(ctx, req)
+	return c.withCredentials(ctx, func(creds Credentials) error {
+		return c.sendChat(ctx, req, creds, callback)
+	})
+}
+
+func (c *Client) sendChat(ctx context.Context, req ChatRequest, creds Credentials, callback func(chunk string) bool) error {
+	body, err := c.buildChatRequestBody(ctx, req, creds.AuthToken)
 	if err != nil {
 		return err
 	}
@@ -429,12 +434,11 @@ func (c *Client) doChatStreamed(ctx context.Context, req ChatRequest, callback f
 	}
 
 	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=UTF-8")
-	c.setAuthHeaders(httpReq)
+	c.setAuthHeaders(httpReq, creds.Cookies)
 	// Required header for chat endpoint (observed in HAR capture)
 	httpReq.Header.Set("x-goog-ext-353267353-jspb", "[null,null,null,282611]")
 
-	client := httpClientWithTimeout(5 * time.Minute)
-	resp, err := client.Do(httpReq)
+	resp, err := c.streamClient().Do(httpReq)
 	if err != nil {
 		return fmt.Errorf("chat request: %w", err)
 	}
@@ -448,8 +452,7 @@ func (c *Client) doChatStreamed(ctx context.Context, req ChatRequest, callback f
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("chat request failed: %d %s: %s", resp.StatusCode, resp.Status, string(respBody)[:min(500, len(respBody))])
+		return chatStatusError(resp)
 	}
 
 	// Wrap body with idle timeout for streaming.
@@ -458,12 +461,27 @@ func (c *Client) doChatStreamed(ctx context.Context, req ChatRequest, callback f
 	return c.parseChatResponse(idleBody, callback)
 }
 
+// streamClient returns the client for chat stream requests: the one set
+// with WithStreamHTTPClient, or one with a 5-minute total timeout.
+func (c *Client) streamClient() *http.Client {
+	if c.config.streamClient != nil {
+		return c.config.streamClient
+	}
+	return httpClientWithTimeout(5 * time.Minute)
+}
+
 // doChatStreamedChunked sends a chat request and streams phase-aware ChatChunks via callback.
 func (c *Client) doChatStreamedChunked(ctx context.Context, req ChatRequest, callback func(ChatChunk) bool) error {
 	sourceIDs := c.resolveSourceIDs(ctx, req.ProjectID, req.SourceIDs)
 	req.SourceIDs = sourceIDs
+	return c.withCredentials(ctx, func(creds Credentials) error {
+		return c.sendChatChunked(ctx, req, creds, callback)
+	})
+}
 
-	body, err := c.buildChatRequestBody(ctx, req)
+func (c *Client) sendChatChunked(ctx context.Context, req ChatRequest, creds Credentials, callback func(ChatChunk) bool) error {
+	sourceIDs := req.SourceIDs
+	body, err := c.buildChatRequestBody(ctx, req, creds.AuthToken)
 	if err != nil {
 		return err
 	}
@@ -476,22 +494,20 @@ func (c *Client) doChatStreamedChunked(ctx context.Context, req ChatRequest, cal
 	}
 
 	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=UTF-8")
-	c.setAuthHeaders(httpReq)
+	c.setAuthHeaders(httpReq, creds.Cookies)
 	httpReq.Header.Set("x-goog-ext-353267353-jspb", "[null,null,null,282611]")
 
 	// Use a long total timeout for initial connection, but rely on
 	// idle timeout for the streaming body — the server may think for
 	// minutes before responding, but should send data regularly once started.
-	client := httpClientWithTimeout(5 * time.Minute)
-	resp, err := client.Do(httpReq)
+	resp, err := c.streamClient().Do(httpReq)
 	if err != nil {
 		return fmt.Errorf("chat request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("chat request failed: %d %s: %s", resp.StatusCode, resp.Status, string(respBody)[:min(500, len(respBody))])
+		return chatStatusError(resp)
 	}
 
 	// Wrap body with an idle timeout for blocked reads, then enforce a separate
@@ -829,6 +845,17 @@ func (c *Client) parseChatResponse(r io.Reader, callback func(chunk string) bool
 	return nil
 }
 
+// chatStatusError describes a chat response with a non-200 status. A 401
+// means the session expired, so it wraps ErrAuthExpired.
+func chatStatusError(resp *http.Response) error {
+	respBody, _ := io.ReadAll(resp.Body)
+	err := fmt.Errorf("chat request failed: %d %s: %s", resp.StatusCode, resp.Status, string(respBody)[:min(500, len(respBody))])
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w: %w", ErrAuthExpired, err)
+	}
+	return err
+}
+
 // chatAuthErrorCodes are the batchexecute dictionary codes that mean the stored
 // session is no longer valid. They appear in the gRPC-Web chat error frame as a
 // bare integer, e.g. 16 (Unauthenticated) or the legacy 277566/277567.
@@ -111,7 +111,11 @@ func (c *Client) startCustomizationUpload(ctx context.Context, projectID, displa
 	req.Header.Set("X-Goog-Upload-Protocol", "resumable")
 	req.Header.Set("X-Goog-Upload-Header-Content-Length", fmt.Sprintf("%d", contentLength))
 	setAuthUserHeader(req.Header, c.config.AuthUser)
-	if cookies := c.rpc.Config.Cookies; cookies != "" {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	if cookies := creds.Cookies; cookies != "" {
 		req.Header.Set("Cookie", cookies)
 	}
 	req.Header.Set("Referer", "https://notebook.google.com/")
@@ -614,7 +618,11 @@ func (c *Client) startResumableUpload(ctx context.Context, projectID, filename,
 
 	// Upload uses cookies only; no Authorization or X-Same-Domain headers.
 	// The current web upload init includes Origin and Referer.
-	if cookies := c.rpc.Config.Cookies; cookies != "" {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	if cookies := creds.Cookies; cookies != "" {
 		req.Header.Set("Cookie", cookies)
 	}
 	req.Header.Set("Origin", "https://notebook.google.com")
@@ -694,7 +702,11 @@ func (c *Client) uploadFileBytes(ctx context.Context, uploadURL string, content
 	setAuthUserHeader(req.Header, c.config.AuthUser)
 
 	// Upload uses cookies only — no Authorization header
-	if cookies := c.rpc.Config.Cookies; cookies != "" {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	if cookies := creds.Cookies; cookies != "" {
 		req.Header.Set("Cookie", cookies)
 	}
 	req.Header.Set("Referer", "https://notebook.google.com/")
@@ -764,8 +776,7 @@ func (c *Client) registerFileSource(ctx context.Context, projectID, filename str
 }
 
 // setAuthHeaders adds authentication headers to an HTTP request.
-func (c *Client) setAuthHeaders(req *http.Request) {
-	cookies := c.rpc.Config.Cookies
+func (c *Client) setAuthHeaders(req *http.Request, cookies string) {
 	if cookies != "" {
 		req.Header.Set("Cookie", cookies)
 	}
@@ -0,0 +1,181 @@
+package notebooklm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tmc/nlm/internal/batchexecute"
+)
+
+// CredentialProvider supplies the credentials for each request, so a
+// long-running client can pick up new credentials without being rebuilt.
+// Implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	// Credentials returns the credentials for the next request.
+	Credentials(ctx context.Context) (Credentials, error)
+	// Refresh is called after the server rejects rejected as expired. It
+	// returns the credentials to retry the request with. Returning
+	// rejected unchanged means there is nothing better to try, and the
+	// request fails.
+	Refresh(ctx context.Context, rejected Credentials) (Credentials, error)
+}
+
+// WithCredentialProvider makes the client ask p for credentials on every
+// request, in place of the credentials passed to New. A request that fails
+// with expired authentication is retried once with the credentials p
+// refreshes.
+func WithCredentialProvider(p CredentialProvider) Option {
+	return func(config *clientConfig) {
+		config.provider = p
+		config.batchOptions = append(config.batchOptions, batchexecute.WithCredentialSource(credentialSource{p}))
+	}
+}
+
+// credentialSource adapts a CredentialProvider to batchexecute.
+type credentialSource struct {
+	p CredentialProvider
+}
+
+func (s credentialSource) Credentials(ctx context.Context) (batchexecute.Credentials, error) {
+	creds, err := s.p.Credentials(ctx)
+	return batchexecute.Credentials(creds), err
+}
+
+func (s credentialSource) Refresh(ctx context.Context, rejected batchexecute.Credentials) (batchexecute.Credentials, error) {
+	creds, err := s.p.Refresh(ctx, Credentials(rejected))
+	return batchexecute.Credentials(creds), err
+}
+
+// DefaultRefreshInterval is how often RefreshingProvider.Run refreshes when
+// no Interval is set.
+const DefaultRefreshInterval = time.Hour
+
+// refreshRetryDelay is how long a failed refresh is reported again, rather
+// than retried, to requests that fail in the meantime.
+const refreshRetryDelay = time.Minute
+
+// RefreshingProvider is a CredentialProvider that refreshes its credentials
+// with a fetch function: periodically while Run is running, and when a
+// request fails authentication. Concurrent failures share one refresh, and
+// requests see either the old credentials or the new ones, never a mix.
+type RefreshingProvider struct {
+	// Interval is how often Run refreshes. Zero means
+	// DefaultRefreshInterval.
+	Interval time.Duration
+	// OnRefresh, if set, is called after each refresh with the new
+	// credentials or the error. Use it to persist or log them.
+	OnRefresh func(Credentials, error)
+
+	fetch   func(context.Context, Credentials) (Credentials, error)
+	current atomic.Pointer[Credentials]
+
+	mu       sync.Mutex // serializes refreshes
+	failure  error
+	failedAt time.Time
+}
+
+// NewRefreshingProvider returns a provider that starts with initial and
+// obtains new credentials by calling fetch with the current ones.
+func NewRefreshingProvider(initial Credentials, fetch func(ctx context.Context, current Credentials) (Credentials, error)) *RefreshingProvider {
+	p := &RefreshingProvider{fetch: fetch}
+	p.current.Store(&initial)
+	return p
+}
+
+// Credentials returns the current credentials.
+func (p *RefreshingProvider) Credentials(context.Context) (Credentials, error) {
+	return *p.current.Load(), nil
+}
+
+// Refresh fetches new credentials unless another caller already replaced
+// rejected. A failed fetch is not retried for a minute; requests failing
+// in that time get the same error.
+func (p *RefreshingProvider) Refresh(ctx context.Context, rejected Credentials) (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current := *p.current.Load()
+	if current != rejected {
+		return current, nil
+	}
+	if p.failure != nil && time.Since(p.failedAt) < refreshRetryDelay {
+		return current, p.failure
+	}
+	return p.refreshLocked(ctx, current)
+}
+
+// Run refreshes the credentials every Interval until ctx is done. A failed
+// refresh leaves the current credentials in place.
+func (p *RefreshingProvider) Run(ctx context.Context) {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.refreshLocked(ctx, *p.current.Load())
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *RefreshingProvider) refreshLocked(ctx context.Context, current Credentials) (Credentials, error) {
+	fresh, err := p.fetch(ctx, current)
+	if err == nil && (fresh.AuthToken == "" || fresh.Cookies == "") {
+		err = errors.New("refresh returned incomplete credentials")
+	}
+	if p.OnRefresh != nil {
+		p.OnRefresh(fresh, err)
+	}
+	if err != nil {
+		p.failure, p.failedAt = err, time.Now()
+		return current, err
+	}
+	p.failure = nil
+	p.current.Store(&fresh)
+	return fresh, nil
+}
+
+// credentials returns the credentials for a request made outside
+// batchexecute.
+func (c *Client) credentials(ctx context.Context) (Credentials, error) {
+	if c.config.provider == nil {
+		return Credentials{AuthToken: c.rpc.Config.AuthToken, Cookies: c.rpc.Config.Cookies}, nil
+	}
+	creds, err := c.config.provider.Credentials(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("get credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// withCredentials runs send with the client's credentials. When send fails
+// with ErrAuthExpired and a CredentialProvider is set, it runs send once
+// more with the credentials the provider refreshes.
+func (c *Client) withCredentials(ctx context.Context, send func(Credentials) error) error {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	err = send(creds)
+	if c.config.provider == nil || !errors.Is(err, ErrAuthExpired) {
+		return err
+	}
+	fresh, rerr := c.config.provider.Refresh(ctx, creds)
+	if rerr != nil {
+		return fmt.Errorf("%w (refresh credentials: %v)", err, rerr)
+	}
+	if fresh == creds {
+		return err
+	}
+	return send(fresh)
+}
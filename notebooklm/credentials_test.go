@@ -0,0 +1,120 @@
+package notebooklm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRefreshingProviderSharesRefresh(t *testing.T) {
+	var fetches atomic.Int32
+	p := NewRefreshingProvider(Credentials{AuthToken: "old", Cookies: "SID=1"}, func(_ context.Context, current Credentials) (Credentials, error) {
+		n := fetches.Add(1)
+		return Credentials{AuthToken: fmt.Sprintf("new%d", n), Cookies: current.Cookies}, nil
+	})
+	var saved []Credentials
+	p.OnRefresh = func(creds Credentials, err error) {
+		if err == nil {
+			saved = append(saved, creds)
+		}
+	}
+	ctx := context.Background()
+	rejected, _ := p.Credentials(ctx)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := p.Refresh(ctx, rejected)
+			if err != nil || got.AuthToken != "new1" {
+				t.Errorf("Refresh = %+v, %v; want new1", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want 1", n)
+	}
+	if len(saved) != 1 || saved[0].AuthToken != "new1" {
+		t.Errorf("OnRefresh saw %+v", saved)
+	}
+	if got, _ := p.Credentials(ctx); got.AuthToken != "new1" {
+		t.Errorf("Credentials after refresh = %+v", got)
+	}
+}
+
+func TestRefreshingProviderHoldsFailure(t *testing.T) {
+	var fetches int
+	errDenied := errors.New("signed out")
+	p := NewRefreshingProvider(Credentials{AuthToken: "old", Cookies: "SID=1"}, func(context.Context, Credentials) (Credentials, error) {
+		fetches++
+		return Credentials{}, errDenied
+	})
+	ctx := context.Background()
+	current, _ := p.Credentials(ctx)
+	for range 3 {
+		got, err := p.Refresh(ctx, current)
+		if !errors.Is(err, errDenied) || got != current {
+			t.Errorf("Refresh = %+v, %v; want the current credentials and %v", got, err, errDenied)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetched %d times after a failure, want 1", fetches)
+	}
+}
+
+func TestChatRetriesWithRefreshedCredentials(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		if r.Form.Get("at") != "fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got := r.Header.Get("Cookie"); got != "SID=1" {
+			t.Errorf("Cookie = %q, want SID=1", got)
+		}
+	}))
+	defer server.Close()
+
+	p := NewRefreshingProvider(Credentials{AuthToken: "stale", Cookies: "SID=1"}, func(_ context.Context, current Credentials) (Credentials, error) {
+		return Credentials{AuthToken: "fresh", Cookies: current.Cookies}, nil
+	})
+	c := New(Credentials{}, WithCredentialProvider(p))
+
+	var sent []Credentials
+	err := c.withCredentials(context.Background(), func(creds Credentials) error {
+		sent = append(sent, creds)
+		req, _ := http.NewRequest("POST", server.URL, strings.NewReader("at="+creds.AuthToken))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		c.setAuthHeaders(req, creds.Cookies)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return chatStatusError(resp)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withCredentials: %v", err)
+	}
+	if len(sent) != 2 || sent[0].AuthToken != "stale" || sent[1].AuthToken != "fresh" {
+		t.Errorf("sent %+v, want stale then fresh", sent)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server saw %d requests, want 2", n)
+	}
+}
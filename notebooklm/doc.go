@@ -5,7 +5,11 @@
 // methods. Construct a client with New; the zero value is not usable.
 //
 // Credentials are NotebookLM web-session values. This package does not launch
-// a browser or refresh a session; callers own the credential lifecycle.
+// a browser or sign in; callers own the credential lifecycle. A long-running
+// client can take its credentials from a CredentialProvider, which the client
+// consults on every request and asks to refresh after an authentication
+// failure. RefreshingProvider refreshes with a caller-supplied function, both
+// periodically and on demand.
 //
 // NotebookLM does not publish a supported service API. This package uses the
 // same private RPCs as the web application, so callers should expect service
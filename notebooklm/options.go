@@ -21,7 +21,9 @@ type clientConfig struct {
 	UseDirectRPC      bool
 	SkipSources       bool
 	AuthUser          string
+	provider          CredentialProvider
 	batchOptions      []batchexecute.Option
+	streamClient      *http.Client
 }
 
 // Option configures a Client.
@@ -70,6 +72,16 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithStreamHTTPClient sets the HTTP client used for the chat stream, such
+// as a recording transport. The default client allows 5 minutes for the
+// whole response, since the server may think for minutes before answering;
+// a client with a shorter Timeout cuts long answers off.
+func WithStreamHTTPClient(client *http.Client) Option {
+	return func(config *clientConfig) {
+		config.streamClient = client
+	}
+}
+
 // WithURLParams adds URL parameters to batchexecute requests.
 func WithURLParams(params map[string]string) Option {
 	return func(config *clientConfig) {
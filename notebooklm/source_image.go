@@ -30,7 +30,11 @@ func (c *Client) DownloadSourceImage(ctx context.Context, imageURL string) ([]by
 	setChromeClientHints(req.Header)
 	req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8")
 	req.Header.Set("Referer", "https://notebook.google.com/")
-	if cookies := c.rpc.Config.Cookies; cookies != "" {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if cookies := creds.Cookies; cookies != "" {
 		req.Header.Set("Cookie", cookies)
 	}
 